@@ -0,0 +1,74 @@
+// dataimport นำเข้า/อัปเดตสถานที่จากไฟล์ Excel ใน config/ (places_data_3, Attraction_data_4, rharn)
+// เทียบแถวกับฐานข้อมูลด้วย PlaceID เขียนเฉพาะแถวที่เปลี่ยน และแตะ PostGIS เฉพาะจุดที่ย้าย
+//
+//	go run ./cmd/dataimport             // import จริง (แถวที่หายจากไฟล์ถูก soft delete)
+//	go run ./cmd/dataimport -dry-run    // ดูรายงานอย่างเดียว
+//	go run ./cmd/dataimport -force      // parse ทุกแถวใหม่ (เช่น หลังแก้ตัวแปลงราคา/เวลา)
+//	go run ./cmd/dataimport -prune=false
+//	go run ./cmd/dataimport -profile my_hotels.json   // ไฟล์/คอลัมน์ตาม profile เอง (ดู config/profiles/)
+//
+// server ไม่โหลด Excel ตอนเริ่มแล้ว ฐานข้อมูลใหม่ต้องรันคำสั่งนี้หนึ่งครั้ง
+// รันจากโฟลเดอร์ backend (ใช้การเชื่อมต่อฐานข้อมูลเดียวกับ server)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "ไม่เขียนฐานข้อมูล แสดงรายงานอย่างเดียว")
+	force := flag.Bool("force", false, "เขียนทุกแถวใหม่แม้ไม่เปลี่ยน")
+	prune := flag.Bool("prune", true, "soft delete สถานที่ที่เคย import จากไฟล์นี้แต่ไม่มีในไฟล์แล้ว (ไม่แตะแถวที่ admin อัปโหลด)")
+	maxErrors := flag.Int("max-errors", 20, "จำนวนแถวที่ผิด/คำเตือนที่แสดงต่อไฟล์ (0 = ทั้งหมด)")
+	profileList := flag.String("profile", "", "ไฟล์ profile คั่นด้วย comma (ว่าง = ทั้งสามไฟล์ใน config/ ตาม profile มาตรฐาน)")
+	flag.Parse()
+
+	var profiles []config.Profile
+	for _, path := range strings.Split(*profileList, ",") {
+		if path = strings.TrimSpace(path); path == "" {
+			continue
+		}
+		p, err := config.LoadProfile(path)
+		if err != nil {
+			log.Fatalf("❌ %s: %v", path, err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	config.ConnectionDB()
+	config.SetupDatabase()
+
+	t := time.Now()
+	results, err := config.ImportExcelData(config.ImportOptions{DryRun: *dryRun, Force: *force, Prune: *prune}, profiles...)
+	for _, r := range results {
+		fmt.Printf("%s (%s): %d แถว → ใหม่ %d, แก้ %d, เท่าเดิม %d, ลบ %d | GIS: ใหม่ %d, ย้าย %d, ลบ %d\n",
+			r.Kind, r.Source, r.Rows, r.Created, r.Updated, r.Unchanged, r.Deleted,
+			r.GisCreated, r.GisMoved, r.GisDeleted)
+		printRows("❌ ข้าม", r.Errors, *maxErrors)
+		printRows("⚠️  เตือน", r.Warnings, *maxErrors)
+	}
+	if err != nil {
+		log.Fatalf("❌ import ไม่สำเร็จ: %v", err)
+	}
+	if *dryRun {
+		fmt.Println("(dry-run: ยังไม่ได้เขียนฐานข้อมูล)")
+		return
+	}
+	fmt.Printf("✅ import เสร็จ (%s)\n", time.Since(t).Round(time.Millisecond))
+}
+
+func printRows(label string, rows []config.RowError, limit int) {
+	for i, e := range rows {
+		if limit > 0 && i == limit {
+			fmt.Printf("   ... อีก %d แถว\n", len(rows)-i)
+			return
+		}
+		fmt.Printf("   %s แถว %d: %s\n", label, e.Line, e.Message)
+	}
+}
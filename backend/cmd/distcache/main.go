@@ -0,0 +1,61 @@
+// distcache จัดการแคชระยะระหว่างสถานที่ (ตาราง distance_cache)
+//
+//	go run ./cmd/distcache -warm              // คำนวณทุกคู่ล่วงหน้า
+//	go run ./cmd/distcache -warm -max-km 50   // เฉพาะคู่ที่ห่างไม่เกิน 50 km (ที่เหลือเติมแบบ lazy)
+//	go run ./cmd/distcache -reconcile         // ล้างคู่ของสถานที่ที่พิกัดเปลี่ยน
+//	go run ./cmd/distcache -clear
+//
+// รันจากโฟลเดอร์ backend (ใช้การเชื่อมต่อฐานข้อมูลเดียวกับ server)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+func main() {
+	warm := flag.Bool("warm", false, "คำนวณระยะทุกคู่ล่วงหน้า")
+	maxKm := flag.Float64("max-km", 0, "ใช้กับ -warm: เฉพาะคู่ที่ห่างไม่เกินนี้ (0 = ทุกคู่)")
+	reconcile := flag.Bool("reconcile", false, "ล้างแคชของสถานที่ที่พิกัดไม่ตรงกับตาราง GIS")
+	clear := flag.Bool("clear", false, "ล้างแคชทั้งหมด")
+	flag.Parse()
+
+	if !*warm && !*reconcile && !*clear {
+		flag.Usage()
+		log.Fatal("ต้องระบุ -warm, -reconcile หรือ -clear")
+	}
+
+	config.ConnectionDB()
+	db := config.PGDB()
+	if err := db.AutoMigrate(&entity.DistanceCache{}, &entity.DistanceCachePoint{}); err != nil {
+		log.Fatalf("❌ สร้างตารางแคชไม่สำเร็จ: %v", err)
+	}
+
+	if *clear {
+		if err := distcache.Clear(db); err != nil {
+			log.Fatalf("❌ ล้างแคชไม่สำเร็จ: %v", err)
+		}
+		fmt.Println("✅ ล้างแคชแล้ว")
+	}
+	if *reconcile {
+		n, err := distcache.Reconcile(db)
+		if err != nil {
+			log.Fatalf("❌ ตรวจแคชไม่สำเร็จ: %v", err)
+		}
+		fmt.Printf("✅ ล้างแคชของสถานที่ที่พิกัดเปลี่ยน %d แห่ง\n", n)
+	}
+	if *warm {
+		t := time.Now()
+		n, err := distcache.Warm(db, *maxKm)
+		if err != nil {
+			log.Fatalf("❌ คำนวณแคชไม่สำเร็จ: %v", err)
+		}
+		fmt.Printf("✅ บันทึก %d คู่ (%s)\n", n, time.Since(t).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,132 @@
+// graphcheck เทียบผลของตัวคำนวณกราฟแบบ pgRouting กับแบบ Go (package graphalgo) บนข้อมูลจริง
+// ต้องรันกับฐานข้อมูลที่มี pgRouting; ผลไม่ตรง → exit 1
+//
+//	go run ./cmd/graphcheck -root P29
+//	go run ./cmd/graphcheck -root P29 -kinds PRA -k 10
+//
+// รันจากโฟลเดอร์ backend (ใช้การเชื่อมต่อฐานข้อมูลเดียวกับ server)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+	"github.com/gtwndtl/trip-spark-builder/controller/Distance"
+)
+
+func main() {
+	root := flag.String("root", "", "landmark ที่ใช้เป็น root เช่น P29")
+	kinds := flag.String("kinds", "P", "ประเภทโหนดของ min-cut/MST (P, PR, PRA)")
+	k := flag.Int("k", 20, "เพื่อนบ้านต่อโหนดของกราฟ KNN")
+	nTop := flag.Int("n-top", 40, "ขนาด zoneA ของ min-cut")
+	maxDist := flag.Float64("distance", 100000, "max_distance ของ MST (เมตร)")
+	flag.Parse()
+
+	rootID, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(*root), "P"))
+	if err != nil || rootID <= 0 {
+		flag.Usage()
+		log.Fatal("ต้องระบุ -root เป็น landmark เช่น P29")
+	}
+
+	config.ConnectionDB()
+	pgr := Distance.NewDistanceController(config.DB(), config.PGDB())
+	pgr.GraphBackend = Distance.BackendPgRouting
+	gog := Distance.NewDistanceController(config.DB(), config.PGDB())
+	gog.GraphBackend = Distance.BackendGo
+
+	ok := true
+	check := func(name string, same bool, detail string) {
+		mark := "✅"
+		if !same {
+			mark, ok = "❌", false
+		}
+		fmt.Printf("%s %-12s %s\n", mark, name, detail)
+	}
+
+	// 1) connected components
+	cp, err1 := pgr.ComponentsHealth(2000, 10)
+	cg, err2 := gog.ComponentsHealth(2000, 10)
+	if err := firstErr(err1, err2); err != nil {
+		log.Fatalf("❌ components: %v", err)
+	}
+	check("components", cp.Components == cg.Components,
+		fmt.Sprintf("pgrouting=%d go=%d", cp.Components, cg.Components))
+
+	// 2) min-cut: max-flow เท่ากัน และฝั่ง source เหมือนกัน
+	mq := Distance.MinCutQuery{Root: "P" + strconv.Itoa(rootID), K: *k, NTop: *nTop, Kinds: *kinds}
+	mp, err1 := pgr.MinCut(mq)
+	mg, err2 := gog.MinCut(mq)
+	if err := firstErr(err1, err2); err != nil {
+		log.Fatalf("❌ min-cut: %v", err)
+	}
+	check("max-flow", mp.TotalCapacity == mg.TotalCapacity,
+		fmt.Sprintf("pgrouting=%d go=%d", mp.TotalCapacity, mg.TotalCapacity))
+	check("cut sides", sameSet(mp.SourceSide, mg.SourceSide),
+		fmt.Sprintf("source side pgrouting=%d go=%d โหนด, cut %d/%d เส้น",
+			len(mp.SourceSide), len(mg.SourceSide), len(mp.CutEdges), len(mg.CutEdges)))
+
+	// 3) MST: ชุดเส้นของต้นไม้และ agg_cost ต่อโหนดเหมือนกัน (ลำดับ DFS ไม่จำเป็นต้องตรง)
+	sq := Distance.MSTByFlowQuery{Root: rootID, K: *k, KMst: *k, NTop: *nTop, Kinds: *kinds, MaxDist: *maxDist}
+	sp, err1 := pgr.MSTByFlow(sq)
+	sg, err2 := gog.MSTByFlow(sq)
+	if err := firstErr(err1, err2); err != nil {
+		log.Fatalf("❌ MST: %v", err)
+	}
+	tp, ap := treeOf(sp.MST)
+	tg, ag := treeOf(sg.MST)
+	check("mst edges", sameSet(tp, tg), fmt.Sprintf("pgrouting=%d go=%d เส้น", len(tp), len(tg)))
+	maxDiff := 0.0
+	for n, a := range ap {
+		if b, found := ag[n]; found {
+			maxDiff = math.Max(maxDiff, math.Abs(a-b))
+		} else {
+			maxDiff = math.Inf(1)
+		}
+	}
+	check("mst agg_cost", len(ap) == len(ag) && maxDiff < 0.01, fmt.Sprintf("ต่างสูงสุด %.4f m", maxDiff))
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+func firstErr(errs ...error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// treeOf เส้นของต้นไม้ในรูป "a-b" (เรียง a < b) และ agg_cost ของแต่ละโหนด
+func treeOf(rows []Distance.MSTRow) ([]string, map[string]float64) {
+	var edges []string
+	agg := map[string]float64{}
+	for _, r := range rows {
+		agg[r.Code] = r.AggCost
+		if r.PredCode == "" {
+			continue
+		}
+		a, b := r.PredCode, r.Code
+		if b < a {
+			a, b = b, a
+		}
+		edges = append(edges, a+"-"+b)
+	}
+	return edges, agg
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
@@ -0,0 +1,74 @@
+// reconcile ตรวจว่าสถานที่ใน SQLite กับจุด/ประเภทใน PostGIS ตรงกันหรือไม่
+// (จุดกำพร้า, สถานที่ไม่มีจุด, พิกัดไม่ตรง, pivot ประเภทไม่ตรง) พบปัญหาแต่ไม่ได้ซ่อม → exit 1
+//
+//	go run ./cmd/reconcile            // รายงานอย่างเดียว
+//	go run ./cmd/reconcile -repair    // ซ่อมตามรายงาน (ยึด SQLite เป็นหลัก)
+//	go run ./cmd/reconcile -max 0     // แสดงทุกรายการ
+//
+// รันจากโฟลเดอร์ backend (ใช้การเชื่อมต่อฐานข้อมูลเดียวกับ server)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+)
+
+func main() {
+	repair := flag.Bool("repair", false, "ซ่อมสิ่งที่พบ")
+	limit := flag.Int("max", 20, "จำนวนรายการที่แสดงต่อหมวด (0 = ทั้งหมด)")
+	flag.Parse()
+
+	config.ConnectionDB()
+	rep, err := config.Reconcile(*repair)
+	if rep.TypesMissing > 0 {
+		fmt.Printf("travel_types: PG ขาด %d ประเภท\n", rep.TypesMissing)
+	}
+	for _, k := range rep.Kinds {
+		fmt.Printf("%s: SQLite %d แห่ง, GIS %d จุด | กำพร้า %d, ไม่มีจุด %d, พิกัดไม่ตรง %d | pivot ขาด %d, เกิน %d\n",
+			k.Kind, k.Places, k.Points, len(k.Orphans), len(k.MissingPoints), len(k.Mismatched),
+			k.PivotMissing, k.PivotExtra)
+		printIssues("กำพร้า", k.Orphans, *limit)
+		printIssues("ไม่มีจุด", k.MissingPoints, *limit)
+		printIssues("ไม่ตรง", k.Mismatched, *limit)
+	}
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	switch {
+	case rep.Clean():
+		fmt.Println("✅ SQLite กับ PostGIS ตรงกัน")
+	case rep.Repaired:
+		fmt.Println("✅ ซ่อมแล้ว (รันอีกครั้งเพื่อตรวจซ้ำ)")
+	default:
+		fmt.Println("❌ ข้อมูลไม่ตรงกัน (ใช้ -repair เพื่อซ่อม)")
+		os.Exit(1)
+	}
+}
+
+func printIssues(label string, issues []config.GisIssue, limit int) {
+	for i, it := range issues {
+		if limit > 0 && i == limit {
+			fmt.Printf("   ... อีก %d รายการ\n", len(issues)-i)
+			return
+		}
+		line := fmt.Sprintf("   %s %s", label, it.Code)
+		if it.Name != "" {
+			line += fmt.Sprintf(" %q", it.Name)
+		}
+		if it.Lat != 0 || it.Lon != 0 {
+			line += fmt.Sprintf(" SQLite(%.6f, %.6f)", it.Lat, it.Lon)
+		}
+		if it.GisLat != 0 || it.GisLon != 0 {
+			line += fmt.Sprintf(" GIS(%.6f, %.6f)", it.GisLat, it.GisLon)
+		}
+		if it.Note != "" {
+			line += " — " + it.Note
+		}
+		fmt.Println(line)
+	}
+}
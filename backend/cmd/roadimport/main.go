@@ -0,0 +1,63 @@
+// roadimport นำเข้าโครงข่ายถนนจากไฟล์ OSM ลง PostGIS (ตาราง ways สำหรับ pgRouting)
+// แล้ว snap สถานที่ทั้งหมดเข้ากับโครงข่าย
+//
+//	go run ./cmd/roadimport -file thailand-north.osm.pbf
+//	go run ./cmd/roadimport -file roads.geojson -snap-m 500
+//	go run ./cmd/roadimport -snap-only          // snap ใหม่หลังเพิ่ม/ย้ายสถานที่
+//
+// รันจากโฟลเดอร์ backend (ใช้การเชื่อมต่อฐานข้อมูลเดียวกับ server)
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+	"github.com/gtwndtl/trip-spark-builder/roads"
+)
+
+func main() {
+	file := flag.String("file", "", "ไฟล์ถนน .osm.pbf หรือ .geojson")
+	snapM := flag.Float64("snap-m", roads.DefaultSnapM, "ระยะ snap สูงสุด (เมตร)")
+	snapOnly := flag.Bool("snap-only", false, "ไม่ import ถนน snap สถานที่ใหม่อย่างเดียว")
+	flag.Parse()
+
+	if *file == "" && !*snapOnly {
+		flag.Usage()
+		log.Fatal("ต้องระบุ -file หรือ -snap-only")
+	}
+
+	config.ConnectionDB()
+	db := config.PGDB()
+
+	if !*snapOnly {
+		t := time.Now()
+		ex, err := roads.ReadFile(*file)
+		if err != nil {
+			log.Fatalf("❌ อ่านไฟล์ไม่สำเร็จ: %v", err)
+		}
+		net := ex.Build()
+		fmt.Printf("อ่าน %d ways → %d เส้นย่อย, %d ทางแยก (%s)\n",
+			len(ex.Ways), len(net.Edges), len(net.Vertices), time.Since(t).Round(time.Millisecond))
+		if len(net.Edges) == 0 {
+			log.Fatal("❌ ไม่พบถนนในไฟล์")
+		}
+
+		t = time.Now()
+		if err := roads.Load(db, net); err != nil {
+			log.Fatalf("❌ บันทึกถนนไม่สำเร็จ: %v", err)
+		}
+		fmt.Printf("✅ บันทึกตาราง ways แล้ว (%s)\n", time.Since(t).Round(time.Millisecond))
+	}
+
+	n, err := roads.Snap(db, *snapM)
+	if err != nil {
+		log.Fatalf("❌ snap สถานที่ไม่สำเร็จ: %v", err)
+	}
+	var total int64
+	db.Raw(`SELECT (SELECT COUNT(*) FROM landmark_gis) + (SELECT COUNT(*) FROM restaurant_gis)
+		+ (SELECT COUNT(*) FROM accommodation_gis)`).Scan(&total)
+	fmt.Printf("✅ snap สถานที่ได้ %d จาก %d แห่ง (ภายใน %.0f m)\n", n, total, *snapM)
+}
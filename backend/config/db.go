@@ -4,17 +4,16 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"regexp"
-	"strconv"
 	"strings"
 	"time"
 
-	"github.com/xuri/excelize/v2"
 	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormLogger "gorm.io/gorm/logger"
 
+	"github.com/gtwndtl/trip-spark-builder/domain"
 	"github.com/gtwndtl/trip-spark-builder/entity"
 )
 
@@ -77,6 +76,7 @@ func SetupDatabase() {
 		&entity.LandmarkType{},
 		&entity.RestaurantType{},
 		&entity.AccommodationType{},
+		&entity.GenJob{},
 	); err != nil {
 		panic(err)
 	}
@@ -86,6 +86,8 @@ func SetupDatabase() {
 		&entity.AccommodationGis{},
 		&entity.LandmarkGis{},
 		&entity.RestaurantGis{},
+		&entity.DistanceCache{},
+		&entity.DistanceCachePoint{},
 		&entity.TravelType{},
 		&entity.LandmarkType{},
 		&entity.RestaurantType{},
@@ -127,73 +129,73 @@ func SetupDatabase() {
 }
 
 // ------------------------------
-// Price parser
+// Excel -> Types helpers
 // ------------------------------
-var reDigits = regexp.MustCompile(`\d[\d,]*`)
-
-func ParsePriceRange(s string) (int, int) {
-	if s == "" {
-		return 0, 0
-	}
-	t := strings.ToLower(strings.TrimSpace(s))
-	t = strings.NewReplacer("–", "-", "—", "-", "−", "-").Replace(t)
-
-	nums := reDigits.FindAllString(t, -1)
-	toInt := func(x string) int {
-		x = strings.ReplaceAll(x, ",", "")
-		n, _ := strconv.Atoi(x)
-		return n
-	}
-	hasFree := strings.Contains(t, "ฟรี") || strings.Contains(t, "free")
-
-	if len(nums) == 0 {
-		if hasFree {
-			return 0, 0
+func headerIndex(headers []string, candidates ...string) int {
+	for i, h := range headers {
+		hl := strings.ToLower(strings.TrimSpace(h))
+		for _, c := range candidates {
+			if hl == strings.ToLower(c) {
+				return i
+			}
 		}
-		return 0, 0
 	}
+	return -1
+}
 
-	minV, maxV := int(^uint(0)>>1), 0
-	for _, ns := range nums {
-		v := toInt(ns)
-		if v < minV {
-			minV = v
-		}
-		if v > maxV {
-			maxV = v
-		}
+// cellAt อ่านค่าช่องตาม index (ไม่มีคอลัมน์/แถวสั้น → "")
+func cellAt(row []string, idx int) string {
+	if idx < 0 || idx >= len(row) {
+		return ""
 	}
-	if minV == int(^uint(0)>>1) {
-		minV = 0
+	return strings.TrimSpace(row[idx])
+}
+
+// openingHours แปลงคอลัมน์เวลาเปิด-ปิดดิบเป็น domain.Hours
+// พร้อมคืนเวลาเปิด/ปิดรอบแรกไว้เก็บลง Time_open/Time_close (ไม่มีข้อมูล → zero time)
+// ส่วนที่อ่านไม่ออกเก็บเป็นคำเตือนของแถว
+func openingHours(r *importRow, open, close, days, open2, close2 string) (domain.Hours, time.Time, time.Time) {
+	h, err := domain.ParseHours(open, close, days, open2, close2)
+	if err != nil {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("เวลาเปิด-ปิด: %v", err))
 	}
-	if hasFree {
-		if maxV == 0 {
-			return 0, 0
+	for _, ws := range h.Week {
+		if len(ws) > 0 {
+			return h, domain.ClockTime(ws[0].Open), domain.ClockTime(ws[0].Close)
 		}
-		return 0, maxV
 	}
-	if len(nums) == 1 {
-		return maxV, maxV
+	return h, time.Time{}, time.Time{}
+}
+
+// priceInfo แปลงราคาดิบ (+ ราคาเด็กถ้ามี) เป็น domain.Price; อ่านไม่ออกเตือนแล้วใช้ค่าที่อ่านได้
+func priceInfo(r *importRow, raw, child string, unit domain.PriceUnit) domain.Price {
+	p, err := domain.ParsePriceTiers(raw, child, unit)
+	if err != nil {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("ราคา: %v", err))
 	}
-	if minV > maxV {
-		minV, maxV = maxV, minV
+	return p
+}
+
+// capacityInfo แปลงจำนวนคน (+ จำนวนห้องของที่พัก) เป็น domain.Capacity
+func capacityInfo(r *importRow, people, rooms string) domain.Capacity {
+	c, err := domain.ParseCapacity(people, rooms)
+	if err != nil {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("จำนวนคน: %v", err))
 	}
-	return minV, maxV
+	return c
 }
 
-// ------------------------------
-// Excel -> Types helpers
-// ------------------------------
-func headerIndex(headers []string, candidates ...string) int {
-	for i, h := range headers {
-		hl := strings.ToLower(strings.TrimSpace(h))
-		for _, c := range candidates {
-			if hl == strings.ToLower(c) {
-				return i
-			}
-		}
+// clockOf เวลาแบบ "14:00" → time ของวันศูนย์ (ว่าง/อ่านไม่ออก → zero time, อ่านไม่ออกเตือน)
+func clockOf(r *importRow, label, s string) time.Time {
+	if s == "" {
+		return time.Time{}
 	}
-	return -1
+	m, _, err := domain.ParseClock(s)
+	if err != nil || m < 0 {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("%s %q อ่านไม่ออก", label, s))
+		return time.Time{}
+	}
+	return domain.ClockTime(m)
 }
 
 func splitTypes(s string) []string {
@@ -228,7 +230,8 @@ func slug(s string) string { return strings.TrimSpace(s) }
 // ------------------------------
 var typeCache = map[string]uint{} // key = kind + "|" + lower(code)
 
-func upsertType(kind, name string) uint {
+// upsertType หา/สร้าง travel_types ด้วย db ที่ส่งมา (ใช้ tx ของ import ได้ ไม่ชนล็อก SQLite)
+func upsertType(db *gorm.DB, kind, name string) uint {
 	code := slug(name)
 	cacheKey := kind + "|" + strings.ToLower(code)
 	if id, ok := typeCache[cacheKey]; ok {
@@ -237,17 +240,17 @@ func upsertType(kind, name string) uint {
 
 	var tt entity.TravelType
 	// หาโดย (kind, code) ตาม composite-unique
-	if err := dbSqlite.Where("kind = ? AND code = ?", kind, code).First(&tt).Error; err == nil {
+	if err := db.Where("kind = ? AND code = ?", kind, code).First(&tt).Error; err == nil {
 		typeCache[cacheKey] = tt.ID
 		return tt.ID
 	}
 
 	tt = entity.TravelType{Code: code, Name: name, Kind: kind}
-	if err := dbSqlite.Create(&tt).Error; err != nil {
+	if err := db.Create(&tt).Error; err != nil {
 		// กัน race: ถ้าซ้ำเพราะ unique ให้ไป select ซ้ำ
 		l := strings.ToLower(err.Error())
 		if strings.Contains(l, "unique") || strings.Contains(l, "constraint") {
-			if err2 := dbSqlite.Where("kind = ? AND code = ?", kind, code).First(&tt).Error; err2 == nil {
+			if err2 := db.Where("kind = ? AND code = ?", kind, code).First(&tt).Error; err2 == nil {
 				typeCache[cacheKey] = tt.ID
 				return tt.ID
 			}
@@ -258,21 +261,23 @@ func upsertType(kind, name string) uint {
 	return tt.ID
 }
 
-func linkLandmarkType(landmarkID uint, typeName string) {
-	typeID := upsertType("landmark", typeName)
-	dbSqlite.Create(&entity.LandmarkType{LandmarkID: landmarkID, TypeID: typeID})
-}
-func linkRestaurantType(rid uint, typeName string) {
-	typeID := upsertType("restaurant", typeName)
-	dbSqlite.Create(&entity.RestaurantType{RestaurantID: rid, TypeID: typeID})
-}
-func linkAccommodationType(aid uint, typeName string) {
-	typeID := upsertType("accommodation", typeName)
-	dbSqlite.Create(&entity.AccommodationType{AccommodationID: aid, TypeID: typeID})
+// linkTypes ผูกประเภทให้สถานที่ (ลบของเดิมก่อน ใช้ได้ทั้งแถวใหม่และแถวที่แก้)
+func linkTypes(db *gorm.DB, spec importSpec, placeID uint, names []string) error {
+	if err := db.Exec("DELETE FROM "+spec.PivotTable+" WHERE "+spec.PivotCol+" = ?", placeID).Error; err != nil {
+		return err
+	}
+	for _, name := range names {
+		typeID := upsertType(db, spec.Kind, name)
+		if err := db.Exec("INSERT INTO "+spec.PivotTable+" ("+spec.PivotCol+", type_id) VALUES (?, ?)",
+			placeID, typeID).Error; err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // ------------------------------
-// Sync types/pivots to Postgres (map ด้วย kind|code; ไม่ยิง First() ทีละแถว)
+// Sync types/pivots to Postgres (map ด้วย kind|code; เขียนเฉพาะส่วนที่ต่าง)
 // ------------------------------
 func syncTypesToPostgres() error {
 	// กัน pivot เสียใน SQLite
@@ -280,86 +285,39 @@ func syncTypesToPostgres() error {
 	dbSqlite.Exec(`DELETE FROM restaurant_types WHERE type_id IS NULL OR type_id = 0`)
 	dbSqlite.Exec(`DELETE FROM accommodation_types WHERE type_id IS NULL OR type_id = 0`)
 
-	// ล้างตารางฝั่ง PG
-	if err := dbPostgres.Exec(`TRUNCATE TABLE travel_types RESTART IDENTITY CASCADE`).Error; err != nil { return err }
-	if err := dbPostgres.Exec(`TRUNCATE TABLE landmark_types RESTART IDENTITY CASCADE`).Error; err != nil { return err }
-	if err := dbPostgres.Exec(`TRUNCATE TABLE restaurant_types RESTART IDENTITY CASCADE`).Error; err != nil { return err }
-	if err := dbPostgres.Exec(`TRUNCATE TABLE accommodation_types RESTART IDENTITY CASCADE`).Error; err != nil { return err }
-
 	// อ่าน types จาก SQLite
 	var types []entity.TravelType
-	if err := dbSqlite.Find(&types).Error; err != nil { return err }
-
-	// map sqliteTypeID -> TravelType
-	sqliteTypeByID := make(map[uint]entity.TravelType, len(types))
-	for _, t := range types { sqliteTypeByID[t.ID] = t }
+	if err := dbSqlite.Find(&types).Error; err != nil {
+		return err
+	}
+	sqliteTypeByID := typesByID(types)
 
-	// ใส่ type ลง PG (ให้ PG gen ID ใหม่)
+	// ใส่ type ที่ PG ยังไม่มี (ให้ PG gen ID เอง; มีแล้วอัปเดตชื่อ)
 	pgTypes := make([]entity.TravelType, 0, len(types))
 	for _, t := range types {
 		pgTypes = append(pgTypes, entity.TravelType{Code: t.Code, Name: t.Name, Kind: t.Kind})
 	}
 	if len(pgTypes) > 0 {
-		if err := dbPostgres.CreateInBatches(&pgTypes, 1000).Error; err != nil { return err }
-	}
-
-	// map (kind|code) -> pgID
-	var allPG []entity.TravelType
-	if err := dbPostgres.Find(&allPG).Error; err != nil { return err }
-	key := func(k, c string) string {
-		return strings.ToLower(strings.TrimSpace(k)) + "|" + strings.ToLower(strings.TrimSpace(c))
-	}
-	idMap := map[string]uint{}
-	for _, t := range allPG {
-		idMap[key(t.Kind, t.Code)] = t.ID
-	}
-
-	// LandmarkType
-	var lmPivot []entity.LandmarkType
-	if err := dbSqlite.Find(&lmPivot).Error; err != nil { return err }
-	pgLm := make([]entity.LandmarkType, 0, len(lmPivot))
-	for _, p := range lmPivot {
-		if p.TypeID == 0 { continue }
-		if tt, ok := sqliteTypeByID[p.TypeID]; ok {
-			if tid, ok2 := idMap[key(tt.Kind, tt.Code)]; ok2 {
-				pgLm = append(pgLm, entity.LandmarkType{LandmarkID: p.LandmarkID, TypeID: tid})
-			}
+		if err := dbPostgres.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "code"}, {Name: "kind"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name"}),
+		}).CreateInBatches(&pgTypes, 1000).Error; err != nil {
+			return err
 		}
 	}
-	if len(pgLm) > 0 {
-		if err := dbPostgres.CreateInBatches(&pgLm, 1000).Error; err != nil { return err }
-	}
 
-	// RestaurantType
-	var rsPivot []entity.RestaurantType
-	if err := dbSqlite.Find(&rsPivot).Error; err != nil { return err }
-	pgRs := make([]entity.RestaurantType, 0, len(rsPivot))
-	for _, p := range rsPivot {
-		if p.TypeID == 0 { continue }
-		if tt, ok := sqliteTypeByID[p.TypeID]; ok {
-			if tid, ok2 := idMap[key(tt.Kind, tt.Code)]; ok2 {
-				pgRs = append(pgRs, entity.RestaurantType{RestaurantID: p.RestaurantID, TypeID: tid})
-			}
-		}
-	}
-	if len(pgRs) > 0 {
-		if err := dbPostgres.CreateInBatches(&pgRs, 1000).Error; err != nil { return err }
+	pgID, err := pgTypeIDs()
+	if err != nil {
+		return err
 	}
-
-	// AccommodationType
-	var accPivot []entity.AccommodationType
-	if err := dbSqlite.Find(&accPivot).Error; err != nil { return err }
-	pgAcc := make([]entity.AccommodationType, 0, len(accPivot))
-	for _, p := range accPivot {
-		if p.TypeID == 0 { continue }
-		if tt, ok := sqliteTypeByID[p.TypeID]; ok {
-			if tid, ok2 := idMap[key(tt.Kind, tt.Code)]; ok2 {
-				pgAcc = append(pgAcc, entity.AccommodationType{AccommodationID: p.AccommodationID, TypeID: tid})
-			}
+	for _, spec := range importSpecs {
+		want, err := pivotsWanted(spec, sqliteTypeByID, pgID)
+		if err != nil {
+			return err
+		}
+		if err := syncPivot(spec, want); err != nil {
+			return err
 		}
-	}
-	if len(pgAcc) > 0 {
-		if err := dbPostgres.CreateInBatches(&pgAcc, 1000).Error; err != nil { return err }
 	}
 
 	// indexes ฝั่ง PG (เงียบอยู่แล้วถ้ามี)
@@ -370,231 +328,217 @@ func syncTypesToPostgres() error {
 	return nil
 }
 
-// ------------------------------
-// Public entry
-// ------------------------------
-func LoadExcelData(db *gorm.DB) {
-	loadAccommodations(db)
-	loadLandmarks(db)
-	loadRestaurants(db)
-	loadAccommodationGIS()
-	loadLandmarkGIS()
-	loadRestaurantGIS()
+// typesByID map sqliteTypeID -> TravelType
+func typesByID(types []entity.TravelType) map[uint]entity.TravelType {
+	m := make(map[uint]entity.TravelType, len(types))
+	for _, t := range types {
+		m[t.ID] = t
+	}
+	return m
+}
 
-	if err := syncTypesToPostgres(); err != nil {
-		panic(fmt.Errorf("sync types to Postgres failed: %w", err))
+// pgTypeIDs หา ID ฝั่ง PG ของ type ด้วย (kind|code)
+func pgTypeIDs() (func(entity.TravelType) (uint, bool), error) {
+	var allPG []entity.TravelType
+	if err := dbPostgres.Find(&allPG).Error; err != nil {
+		return nil, err
+	}
+	key := func(k, c string) string {
+		return strings.ToLower(strings.TrimSpace(k)) + "|" + strings.ToLower(strings.TrimSpace(c))
+	}
+	idMap := map[string]uint{}
+	for _, t := range allPG {
+		idMap[key(t.Kind, t.Code)] = t.ID
 	}
+	return func(t entity.TravelType) (uint, bool) {
+		id, ok := idMap[key(t.Kind, t.Code)]
+		return id, ok
+	}, nil
 }
 
-// ------------------------------
-// Accommodation loader
-// ------------------------------
-func loadAccommodations(db *gorm.DB) {
-	f, err := excelize.OpenFile("config/places_data_3.xlsx")
-	if err != nil { panic(err) }
-	rows, err := f.GetRows("Sheet1")
-	if err != nil || len(rows) == 0 { panic(err) }
-
-	header := rows[0]
-	idxType := headerIndex(header, "type", "Type", "ประเภท")
-
-	for i, row := range rows {
-		if i == 0 || len(row) < 19 { continue }
-		lat, _ := strconv.ParseFloat(row[3], 32)
-		lon, _ := strconv.ParseFloat(row[4], 32)
-		place, _ := strconv.Atoi(row[0])
-
-		priceRaw := row[17]
-		totalPeopleRaw := row[18]
-		pmin, pmax := ParsePriceRange(priceRaw)
-
-		data := entity.Accommodation{
-			PlaceID:      place,
-			Name:         row[1],
-			Category:     row[2],
-			Lat:          float32(lat),
-			Lon:          float32(lon),
-			Province:     row[6],
-			District:     row[7],
-			SubDistrict:  row[8],
-			Postcode:     row[9],
-			ThumbnailURL: row[10],
-			Time_open:    time.Now(),
-			Time_close:   time.Now(),
-			Total_people: totalPeopleRaw,
-			Price:        priceRaw,
-			Review:       0,
-			PriceMin:     pmin,
-			PriceMax:     pmax,
-		}
-		db.Create(&data)
+// pivotKey คู่ (สถานที่, type) ของ pivot หนึ่งแถว
+type pivotKey struct{ PlaceID, TypeID uint }
 
-		if idxType >= 0 && idxType < len(row) {
-			for _, t := range splitTypes(row[idxType]) {
-				linkAccommodationType(data.ID, t)
+// pivotsWanted pivot ใน SQLite แปลง type_id เป็น ID ฝั่ง PG แล้ว
+func pivotsWanted(spec importSpec, sqliteTypeByID map[uint]entity.TravelType, pgID func(entity.TravelType) (uint, bool)) (map[pivotKey]bool, error) {
+	var rows []pivotKey
+	if err := dbSqlite.Raw("SELECT " + spec.PivotCol + " AS place_id, type_id FROM " + spec.PivotTable).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	want := make(map[pivotKey]bool, len(rows))
+	for _, p := range rows {
+		if tt, ok := sqliteTypeByID[p.TypeID]; ok {
+			if tid, ok2 := pgID(tt); ok2 {
+				want[pivotKey{p.PlaceID, tid}] = true
 			}
 		}
 	}
-	fmt.Println("Accommodation data loaded successfully ✅")
+	return want, nil
 }
 
-// ------------------------------
-// Landmark loader
-// ------------------------------
-func loadLandmarks(db *gorm.DB) {
-	f, err := excelize.OpenFile("config/Attraction_data_4.xlsx")
-	if err != nil { panic(err) }
-	rows, err := f.GetRows("Sheet1")
-	if err != nil || len(rows) == 0 { panic(err) }
-
-	header := rows[0]
-	idxType := headerIndex(header, "type", "Type", "ประเภท")
-
-	for i, row := range rows {
-		if i == 0 || len(row) < 22 { continue }
-		lat, _ := strconv.ParseFloat(row[3], 32)
-		lon, _ := strconv.ParseFloat(row[4], 32)
-		place, _ := strconv.Atoi(row[0])
-
-		priceRaw := row[20]
-		totalPeopleRaw := row[21]
-		pmin, pmax := ParsePriceRange(priceRaw)
-
-		data := entity.Landmark{
-			PlaceID:      place,
-			Name:         row[1],
-			Category:     row[2],
-			Lat:          float32(lat),
-			Lon:          float32(lon),
-			Province:     row[6],
-			District:     row[7],
-			SubDistrict:  row[8],
-			Postcode:     row[9],
-			ThumbnailURL: row[10],
-			Time_open:    time.Now(),
-			Time_close:   time.Now(),
-			Total_people: totalPeopleRaw,
-			Price:        priceRaw,
-			Review:       0,
-			PriceMin:     pmin,
-			PriceMax:     pmax,
+// pivotDiff เทียบ pivot ฝั่ง PG กับ want: extra = id แถวที่เกิน (รวมแถวซ้ำ), missing = คู่ที่ยังไม่มี
+func pivotDiff(spec importSpec, want map[pivotKey]bool) (extra []uint, missing []pivotKey, err error) {
+	var have []struct {
+		ID uint
+		pivotKey
+	}
+	if err := dbPostgres.Raw("SELECT id, " + spec.PivotCol + " AS place_id, type_id FROM " + spec.PivotTable).
+		Scan(&have).Error; err != nil {
+		return nil, nil, err
+	}
+	seen := make(map[pivotKey]bool, len(have))
+	for _, h := range have {
+		if !want[h.pivotKey] || seen[h.pivotKey] {
+			extra = append(extra, h.ID)
+			continue
 		}
-		db.Create(&data)
-
-		if idxType >= 0 && idxType < len(row) {
-			for _, t := range splitTypes(row[idxType]) {
-				linkLandmarkType(data.ID, t)
-			}
+		seen[h.pivotKey] = true
+	}
+	for k := range want {
+		if !seen[k] {
+			missing = append(missing, k)
 		}
 	}
-	fmt.Println("Landmark data loaded successfully ✅")
+	return extra, missing, nil
 }
 
-// ------------------------------
-// Restaurant loader
-// ------------------------------
-func loadRestaurants(db *gorm.DB) {
-	f, err := excelize.OpenFile("config/rharn.xlsx")
-	if err != nil { panic(err) }
-	rows, err := f.GetRows("Sheet1")
-	if err != nil || len(rows) == 0 { panic(err) }
-
-	header := rows[0]
-	idxType := headerIndex(header, "type", "Type", "ประเภท")
-
-	for i, row := range rows {
-		if i == 0 || len(row) < 18 { continue }
-		lat, _ := strconv.ParseFloat(row[3], 32)
-		lon, _ := strconv.ParseFloat(row[4], 32)
-		place, _ := strconv.Atoi(row[0])
-
-		priceRaw := row[17] // "฿60-150/คน"
-		totalPeopleRaw := row[16]
-		pmin, pmax := ParsePriceRange(priceRaw)
-
-		data := entity.Restaurant{
-			PlaceID:      place,
-			Name:         row[1],
-			Category:     row[2],
-			Lat:          float32(lat),
-			Lon:          float32(lon),
-			Province:     row[6],
-			District:     row[7],
-			SubDistrict:  row[8],
-			Postcode:     row[9],
-			ThumbnailURL: row[10],
-			Time_open:    time.Now(),
-			Time_close:   time.Now(),
-			Total_people: totalPeopleRaw,
-			Price:        priceRaw,
-			Review:       0,
-			PriceMin:     pmin,
-			PriceMax:     pmax,
-		}
-		db.Create(&data)
+// syncPivot ทำให้ pivot ฝั่ง PG ตรงกับ want: เพิ่มที่ขาด ลบที่เกิน (แถวซ้ำก็ลบ)
+func syncPivot(spec importSpec, want map[pivotKey]bool) error {
+	extra, missing, err := pivotDiff(spec, want)
+	if err != nil {
+		return err
+	}
+	if len(extra) == 0 && len(missing) == 0 {
+		return nil
+	}
 
-		if idxType >= 0 && idxType < len(row) {
-			for _, t := range splitTypes(row[idxType]) {
-				linkRestaurantType(data.ID, t)
+	return dbPostgres.Transaction(func(tx *gorm.DB) error {
+		for lo := 0; lo < len(extra); lo += 1000 {
+			if err := tx.Exec("DELETE FROM "+spec.PivotTable+" WHERE id IN ?", extra[lo:min(lo+1000, len(extra))]).Error; err != nil {
+				return err
 			}
 		}
-	}
-	fmt.Println("Restaurant data loaded successfully ✅")
+		for _, k := range missing {
+			if err := tx.Exec("INSERT INTO "+spec.PivotTable+" ("+spec.PivotCol+", type_id) VALUES (?, ?)",
+				k.PlaceID, k.TypeID).Error; err != nil {
+				return err
+			}
+		}
+		fmt.Printf("✅ %s: +%d / -%d แถว\n", spec.PivotTable, len(missing), len(extra))
+		return nil
+	})
 }
 
 // ------------------------------
-// GIS loaders
+// Row builders: record (ตาม profiles/<kind>.json) → entity
 // ------------------------------
-func tableExists(tableName string) bool {
-	var exists bool
-	query := `
-		SELECT EXISTS (
-			SELECT FROM information_schema.tables 
-			WHERE table_schema = 'public' 
-			  AND table_name = ?
-		);`
-	dbPostgres.Raw(query, tableName).Scan(&exists)
-	return exists
-}
-
-func loadAccommodationGIS() {
-	if tableExists("accommodation_gis") {
-		dbPostgres.Exec("TRUNCATE TABLE accommodation_gis RESTART IDENTITY CASCADE")
+func buildAccommodation(rec record, r *importRow) {
+	price := priceInfo(r, rec["Price"], "", domain.PerRoomNight)
+	pmin, pmax := price.Bounds()
+
+	// ที่พักเปิดตลอด 24 ชม. ตามวันที่ระบุ ส่วน Time_open/Time_close เก็บเวลา check-in/check-out
+	hours, _, _ := openingHours(r, "24 ชม.", "", rec["OpenDays"], "", "")
+
+	r.Types = splitTypes(rec["Types"])
+	r.Model = &entity.Accommodation{
+		PlaceID:      r.PlaceID,
+		Name:         rec["Name"],
+		Category:     rec["Category"],
+		Lat:          r.Lat,
+		Lon:          r.Lon,
+		Address:      rec["Address"],
+		Province:     rec["Province"],
+		District:     rec["District"],
+		SubDistrict:  rec["SubDistrict"],
+		Postcode:     rec["Postcode"],
+		ThumbnailURL: rec["ThumbnailURL"],
+		Time_open:    clockOf(r, "CheckIn", rec["CheckIn"]),
+		Time_close:   clockOf(r, "CheckOut", rec["CheckOut"]),
+		Open_days:    rec["OpenDays"],
+		OpeningHours: hours,
+		Total_people: rec["TotalPeople"],
+		Price:        rec["Price"],
+		PriceInfo:    price,
+		Capacity:     capacityInfo(r, rec["TotalPeople"], rec["TotalRoom"]),
+		PriceMin:     pmin,
+		PriceMax:     pmax,
+		SourceHash:   r.Hash,
+		ImportSource: r.Source,
+		SourceRecord: r.Record,
 	}
-	var accommodations []entity.Accommodation
-	dbSqlite.Find(&accommodations)
-	for _, acc := range accommodations {
-		location := fmt.Sprintf("SRID=4326;POINT(%f %f)", acc.Lon, acc.Lat)
-		gis := entity.AccommodationGis{Acc_ID: acc.ID, Location: location}
-		dbPostgres.Create(&gis)
-	}
-	fmt.Println("✅ Accommodation GIS data reloaded")
 }
 
-func loadLandmarkGIS() {
-	if tableExists("landmark_gis") {
-		dbPostgres.Exec("TRUNCATE TABLE landmark_gis RESTART IDENTITY CASCADE")
-	}
-	var landmarks []entity.Landmark
-	dbSqlite.Find(&landmarks)
-	for _, lm := range landmarks {
-		location := fmt.Sprintf("SRID=4326;POINT(%f %f)", lm.Lon, lm.Lat)
-		gis := entity.LandmarkGis{LandmarkID: lm.ID, Location: location}
-		dbPostgres.Create(&gis)
+func buildLandmark(rec record, r *importRow) {
+	price := priceInfo(r, rec["Price"], rec["PriceChild"], domain.PerPerson)
+	pmin, pmax := price.Bounds()
+
+	hours, timeOpen, timeClose := openingHours(r,
+		rec["TimeOpen"], rec["TimeClose"], rec["OpenDays"],
+		rec["TimeOpen2"], rec["TimeClose2"])
+
+	r.Types = splitTypes(rec["Types"])
+	r.Model = &entity.Landmark{
+		PlaceID:      r.PlaceID,
+		Name:         rec["Name"],
+		Category:     rec["Category"],
+		Lat:          r.Lat,
+		Lon:          r.Lon,
+		Address:      rec["Address"],
+		Province:     rec["Province"],
+		District:     rec["District"],
+		SubDistrict:  rec["SubDistrict"],
+		Postcode:     rec["Postcode"],
+		ThumbnailURL: rec["ThumbnailURL"],
+		Time_open:    timeOpen,
+		Time_close:   timeClose,
+		Open_days:    rec["OpenDays"],
+		OpeningHours: hours,
+		Total_people: rec["TotalPeople"],
+		Price:        rec["Price"],
+		PriceChild:   rec["PriceChild"],
+		PriceInfo:    price,
+		Capacity:     capacityInfo(r, rec["TotalPeople"], ""),
+		PriceMin:     pmin,
+		PriceMax:     pmax,
+		SourceHash:   r.Hash,
+		ImportSource: r.Source,
+		SourceRecord: r.Record,
 	}
-	fmt.Println("✅ Landmark GIS data reloaded")
 }
 
-func loadRestaurantGIS() {
-	if tableExists("restaurant_gis") {
-		dbPostgres.Exec("TRUNCATE TABLE restaurant_gis RESTART IDENTITY CASCADE")
-	}
-	var restaurants []entity.Restaurant
-	dbSqlite.Find(&restaurants)
-	for _, r := range restaurants {
-		location := fmt.Sprintf("SRID=4326;POINT(%f %f)", r.Lon, r.Lat)
-		gis := entity.RestaurantGis{RestaurantID: r.ID, Location: location}
-		dbPostgres.Create(&gis)
+func buildRestaurant(rec record, r *importRow) {
+	price := priceInfo(r, rec["Price"], "", domain.PerPerson) // "฿60-150/คน"
+	pmin, pmax := price.Bounds()
+
+	// ชีตร้านยังไม่มีคอลัมน์วันเปิด → ถือว่าเปิดทุกวัน
+	hours, timeOpen, timeClose := openingHours(r, rec["TimeOpen"], rec["TimeClose"], rec["OpenDays"], "", "")
+
+	r.Types = splitTypes(rec["Types"])
+	r.Model = &entity.Restaurant{
+		PlaceID:      r.PlaceID,
+		Name:         rec["Name"],
+		Category:     rec["Category"],
+		Lat:          r.Lat,
+		Lon:          r.Lon,
+		Address:      rec["Address"],
+		Province:     rec["Province"],
+		District:     rec["District"],
+		SubDistrict:  rec["SubDistrict"],
+		Postcode:     rec["Postcode"],
+		ThumbnailURL: rec["ThumbnailURL"],
+		Time_open:    timeOpen,
+		Time_close:   timeClose,
+		Open_days:    rec["OpenDays"],
+		OpeningHours: hours,
+		Total_people: rec["TotalPeople"],
+		Price:        rec["Price"],
+		PriceInfo:    price,
+		Capacity:     capacityInfo(r, rec["TotalPeople"], ""),
+		PriceMin:     pmin,
+		PriceMax:     pmax,
+		SourceHash:   r.Hash,
+		ImportSource: r.Source,
+		SourceRecord: r.Record,
 	}
-	fmt.Println("✅ Restaurant GIS data reloaded")
 }
@@ -0,0 +1,273 @@
+package config
+
+import (
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+// ------------------------------
+// Export สถานที่กลับออกเป็นแถวตาม profile (ทางกลับของ import)
+//   - แถวที่ยังตรงกับตอน import → เขียนค่าในไฟล์เดิมตรงตัว (SourceRecord) ไฟล์ที่ export แล้วไม่ได้แก้
+//     อัปโหลดกลับได้แฮชเท่าเดิม ทุกแถวเป็น skip
+//   - แถวที่สร้าง/แก้ผ่าน API → ค่าในตาราง SQLite ในรูปที่ parser/builder ของ import อ่านได้
+//   - Lat/Lon ในไฟล์มาจาก SQLite เสมอ; จุดใน PostGIS ใช้กับ geojson (ExportedPlace.Lon/Lat)
+// ------------------------------
+
+// ExportFilter เงื่อนไขเดียวกับ /places/search
+type ExportFilter struct {
+	Province string   // ตรงตัว (ตัดช่องว่างหัวท้าย)
+	Types    []string // ชื่อหรือ code ของ travel_types (ตรงอันใดอันหนึ่ง)
+	PriceMin *int     // ช่วงราคาของสถานที่ต้องซ้อนกับ [PriceMin, PriceMax]
+	PriceMax *int
+}
+
+// ExportedPlace สถานที่หนึ่งแถว
+type ExportedPlace struct {
+	Code     string // P12 / R3 / A7
+	ID       uint
+	Values   map[string]string // field ของ profile → ค่า
+	Types    []string          // ชื่อ TravelType ที่ผูกไว้
+	PriceMin int
+	PriceMax int
+	Review   int
+	Lon, Lat float64
+	HasPoint bool // false = ไม่มีจุดใน *_gis (พิกัดมาจาก SQLite)
+}
+
+// exportRow ผลของ importSpec.dump
+type exportRow struct {
+	ID       uint
+	Rec      record
+	Types    []entity.TravelType
+	PriceMin int
+	PriceMax int
+	Review   int
+	Lat, Lon float32
+	Source   string // SourceRecord ของแถว
+}
+
+// ExportPlaces สถานที่ของ kind ที่ผ่าน filter เรียงตาม PlaceID พร้อม profile ที่ใช้จัดคอลัมน์
+func ExportPlaces(kind string, f ExportFilter) (Profile, []ExportedPlace, error) {
+	p, err := DefaultProfile(kind)
+	if err != nil {
+		return p, nil, err
+	}
+	spec, _ := findImportSpec(kind)
+
+	q := dbSqlite.Model(spec.model()).Order("place_id, id")
+	if f.Province != "" {
+		q = q.Where("TRIM(province) = ?", strings.TrimSpace(f.Province))
+	}
+	if len(f.Types) > 0 {
+		q = q.Where("EXISTS (SELECT 1 FROM "+spec.PivotTable+" pv JOIN travel_types t ON t.id = pv.type_id "+
+			"WHERE pv."+spec.PivotCol+" = "+spec.Table+".id AND (t.name IN ? OR t.code IN ?))", f.Types, f.Types)
+	}
+	if f.PriceMin != nil {
+		q = q.Where("price_max >= ?", *f.PriceMin)
+	}
+	if f.PriceMax != nil {
+		q = q.Where("price_min <= ?", *f.PriceMax)
+	}
+	models, err := spec.find(q)
+	if err != nil {
+		return p, nil, err
+	}
+	points, err := gisPoints(spec)
+	if err != nil {
+		return p, nil, err
+	}
+	types, err := pivotTypes(spec)
+	if err != nil {
+		return p, nil, err
+	}
+
+	out := make([]ExportedPlace, 0, len(models))
+	for _, m := range models {
+		row := spec.dump(m)
+		row.Types = types[row.ID]
+		e := ExportedPlace{
+			Code: spec.Prefix + strconv.FormatUint(uint64(row.ID), 10), ID: row.ID,
+			Values: row.Rec, Types: make([]string, 0, len(row.Types)),
+			PriceMin: row.PriceMin, PriceMax: row.PriceMax, Review: row.Review,
+			Lon: float64(row.Lon), Lat: float64(row.Lat),
+		}
+		if pt, ok := points[row.ID]; ok {
+			e.Lon, e.Lat, e.HasPoint = pt[0], pt[1], true
+		}
+		for _, t := range row.Types {
+			e.Types = append(e.Types, t.Name)
+		}
+		if src := sourceValues(spec, row); src != nil {
+			e.Values = src
+		} else {
+			// float32 แบบสั้นสุดที่ parse กลับได้ค่าเดิม; splitTypes แยกด้วยจุลภาค → ต่อกลับแบบเดียวกัน
+			e.Values["Lat"] = strconv.FormatFloat(float64(row.Lat), 'f', -1, 32)
+			e.Values["Lon"] = strconv.FormatFloat(float64(row.Lon), 'f', -1, 32)
+			e.Values["Types"] = strings.Join(e.Types, ",")
+		}
+		out = append(out, e)
+	}
+	return p, out, nil
+}
+
+// Header ชื่อคอลัมน์หลักของทุก field ตามลำดับใน profile (ชุดที่ import อ่านได้แน่นอน)
+func (p Profile) Header() []string {
+	h := make([]string, len(p.Fields))
+	for i, f := range p.Fields {
+		h[i] = f.Columns[0]
+	}
+	return h
+}
+
+// Row ค่าของ values เรียงตาม Header
+func (p Profile) Row(values map[string]string) []string {
+	row := make([]string, len(p.Fields))
+	for i, f := range p.Fields {
+		row[i] = values[f.Field]
+	}
+	return row
+}
+
+// sourceValues ค่าในไฟล์ตอน import ล่าสุด ถ้า build กลับแล้วได้แถวเดียวกับในตาราง (nil = แก้ผ่าน API ไปแล้ว/ไม่ได้มาจาก import)
+// เทียบผ่าน dump ทั้งสองฝั่ง: เวลา ราคา ความจุ ที่เขียนต่างกันแต่ความหมายเดียวกันถือว่าเท่ากัน
+func sourceValues(spec importSpec, row exportRow) record {
+	src := decodeRecord(row.Source)
+	if src == nil {
+		return nil
+	}
+	r, err := newImportRow(src)
+	if err != nil || r.Lat != row.Lat || r.Lon != row.Lon {
+		return nil
+	}
+	spec.build(src, &r)
+	if !maps.Equal(spec.dump(r.Model).Rec, row.Rec) {
+		return nil
+	}
+	types := map[string]bool{}
+	for _, t := range row.Types {
+		types[strings.ToLower(t.Code)] = true
+	}
+	if len(r.Types) != len(types) {
+		return nil
+	}
+	for _, t := range r.Types {
+		if !types[strings.ToLower(slug(t))] {
+			return nil
+		}
+	}
+	return src
+}
+
+// pivotTypes travel_types ของทุกแถวตาม id ของ SQLite
+// (pivot ที่ linkTypes เขียนใช้คอลัมน์ type_id ไม่ใช่ travel_type_id ที่ Preload ของ gorm อ่าน)
+func pivotTypes(spec importSpec) (map[uint][]entity.TravelType, error) {
+	var links []struct {
+		PlaceID uint
+		entity.TravelType
+	}
+	if err := dbSqlite.Raw("SELECT pv." + spec.PivotCol + " AS place_id, t.* FROM " + spec.PivotTable +
+		" pv JOIN travel_types t ON t.id = pv.type_id WHERE t.deleted_at IS NULL ORDER BY t.id").
+		Scan(&links).Error; err != nil {
+		return nil, err
+	}
+	out := map[uint][]entity.TravelType{}
+	for _, l := range links {
+		out[l.PlaceID] = append(out[l.PlaceID], l.TravelType)
+	}
+	return out, nil
+}
+
+func findAll[T any](q *gorm.DB) ([]any, error) {
+	var xs []T
+	if err := q.Find(&xs).Error; err != nil {
+		return nil, err
+	}
+	out := make([]any, len(xs))
+	for i := range xs {
+		out[i] = &xs[i]
+	}
+	return out, nil
+}
+
+// ------------------------------
+// entity → record (กลับด้านของ buildXxx ใน db.go)
+// ------------------------------
+
+func dumpAccommodation(m any) exportRow {
+	a := m.(*entity.Accommodation)
+	rec := record{
+		"PlaceID": strconv.Itoa(a.PlaceID), "Name": a.Name, "Category": a.Category,
+		"Address": a.Address, "Province": a.Province, "District": a.District,
+		"SubDistrict": a.SubDistrict, "Postcode": a.Postcode, "ThumbnailURL": a.ThumbnailURL,
+		"CheckIn": clockText(a.Time_open), "CheckOut": clockText(a.Time_close), "OpenDays": a.Open_days,
+		"Price": a.Price, "TotalPeople": a.Total_people,
+	}
+	if a.Capacity.Rooms > 0 {
+		rec["TotalRoom"] = strconv.Itoa(a.Capacity.Rooms)
+	}
+	return exportRow{ID: a.ID, Rec: rec, PriceMin: a.PriceMin, PriceMax: a.PriceMax,
+		Review: a.Review, Lat: a.Lat, Lon: a.Lon, Source: a.SourceRecord}
+}
+
+func dumpLandmark(m any) exportRow {
+	l := m.(*entity.Landmark)
+	rec := record{
+		"PlaceID": strconv.Itoa(l.PlaceID), "Name": l.Name, "Category": l.Category,
+		"Address": l.Address, "Province": l.Province, "District": l.District,
+		"SubDistrict": l.SubDistrict, "Postcode": l.Postcode, "ThumbnailURL": l.ThumbnailURL,
+		"OpenDays": l.Open_days, "Price": l.Price, "PriceChild": l.PriceChild, "TotalPeople": l.Total_people,
+	}
+	hoursColumns(rec, l.OpeningHours)
+	return exportRow{ID: l.ID, Rec: rec, PriceMin: l.PriceMin, PriceMax: l.PriceMax,
+		Review: l.Review, Lat: l.Lat, Lon: l.Lon, Source: l.SourceRecord}
+}
+
+func dumpRestaurant(m any) exportRow {
+	r := m.(*entity.Restaurant)
+	rec := record{
+		"PlaceID": strconv.Itoa(r.PlaceID), "Name": r.Name, "Category": r.Category,
+		"Address": r.Address, "Province": r.Province, "District": r.District,
+		"SubDistrict": r.SubDistrict, "Postcode": r.Postcode, "ThumbnailURL": r.ThumbnailURL,
+		"OpenDays": r.Open_days, "Price": r.Price, "TotalPeople": r.Total_people,
+	}
+	hoursColumns(rec, r.OpeningHours)
+	return exportRow{ID: r.ID, Rec: rec, PriceMin: r.PriceMin, PriceMax: r.PriceMax,
+		Review: r.Review, Lat: r.Lat, Lon: r.Lon, Source: r.SourceRecord}
+}
+
+// hoursColumns เขียน TimeOpen/TimeClose(/2) จากช่วงเวลาของวันแรกที่เปิด
+// (ไฟล์มีช่วงเวลาชุดเดียวใช้กับทุกวันใน Date อยู่แล้ว; ไม่รู้เวลา/ปิดถาวร → เว้นว่าง)
+func hoursColumns(rec record, h domain.Hours) {
+	if h.Unknown || h.Closed {
+		return
+	}
+	for _, ws := range h.Week {
+		if len(ws) == 0 {
+			continue
+		}
+		keys := [][2]string{{"TimeOpen", "TimeClose"}, {"TimeOpen2", "TimeClose2"}}
+		for i, w := range ws[:min(len(ws), len(keys))] {
+			if w.Open == 0 && w.Close == 24*60 {
+				rec[keys[i][0]] = "24 ชม."
+				continue
+			}
+			rec[keys[i][0]], rec[keys[i][1]] = domain.FormatClock(w.Open), domain.FormatClock(w.Close)
+		}
+		return
+	}
+}
+
+// clockText เวลา Time_open/Time_close → "HH:MM" (zero time → ว่าง)
+func clockText(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(domain.Bangkok).Format("15:04")
+}
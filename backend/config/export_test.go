@@ -0,0 +1,80 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+// exportLandmarks export แลนด์มาร์กทั้งหมดเป็น header + แถว (แบบเดียวกับ xlsx/csv ของ /exports)
+func exportLandmarks(t *testing.T) ([]string, [][]string) {
+	t.Helper()
+	p, places, err := ExportPlaces("landmark", ExportFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := make([][]string, len(places))
+	for i, pl := range places {
+		rows[i] = p.Row(pl.Values)
+	}
+	return p.Header(), rows
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	openTestDBs(t)
+	p, err := DefaultProfile("landmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ค่าที่เขียนได้หลายแบบ: พิกัดเกินความละเอียด float32, เวลา "9.00 น.", ประเภทคั่นด้วย ", " และราคามีวงเล็บ
+	header := []string{"Place ID", "Name", "Latitude", "Longitude", "TimeOpen", "TimeClose", "Date", "PriceAdult", "Type"}
+	rows := [][]string{
+		{"1", "วัดหนึ่ง", "13.7563309", "100.5017651", "8.30", "16.30 น.", "ทุกวัน", "100 บาท/คน (เด็ก 50 บาท)", "วัด, ประวัติศาสตร์"},
+		{"2", "ตลาดน้ำ", "13.5200", "100.1", "24 ชั่วโมง", "", "เสาร์-อาทิตย์", "ฟรี", "ตลาด"},
+	}
+	if _, err := importKind(p, header, rows, ImportOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	h, out := exportLandmarks(t)
+	res, err := importKind(p, h, out, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Errors) > 0 || len(res.Plan) != len(rows) {
+		t.Fatalf("errors %v plan %v", res.Errors, res.Plan)
+	}
+	for _, pl := range res.Plan {
+		if pl.Action != ActionSkip {
+			t.Errorf("PlaceID %d: %s ต้องเป็น skip (ไฟล์ที่ export ไม่ได้แก้)", pl.PlaceID, pl.Action)
+		}
+	}
+	if res.GisMoved != 0 || res.GisCreated != 0 {
+		t.Errorf("GIS moved %d created %d ต้องไม่เปลี่ยน", res.GisMoved, res.GisCreated)
+	}
+
+	// แก้ผ่าน API หลัง import → export ใช้ค่าในตาราง และ import กลับเป็น update ของแถวนั้นแถวเดียว
+	if err := dbSqlite.Model(&entity.Landmark{}).Where("place_id = 1").Update("name", "วัดหนึ่ง (ใหม่)").Error; err != nil {
+		t.Fatal(err)
+	}
+	h, out = exportLandmarks(t)
+	if out[0][1] != "วัดหนึ่ง (ใหม่)" {
+		t.Fatalf("Name = %q ต้องเป็นค่าในตาราง", out[0][1])
+	}
+	res, err = importKind(p, h, out, ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, pl := range res.Plan {
+		want := ActionSkip
+		if pl.PlaceID == 1 {
+			want = ActionUpdate
+		}
+		if pl.Action != want {
+			t.Errorf("หลังแก้ผ่าน API: PlaceID %d = %s ต้องการ %s", pl.PlaceID, pl.Action, want)
+		}
+	}
+	if res.GisMoved != 0 {
+		t.Errorf("พิกัดจากตาราง (float32) ต้องไม่ทำให้จุดย้าย: moved %d", res.GisMoved)
+	}
+}
@@ -0,0 +1,545 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/roads"
+)
+
+// ------------------------------
+// Import สถานที่จากไฟล์ Excel แบบ incremental (cmd/dataimport)
+//   - จับคู่แถวกับฐานข้อมูลด้วย PlaceID, แถวที่แฮชเท่าเดิมไม่แตะ
+//   - แถวที่หายไปจากไฟล์ → soft delete (เฉพาะแถวที่เคย import จากไฟล์เดียวกัน; แถวที่ admin อัปโหลดไม่ถูกลบ)
+//   - PostGIS เขียนเฉพาะจุดที่เพิ่ม/ย้าย/ลบ แล้วล้างแคชระยะของจุดนั้น
+//
+// รันซ้ำกี่รอบก็ได้ผลเท่าเดิม: ฝั่ง GIS เทียบกับพิกัดจริงในตาราง ไม่ได้เทียบแฮช
+// ถ้ารอบก่อนพังกลางทางหลังเขียน SQLite แล้ว รอบถัดไปจะเติม GIS ที่ขาดให้เอง
+// ------------------------------
+
+// importVersion เปลี่ยนเมื่อวิธีแปลงแถว → entity เปลี่ยน (ทุกแถวจะถูก parse ใหม่หนึ่งรอบ)
+const importVersion = "3"
+
+// gisEpsilon พิกัดต่างกันไม่เกินนี้ (องศา ~0.1 m) ถือว่าไม่ย้าย; WKT เขียนด้วย %f อยู่แล้ว
+const gisEpsilon = 1e-6
+
+// importMu import ทีละงาน (typeCache ใช้ร่วมกัน และกันสองงานเขียน PlaceID เดียวกันพร้อมกัน)
+var importMu sync.Mutex
+
+type ImportOptions struct {
+	DryRun bool // คำนวณรายงานอย่างเดียว ไม่เขียนฐานข้อมูล
+	Force  bool // parse/เขียนทุกแถวใหม่แม้แฮชเท่าเดิม
+	Prune  bool // soft delete แถวที่เคย import จากไฟล์นี้ (Source) แต่ไม่มีในไฟล์แล้ว
+
+	Source string // ที่มาที่บันทึกลงแถว (ว่าง = Profile.File)
+}
+
+// UploadSource ที่มาของแถวที่ admin อัปโหลดผ่าน /admin/imports (prune ของไฟล์ใน config/ ไม่แตะ)
+const UploadSource = "upload"
+
+// RowError แถวที่ import ไม่ได้ (Line นับแบบ Excel: header = แถว 1)
+type RowError struct {
+	Line    int    `json:"line"`
+	PlaceID int    `json:"place_id,omitempty"`
+	Message string `json:"message"`
+}
+
+// ImportResult สรุปผลของไฟล์/ชนิดสถานที่หนึ่ง
+type ImportResult struct {
+	Kind      string `json:"kind"`
+	Source    string `json:"source,omitempty"`
+	Rows      int    `json:"rows"`
+	Created   int    `json:"created"`
+	Updated   int    `json:"updated"`
+	Unchanged int    `json:"unchanged"`
+	Deleted   int    `json:"deleted"`
+
+	GisCreated int `json:"gis_created"`
+	GisMoved   int `json:"gis_moved"`
+	GisDeleted int `json:"gis_deleted"`
+
+	Errors   []RowError `json:"errors,omitempty"`   // แถวที่ไม่ได้ import
+	Warnings []RowError `json:"warnings,omitempty"` // import แล้วแต่บางค่าอ่านไม่ออก (ราคา/เวลา/ความจุ)
+	Plan     []RowPlan  `json:"plan,omitempty"`
+}
+
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionSkip   = "skip" // แถวเท่าเดิม
+	ActionDelete = "delete"
+)
+
+// RowPlan สิ่งที่ import ทำ (หรือจะทำ ถ้า dry-run) กับแต่ละแถว; ID = id ในฐานข้อมูล (แถวใหม่ตอน dry-run = 0)
+type RowPlan struct {
+	Line    int    `json:"line,omitempty"` // delete ไม่มีแถวในไฟล์
+	PlaceID int    `json:"place_id"`
+	ID      uint   `json:"id,omitempty"`
+	Action  string `json:"action"`
+}
+
+func (r ImportResult) gisChanged() bool {
+	return r.GisCreated+r.GisMoved+r.GisDeleted > 0
+}
+
+// importRow แถวที่แปลงแล้ว (Model = *entity.Landmark / *entity.Restaurant / *entity.Accommodation)
+type importRow struct {
+	Line     int
+	PlaceID  int
+	Lat, Lon float32
+	Types    []string
+	Hash     string
+	Source   string // ImportOptions.Source
+	Record   string // rec.encode() → SourceRecord
+	Model    any
+	Warnings []string
+}
+
+// importSpec ตารางของสถานที่แต่ละชนิด (คอลัมน์ของไฟล์อยู่ใน Profile)
+type importSpec struct {
+	Kind       string // kind ใน travel_types
+	Prefix     string // รหัสสถานที่ P/R/A
+	Table      string
+	GisTable   string
+	GisCol     string
+	PivotTable string
+	PivotCol   string
+
+	model func() any                      // model เปล่าของตาราง (ใช้ลบ/อัปเดต)
+	id    func(m any) uint                // ID หลัง Create
+	build func(rec record, r *importRow)  // record → r.Model, r.Types, r.Warnings
+	find  func(q *gorm.DB) ([]any, error) // export: แถวตาม q (ชี้ไปที่ entity)
+	dump  func(m any) exportRow           // export: entity → record (กลับด้านของ build)
+}
+
+var importSpecs = []importSpec{
+	{
+		Kind: "accommodation", Prefix: "A",
+		Table: "accommodations", GisTable: "accommodation_gis", GisCol: "acc_id",
+		PivotTable: "accommodation_types", PivotCol: "accommodation_id",
+		model: func() any { return &entity.Accommodation{} },
+		id:    func(m any) uint { return m.(*entity.Accommodation).ID },
+		build: buildAccommodation,
+		find:  findAll[entity.Accommodation],
+		dump:  dumpAccommodation,
+	},
+	{
+		Kind: "landmark", Prefix: "P",
+		Table: "landmarks", GisTable: "landmark_gis", GisCol: "landmark_id",
+		PivotTable: "landmark_types", PivotCol: "landmark_id",
+		model: func() any { return &entity.Landmark{} },
+		id:    func(m any) uint { return m.(*entity.Landmark).ID },
+		build: buildLandmark,
+		find:  findAll[entity.Landmark],
+		dump:  dumpLandmark,
+	},
+	{
+		Kind: "restaurant", Prefix: "R",
+		Table: "restaurants", GisTable: "restaurant_gis", GisCol: "restaurant_id",
+		PivotTable: "restaurant_types", PivotCol: "restaurant_id",
+		model: func() any { return &entity.Restaurant{} },
+		id:    func(m any) uint { return m.(*entity.Restaurant).ID },
+		build: buildRestaurant,
+		find:  findAll[entity.Restaurant],
+		dump:  dumpRestaurant,
+	},
+}
+
+func findImportSpec(kind string) (importSpec, bool) {
+	for _, s := range importSpecs {
+		if s.Kind == kind {
+			return s, true
+		}
+	}
+	return importSpec{}, false
+}
+
+// ------------------------------
+// Public entry
+// ------------------------------
+
+// ImportExcelData import ไฟล์ตาม profiles (ไม่ส่ง = profile ที่ฝังมาของทั้งสามชนิด; รันจากโฟลเดอร์ backend)
+func ImportExcelData(opt ImportOptions, profiles ...Profile) ([]ImportResult, error) {
+	if len(profiles) == 0 {
+		for _, spec := range importSpecs {
+			p, err := DefaultProfile(spec.Kind)
+			if err != nil {
+				return nil, err
+			}
+			profiles = append(profiles, p)
+		}
+	}
+
+	importMu.Lock()
+	defer importMu.Unlock()
+	var results []ImportResult
+	for _, p := range profiles {
+		header, rows, err := readSheet(p.File, p.Sheet)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", p.File, err)
+		}
+		res, err := importKind(p, header, rows, opt)
+		res.Source = p.File
+		results = append(results, res)
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", p.File, err)
+		}
+	}
+	return results, finishImport(results, opt)
+}
+
+// ImportRows import แถวของสถานที่ชนิดเดียวด้วย profile ที่ฝังมา (header + แถวข้อมูล ไม่รวม header)
+func ImportRows(kind string, header []string, rows [][]string, opt ImportOptions) (ImportResult, error) {
+	p, err := DefaultProfile(kind)
+	if err != nil {
+		return ImportResult{Kind: kind}, err
+	}
+	importMu.Lock()
+	defer importMu.Unlock()
+	res, err := importKind(p, header, rows, opt)
+	if err != nil {
+		return res, err
+	}
+	return res, finishImport([]ImportResult{res}, opt)
+}
+
+// readSheet อ่านชีต (ว่าง = ชีตแรก) คืน header กับแถวข้อมูล
+func readSheet(path, sheet string) ([]string, [][]string, error) {
+	f, err := excelize.OpenFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+	if sheet == "" {
+		sheet = f.GetSheetName(0)
+	}
+	rows, err := f.GetRows(sheet)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("ไม่มี header")
+	}
+	return rows[0], rows[1:], nil
+}
+
+// finishImport งานหลัง import: snap ถนนใหม่ (ถ้ามีจุดเปลี่ยน) + sync types ไป PG
+func finishImport(results []ImportResult, opt ImportOptions) error {
+	if opt.DryRun {
+		return nil
+	}
+	changed := false
+	for _, r := range results {
+		changed = changed || r.gisChanged()
+	}
+	// มีโครงข่ายถนน (cmd/roadimport) → snap สถานที่ใหม่ให้ตรงกับพิกัดล่าสุด
+	if changed && roads.HasNetwork(dbPostgres) {
+		if n, err := roads.Snap(dbPostgres, roads.DefaultSnapM); err != nil {
+			fmt.Println("⚠️ snap สถานที่เข้ากับถนนไม่สำเร็จ:", err)
+		} else {
+			fmt.Printf("✅ Snapped %d places to road network\n", n)
+		}
+	}
+	if err := syncTypesToPostgres(); err != nil {
+		return fmt.Errorf("sync types to Postgres failed: %w", err)
+	}
+	return nil
+}
+
+// ------------------------------
+// Row parsing
+// ------------------------------
+
+// hashParts แฮชของค่าที่ import (ผูกกับ importVersion)
+func hashParts(parts []string) string {
+	sum := sha256.Sum256([]byte(importVersion + "\x1e" + strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:])
+}
+
+// newImportRow ค่าที่ทุกชนิดใช้ร่วม (PlaceID + พิกัด ผ่าน parser ของ profile มาแล้ว)
+func newImportRow(rec record) (importRow, error) {
+	r := importRow{PlaceID: rec.int("PlaceID"), Lat: rec.float32("Lat"), Lon: rec.float32("Lon")}
+	if r.PlaceID <= 0 {
+		return r, fmt.Errorf("PlaceID ต้องเป็นจำนวนเต็มบวก")
+	}
+	if r.Lat == 0 && r.Lon == 0 {
+		return r, fmt.Errorf("ไม่มีพิกัด (0, 0)")
+	}
+	return r, nil
+}
+
+func blankRow(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ------------------------------
+// Import หนึ่งชนิด
+// ------------------------------
+
+// existingPlace แถวเดิมใน SQLite (รวมที่ถูก soft delete เพื่อกู้คืน ID เดิมได้)
+type existingPlace struct {
+	ID           uint
+	PlaceID      int
+	SourceHash   string
+	ImportSource string
+	Deleted      bool
+}
+
+func importKind(p Profile, header []string, rows [][]string, opt ImportOptions) (ImportResult, error) {
+	res := ImportResult{Kind: p.Kind}
+	spec, ok := findImportSpec(p.Kind)
+	if !ok {
+		return res, fmt.Errorf("ไม่รู้จักชนิดสถานที่ %q", p.Kind)
+	}
+	b := p.bind(header)
+	if err := b.err(); err != nil {
+		return res, err
+	}
+	source := opt.Source
+	if source == "" {
+		source = p.File
+	}
+
+	// ---- แปลงแถว ----
+	var parsed []importRow
+	seen := map[int]int{} // PlaceID → line (รวมแถวที่ error เพื่อไม่ให้ถูกมองว่าหายไป)
+	for i, row := range rows {
+		line := i + 2
+		if blankRow(row) {
+			continue
+		}
+		res.Rows++
+		rec, errs, warns := b.record(row)
+		r, err := newImportRow(rec)
+		if r.PlaceID > 0 {
+			if first, dup := seen[r.PlaceID]; dup {
+				res.Errors = append(res.Errors, RowError{Line: line, PlaceID: r.PlaceID,
+					Message: fmt.Sprintf("PlaceID ซ้ำกับแถว %d", first)})
+				continue
+			}
+			seen[r.PlaceID] = line
+		}
+		if err != nil && len(errs) == 0 {
+			errs = append(errs, err.Error())
+		}
+		if len(errs) > 0 {
+			res.Errors = append(res.Errors, RowError{Line: line, PlaceID: r.PlaceID, Message: strings.Join(errs, "; ")})
+			continue
+		}
+		r.Line, r.Hash, r.Source, r.Record, r.Warnings = line, rec.hash(p), source, rec.encode(), warns
+		spec.build(rec, &r)
+		for _, w := range r.Warnings {
+			res.Warnings = append(res.Warnings, RowError{Line: line, PlaceID: r.PlaceID, Message: w})
+		}
+		parsed = append(parsed, r)
+	}
+
+	// ---- แถวเดิม: PlaceID → แถวที่จะใช้ต่อ (active ID น้อยสุดก่อน) ที่เหลือเป็นแถวซ้ำ ----
+	var existing []existingPlace
+	if err := dbSqlite.Raw("SELECT id, place_id, source_hash, import_source, deleted_at IS NOT NULL AS deleted FROM " +
+		spec.Table + " ORDER BY deleted_at IS NOT NULL, id").Scan(&existing).Error; err != nil {
+		return res, err
+	}
+	current := map[int]existingPlace{}
+	var drop []uint // ID ที่จะ soft delete (ซ้ำ/หายจากไฟล์)
+	dropRow := func(e existingPlace) {
+		drop = append(drop, e.ID)
+		res.Plan = append(res.Plan, RowPlan{PlaceID: e.PlaceID, ID: e.ID, Action: ActionDelete})
+	}
+	for _, e := range existing {
+		if _, ok := current[e.PlaceID]; !ok {
+			current[e.PlaceID] = e
+		} else if !e.Deleted && seen[e.PlaceID] > 0 {
+			dropRow(e) // แถวซ้ำจากการโหลดซ้ำตอนบูตแบบเดิม
+		}
+	}
+	if opt.Prune {
+		legacy := "" // ไฟล์ของแถวที่ import ก่อนมี import_source
+		if d, err := DefaultProfile(p.Kind); err == nil {
+			legacy = d.File
+		}
+		for pid, e := range current {
+			from := e.ImportSource
+			if from == "" {
+				from = legacy
+			}
+			if !e.Deleted && e.SourceHash != "" && seen[pid] == 0 && from == source {
+				dropRow(e)
+			}
+		}
+	}
+	res.Deleted = len(drop)
+
+	// ---- SQLite ----
+	keep := map[uint]importRow{} // ID → แถว (ทุกแถวในไฟล์ที่ผ่าน ใช้เทียบ GIS)
+	var fresh []importRow        // แถวใหม่ (dry-run ยังไม่มี ID)
+	write := func(tx *gorm.DB) error {
+		for _, r := range parsed {
+			e, ok := current[r.PlaceID]
+			plan := RowPlan{Line: r.Line, PlaceID: r.PlaceID, ID: e.ID}
+			switch {
+			case !ok:
+				res.Created++
+				if opt.DryRun {
+					fresh = append(fresh, r)
+					res.Plan = append(res.Plan, RowPlan{Line: r.Line, PlaceID: r.PlaceID, Action: ActionCreate})
+					continue
+				}
+				if err := tx.Create(r.Model).Error; err != nil {
+					return fmt.Errorf("แถว %d: %w", r.Line, err)
+				}
+				e.ID = spec.id(r.Model)
+				plan.ID, plan.Action = e.ID, ActionCreate
+			case e.Deleted && e.SourceHash == r.Hash:
+				res.Unchanged++ // ถูกลบผ่าน API หลัง import และไฟล์ยังไม่เปลี่ยน → ไม่กู้คืน
+				plan.Action = ActionSkip
+				res.Plan = append(res.Plan, plan)
+				continue
+			case e.SourceHash == r.Hash && !opt.Force:
+				res.Unchanged++
+				keep[e.ID] = r
+				plan.Action = ActionSkip
+				res.Plan = append(res.Plan, plan)
+				continue
+			default:
+				res.Updated++
+				plan.Action = ActionUpdate
+				if opt.DryRun {
+					res.Plan = append(res.Plan, plan)
+					keep[e.ID] = r
+					continue
+				}
+				// เขียนทุกคอลัมน์ (รวม zero value; deleted_at = NULL กู้คืนแถวที่แถวในไฟล์เปลี่ยน) ยกเว้นรีวิว
+				if err := tx.Unscoped().Model(spec.model()).Where("id = ?", e.ID).
+					Select("*").Omit("id", "created_at", "review").Updates(r.Model).Error; err != nil {
+					return fmt.Errorf("แถว %d: %w", r.Line, err)
+				}
+			}
+			keep[e.ID] = r
+			res.Plan = append(res.Plan, plan)
+			if err := linkTypes(tx, spec, e.ID, r.Types); err != nil {
+				return fmt.Errorf("แถว %d: %w", r.Line, err)
+			}
+		}
+		if len(drop) > 0 && !opt.DryRun {
+			if err := tx.Where("id IN ?", drop).Delete(spec.model()).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if opt.DryRun {
+		if err := write(dbSqlite); err != nil {
+			return res, err
+		}
+	} else if err := dbSqlite.Transaction(write); err != nil {
+		typeCache = map[string]uint{} // ID ใน cache อาจมาจาก tx ที่ rollback
+		return res, err
+	}
+
+	// ---- PostGIS ----
+	res.GisCreated = len(fresh)
+	return res, syncGis(spec, keep, drop, &res, opt.DryRun)
+}
+
+// syncGis เทียบพิกัดในตาราง *_gis กับแถวในไฟล์ เขียนเฉพาะจุดที่ต่าง แล้วล้างแคชระยะของจุดนั้น
+func syncGis(spec importSpec, keep map[uint]importRow, drop []uint, res *ImportResult, dryRun bool) error {
+	have, err := gisPoints(spec)
+	if err != nil {
+		return err
+	}
+
+	var create, move []uint
+	for id, r := range keep {
+		p, ok := have[id]
+		switch {
+		case !ok:
+			create = append(create, id)
+		case math.Abs(p[0]-roundCoord(r.Lon)) > gisEpsilon || math.Abs(p[1]-roundCoord(r.Lat)) > gisEpsilon:
+			move = append(move, id)
+		}
+	}
+	var remove []uint
+	for _, id := range drop {
+		if _, ok := have[id]; ok {
+			remove = append(remove, id)
+		}
+	}
+	res.GisCreated += len(create)
+	res.GisMoved = len(move)
+	res.GisDeleted = len(remove)
+	if dryRun || !res.gisChanged() {
+		return nil
+	}
+
+	err = dbPostgres.Transaction(func(tx *gorm.DB) error {
+		for _, id := range create {
+			r := keep[id]
+			if err := tx.Exec("INSERT INTO "+spec.GisTable+" ("+spec.GisCol+", location, created_at, updated_at) "+
+				"VALUES (?, ST_GeomFromText(?, 4326), NOW(), NOW())", id, pointWKT(r)).Error; err != nil {
+				return err
+			}
+		}
+		for _, id := range move {
+			if err := tx.Exec("UPDATE "+spec.GisTable+" SET location = ST_GeomFromText(?, 4326), updated_at = NOW() "+
+				"WHERE "+spec.GisCol+" = ?", pointWKT(keep[id]), id).Error; err != nil {
+				return err
+			}
+		}
+		if len(remove) > 0 {
+			return tx.Exec("DELETE FROM "+spec.GisTable+" WHERE "+spec.GisCol+" IN ?", remove).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// ระยะที่แคชไว้ของจุดที่ย้าย/ลบใช้ไม่ได้แล้ว (จุดใหม่อาจถูกจำไว้ว่าไม่มีจุด)
+	codes := make([]string, 0, len(create)+len(move)+len(remove))
+	for _, id := range slices.Concat(create, move, remove) {
+		codes = append(codes, fmt.Sprintf("%s%d", spec.Prefix, id))
+	}
+	return distcache.Invalidate(dbPostgres, codes...)
+}
+
+// gisPoints พิกัด [lon, lat] ทั้งหมดในตาราง *_gis ของชนิดนี้ ตาม id ของ SQLite
+func gisPoints(spec importSpec) (map[uint][2]float64, error) {
+	var points []struct {
+		ID       uint
+		Lon, Lat float64
+	}
+	if err := dbPostgres.Raw("SELECT " + spec.GisCol + " AS id, ST_X(location) AS lon, ST_Y(location) AS lat FROM " +
+		spec.GisTable).Scan(&points).Error; err != nil {
+		return nil, err
+	}
+	have := make(map[uint][2]float64, len(points))
+	for _, p := range points {
+		have[p.ID] = [2]float64{p.Lon, p.Lat}
+	}
+	return have, nil
+}
+
+// roundCoord ค่าที่ได้หลังเขียนลง WKT ด้วย %f (6 ตำแหน่ง)
+func roundCoord(v float32) float64 {
+	return math.Round(float64(v)*1e6) / 1e6
+}
+
+func pointWKT(r importRow) string {
+	return fmt.Sprintf("POINT(%f %f)", r.Lon, r.Lat)
+}
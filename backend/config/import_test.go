@@ -0,0 +1,253 @@
+package config
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+var landmarkHeader = []string{"Place ID", "Name", "Latitude", "Longitude", "PriceAdult", "Type"}
+
+func importLandmarks(t *testing.T, rows [][]string, opt ImportOptions) ImportResult {
+	t.Helper()
+	p, err := DefaultProfile("landmark")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := importKind(p, landmarkHeader, rows, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res
+}
+
+// activePlaceIDs PlaceID ของแลนด์มาร์กที่ยังไม่ถูกลบ
+func activePlaceIDs(t *testing.T) []int {
+	t.Helper()
+	var ids []int
+	if err := dbSqlite.Model(&entity.Landmark{}).Order("place_id").Pluck("place_id", &ids).Error; err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestPruneKeepsUploadedRows(t *testing.T) {
+	openTestDBs(t)
+	importLandmarks(t, [][]string{
+		{"1", "วัดหนึ่ง", "13.75", "100.50", "50", "วัด"},
+		{"2", "วัดสอง", "13.76", "100.51", "", "วัด"},
+	}, ImportOptions{})
+	importLandmarks(t, [][]string{
+		{"3", "ตลาดที่อัปโหลด", "13.77", "100.52", "", "ตลาด"},
+	}, ImportOptions{Source: UploadSource})
+	// แถวที่ import ก่อนมีคอลัมน์ import_source ถือว่ามาจากไฟล์มาตรฐาน
+	if err := dbSqlite.Model(&entity.Landmark{}).Where("place_id = 2").Update("import_source", "").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// รัน import มาตรฐาน (-prune) ด้วยไฟล์ที่เหลือแค่ PlaceID 1
+	res := importLandmarks(t, [][]string{{"1", "วัดหนึ่ง", "13.75", "100.50", "50", "วัด"}}, ImportOptions{Prune: true})
+	if res.Deleted != 1 || res.GisDeleted != 1 {
+		t.Errorf("ลบ %d แถว / %d จุด ต้องการ 1 / 1", res.Deleted, res.GisDeleted)
+	}
+	if got := activePlaceIDs(t); !equalInts(got, []int{1, 3}) {
+		t.Errorf("เหลือ %v ต้องการ [1 3] (แถวที่อัปโหลดต้องไม่ถูก prune)", got)
+	}
+
+	// prune ของไฟล์ที่อัปโหลดก็ไม่แตะแถวจาก config/*.xlsx
+	res = importLandmarks(t, nil, ImportOptions{Prune: true, Source: UploadSource})
+	if got := activePlaceIDs(t); res.Deleted != 1 || !equalInts(got, []int{1}) {
+		t.Errorf("prune ของ upload: ลบ %d เหลือ %v ต้องการ 1, [1]", res.Deleted, got)
+	}
+}
+
+// planActions "PlaceID:action" ของแต่ละแถวใน Plan ตามลำดับ
+func planActions(res ImportResult) []string {
+	out := make([]string, len(res.Plan))
+	for i, p := range res.Plan {
+		out[i] = strconv.Itoa(p.PlaceID) + ":" + p.Action
+	}
+	return out
+}
+
+// counts สรุปตัวเลขของผล import (created/updated/unchanged/deleted + GIS)
+func counts(res ImportResult) [7]int {
+	return [7]int{res.Created, res.Updated, res.Unchanged, res.Deleted, res.GisCreated, res.GisMoved, res.GisDeleted}
+}
+
+func landmarkByPlaceID(t *testing.T, pid int) entity.Landmark {
+	t.Helper()
+	var l entity.Landmark
+	if err := dbSqlite.Unscoped().Where("place_id = ?", pid).First(&l).Error; err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func gisCount(t *testing.T) int64 {
+	t.Helper()
+	var n int64
+	if err := dbPostgres.Table("landmark_gis").Count(&n).Error; err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestImportCreateUpdateSkip(t *testing.T) {
+	openTestDBs(t)
+	rows := [][]string{
+		{"1", "วัดหนึ่ง", "13.75", "100.50", "50", "วัด"},
+		{"2", "ตลาดสอง", "13.76", "100.51", "", "ตลาด"},
+	}
+	res := importLandmarks(t, rows, ImportOptions{})
+	if got := counts(res); got != [7]int{2, 0, 0, 0, 2, 0, 0} {
+		t.Errorf("รอบแรก = %v ต้องสร้าง 2 แถว 2 จุด", got)
+	}
+	if got := planActions(res); !reflect.DeepEqual(got, []string{"1:create", "2:create"}) {
+		t.Errorf("plan = %v", got)
+	}
+	first := landmarkByPlaceID(t, 1)
+	if first.ImportSource != "config/Attraction_data_4.xlsx" || first.SourceHash == "" || first.PriceMin != 50 {
+		t.Errorf("แถวที่สร้าง = %+v", first)
+	}
+
+	// ไฟล์เดิม → ไม่แตะอะไร
+	res = importLandmarks(t, rows, ImportOptions{})
+	if got := counts(res); got != [7]int{0, 0, 2, 0, 0, 0, 0} {
+		t.Errorf("ไฟล์เดิม = %v ต้อง skip ทั้งหมด", got)
+	}
+	// Force → เขียนใหม่ทุกแถวแม้แฮชเท่าเดิม (พิกัดไม่ย้าย)
+	res = importLandmarks(t, rows, ImportOptions{Force: true})
+	if got := counts(res); got != [7]int{0, 2, 0, 0, 0, 0, 0} {
+		t.Errorf("Force = %v ต้อง update ทั้งหมด", got)
+	}
+
+	// แก้ชื่อ + ย้ายพิกัดแถว 1 → update ID เดิม, ย้ายจุด และล้างแคชระยะของจุดนั้น
+	if err := dbPostgres.Exec("INSERT INTO distance_cache VALUES ('P1', 'P2', 1000, NULL), ('P2', 'P9', 500, NULL)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := dbSqlite.Model(&entity.Landmark{}).Where("place_id = 1").Update("review", 7).Error; err != nil {
+		t.Fatal(err)
+	}
+	rows[0] = []string{"1", "วัดหนึ่ง (ใหม่)", "13.7501", "100.50", "50", "วัด"}
+	res = importLandmarks(t, rows, ImportOptions{})
+	if got := counts(res); got != [7]int{0, 1, 1, 0, 0, 1, 0} {
+		t.Errorf("แก้แถว 1 = %v ต้อง update 1 skip 1 ย้าย 1 จุด", got)
+	}
+	l := landmarkByPlaceID(t, 1)
+	if l.ID != first.ID || l.Name != "วัดหนึ่ง (ใหม่)" || l.Review != 7 {
+		t.Errorf("หลัง update = id %d %q review %d (ต้องคง ID และรีวิวเดิม)", l.ID, l.Name, l.Review)
+	}
+	var cached []string
+	dbPostgres.Table("distance_cache").Order("from_code").Pluck("from_code || '-' || to_code", &cached)
+	if !reflect.DeepEqual(cached, []string{"P2-P9"}) {
+		t.Errorf("แคชระยะที่เหลือ = %v ต้องล้างเฉพาะคู่ที่มี P1", cached)
+	}
+}
+
+func TestImportRestoreAfterChange(t *testing.T) {
+	openTestDBs(t)
+	rows := [][]string{{"1", "วัดหนึ่ง", "13.75", "100.50", "50", "วัด"}}
+	importLandmarks(t, rows, ImportOptions{})
+	id := landmarkByPlaceID(t, 1).ID
+	// admin ลบผ่าน API
+	if err := dbSqlite.Delete(&entity.Landmark{}, id).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// ไฟล์ยังเหมือนเดิม → ไม่กู้คืน
+	res := importLandmarks(t, rows, ImportOptions{})
+	if res.Unchanged != 1 || len(activePlaceIDs(t)) != 0 {
+		t.Errorf("ไฟล์เดิม: unchanged %d active %v ต้องยังถูกลบอยู่", res.Unchanged, activePlaceIDs(t))
+	}
+
+	// แถวในไฟล์เปลี่ยน → กู้คืนด้วย ID เดิม
+	rows[0][1] = "วัดหนึ่ง (บูรณะแล้ว)"
+	res = importLandmarks(t, rows, ImportOptions{})
+	l := landmarkByPlaceID(t, 1)
+	if res.Updated != 1 || l.DeletedAt.Valid || l.ID != id || l.Name != rows[0][1] {
+		t.Errorf("แถวเปลี่ยน: updated %d deleted %v id %d/%d %q", res.Updated, l.DeletedAt.Valid, l.ID, id, l.Name)
+	}
+}
+
+func TestImportDuplicatePlaceID(t *testing.T) {
+	openTestDBs(t)
+	// แถวซ้ำในฐานข้อมูล (โหลดซ้ำตอนบูตแบบเดิม) → เก็บ ID น้อยสุด ลบที่เหลือ
+	for _, name := range []string{"เดิม", "ซ้ำ"} {
+		if err := dbSqlite.Create(&entity.Landmark{PlaceID: 1, Name: name, SourceHash: "old"}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	res := importLandmarks(t, [][]string{
+		{"1", "วัดหนึ่ง", "13.75", "100.50", "", "วัด"},
+		{"1", "วัดหนึ่งซ้ำในไฟล์", "13.75", "100.50", "", "วัด"},
+		{"x", "ไม่มี PlaceID", "13.75", "100.50", "", ""},
+		{"", "", "", "", "", ""}, // แถวว่างไม่นับ
+	}, ImportOptions{})
+
+	if res.Rows != 3 || len(res.Errors) != 2 || res.Errors[0].Line != 3 || res.Errors[1].Line != 4 {
+		t.Errorf("rows %d errors %+v ต้องรายงานแถว 3 (ซ้ำ) และ 4 (PlaceID ผิด)", res.Rows, res.Errors)
+	}
+	if got := planActions(res); !reflect.DeepEqual(got, []string{"1:delete", "1:update"}) {
+		t.Errorf("plan = %v ต้องลบแถวซ้ำแล้ว update แถวแรก", got)
+	}
+	var names []string
+	dbSqlite.Model(&entity.Landmark{}).Order("id").Pluck("name", &names)
+	if !reflect.DeepEqual(names, []string{"วัดหนึ่ง"}) {
+		t.Errorf("เหลือ %v ต้องการแถวเดียว", names)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	openTestDBs(t)
+	importLandmarks(t, [][]string{
+		{"1", "วัดหนึ่ง", "13.75", "100.50", "50", "วัด"},
+		{"2", "ตลาดสอง", "13.76", "100.51", "", "ตลาด"},
+	}, ImportOptions{})
+	before := landmarkByPlaceID(t, 1)
+
+	res := importLandmarks(t, [][]string{
+		{"1", "วัดหนึ่ง", "13.80", "100.50", "50", "วัด"}, // ย้าย
+		{"3", "ร้านใหม่", "13.77", "100.52", "", ""},
+	}, ImportOptions{DryRun: true, Prune: true})
+	if got := counts(res); got != [7]int{1, 1, 0, 1, 1, 1, 1} {
+		t.Errorf("dry-run = %v", got)
+	}
+	want := []RowPlan{
+		{PlaceID: 2, ID: landmarkByPlaceID(t, 2).ID, Action: ActionDelete},
+		{Line: 2, PlaceID: 1, ID: before.ID, Action: ActionUpdate},
+		{Line: 3, PlaceID: 3, Action: ActionCreate}, // ยังไม่มี ID
+	}
+	if !reflect.DeepEqual(res.Plan, want) {
+		t.Errorf("plan = %+v ต้องการ %+v", res.Plan, want)
+	}
+
+	// ไม่มีอะไรถูกเขียน
+	if got := activePlaceIDs(t); !equalInts(got, []int{1, 2}) {
+		t.Errorf("dry-run เขียน SQLite: %v", got)
+	}
+	if after := landmarkByPlaceID(t, 1); after.Lat != before.Lat || after.SourceHash != before.SourceHash {
+		t.Errorf("dry-run แก้แถว 1")
+	}
+	if n := gisCount(t); n != 2 {
+		t.Errorf("dry-run เขียน GIS: %d จุด", n)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	a, b = append([]int(nil), a...), append([]int(nil), b...)
+	sort.Ints(a)
+	sort.Ints(b)
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
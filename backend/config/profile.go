@@ -0,0 +1,257 @@
+package config
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------
+// Column mapping profiles: ผูกชื่อ header ของไฟล์ → field ของสถานที่
+//   - profiles/<kind>.json ฝังมากับ binary (แก้ไฟล์แล้ว build ใหม่ หรือส่งไฟล์เองด้วย -profile)
+//   - แต่ละ field: columns = ชื่อ header ที่รับ (ตัวแรกคือชื่อหลัก ใช้ตอน export),
+//     parser = ตรวจ/แปลงค่า, required = ว่าง/ผิดไม่ได้, default = ค่าเมื่อช่องว่าง/ไม่มีคอลัมน์
+//   - ค่าที่ผิดรายงานเป็นรายแถว ไม่ทิ้งเงียบ: field required → ข้ามแถว (Errors), ที่เหลือ → Warnings
+// ------------------------------
+
+//go:embed profiles/*.json
+var profileFS embed.FS
+
+type FieldMap struct {
+	Field    string   `json:"field"`
+	Columns  []string `json:"columns"`
+	Parser   string   `json:"parser,omitempty"` // ว่าง = text
+	Required bool     `json:"required,omitempty"`
+	Default  string   `json:"default,omitempty"`
+}
+
+type Profile struct {
+	Kind   string     `json:"kind"`
+	File   string     `json:"file"`
+	Sheet  string     `json:"sheet,omitempty"` // ว่าง = ชีตแรก
+	Fields []FieldMap `json:"fields"`
+}
+
+// profileFields field ที่ตัวแปลงแถวรู้จัก (ไม่ใช่ทุก kind ใช้ทุกตัว)
+var profileFields = map[string]bool{
+	"PlaceID": true, "Name": true, "Category": true, "Lat": true, "Lon": true,
+	"Address": true, "Province": true, "District": true, "SubDistrict": true, "Postcode": true,
+	"ThumbnailURL": true, "TimeOpen": true, "TimeClose": true, "TimeOpen2": true, "TimeClose2": true,
+	"CheckIn": true, "CheckOut": true, "OpenDays": true, "Price": true, "PriceChild": true,
+	"TotalPeople": true, "TotalRoom": true, "Types": true,
+}
+
+// profileRequired field ที่ import ขาดไม่ได้ (จับคู่ PlaceID + พิกัด GIS)
+var profileRequired = []string{"PlaceID", "Name", "Lat", "Lon"}
+
+// fieldParsers ตรวจค่าดิบ (ตัดช่องว่างแล้ว) คืนค่าที่จะเก็บใน record
+var fieldParsers = map[string]func(string) (string, error){
+	"text": func(s string) (string, error) { return s, nil },
+	"int": func(s string) (string, error) {
+		n, err := strconv.Atoi(strings.ReplaceAll(s, ",", ""))
+		if err != nil {
+			return "", fmt.Errorf("ไม่ใช่จำนวนเต็ม")
+		}
+		return strconv.Itoa(n), nil
+	},
+	"float": func(s string) (string, error) {
+		if _, err := strconv.ParseFloat(s, 64); err != nil {
+			return "", fmt.Errorf("ไม่ใช่ตัวเลข")
+		}
+		return s, nil
+	},
+	"lat": coordParser(90),
+	"lon": coordParser(180),
+	"url": func(s string) (string, error) {
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("ไม่ใช่ URL http(s)")
+		}
+		return s, nil
+	},
+}
+
+func coordParser(limit float64) func(string) (string, error) {
+	return func(s string) (string, error) {
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < -limit || v > limit {
+			return "", fmt.Errorf("ต้องเป็นตัวเลขระหว่าง -%g ถึง %g", limit, limit)
+		}
+		return s, nil
+	}
+}
+
+// validate ตรวจ profile ก่อนใช้ (field/parser ที่ไม่รู้จัก, field ซ้ำ, ขาด field ที่จำเป็น)
+func (p Profile) validate() error {
+	if _, ok := findImportSpec(p.Kind); !ok {
+		return fmt.Errorf("profile: ไม่รู้จัก kind %q", p.Kind)
+	}
+	seen := map[string]bool{}
+	for _, f := range p.Fields {
+		switch {
+		case !profileFields[f.Field]:
+			return fmt.Errorf("profile %s: ไม่รู้จัก field %q", p.Kind, f.Field)
+		case seen[f.Field]:
+			return fmt.Errorf("profile %s: field %q ซ้ำ", p.Kind, f.Field)
+		case len(f.Columns) == 0:
+			return fmt.Errorf("profile %s: field %q ไม่มี columns", p.Kind, f.Field)
+		case f.Parser != "" && fieldParsers[f.Parser] == nil:
+			return fmt.Errorf("profile %s: field %q ใช้ parser %q ที่ไม่รู้จัก", p.Kind, f.Field, f.Parser)
+		}
+		seen[f.Field] = true
+	}
+	for _, f := range profileRequired {
+		if !seen[f] {
+			return fmt.Errorf("profile %s: ต้องมี field %s", p.Kind, f)
+		}
+	}
+	return nil
+}
+
+func parseProfile(b []byte) (Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("อ่าน profile ไม่ได้: %w", err)
+	}
+	return p, p.validate()
+}
+
+// LoadProfile อ่าน profile จากไฟล์ (แทน profile ที่ฝังมาของ kind เดียวกัน)
+func LoadProfile(path string) (Profile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, err
+	}
+	return parseProfile(b)
+}
+
+// DefaultProfile profile ที่ฝังมากับ binary ของ kind
+func DefaultProfile(kind string) (Profile, error) {
+	b, err := profileFS.ReadFile("profiles/" + kind + ".json")
+	if err != nil {
+		return Profile{}, fmt.Errorf("ไม่มี profile ของ %q", kind)
+	}
+	return parseProfile(b)
+}
+
+// ------------------------------
+// Binding: profile + header ของไฟล์จริง
+// ------------------------------
+
+// record ค่าของแต่ละ field ในหนึ่งแถว (ผ่าน parser + default แล้ว)
+type record map[string]string
+
+func (r record) int(field string) int {
+	n, _ := strconv.Atoi(r[field])
+	return n
+}
+
+func (r record) float32(field string) float32 {
+	v, _ := strconv.ParseFloat(r[field], 32)
+	return float32(v)
+}
+
+type binding struct {
+	profile Profile
+	index   []int // ตำแหน่งคอลัมน์ของ profile.Fields[i] (-1 = ไม่มีในไฟล์)
+	Missing []string
+	Unused  []string
+}
+
+// headerCandidates ชื่อ header ที่รับของ field: ตามที่ profile ระบุ + แบบ snake_case/ติดกัน
+func headerCandidates(f FieldMap) []string {
+	var out []string
+	for _, c := range f.Columns {
+		snake := strings.NewReplacer(" ", "_", "-", "_").Replace(strings.ToLower(c))
+		out = append(out, c, snake, strings.ReplaceAll(snake, "_", ""))
+	}
+	return out
+}
+
+// bind จับคู่ header กับ profile; คอลัมน์ของ field ที่ required หายไปเก็บใน Missing
+func (p Profile) bind(header []string) *binding {
+	b := &binding{profile: p, index: make([]int, len(p.Fields))}
+	used := make([]bool, len(header))
+	for i, f := range p.Fields {
+		b.index[i] = headerIndex(header, headerCandidates(f)...)
+		switch {
+		case b.index[i] >= 0:
+			used[b.index[i]] = true
+		case f.Required && f.Default == "":
+			b.Missing = append(b.Missing, f.Columns[0])
+		}
+	}
+	for i, h := range header {
+		if !used[i] && strings.TrimSpace(h) != "" {
+			b.Unused = append(b.Unused, h)
+		}
+	}
+	return b
+}
+
+// err คอลัมน์ที่จำเป็นไม่ครบ → import ทั้งไฟล์ไม่ได้
+func (b *binding) err() error {
+	if len(b.Missing) == 0 {
+		return nil
+	}
+	return fmt.Errorf("ไม่พบคอลัมน์ที่จำเป็น: %s", strings.Join(b.Missing, ", "))
+}
+
+// record อ่านหนึ่งแถว (แถวสั้นไม่ error ช่องที่ขาดถือว่าว่าง)
+// field required ที่ว่าง/ผิด → errs (ข้ามทั้งแถว); field อื่นที่ผิด → warns แล้วปล่อยว่าง
+func (b *binding) record(row []string) (rec record, errs, warns []string) {
+	rec = record{}
+	for i, f := range b.profile.Fields {
+		v := cellAt(row, b.index[i])
+		if v == "" {
+			v = f.Default
+		}
+		if v == "" {
+			if f.Required {
+				errs = append(errs, fmt.Sprintf("%s ว่าง", f.Columns[0]))
+			}
+			continue
+		}
+		parser := fieldParsers[f.Parser]
+		if parser == nil {
+			parser = fieldParsers["text"]
+		}
+		pv, err := parser(v)
+		switch {
+		case err == nil:
+			rec[f.Field] = pv
+		case f.Required:
+			errs = append(errs, fmt.Sprintf("%s %q %v", f.Columns[0], v, err))
+		default:
+			warns = append(warns, fmt.Sprintf("%s %q %v (เว้นว่างไว้)", f.Columns[0], v, err))
+		}
+	}
+	return rec, errs, warns
+}
+
+// hash แฮชของค่าทุก field ตามลำดับใน profile (ลำดับ/ชื่อคอลัมน์ในไฟล์ไม่มีผล)
+func (r record) hash(p Profile) string {
+	parts := make([]string, len(p.Fields))
+	for i, f := range p.Fields {
+		parts[i] = f.Field + "=" + r[f.Field]
+	}
+	return hashParts(parts)
+}
+
+// encode record เป็น JSON (เก็บใน SourceRecord ของ entity; decodeRecord คือขากลับ)
+func (r record) encode() string {
+	b, _ := json.Marshal(map[string]string(r))
+	return string(b)
+}
+
+func decodeRecord(s string) record {
+	var r record
+	if s == "" || json.Unmarshal([]byte(s), &r) != nil {
+		return nil
+	}
+	return r
+}
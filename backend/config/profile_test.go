@@ -0,0 +1,159 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func testProfile() Profile {
+	return Profile{Kind: "landmark", Fields: []FieldMap{
+		{Field: "PlaceID", Columns: []string{"Place ID"}, Parser: "int", Required: true},
+		{Field: "Name", Columns: []string{"Name", "ชื่อ"}, Required: true},
+		{Field: "Category", Columns: []string{"Category"}, Default: "สถานที่ท่องเที่ยว"},
+		{Field: "Lat", Columns: []string{"Latitude"}, Parser: "lat", Required: true},
+		{Field: "Lon", Columns: []string{"Longitude"}, Parser: "lon", Required: true},
+		{Field: "SubDistrict", Columns: []string{"Sub-District"}},
+		{Field: "ThumbnailURL", Columns: []string{"Thumbnail URL"}, Parser: "url"},
+		{Field: "TotalPeople", Columns: []string{"TotalPeople"}, Parser: "int"},
+	}}
+}
+
+func TestBindHeaderAliases(t *testing.T) {
+	tests := []struct {
+		name   string
+		header []string
+		index  []int
+	}{
+		{"ชื่อหลัก", []string{"Place ID", "Name", "Latitude", "Longitude", "Sub-District", "Thumbnail URL"},
+			[]int{0, 1, -1, 2, 3, 4, 5, -1}},
+		{"snake_case + ตัวพิมพ์", []string{"place_id", "NAME", "latitude", "longitude", "sub_district", "thumbnail_url", "totalpeople"},
+			[]int{0, 1, -1, 2, 3, 4, 5, 6}},
+		{"ติดกัน + ชื่อสำรอง", []string{" PlaceID ", "ชื่อ", "Latitude", "Longitude", "subdistrict", "thumbnailurl"},
+			[]int{0, 1, -1, 2, 3, 4, 5, -1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := testProfile().bind(tt.header)
+			if !reflect.DeepEqual(b.index, tt.index) {
+				t.Errorf("index = %v ต้องการ %v", b.index, tt.index)
+			}
+			if b.err() != nil || len(b.Unused) != 0 {
+				t.Errorf("err %v unused %v", b.err(), b.Unused)
+			}
+		})
+	}
+
+	// ขาดคอลัมน์ required (ที่ไม่มี default) → import ทั้งไฟล์ไม่ได้; คอลัมน์ที่ไม่รู้จักรายงานเป็น Unused
+	b := testProfile().bind([]string{"Name", "Latitude", "Note", ""})
+	if !reflect.DeepEqual(b.Missing, []string{"Place ID", "Longitude"}) || !reflect.DeepEqual(b.Unused, []string{"Note"}) {
+		t.Errorf("missing %v unused %v", b.Missing, b.Unused)
+	}
+	if err := b.err(); err == nil || !strings.Contains(err.Error(), "Place ID, Longitude") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestBindingRecord(t *testing.T) {
+	b := testProfile().bind([]string{"Place ID", "Name", "Latitude", "Longitude", "Thumbnail URL", "TotalPeople"})
+	tests := []struct {
+		name        string
+		row         []string
+		rec         record
+		errs, warns int
+	}{
+		{"ครบ", []string{"1,024", " วัด ", "13.75", "100.5", "https://x.th/a.jpg", "30"},
+			record{"PlaceID": "1024", "Name": "วัด", "Category": "สถานที่ท่องเที่ยว", "Lat": "13.75", "Lon": "100.5",
+				"ThumbnailURL": "https://x.th/a.jpg", "TotalPeople": "30"}, 0, 0},
+		// field ที่ไม่ required ผิด → เตือนแล้วเว้นว่าง แถวยังใช้ได้
+		{"ค่าเสริมผิด", []string{"2", "ตลาด", "13.75", "100.5", "ftp://x", "ราว 30"},
+			record{"PlaceID": "2", "Name": "ตลาด", "Category": "สถานที่ท่องเที่ยว", "Lat": "13.75", "Lon": "100.5"}, 0, 2},
+		// required ผิด/ว่าง → error ทั้งแถว; แถวสั้นถือว่าช่องที่ขาดว่าง
+		{"required ผิด", []string{"x", "ร้าน", "95", "100.5"},
+			record{"Name": "ร้าน", "Category": "สถานที่ท่องเที่ยว", "Lon": "100.5"}, 2, 0},
+		{"แถวสั้น", []string{"3"},
+			record{"PlaceID": "3", "Category": "สถานที่ท่องเที่ยว"}, 3, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, errs, warns := b.record(tt.row)
+			if !reflect.DeepEqual(rec, tt.rec) {
+				t.Errorf("record = %v ต้องการ %v", rec, tt.rec)
+			}
+			if len(errs) != tt.errs || len(warns) != tt.warns {
+				t.Errorf("errs %q warns %q ต้องการ %d / %d", errs, warns, tt.errs, tt.warns)
+			}
+		})
+	}
+}
+
+func TestProfileValidate(t *testing.T) {
+	edit := func(fn func(p *Profile)) Profile {
+		p := testProfile()
+		p.Fields = append([]FieldMap(nil), p.Fields...)
+		fn(&p)
+		return p
+	}
+	tests := []struct {
+		name string
+		p    Profile
+		want string // ส่วนหนึ่งของ error ("" = ผ่าน)
+	}{
+		{"ใช้ได้", testProfile(), ""},
+		{"kind ผิด", edit(func(p *Profile) { p.Kind = "hotel" }), "ไม่รู้จัก kind"},
+		{"field ผิด", edit(func(p *Profile) { p.Fields[2].Field = "Rating" }), "ไม่รู้จัก field"},
+		{"field ซ้ำ", edit(func(p *Profile) { p.Fields[2].Field = "Name" }), "ซ้ำ"},
+		{"ไม่มี columns", edit(func(p *Profile) { p.Fields[2].Columns = nil }), "ไม่มี columns"},
+		{"parser ผิด", edit(func(p *Profile) { p.Fields[2].Parser = "date" }), "parser"},
+		{"ขาด Lat", edit(func(p *Profile) { p.Fields = append(p.Fields[:3], p.Fields[4:]...) }), "ต้องมี field Lat"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.p.validate()
+			if (err == nil) != (tt.want == "") || (err != nil && !strings.Contains(err.Error(), tt.want)) {
+				t.Errorf("validate = %v ต้องการ %q", err, tt.want)
+			}
+		})
+	}
+
+	if _, err := parseProfile([]byte(`{"kind": "landmark", "fields": [`)); err == nil {
+		t.Errorf("JSON เสียต้อง error")
+	}
+}
+
+// profile ที่ฝังมาต้องอ่านไฟล์ xlsx ที่มากับ repo ได้ครบโดยไม่มีแถวเสีย
+func TestDefaultProfilesBindShippedFiles(t *testing.T) {
+	for _, kind := range []string{"landmark", "restaurant", "accommodation"} {
+		t.Run(kind, func(t *testing.T) {
+			p, err := DefaultProfile(kind)
+			if err != nil {
+				t.Fatal(err)
+			}
+			header, rows, err := readSheet(filepath.Join("..", p.File), p.Sheet)
+			if err != nil {
+				t.Fatal(err)
+			}
+			b := p.bind(header)
+			if err := b.err(); err != nil {
+				t.Fatal(err)
+			}
+			n := 0
+			for i, row := range rows {
+				if blankRow(row) {
+					continue
+				}
+				n++
+				rec, errs, _ := b.record(row)
+				if len(errs) > 0 {
+					t.Errorf("แถว %d: %s", i+2, strings.Join(errs, "; "))
+				} else if _, err := newImportRow(rec); err != nil {
+					t.Errorf("แถว %d: %v", i+2, err)
+				}
+			}
+			if n == 0 {
+				t.Errorf("ไม่มีแถวข้อมูล")
+			}
+		})
+	}
+}
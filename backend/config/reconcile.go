@@ -0,0 +1,274 @@
+package config
+
+import (
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/roads"
+)
+
+// ------------------------------
+// ตรวจ/ซ่อมความสอดคล้องระหว่าง SQLite กับ PostGIS (cmd/reconcile, /admin/reconcile)
+//   controller เขียนสองฐานแยกกันโดยไม่มี transaction ร่วม (สร้างแล้ว GIS พัง, ลบ GIS แล้ว SQLite พัง ฯลฯ)
+//   ตัวนี้หาสิ่งที่หลุด แล้วถ้าสั่ง Repair ให้ยึด SQLite เป็นหลัก:
+//   - จุดใน *_gis ที่ไม่มีสถานที่ (หรือถูก soft delete แล้ว) / จุดซ้ำของสถานที่เดียวกัน → ลบ
+//   - สถานที่ที่ไม่มีจุด → เพิ่มจาก Lat/Lon
+//   - จุดที่ต่างจาก Lat/Lon เกิน gisEpsilon หรือ location ว่าง → ย้ายตาม Lat/Lon
+//   - pivot ประเภทฝั่ง PG → syncTypesToPostgres
+//   สถานที่ที่ SQLite ไม่มีพิกัด (0, 0) รายงานอย่างเดียว ไม่เขียนจุดขยะลง GIS
+// ------------------------------
+
+// GisIssue จุด/สถานที่หนึ่งรายการที่สองฐานไม่ตรงกัน (พิกัด 0 = ไม่มีค่าฝั่งนั้น)
+type GisIssue struct {
+	Code    string  `json:"code"`
+	PlaceID int     `json:"place_id,omitempty"`
+	Name    string  `json:"name,omitempty"`
+	Lat     float64 `json:"lat,omitempty"` // SQLite
+	Lon     float64 `json:"lon,omitempty"`
+	GisLat  float64 `json:"gis_lat,omitempty"` // PostGIS
+	GisLon  float64 `json:"gis_lon,omitempty"`
+	Note    string  `json:"note,omitempty"`
+
+	gisRowID uint   // id ของแถวใน *_gis (ใช้ลบจุดซ้ำทีละแถว)
+	placeID  uint   // id ใน SQLite
+	wkt      string // จุดตาม Lat/Lon ของ SQLite (ว่าง = ไม่มีพิกัด ซ่อมไม่ได้)
+}
+
+// ReconcileResult ผลของสถานที่ชนิดหนึ่ง
+type ReconcileResult struct {
+	Kind          string     `json:"kind"`
+	Places        int        `json:"places"` // แถวที่ยังไม่ถูกลบใน SQLite
+	Points        int        `json:"points"` // แถวใน *_gis
+	Orphans       []GisIssue `json:"orphans"`
+	MissingPoints []GisIssue `json:"missing_points"`
+	Mismatched    []GisIssue `json:"mismatched"`
+	PivotMissing  int        `json:"pivot_missing"` // คู่ (สถานที่, ประเภท) ที่ PG ยังไม่มี
+	PivotExtra    int        `json:"pivot_extra"`   // แถว pivot ฝั่ง PG ที่เกิน/ซ้ำ
+}
+
+func (r ReconcileResult) gisIssues() int {
+	return len(r.Orphans) + len(r.MissingPoints) + len(r.Mismatched)
+}
+
+type ReconcileReport struct {
+	Repaired     bool              `json:"repaired"`
+	TypesMissing int               `json:"types_missing"` // travel_types ใน SQLite ที่ PG ยังไม่มี
+	Kinds        []ReconcileResult `json:"kinds"`
+}
+
+// Clean ไม่พบอะไรไม่ตรงเลย
+func (r ReconcileReport) Clean() bool {
+	if r.TypesMissing > 0 {
+		return false
+	}
+	for _, k := range r.Kinds {
+		if k.gisIssues()+k.PivotMissing+k.PivotExtra > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Reconcile ตรวจทุกชนิดสถานที่; repair = ซ่อมตามที่พบ (รายงานคือสิ่งที่พบก่อนซ่อม)
+func Reconcile(repair bool) (ReconcileReport, error) {
+	// กันชนกับ import ที่กำลังเขียนสองฐานอยู่
+	importMu.Lock()
+	defer importMu.Unlock()
+
+	rep := ReconcileReport{}
+	var types []entity.TravelType
+	if err := dbSqlite.Find(&types).Error; err != nil {
+		return rep, err
+	}
+	pgID, err := pgTypeIDs()
+	if err != nil {
+		return rep, err
+	}
+	for _, t := range types {
+		if _, ok := pgID(t); !ok {
+			rep.TypesMissing++
+		}
+	}
+
+	for _, spec := range importSpecs {
+		res, err := reconcileGis(spec)
+		if err != nil {
+			return rep, fmt.Errorf("%s: %w", spec.GisTable, err)
+		}
+		want, err := pivotsWanted(spec, typesByID(types), pgID)
+		if err != nil {
+			return rep, err
+		}
+		extra, missing, err := pivotDiff(spec, want)
+		if err != nil {
+			return rep, fmt.Errorf("%s: %w", spec.PivotTable, err)
+		}
+		res.PivotExtra, res.PivotMissing = len(extra), len(missing)
+		rep.Kinds = append(rep.Kinds, res)
+	}
+	if !repair || rep.Clean() {
+		return rep, nil
+	}
+
+	rep.Repaired = true
+	gisChanged := false
+	for i, spec := range importSpecs {
+		n, err := repairGis(spec, rep.Kinds[i])
+		if err != nil {
+			return rep, fmt.Errorf("ซ่อม %s ไม่สำเร็จ: %w", spec.GisTable, err)
+		}
+		gisChanged = gisChanged || n > 0
+	}
+	if gisChanged && roads.HasNetwork(dbPostgres) {
+		if _, err := roads.Snap(dbPostgres, roads.DefaultSnapM); err != nil {
+			return rep, fmt.Errorf("snap สถานที่เข้ากับถนนไม่สำเร็จ: %w", err)
+		}
+	}
+	if err := syncTypesToPostgres(); err != nil {
+		return rep, fmt.Errorf("sync types to Postgres failed: %w", err)
+	}
+	return rep, nil
+}
+
+type reconcilePlace struct {
+	ID       uint
+	PlaceID  int
+	Name     string
+	Lat, Lon float32
+	Deleted  bool
+}
+
+// reconcileGis เทียบแถวใน SQLite (รวมที่ถูก soft delete) กับจุดใน *_gis
+func reconcileGis(spec importSpec) (ReconcileResult, error) {
+	res := ReconcileResult{Kind: spec.Kind, Orphans: []GisIssue{}, MissingPoints: []GisIssue{}, Mismatched: []GisIssue{}}
+
+	var places []reconcilePlace
+	if err := dbSqlite.Raw("SELECT id, place_id, name, lat, lon, deleted_at IS NOT NULL AS deleted FROM " +
+		spec.Table).Scan(&places).Error; err != nil {
+		return res, err
+	}
+	var points []struct {
+		RowID    uint
+		ID       uint
+		Lon, Lat *float64 // location ว่าง → nil
+	}
+	if err := dbPostgres.Raw("SELECT id AS row_id, " + spec.GisCol + " AS id, ST_X(location) AS lon, ST_Y(location) AS lat FROM " +
+		spec.GisTable + " ORDER BY " + spec.GisCol + ", id").Scan(&points).Error; err != nil {
+		return res, err
+	}
+	res.Points = len(points)
+
+	byID := make(map[uint]reconcilePlace, len(places))
+	for _, p := range places {
+		byID[p.ID] = p
+		if !p.Deleted {
+			res.Places++
+		}
+	}
+
+	issue := func(id uint, p reconcilePlace, note string) GisIssue {
+		it := GisIssue{Code: fmt.Sprintf("%s%d", spec.Prefix, id), PlaceID: p.PlaceID, Name: p.Name,
+			Lat: roundCoord(p.Lat), Lon: roundCoord(p.Lon), Note: note, placeID: id}
+		if p.Lat != 0 || p.Lon != 0 {
+			it.wkt = pointWKT(importRow{Lat: p.Lat, Lon: p.Lon})
+		}
+		return it
+	}
+	seen := map[uint]bool{}
+	for _, pt := range points {
+		p, ok := byID[pt.ID]
+		var it GisIssue
+		switch {
+		case !ok:
+			it = issue(pt.ID, p, "ไม่มีใน SQLite")
+		case p.Deleted:
+			it = issue(pt.ID, p, "ถูกลบใน SQLite แล้ว")
+		case seen[pt.ID]:
+			it = issue(pt.ID, p, "จุดซ้ำ")
+		}
+		if it.Code != "" {
+			it.gisRowID = pt.RowID
+			if pt.Lat != nil {
+				it.GisLat, it.GisLon = *pt.Lat, *pt.Lon
+			}
+			res.Orphans = append(res.Orphans, it)
+			continue
+		}
+		seen[pt.ID] = true
+
+		switch {
+		case pt.Lat == nil:
+			res.Mismatched = append(res.Mismatched, issue(pt.ID, p, "location ว่าง"))
+		case math.Abs(*pt.Lon-roundCoord(p.Lon)) > gisEpsilon || math.Abs(*pt.Lat-roundCoord(p.Lat)) > gisEpsilon:
+			it = issue(pt.ID, p, "")
+			it.GisLat, it.GisLon = *pt.Lat, *pt.Lon
+			res.Mismatched = append(res.Mismatched, it)
+		}
+	}
+	for _, p := range places {
+		if !p.Deleted && !seen[p.ID] {
+			res.MissingPoints = append(res.MissingPoints, issue(p.ID, p, ""))
+		}
+	}
+	for _, list := range [][]GisIssue{res.MissingPoints, res.Mismatched} {
+		for i := range list {
+			if list[i].wkt == "" {
+				list[i].Note = "ไม่มีพิกัดใน SQLite (ไม่ซ่อม)"
+			}
+		}
+	}
+	return res, nil
+}
+
+// repairGis ลบจุดกำพร้า/ซ้ำ เพิ่มจุดที่ขาด ย้ายจุดที่ไม่ตรง แล้วล้างแคชระยะของจุดที่แตะ คืนจำนวนจุดที่แตะ
+func repairGis(spec importSpec, res ReconcileResult) (int, error) {
+	if res.gisIssues() == 0 {
+		return 0, nil
+	}
+	var codes []string
+	n := 0
+	err := dbPostgres.Transaction(func(tx *gorm.DB) error {
+		var rowIDs []uint
+		for _, it := range res.Orphans {
+			rowIDs = append(rowIDs, it.gisRowID)
+			codes = append(codes, it.Code)
+		}
+		if len(rowIDs) > 0 {
+			if err := tx.Exec("DELETE FROM "+spec.GisTable+" WHERE id IN ?", rowIDs).Error; err != nil {
+				return err
+			}
+			n += len(rowIDs)
+		}
+		for _, it := range res.MissingPoints {
+			if it.wkt == "" {
+				continue
+			}
+			if err := tx.Exec("INSERT INTO "+spec.GisTable+" ("+spec.GisCol+", location, created_at, updated_at) "+
+				"VALUES (?, ST_GeomFromText(?, 4326), NOW(), NOW())", it.placeID, it.wkt).Error; err != nil {
+				return err
+			}
+			codes = append(codes, it.Code)
+			n++
+		}
+		for _, it := range res.Mismatched {
+			if it.wkt == "" {
+				continue
+			}
+			if err := tx.Exec("UPDATE "+spec.GisTable+" SET location = ST_GeomFromText(?, 4326), updated_at = NOW() "+
+				"WHERE "+spec.GisCol+" = ?", it.wkt, it.placeID).Error; err != nil {
+				return err
+			}
+			codes = append(codes, it.Code)
+			n++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return n, distcache.Invalidate(dbPostgres, codes...)
+}
@@ -0,0 +1,76 @@
+package config
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+// ------------------------------
+// ฐานข้อมูลทดสอบ: SQLite ในไฟล์ชั่วคราวทั้งสองฝั่ง
+// ฝั่ง "PostGIS" เก็บ location เป็น WKT และมีฟังก์ชันเท่าที่ import ใช้ (ST_GeomFromText, ST_X, ST_Y, NOW)
+// ------------------------------
+
+var registerGisDriver sync.Once
+
+const gisDriver = "sqlite3_gis_test"
+
+func openTestDBs(t *testing.T) {
+	t.Helper()
+	registerGisDriver.Do(func() {
+		sql.Register(gisDriver, &sqlite3.SQLiteDriver{ConnectHook: func(c *sqlite3.SQLiteConn) error {
+			coord := func(wkt string, i int) float64 {
+				var p [2]float64
+				fmt.Sscanf(wkt, "POINT(%g %g)", &p[0], &p[1])
+				return p[i]
+			}
+			for name, fn := range map[string]any{
+				"ST_GeomFromText": func(wkt string, srid int) string { return wkt },
+				"ST_X":            func(wkt string) float64 { return coord(wkt, 0) },
+				"ST_Y":            func(wkt string) float64 { return coord(wkt, 1) },
+				"NOW":             func() string { return time.Now().UTC().Format(time.RFC3339) },
+			} {
+				if err := c.RegisterFunc(name, fn, name != "NOW"); err != nil {
+					return err
+				}
+			}
+			return nil
+		}})
+	})
+
+	cfg := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	dir := t.TempDir()
+	var err error
+	if dbSqlite, err = gorm.Open(sqlite.Open(filepath.Join(dir, "app.db")), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if dbPostgres, err = gorm.Open(sqlite.New(sqlite.Config{DriverName: gisDriver, DSN: filepath.Join(dir, "gis.db")}), cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := dbSqlite.AutoMigrate(&entity.Landmark{}, &entity.TravelType{}, &entity.LandmarkType{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, ddl := range []string{
+		`CREATE TABLE landmark_gis (id INTEGER PRIMARY KEY, landmark_id INTEGER, location TEXT,
+			created_at DATETIME, updated_at DATETIME, deleted_at DATETIME)`,
+		`CREATE TABLE distance_cache (from_code TEXT, to_code TEXT, meters REAL, updated_at DATETIME,
+			PRIMARY KEY (from_code, to_code))`,
+		`CREATE TABLE distance_cache_points (code TEXT PRIMARY KEY, location TEXT)`,
+	} {
+		if err := dbPostgres.Exec(ddl).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	typeCache = map[string]uint{}
+	t.Cleanup(func() { dbSqlite, dbPostgres = nil, nil })
+}
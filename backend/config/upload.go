@@ -0,0 +1,65 @@
+package config
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ------------------------------
+// ไฟล์ชุดข้อมูลที่ admin อัปโหลด (.xlsx / .csv)
+// คอลัมน์จับคู่ด้วยชื่อ header ตาม profile ของชนิดสถานที่ (ลำดับไม่ต้องตรงไฟล์ใน config/)
+// ------------------------------
+
+// ReadTable อ่านไฟล์ตามนามสกุล: .xlsx ใช้ชีตแรก, .csv ต้องเป็น UTF-8 (มี BOM ได้)
+func ReadTable(filename string, r io.Reader) ([]string, [][]string, error) {
+	var rows [][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".xlsx":
+		f, err := excelize.OpenReader(r)
+		if err != nil {
+			return nil, nil, fmt.Errorf("เปิดไฟล์ xlsx ไม่ได้: %w", err)
+		}
+		defer f.Close()
+		sheets := f.GetSheetList()
+		if len(sheets) == 0 {
+			return nil, nil, fmt.Errorf("ไฟล์ไม่มีชีต")
+		}
+		if rows, err = f.GetRows(sheets[0]); err != nil {
+			return nil, nil, err
+		}
+	case ".csv":
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		cr := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(b, []byte("\ufeff"))))
+		cr.FieldsPerRecord = -1
+		cr.LazyQuotes = true
+		if rows, err = cr.ReadAll(); err != nil {
+			return nil, nil, fmt.Errorf("อ่าน csv ไม่ได้: %w", err)
+		}
+	default:
+		return nil, nil, fmt.Errorf("รองรับเฉพาะไฟล์ .xlsx และ .csv")
+	}
+	if len(rows) == 0 {
+		return nil, nil, fmt.Errorf("ไม่มี header")
+	}
+	return rows[0], rows[1:], nil
+}
+
+// CheckColumns ตรวจ header ของไฟล์กับ profile ของ kind คืน header ที่ profile ไม่ได้ใช้
+// (error = ขาดคอลัมน์ที่จำเป็น import ไม่ได้ทั้งไฟล์)
+func CheckColumns(kind string, header []string) ([]string, error) {
+	p, err := DefaultProfile(kind)
+	if err != nil {
+		return nil, err
+	}
+	b := p.bind(header)
+	return b.Unused, b.err()
+}
@@ -2,15 +2,17 @@ package Accommodation
 
 import (
 	"fmt"
+	"log"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/domain"
 	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/search"
 )
 
 type AccommodationController struct {
@@ -25,49 +27,6 @@ func NewAccommodationController(db *gorm.DB, gisDB *gorm.DB) *AccommodationContr
 	}
 }
 
-// ------------------------ helpers ------------------------
-
-var reDigits = regexp.MustCompile(`\d[\d,]*`)
-
-// ParsePriceRange (ไม่ถามสัญชาติ): ถ้ามี "ฟรี" → min=0, max=เลขสูงสุดที่เจอ
-func parsePriceRange(s string) (int, int) {
-	if s == "" { return 0, 0 }
-	t := strings.ToLower(strings.TrimSpace(s))
-	t = strings.NewReplacer("–", "-", "—", "-", "−", "-").Replace(t)
-
-	nums := reDigits.FindAllString(t, -1)
-	toInt := func(x string) int {
-		x = strings.ReplaceAll(x, ",", "")
-		n, _ := strconv.Atoi(x)
-		return n
-	}
-	hasFree := strings.Contains(t, "ฟรี") || strings.Contains(t, "free")
-
-	if len(nums) == 0 {
-		if hasFree { return 0, 0 }
-		return 0, 0
-	}
-
-	minV := int(^uint(0) >> 1) // MaxInt
-	maxV := 0
-	for _, ns := range nums {
-		v := toInt(ns)
-		if v < minV { minV = v }
-		if v > maxV { maxV = v }
-	}
-	if minV == int(^uint(0)>>1) { minV = 0 }
-
-	if hasFree {
-		if maxV == 0 { return 0, 0 }
-		return 0, maxV
-	}
-	if len(nums) == 1 {
-		return maxV, maxV
-	}
-	if minV > maxV { minV, maxV = maxV, minV }
-	return minV, maxV
-}
-
 // ------------------------ handlers ------------------------
 
 // Create Accommodation + GIS
@@ -86,7 +45,9 @@ func (ctl *AccommodationController) Create(c *gin.Context) {
 		ThumbnailURL string    `json:"thumbnail_url"`
 		TimeOpen     time.Time `json:"time_open"`
 		TimeClose    time.Time `json:"time_close"`
+		OpenDays     string    `json:"open_days"`
 		TotalPeople  string    `json:"total_people"`
+		TotalRoom    string    `json:"total_room"`
 		Price        string    `json:"price"`
 		Review       int       `json:"review"`
 	}
@@ -95,7 +56,22 @@ func (ctl *AccommodationController) Create(c *gin.Context) {
 		return
 	}
 
-	pmin, pmax := parsePriceRange(input.Price)
+	// ค่าที่อ่านไม่ออกไม่ทำให้ request ล้ม: เก็บข้อความดิบไว้ (ค่าที่ parse เป็น Unknown) แล้วแจ้งใน warnings แบบเดียวกับ import
+	var warnings []string
+	warn := func(field string, err error) {
+		if err != nil {
+			warnings = append(warnings, field+": "+err.Error())
+		}
+	}
+
+	// ที่พักเปิด 24 ชม. ตามวันเปิด (time_open/time_close = check-in/check-out)
+	hours, err := domain.ParseHours("24 ชม.", "", input.OpenDays, "", "")
+	warn("open_days", err)
+	price, err := domain.ParsePrice(input.Price, domain.PerRoomNight)
+	warn("price", err)
+	capacity, err := domain.ParseCapacity(input.TotalPeople, input.TotalRoom)
+	warn("capacity", err)
+	pmin, pmax := price.Bounds()
 
 	acc := entity.Accommodation{
 		PlaceID:      input.PlaceID,
@@ -111,8 +87,12 @@ func (ctl *AccommodationController) Create(c *gin.Context) {
 		ThumbnailURL: input.ThumbnailURL,
 		Time_open:    input.TimeOpen,
 		Time_close:   input.TimeClose,
+		Open_days:    input.OpenDays,
+		OpeningHours: hours,
 		Total_people: input.TotalPeople,
 		Price:        input.Price,
+		PriceInfo:    price,
+		Capacity:     capacity,
 		Review:       input.Review,
 		PriceMin:     pmin,
 		PriceMax:     pmax,
@@ -132,7 +112,18 @@ func (ctl *AccommodationController) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Accommodation created", "id": acc.ID})
+	// เคยถูกถามระยะก่อนมีจุด → แคชจำไว้ว่าไม่มีจุด ต้องล้างทิ้ง
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("A%d", acc.ID)); err != nil {
+		log.Printf("accommodation %d: invalidate distance cache: %v", acc.ID, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
+
+	search.Invalidate() // ดัชนี typeahead สร้างใหม่ตอนค้นครั้งถัดไป
+	resp := gin.H{"message": "Accommodation created", "id": acc.ID}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 // Get all accommodations + location
@@ -148,18 +139,25 @@ func (ctl *AccommodationController) GetAll(c *gin.Context) {
 		Location string `json:"location"`
 	}
 
+	// WKT ของทุกแถวใน query เดียว (ไม่มีใน GIS → location ว่าง)
+	var locs []struct {
+		ID  uint
+		WKT string
+	}
+	if err := ctl.PostgisDB.Raw(
+		"SELECT acc_id AS id, ST_AsText(location) AS wkt FROM accommodation_gis").Scan(&locs).Error; err != nil {
+		locs = nil
+	}
+	wkt := make(map[uint]string, len(locs))
+	for _, l := range locs {
+		wkt[l.ID] = l.WKT
+	}
+
 	results := make([]AccWithLocation, 0, len(accs))
 	for _, acc := range accs {
-		var location string
-		err := ctl.PostgisDB.Raw(
-			"SELECT ST_AsText(location) FROM accommodation_gis WHERE acc_id = ?", acc.ID).
-			Scan(&location).Error
-		if err != nil {
-			location = ""
-		}
 		results = append(results, AccWithLocation{
 			Accommodation: acc,
-			Location:      location,
+			Location:      wkt[acc.ID],
 		})
 	}
 
@@ -214,7 +212,9 @@ func (ctl *AccommodationController) Update(c *gin.Context) {
 		ThumbnailURL string    `json:"thumbnail_url"`
 		TimeOpen     time.Time `json:"time_open"`
 		TimeClose    time.Time `json:"time_close"`
+		OpenDays     string    `json:"open_days"`
 		TotalPeople  string    `json:"total_people"`
+		TotalRoom    string    `json:"total_room"`
 		Price        string    `json:"price"`
 		Review       int       `json:"review"`
 	}
@@ -229,7 +229,22 @@ func (ctl *AccommodationController) Update(c *gin.Context) {
 		return
 	}
 
-	pmin, pmax := parsePriceRange(input.Price)
+	// ค่าที่อ่านไม่ออกไม่ทำให้ request ล้ม: เก็บข้อความดิบไว้ (ค่าที่ parse เป็น Unknown) แล้วแจ้งใน warnings แบบเดียวกับ import
+	var warnings []string
+	warn := func(field string, err error) {
+		if err != nil {
+			warnings = append(warnings, field+": "+err.Error())
+		}
+	}
+
+	// ที่พักเปิด 24 ชม. ตามวันเปิด (time_open/time_close = check-in/check-out)
+	hours, err := domain.ParseHours("24 ชม.", "", input.OpenDays, "", "")
+	warn("open_days", err)
+	price, err := domain.ParsePrice(input.Price, domain.PerRoomNight)
+	warn("price", err)
+	capacity, err := domain.ParseCapacity(input.TotalPeople, input.TotalRoom)
+	warn("capacity", err)
+	pmin, pmax := price.Bounds()
 
 	acc.PlaceID = input.PlaceID
 	acc.Name = input.Name
@@ -244,8 +259,12 @@ func (ctl *AccommodationController) Update(c *gin.Context) {
 	acc.ThumbnailURL = input.ThumbnailURL
 	acc.Time_open = input.TimeOpen
 	acc.Time_close = input.TimeClose
+	acc.Open_days = input.OpenDays
+	acc.OpeningHours = hours
 	acc.Total_people = input.TotalPeople
 	acc.Price = input.Price
+	acc.PriceInfo = price
+	acc.Capacity = capacity
 	acc.Review = input.Review
 	acc.PriceMin = pmin
 	acc.PriceMax = pmax
@@ -263,7 +282,18 @@ func (ctl *AccommodationController) Update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Accommodation updated"})
+	// พิกัดเปลี่ยน → ระยะที่แคชไว้ของที่นี่ใช้ไม่ได้แล้ว
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("A%d", acc.ID)); err != nil {
+		log.Printf("accommodation %d: invalidate distance cache: %v", acc.ID, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
+
+	search.Invalidate()
+	resp := gin.H{"message": "Accommodation updated"}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // Delete accommodation + GIS
@@ -279,11 +309,21 @@ func (ctl *AccommodationController) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete accommodation GIS"})
 		return
 	}
+	var warnings []string
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("A%d", id)); err != nil {
+		log.Printf("accommodation %d: invalidate distance cache: %v", id, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
 
 	if err := ctl.MysqlDB.Delete(&entity.Accommodation{}, id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete accommodation"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Accommodation deleted"})
+	search.Invalidate()
+	resp := gin.H{"message": "Accommodation deleted"}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
 }
@@ -0,0 +1,180 @@
+package Dataset
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+	"github.com/gtwndtl/trip-spark-builder/search"
+)
+
+// ------------------------------------------------------------
+// นำเข้าชุดข้อมูลสถานที่จากไฟล์ (admin)
+//   POST   /admin/imports?kind=landmark  (multipart: file=.xlsx|.csv) → dry-run report + import_id
+//   POST   /admin/imports/:id/apply      เขียนจริงลง SQLite + PostGIS (คำนวณใหม่อีกรอบตอนเขียน)
+//   DELETE /admin/imports/:id            ทิ้งไฟล์ที่อัปโหลดไว้
+// ใช้ตัว import เดียวกับ cmd/dataimport (upsert ตาม PlaceID) แต่ไม่ลบสถานที่ที่ไม่มีในไฟล์
+// แถวที่เขียนถูกบันทึกที่มาเป็น config.UploadSource → prune ของ cmd/dataimport ไม่ลบแถวเหล่านี้
+// ------------------------------------------------------------
+
+const (
+	maxUploadBytes = 20 << 20
+	maxUploadRows  = 20000
+	pendingTTL     = 30 * time.Minute
+	maxPending     = 20
+)
+
+// pendingImport ไฟล์ที่ผ่าน dry-run แล้ว รอ admin ยืนยัน (เก็บในหน่วยความจำ หายเมื่อ restart)
+type pendingImport struct {
+	ID       string
+	Kind     string
+	Filename string
+	Header   []string
+	Rows     [][]string
+	Expires  time.Time
+}
+
+type DatasetController struct {
+	mu      sync.Mutex
+	pending map[string]*pendingImport
+}
+
+func NewDatasetController() *DatasetController {
+	return &DatasetController{pending: map[string]*pendingImport{}}
+}
+
+type PreviewResp struct {
+	ImportID      string              `json:"import_id"`
+	ExpiresAt     time.Time           `json:"expires_at"`
+	Filename      string              `json:"filename"`
+	UnusedColumns []string            `json:"unused_columns,omitempty"`
+	Report        config.ImportResult `json:"report"`
+}
+
+var kinds = map[string]bool{"landmark": true, "restaurant": true, "accommodation": true}
+
+// POST /admin/imports?kind=landmark
+func (ctrl *DatasetController) Preview(c *gin.Context) {
+	kind := c.Query("kind")
+	if !kinds[kind] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind ต้องเป็น landmark, restaurant หรือ accommodation"})
+		return
+	}
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxUploadBytes)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาแนบไฟล์ในฟิลด์ file (ไม่เกิน 20 MB)"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	header, rows, err := config.ReadTable(fh.Filename, f)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(rows) > maxUploadRows {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ไฟล์มี %d แถว เกิน %d", len(rows), maxUploadRows)})
+		return
+	}
+	unused, err := config.CheckColumns(kind, header)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := config.ImportRows(kind, header, rows, config.ImportOptions{DryRun: true, Source: config.UploadSource})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ตรวจไฟล์ไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+	report.Source = fh.Filename
+
+	p := &pendingImport{ID: newImportID(), Kind: kind, Filename: fh.Filename,
+		Header: header, Rows: rows, Expires: time.Now().Add(pendingTTL)}
+	if !ctrl.put(p) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "มีไฟล์รอยืนยันมากเกินไป กรุณายืนยันหรือยกเลิกของเดิมก่อน"})
+		return
+	}
+	c.JSON(http.StatusOK, PreviewResp{ImportID: p.ID, ExpiresAt: p.Expires, Filename: p.Filename,
+		UnusedColumns: unused, Report: report})
+}
+
+// POST /admin/imports/:id/apply
+func (ctrl *DatasetController) Apply(c *gin.Context) {
+	p := ctrl.take(c.Param("id"))
+	if p == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบไฟล์ที่รอยืนยัน (อาจหมดอายุแล้ว กรุณาอัปโหลดใหม่)"})
+		return
+	}
+
+	res, err := config.ImportRows(p.Kind, p.Header, p.Rows, config.ImportOptions{Source: config.UploadSource})
+	res.Source = p.Filename
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "นำเข้าข้อมูลไม่สำเร็จ", "detail": err.Error(), "report": res})
+		return
+	}
+	search.Invalidate()
+	c.JSON(http.StatusOK, res)
+}
+
+// DELETE /admin/imports/:id
+func (ctrl *DatasetController) Discard(c *gin.Context) {
+	if ctrl.take(c.Param("id")) == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบไฟล์ที่รอยืนยัน"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "ยกเลิกแล้ว"})
+}
+
+// ------------------------------------------------------------
+// pending store
+// ------------------------------------------------------------
+
+// put เก็บไฟล์รอยืนยัน (ล้างตัวที่หมดอายุก่อน) คืน false ถ้าเต็ม
+func (ctrl *DatasetController) put(p *pendingImport) bool {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	now := time.Now()
+	for id, q := range ctrl.pending {
+		if now.After(q.Expires) {
+			delete(ctrl.pending, id)
+		}
+	}
+	if len(ctrl.pending) >= maxPending {
+		return false
+	}
+	ctrl.pending[p.ID] = p
+	return true
+}
+
+// take ดึงไฟล์ออกจาก store (ใช้ได้ครั้งเดียว กันยืนยันซ้ำ)
+func (ctrl *DatasetController) take(id string) *pendingImport {
+	ctrl.mu.Lock()
+	defer ctrl.mu.Unlock()
+	p := ctrl.pending[id]
+	delete(ctrl.pending, id)
+	if p == nil || time.Now().After(p.Expires) {
+		return nil
+	}
+	return p
+}
+
+func newImportID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
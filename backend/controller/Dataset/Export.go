@@ -0,0 +1,183 @@
+package Dataset
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xuri/excelize/v2"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+	"github.com/gtwndtl/trip-spark-builder/controller/Distance"
+)
+
+// ------------------------------------------------------------
+// ส่งออกชุดข้อมูลสถานที่
+//   GET /exports/:kind?format=xlsx|csv|geojson &province=... &type=คาเฟ่,ตลาด &price_min=0&price_max=500
+//   xlsx/csv ใช้คอลัมน์เดียวกับ profile ของ import → แก้ไฟล์แล้วอัปโหลดกลับที่ /admin/imports ได้เลย
+//   geojson ใช้จุดจาก PostGIS; สถานที่ที่ยังไม่มีจุดไม่ถูกใส่ (จำนวนอยู่ใน header X-Missing-Geometry)
+// ------------------------------------------------------------
+
+// GET /exports/:kind
+func (ctrl *DatasetController) Export(c *gin.Context) {
+	kind := c.Param("kind")
+	if !kinds[kind] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind ต้องเป็น landmark, restaurant หรือ accommodation"})
+		return
+	}
+	format := strings.ToLower(c.DefaultQuery("format", "xlsx"))
+	if format != "xlsx" && format != "csv" && format != "geojson" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format ต้องเป็น xlsx, csv หรือ geojson"})
+		return
+	}
+
+	f := config.ExportFilter{Province: strings.TrimSpace(c.Query("province"))}
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			f.Types = append(f.Types, t)
+		}
+	}
+	for _, p := range []struct {
+		key string
+		dst **int
+	}{{"price_min", &f.PriceMin}, {"price_max", &f.PriceMax}} {
+		if s := c.Query(p.key); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": p.key + " ต้องเป็นจำนวนเต็มไม่ติดลบ"})
+				return
+			}
+			*p.dst = &n
+		}
+	}
+
+	profile, places, err := config.ExportPlaces(kind, f)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ส่งออกข้อมูลไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+
+	filename := kind + "s." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	switch format {
+	case "xlsx":
+		b, err := xlsxBytes(profile, places)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างไฟล์ xlsx ไม่สำเร็จ", "detail": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", b)
+	case "csv":
+		b, err := csvBytes(profile, places)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างไฟล์ csv ไม่สำเร็จ", "detail": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "text/csv; charset=utf-8", b)
+	case "geojson":
+		fc, missing := placesGeoJSON(kind, places)
+		c.Header("X-Missing-Geometry", strconv.Itoa(missing))
+		c.Header("Content-Type", "application/geo+json")
+		c.JSON(http.StatusOK, fc)
+	}
+}
+
+// xlsxBytes ชีตเดียว (ชื่อตาม profile) ทุกช่องเป็นข้อความ ให้ import อ่านกลับได้ค่าเดิมทุกตัวอักษร
+func xlsxBytes(p config.Profile, places []config.ExportedPlace) ([]byte, error) {
+	x := excelize.NewFile()
+	defer x.Close()
+	sheet := x.GetSheetName(0)
+	if p.Sheet != "" && p.Sheet != sheet {
+		if err := x.SetSheetName(sheet, p.Sheet); err != nil {
+			return nil, err
+		}
+		sheet = p.Sheet
+	}
+
+	sw, err := x.NewStreamWriter(sheet)
+	if err != nil {
+		return nil, err
+	}
+	writeRow := func(n int, cells []string) error {
+		row := make([]any, len(cells))
+		for i, v := range cells {
+			row[i] = v
+		}
+		cell, _ := excelize.CoordinatesToCellName(1, n)
+		return sw.SetRow(cell, row)
+	}
+	if err := writeRow(1, p.Header()); err != nil {
+		return nil, err
+	}
+	for i, pl := range places {
+		if err := writeRow(i+2, p.Row(pl.Values)); err != nil {
+			return nil, err
+		}
+	}
+	if err := sw.Flush(); err != nil {
+		return nil, err
+	}
+	buf, err := x.WriteToBuffer()
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// csvBytes UTF-8 มี BOM (Excel เปิดภาษาไทยได้; ReadTable ตัด BOM ออกเอง)
+func csvBytes(p config.Profile, places []config.ExportedPlace) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString("\ufeff")
+	w := csv.NewWriter(&buf)
+	if err := w.Write(p.Header()); err != nil {
+		return nil, err
+	}
+	for _, pl := range places {
+		if err := w.Write(p.Row(pl.Values)); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// placesGeoJSON FeatureCollection ของสถานที่ที่มีจุดใน PostGIS คืนจำนวนที่ไม่มีจุดด้วย
+func placesGeoJSON(kind string, places []config.ExportedPlace) (Distance.GeoJSONCollection, int) {
+	fc := Distance.GeoJSONCollection{Type: "FeatureCollection", Features: []Distance.GeoJSONFeature{}}
+	missing := 0
+	for _, pl := range places {
+		if !pl.HasPoint {
+			missing++
+			continue
+		}
+		v := pl.Values
+		placeID, _ := strconv.Atoi(v["PlaceID"])
+		fc.Features = append(fc.Features, Distance.GeoJSONFeature{
+			Type:     "Feature",
+			Geometry: Distance.GeoJSONGeometry{Type: "Point", Coordinates: [2]float64{pl.Lon, pl.Lat}},
+			Properties: map[string]any{
+				"code":          pl.Code,
+				"kind":          kind,
+				"id":            pl.ID,
+				"place_id":      placeID,
+				"name":          v["Name"],
+				"category":      v["Category"],
+				"address":       v["Address"],
+				"province":      v["Province"],
+				"district":      v["District"],
+				"sub_district":  v["SubDistrict"],
+				"postcode":      v["Postcode"],
+				"thumbnail_url": v["ThumbnailURL"],
+				"price":         v["Price"],
+				"price_min":     pl.PriceMin,
+				"price_max":     pl.PriceMax,
+				"review":        pl.Review,
+				"types":         pl.Types,
+			},
+		})
+	}
+	return fc, missing
+}
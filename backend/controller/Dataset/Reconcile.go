@@ -0,0 +1,39 @@
+package Dataset
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gtwndtl/trip-spark-builder/config"
+	"github.com/gtwndtl/trip-spark-builder/search"
+)
+
+// ------------------------------------------------------------
+// ความสอดคล้อง SQLite ↔ PostGIS (admin)
+//   GET  /admin/reconcile   รายงานอย่างเดียว
+//   POST /admin/reconcile   ซ่อมตามรายงาน (ยึด SQLite เป็นหลัก) แล้วคืนรายงานก่อนซ่อม
+// ------------------------------------------------------------
+
+// GET /admin/reconcile
+func (ctrl *DatasetController) CheckConsistency(c *gin.Context) {
+	rep, err := config.Reconcile(false)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ตรวจความสอดคล้องไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
+
+// POST /admin/reconcile
+func (ctrl *DatasetController) Reconcile(c *gin.Context) {
+	rep, err := config.Reconcile(true)
+	if rep.Repaired {
+		search.Invalidate() // พิกัดใน GIS อาจเปลี่ยน
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ซ่อมข้อมูลไม่สำเร็จ", "detail": err.Error(), "report": rep})
+		return
+	}
+	c.JSON(http.StatusOK, rep)
+}
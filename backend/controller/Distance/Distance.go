@@ -1,40 +1,59 @@
 package Distance
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/roads"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
 )
 
 type DistanceController struct {
 	MysqlDB   *gorm.DB
 	PostgisDB *gorm.DB
+
+	// GraphBackend ตัวคำนวณกราฟ: BackendAuto | BackendPgRouting | BackendGo (ดู backend.go)
+	GraphBackend string
 }
 
 func NewDistanceController(mysqlDB, postgisDB *gorm.DB) *DistanceController {
 	return &DistanceController{
 		MysqlDB:   mysqlDB,
 		PostgisDB: postgisDB,
+
+		GraphBackend: defaultGraphBackend(),
+	}
+}
+
+// WithContext สำเนาของ controller ที่ทุก query ผูกกับ ctx (ยกเลิก ctx → query ที่ค้างอยู่ถูกยกเลิกด้วย)
+func (ctrl *DistanceController) WithContext(ctx context.Context) *DistanceController {
+	return &DistanceController{
+		MysqlDB:   ctrl.MysqlDB.WithContext(ctx),
+		PostgisDB: ctrl.PostgisDB.WithContext(ctx),
+
+		GraphBackend: ctrl.GraphBackend,
 	}
 }
 
 type DistanceNeighbor struct {
 	To       string  `json:"to"`
 	Distance float64 `json:"distance"` // km
+
+	// เฉพาะ ?mode=road: "road" = ระยะตามโครงข่ายถนน, "sphere" = ไม่มีเส้นทาง/ไม่ได้ snap ใช้ระยะเส้นตรงแทน
+	Source   string `json:"source,omitempty"`
+	Polyline string `json:"polyline,omitempty"` // Google encoded polyline
+
+	// เวลาเดินทาง (เฉพาะเมื่อส่ง ?mode=walk|car|transit)
+	*timeline.Estimate
 }
 
 func (ctrl *DistanceController) GetDistances(c *gin.Context) {
-	type DistanceResult struct {
-		FromType string
-		FromID   int
-		ToType   string
-		ToID     int
-		Distance float64
-	}
-
 	// ดึง query param "ids" เช่น "P1,R2,A3"
 	idsParam := c.Query("ids")
 	if idsParam == "" {
@@ -42,8 +61,155 @@ func (ctrl *DistanceController) GetDistances(c *gin.Context) {
 		return
 	}
 
-	// แยก ids เป็น slice
-	idList := strings.Split(idsParam, ",")
+	// ?mode= รับหลายค่าคั่นด้วย comma เช่น "road", "car", "road,walk"
+	//   road              → ระยะตามโครงข่ายถนน (ต้อง import ด้วย cmd/roadimport ก่อน) + polyline (ปิดด้วย polyline=0)
+	//   walk|car|transit  → เติมระยะถนน/เวลาเดินทาง/แบบที่แนะนำ ให้ทุกเส้น
+	road, travel := false, ""
+	for _, m := range strings.Split(c.Query("mode"), ",") {
+		switch m = strings.TrimSpace(m); m {
+		case "":
+		case "road":
+			road = true
+		default:
+			travel = m
+		}
+	}
+
+	ids := strings.Split(idsParam, ",")
+	var graph map[string][]DistanceNeighbor
+	var err error
+	if road {
+		graph, err = ctrl.RoadDistanceGraph(ids, travel != "walk", c.DefaultQuery("polyline", "1") != "0")
+	} else {
+		graph, err = ctrl.DistanceGraph(ids)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate distances"})
+		return
+	}
+
+	if travel != "" {
+		tl := timeline.FromEnv()
+		for from, nbrs := range graph {
+			for i := range nbrs {
+				var est timeline.Estimate
+				if nbrs[i].Source == "road" {
+					est = tl.EstimateRoad(travel, nbrs[i].Distance)
+				} else {
+					est = tl.Estimate(travel, nbrs[i].Distance)
+				}
+				nbrs[i].Estimate = &est
+			}
+			graph[from] = nbrs
+		}
+	}
+
+	c.JSON(http.StatusOK, graph)
+}
+
+// GetMatrix ระยะแบบ dense (จากแคช) สำหรับ planner/สคริปต์ที่ส่งรหัสจำนวนมาก
+//   GET  /distances/matrix?ids=P1,R2,A3&format=json|binary
+//   POST /distances/matrix {"ids": ["P1", "R2", "A3"]}   (รหัสเยอะจน URL ยาวเกิน)
+// format=binary → application/octet-stream ตาม distcache.Matrix.MarshalBinary
+func (ctrl *DistanceController) GetMatrix(c *gin.Context) {
+	ids := strings.Split(c.Query("ids"), ",")
+	if c.Request.Method == http.MethodPost {
+		var body struct {
+			IDs []string `json:"ids" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ids = body.IDs
+	}
+	if len(ids) == 0 || (len(ids) == 1 && strings.TrimSpace(ids[0]) == "") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ต้องระบุ ids"})
+		return
+	}
+
+	m, err := ctrl.Matrix(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate distances", "detail": err.Error()})
+		return
+	}
+
+	switch c.DefaultQuery("format", "json") {
+	case "json":
+		c.JSON(http.StatusOK, m)
+	case "binary":
+		b, _ := m.MarshalBinary()
+		c.Data(http.StatusOK, "application/octet-stream", b)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format ต้องเป็น json หรือ binary"})
+	}
+}
+
+// RoadDistanceGraph เหมือน DistanceGraph แต่ใช้ระยะตามโครงข่ายถนน (roads.Routes)
+// คู่ที่ไม่มีเส้นทาง (ไม่ได้ snap / ไปไม่ถึง) คงระยะเส้นตรงไว้พร้อม Source "sphere"
+// directed=false สำหรับเดิน (ไม่สนถนนทางเดียว)
+func (ctrl *DistanceController) RoadDistanceGraph(idList []string, directed, polyline bool) (map[string][]DistanceNeighbor, error) {
+	graph, err := ctrl.DistanceGraph(idList)
+	if err != nil {
+		return nil, err
+	}
+	codes := make([]string, 0, len(graph))
+	for code := range graph {
+		codes = append(codes, code)
+	}
+	routes, err := roads.Routes(ctrl.PostgisDB, codes, directed, polyline)
+	if err != nil {
+		return nil, err
+	}
+	for from, nbrs := range graph {
+		for i := range nbrs {
+			nbrs[i].Source = "sphere"
+			if r, ok := routes[[2]string{from, nbrs[i].To}]; ok {
+				nbrs[i].Distance = r.Meters / 1000
+				nbrs[i].Source = "road"
+				nbrs[i].Polyline = r.Polyline
+			}
+		}
+	}
+	return graph, nil
+}
+
+// DistanceGraph คำนวณระยะ (km) แบบ all-pairs ระหว่างรหัสที่ส่งมา (P/R/A)
+// ใช้ได้ทั้งจาก handler /distances และเรียกตรงจาก planner (ไม่ต้องยิง HTTP วนกลับมา)
+func (ctrl *DistanceController) DistanceGraph(idList []string) (map[string][]DistanceNeighbor, error) {
+	m, err := ctrl.Matrix(idList)
+	if err != nil {
+		return nil, err
+	}
+	graph := make(map[string][]DistanceNeighbor)
+	for i, from := range m.Codes {
+		for j, to := range m.Codes {
+			if i == j {
+				continue
+			}
+			if km, ok := m.At(i, j); ok {
+				graph[from] = append(graph[from], DistanceNeighbor{To: to, Distance: km})
+			}
+		}
+	}
+	return graph, nil
+}
+
+// Matrix ระยะแบบ dense ระหว่างรหัสที่ส่งมา อ่านจากแคช (distance_cache) คำนวณเฉพาะคู่ที่ยังไม่มีแล้วเก็บไว้
+func (ctrl *DistanceController) Matrix(idList []string) (*distcache.Matrix, error) {
+	return distcache.Fill(ctrl.PostgisDB, idList, ctrl.sphereDistances)
+}
+
+// sphereDistances ST_DistanceSphere (เมตร) ของทุกคู่ใน idList ที่มีอย่างน้อยหนึ่งฝั่งอยู่ใน missing
+// คืนคู่ละครั้งตาม distcache.Key
+func (ctrl *DistanceController) sphereDistances(idList, missing []string) (map[[2]string]float64, error) {
+	type DistanceResult struct {
+		FromType string
+		FromID   int
+		ToType   string
+		ToID     int
+		Distance float64
+	}
 
 	// แยกประเภทและ id ออกเพื่อใช้ใน SQL
 	pIDs := []int{}
@@ -51,6 +217,7 @@ func (ctrl *DistanceController) GetDistances(c *gin.Context) {
 	aIDs := []int{}
 
 	for _, id := range idList {
+		id = strings.TrimSpace(id)
 		if len(id) < 2 {
 			continue
 		}
@@ -70,12 +237,18 @@ func (ctrl *DistanceController) GetDistances(c *gin.Context) {
 		}
 	}
 
+	out := make(map[[2]string]float64)
+
 	// ถ้าไม่มี id อะไรเลย ก็ส่งกลับ empty
-	if len(pIDs) == 0 && len(rIDs) == 0 && len(aIDs) == 0 {
-		c.JSON(http.StatusOK, gin.H{})
-		return
+	if (len(pIDs) == 0 && len(rIDs) == 0 && len(aIDs) == 0) || len(missing) == 0 {
+		return out, nil
 	}
 
+	// กัน IN () ว่าง (Postgres ไม่รับ) เมื่อบางประเภทไม่มี id
+	pIDs = ensureNonEmptyInt(pIDs)
+	rIDs = ensureNonEmptyInt(rIDs)
+	aIDs = ensureNonEmptyInt(aIDs)
+
 	var distances []DistanceResult
 
 	// สร้าง SQL แบบ dynamic สำหรับ WHERE IN แต่ละประเภท
@@ -105,7 +278,9 @@ func (ctrl *DistanceController) GetDistances(c *gin.Context) {
 			b.type AS to_type, b.id AS to_id,
 			ST_DistanceSphere(a.location, b.location) AS distance
 		FROM filtered_points a
-		JOIN filtered_points b ON (a.type != b.type OR a.id != b.id)
+		JOIN filtered_points b ON (a.type || a.id) < (b.type || b.id) COLLATE "C"
+		WHERE (a.type || a.id) IN (` + makePlaceholders(len(missing)) + `)
+		   OR (b.type || b.id) IN (` + makePlaceholders(len(missing)) + `)
 	`
 
 	// รวม params ทั้งหมดสำหรับ placeholders ตามลำดับ
@@ -119,26 +294,21 @@ func (ctrl *DistanceController) GetDistances(c *gin.Context) {
 	for _, v := range rIDs {
 		params = append(params, v)
 	}
+	for range 2 {
+		for _, code := range missing {
+			params = append(params, code)
+		}
+	}
 
-	err := ctrl.PostgisDB.Raw(query, params...).Scan(&distances).Error
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate distances"})
-		return
+	if err := ctrl.PostgisDB.Raw(query, params...).Scan(&distances).Error; err != nil {
+		return nil, err
 	}
 
-	// สร้าง graph map[string][]DistanceNeighbor
-	graph := make(map[string][]DistanceNeighbor)
 	for _, d := range distances {
 		fromKey := d.FromType + strconv.Itoa(d.FromID)
 		toKey := d.ToType + strconv.Itoa(d.ToID)
-
-		distKm := d.Distance / 1000 // แปลงเป็นกิโลเมตร
-
-		graph[fromKey] = append(graph[fromKey], DistanceNeighbor{
-			To:       toKey,
-			Distance: distKm,
-		})
+		out[distcache.Key(fromKey, toKey)] = d.Distance
 	}
 
-	c.JSON(http.StatusOK, graph)
+	return out, nil
 }
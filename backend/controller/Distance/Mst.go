@@ -2,12 +2,17 @@
 package Distance
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/graphalgo"
 )
 
 // ------------------------------------------------------------
@@ -21,10 +26,6 @@ const flowFn = "pgr_boykovkolmogorov"
 // types
 // ------------------------------------------------------------
 
-type MinCutResp struct {
-	CutEdgeIDs [][2]int `json:"cut_edge_ids"` // (source,target) ที่เป็นคอขวด
-}
-
 type MSTRow struct {
 	Seq      int     `json:"seq"        gorm:"column:seq"`
 	Depth    int     `json:"depth"      gorm:"column:depth"`
@@ -34,6 +35,55 @@ type MSTRow struct {
 	Cost     float64 `json:"cost"       gorm:"column:cost"`
 	AggCost  float64 `json:"agg_cost"   gorm:"column:agg_cost"`
 	Pred     *int    `json:"pred,omitempty" gorm:"column:pred"`
+
+	// รหัส P/R/A ของ node/pred (node id ของร้าน/ที่พักบวก offset ไว้)
+	Code     string `json:"code"                gorm:"-"`
+	PredCode string `json:"pred_code,omitempty" gorm:"-"`
+}
+
+// MSTByFlowQuery พารามิเตอร์ของ /mst/byflow (ใช้ทั้ง handler และ planner ที่เรียกแบบ in-process)
+type MSTByFlowQuery struct {
+	Root    int     // landmark_id ที่เป็น start_vid
+	ZoneA   string  // CSV ของ landmark_id (ว่าง = auto-zone)
+	ZoneB   string  // CSV ของ landmark_id (ว่าง = auto-zone)
+	K       int     // K ฝั่ง flow (BK)
+	KMst    int     // K ฝั่ง MST
+	NTop    int     // ขนาด zoneA เมื่อ auto-zone
+	Mode    string  // penalize | exclude
+	Penalty float64 // ตัวคูณราคาเมื่อเป็น cut
+	MaxDist float64 // max_distance ของ pgr_primDD
+
+	Prefer, Prefer2, Prefer3 string
+	W1, W2, W3               float64
+
+	// Kinds ประเภทโหนดในกราฟ "P" (ค่าเริ่มต้น), "PR", "PRA"
+	// WR/WA ตัวคูณ cost ของเส้นที่แตะร้าน/ที่พัก (< 1 = ดึงเข้าโครงสร้าง, > 1 = เลี่ยง; 0 = 1)
+	Kinds  string
+	WR, WA float64
+
+	// type preference ของร้าน/ที่พัก (ชื่อใน travel_types ผ่าน restaurant_types/accommodation_types)
+	PreferR, PreferA   string
+	WPreferR, WPreferA float64 // 0 = 0.8
+
+	// Exclude landmark_id ที่ต้องตัดออกจากกราฟ (เช่น avoid ของ /gen-route)
+	// ExcludeCodes รหัส R/A ที่ต้องตัดออก (มีผลเมื่อ Kinds มีประเภทนั้น)
+	Exclude      []int
+	ExcludeCodes []string
+
+	// Progress แจ้งขั้นตอนที่กำลังทำ (PhaseMinCut/PhaseMST) ให้ job ของ GenTrip; nil = ไม่แจ้ง
+	Progress func(phase string)
+}
+
+// ชื่อขั้นตอนที่ส่งออกทาง MSTByFlowQuery.Progress (ตรงกับ planner.PhaseMinCut/PhaseMST)
+const (
+	PhaseMinCut = "computing min-cut"
+	PhaseMST    = "building MST"
+)
+
+func (q MSTByFlowQuery) progress(phase string) {
+	if q.Progress != nil {
+		q.Progress(phase)
+	}
 }
 
 type ByFlowResp struct {
@@ -41,8 +91,19 @@ type ByFlowResp struct {
 	AppliedCutEdges [][2]int `json:"applied_cut_edges"` // (source,target) จาก min-cut
 	Mode            string   `json:"mode"`              // penalize | exclude
 	PenaltyFactor   float64  `json:"penalty"`           // ถ้า penalize
+	Kinds           string   `json:"kinds"`             // ประเภทโหนดในกราฟ
+	Backend         string   `json:"backend"`           // pgrouting | go
 }
 
+// flowError เก็บข้อความ error ที่ handler ส่งกลับ แยกจากรายละเอียดของ DB
+type flowError struct {
+	Msg string
+	Err error
+}
+
+func (e *flowError) Error() string { return e.Msg + ": " + e.Err.Error() }
+func (e *flowError) Unwrap() error { return e.Err }
+
 // ------------------------------------------------------------
 // helpers
 // ------------------------------------------------------------
@@ -68,212 +129,16 @@ func valsPairs(pairs []struct{ S, T int }) string {
 	return b.String()
 }
 
-// ------------------------------------------------------------
-// Auto-zone (Top-N ใกล้ root) — ไม่เช็ค component
-// ------------------------------------------------------------
-//
-// zoneA = N จุดที่ใกล้ root มากที่สุด (รวม root เองด้วยถ้ามี)
-// zoneB = โหนดที่เหลือทั้งหมด
-// บังคับให้ zoneB ไม่ว่าง (ถ้าจำนวนโหนด > 1) ด้วย n_eff = LEAST(nTop, cnt-1)
-func (ctrl *DistanceController) findZonesTopN(root, nTop int) (string, string, error) {
-	if nTop < 1 {
-		nTop = 1
+// excludeClause "<kw> col NOT IN (1,2,3)" สำหรับฝังใน SQL (ค่าเป็น int ล้วน) ถ้าไม่มี id คืน ""
+func excludeClause(kw, col string, ids []int) string {
+	if len(ids) == 0 {
+		return ""
 	}
-
-	sql := `
-WITH nodes AS (
-  SELECT landmark_id::int AS id, location AS geom
-  FROM public.landmark_gis
-),
-sizes AS (
-  SELECT COUNT(*)::int AS cnt FROM nodes
-),
-n_eff AS (
-  SELECT CASE WHEN cnt <= 1 THEN cnt ELSE LEAST($2::int, cnt-1) END AS n
-  FROM sizes
-),
-root_pt AS (
-  SELECT location AS geom FROM public.landmark_gis WHERE landmark_id = $1
-),
-ordered AS (
-  SELECT n.id, ST_DistanceSphere(n.geom, r.geom) AS d
-  FROM nodes n, root_pt r
-  ORDER BY d
-),
-za AS (
-  SELECT COALESCE(STRING_AGG(id::text, ','), '') AS csv
-  FROM (SELECT id FROM ordered LIMIT (SELECT n FROM n_eff)) s
-),
-zb AS (
-  SELECT COALESCE(STRING_AGG(id::text, ','), '') AS csv
-  FROM (SELECT id FROM ordered OFFSET (SELECT n FROM n_eff)) s
-)
-SELECT (SELECT csv FROM za) AS za, (SELECT csv FROM zb) AS zb;
-`
-	type zones struct {
-		Za *string `gorm:"column:za"`
-		Zb *string `gorm:"column:zb"`
-	}
-	var z zones
-	if err := ctrl.PostgisDB.Raw(sql, root, nTop).Scan(&z).Error; err != nil {
-		return "", "", err
-	}
-	za, zb := "", ""
-	if z.Za != nil {
-		za = *z.Za
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = strconv.Itoa(id)
 	}
-	if z.Zb != nil {
-		zb = *z.Zb
-	}
-	return za, zb, nil
-}
-
-// ------------------------------------------------------------
-// /flow/mincut
-// ------------------------------------------------------------
-//
-// GET /flow/mincut?root=29&k=20&n_top=40
-// หรือส่ง zoneA/zoneB เองก็ได้
-//
-func (ctrl *DistanceController) GetFlowMinCut(c *gin.Context) {
-	zoneA := strings.TrimSpace(c.Query("zoneA"))
-	zoneB := strings.TrimSpace(c.Query("zoneB"))
-
-	k, _ := strconv.Atoi(c.DefaultQuery("k", "20"))
-	if k < 1 {
-		k = 20
-	}
-	nTop, _ := strconv.Atoi(c.DefaultQuery("n_top", "40"))
-	if nTop < 1 {
-		nTop = 1
-	}
-
-	// auto-zone (Top-N) ถ้าไม่ส่ง zoneA/zoneB
-	if zoneA == "" || zoneB == "" {
-		rootStr := c.Query("root")
-		root, err := strconv.Atoi(rootStr)
-		if err != nil || root <= 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "ต้องระบุ root เป็นจำนวนเต็มบวก เมื่อไม่ส่ง zoneA/zoneB"})
-			return
-		}
-		a, b, err := ctrl.findZonesTopN(root, nTop)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "หา zoneA/zoneB อัตโนมัติ (Top-N) ไม่สำเร็จ", "detail": err.Error()})
-			return
-		}
-		zoneA, zoneB = a, b
-	}
-
-	sqlCut := fmt.Sprintf(`
-WITH RECURSIVE
-bk AS (
-  SELECT * FROM %s(
-    $$
-WITH lm AS (
-  SELECT landmark_id::int AS id, location AS geom FROM public.landmark_gis
-),
-edges_base AS (
-  SELECT ROW_NUMBER() OVER ()::int AS id,
-         a.id::int AS source, b.id::int AS target,
-         ST_DistanceSphere(a.geom,b.geom) AS dist
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id<>a.id
-    ORDER BY b.geom <-> a.geom
-    LIMIT %d
-  ) b ON TRUE
-),
-edges_cap AS (
-  SELECT
-    id, source, target,
-    CEIL(GREATEST(1.0, 10000.0/NULLIF(dist,0)))::int AS capacity,
-    CEIL(GREATEST(1.0, 10000.0/NULLIF(dist,0)))::int AS reverse_capacity
-  FROM edges_base
-)
-SELECT id, source, target, capacity, reverse_capacity
-FROM edges_cap
-ORDER BY id
-$$::text,
-    STRING_TO_ARRAY($1, ',')::int[],
-    STRING_TO_ARRAY($2, ',')::int[]
-  )
-),
-residual_fwd AS (
-  SELECT e.source, e.target
-  FROM bk
-  JOIN (
-WITH lm AS (
-  SELECT landmark_id::int AS id, location AS geom FROM public.landmark_gis
-),
-edges_base AS (
-  SELECT ROW_NUMBER() OVER ()::int AS id,
-         a.id::int AS source, b.id::int AS target,
-         ST_DistanceSphere(a.geom,b.geom) AS dist
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id<>a.id
-    ORDER BY b.geom <-> a.geom
-    LIMIT %d
-  ) b ON TRUE
-)
-SELECT id, source, target FROM edges_base
-  ) e ON e.id = bk.edge
-  WHERE bk.residual_capacity > 0
-),
-reach(node) AS (
-  SELECT unnest(STRING_TO_ARRAY($1, ',')::int[])::int
-  UNION ALL
-  SELECT r.target
-  FROM reach r0
-  JOIN residual_fwd r ON r0.node = r.source
-),
-cut_edges AS (
-  SELECT e.source, e.target
-  FROM (
-WITH lm AS (
-  SELECT landmark_id::int AS id, location AS geom FROM public.landmark_gis
-),
-edges_base AS (
-  SELECT ROW_NUMBER() OVER ()::int AS id,
-         a.id::int AS source, b.id::int AS target,
-         ST_DistanceSphere(a.geom,b.geom) AS dist
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id<>a.id
-    ORDER BY b.geom <-> a.geom
-    LIMIT %d
-  ) b ON TRUE
-)
-SELECT source, target FROM edges_base
-  ) e
-  WHERE e.source IN (SELECT node FROM reach)
-    AND e.target NOT IN (SELECT node FROM reach)
-)
-SELECT source, target
-FROM cut_edges
-ORDER BY source, target;
-`, flowFn, k, k, k)
-
-	type pair struct {
-		S int `gorm:"column:source"`
-		T int `gorm:"column:target"`
-	}
-	var rows []pair
-	if err := ctrl.PostgisDB.Raw(sqlCut, zoneA, zoneB).Scan(&rows).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "คำนวณ min-cut ล้มเหลว", "detail": err.Error()})
-		return
-	}
-	out := make([][2]int, 0, len(rows))
-	for _, r := range rows {
-		out = append(out, [2]int{r.S, r.T})
-	}
-	c.JSON(http.StatusOK, MinCutResp{CutEdgeIDs: out})
+	return fmt.Sprintf(" %s %s NOT IN (%s)", kw, col, strings.Join(parts, ","))
 }
 
 // ------------------------------------------------------------
@@ -284,11 +149,15 @@ ORDER BY source, target;
 //    &prefer=สายบุญ,วัฒนธรรม&w1=0.6
 //    &prefer2=ชิวๆ,เดินเล่น&w2=0.8
 //    &prefer3=จุดชมวิว&w3=0.9
+//    &exclude=P12,P40   (ตัดสถานที่ออกจากกราฟ รับ R/A ด้วย)
+//    &kinds=P,R,A&w_r=0.9&w_a=1.2   (รวมร้าน/ที่พักเป็นโหนด + น้ำหนักเส้นตามประเภท)
+//    &prefer_r=อาหารเหนือ&wp_r=0.8&prefer_a=โฮมสเตย์&wp_a=0.8
 //
 // - ไม่มีเพดานระยะทั้งฝั่ง flow และฝั่ง MST (KNN only)
-// - หา min-cut ด้วย BK แล้ว penalize/exclude ในกราฟ MST
+// - หา min-cut ด้วย BK (บนกราฟประเภทเดียวกัน) แล้ว penalize/exclude ในกราฟ MST
+// - ไม่มี pgRouting (หรือ GRAPH_BACKEND=go) → KNN/min-cut/Prim คำนวณใน Go ราคาเส้นยังคิดด้วย SQL เดียวกัน
 // - พาร์ต preference ลด cost ด้วยค่าน้ำหนัก w1/w2/w3 (< 1.0 → สั้นลง → ถูกเลือกก่อน)
-//   (ใช้เฉพาะ LANDMARK; Restaurant/Accommodation ไม่แตะใน MST นี้)
+//   ร้าน/ที่พักใช้ prefer_r/prefer_a คูณเพิ่ม (kinds=P แบบเดิม จะไม่มีโหนดร้าน/ที่พักให้แตะ)
 func (ctrl *DistanceController) GetMSTByFlow(c *gin.Context) {
 	root, _ := strconv.Atoi(c.DefaultQuery("root", "0"))
 	if root <= 0 {
@@ -296,184 +165,150 @@ func (ctrl *DistanceController) GetMSTByFlow(c *gin.Context) {
 		return
 	}
 
-	zoneA := strings.TrimSpace(c.Query("zoneA"))
-	zoneB := strings.TrimSpace(c.Query("zoneB"))
 	k, _ := strconv.Atoi(c.DefaultQuery("k", "20"))
 	kMst, _ := strconv.Atoi(c.DefaultQuery("k_mst", "20"))
-	mode := strings.ToLower(strings.TrimSpace(c.DefaultQuery("mode", "penalize")))
-	if mode != "penalize" && mode != "exclude" {
-		mode = "penalize"
-	}
+	nTop, _ := strconv.Atoi(c.DefaultQuery("n_top", "40"))
 	penalty, _ := strconv.ParseFloat(c.DefaultQuery("penalty", "1.3"), 64)
-	if penalty <= 0 {
-		penalty = 1.3
-	}
+	maxDist, _ := strconv.ParseFloat(c.DefaultQuery("distance", "100000"), 64)
 
 	// prefs (ไทยได้) + น้ำหนัก
-	pref1 := c.DefaultQuery("prefer", "")
-	pref2 := c.DefaultQuery("prefer2", "")
-	pref3 := c.DefaultQuery("prefer3", "")
 	w1, _ := strconv.ParseFloat(c.DefaultQuery("w1", "0.75"), 64)
 	w2, _ := strconv.ParseFloat(c.DefaultQuery("w2", "0.85"), 64)
 	w3, _ := strconv.ParseFloat(c.DefaultQuery("w3", "0.95"), 64)
-	clamp := func(x float64) float64 { if x <= 0 { return 0.5 }; if x > 1 { return 1 }; return x }
-	w1, w2, w3 = clamp(w1), clamp(w2), clamp(w3)
 
-	// auto-zone (Top-N) ถ้าไม่ส่ง
-	if zoneA == "" || zoneB == "" {
-		nTop, _ := strconv.Atoi(c.DefaultQuery("n_top", "40"))
-		if nTop < 1 {
-			nTop = 1
+	// ร้าน/ที่พัก (kinds=P ไม่ใช้)
+	wR, _ := strconv.ParseFloat(c.DefaultQuery("w_r", "1"), 64)
+	wA, _ := strconv.ParseFloat(c.DefaultQuery("w_a", "1"), 64)
+	wpR, _ := strconv.ParseFloat(c.DefaultQuery("wp_r", "0.8"), 64)
+	wpA, _ := strconv.ParseFloat(c.DefaultQuery("wp_a", "0.8"), 64)
+
+	var exclP []int
+	var exclOther []string
+	for _, code := range parseNodeCodes(c.Query("exclude")) {
+		if id, ok := nodeID(code); ok && code[0] == 'P' {
+			exclP = append(exclP, id)
+		} else {
+			exclOther = append(exclOther, code)
 		}
-		autoA, autoB, err := ctrl.findZonesTopN(root, nTop)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถหาโซนอัตโนมัติ (Top-N)", "detail": err.Error()})
+	}
+
+	resp, err := ctrl.MSTByFlow(MSTByFlowQuery{
+		Root:    root,
+		ZoneA:   strings.TrimSpace(c.Query("zoneA")),
+		ZoneB:   strings.TrimSpace(c.Query("zoneB")),
+		K:       k,
+		KMst:    kMst,
+		NTop:    nTop,
+		Mode:    c.DefaultQuery("mode", "penalize"),
+		Penalty: penalty,
+		MaxDist: maxDist,
+		Prefer:  c.DefaultQuery("prefer", ""),
+		Prefer2: c.DefaultQuery("prefer2", ""),
+		Prefer3: c.DefaultQuery("prefer3", ""),
+		W1:      w1,
+		W2:      w2,
+		W3:      w3,
+
+		Kinds:    c.DefaultQuery("kinds", "P"),
+		WR:       wR,
+		WA:       wA,
+		PreferR:  c.Query("prefer_r"),
+		PreferA:  c.Query("prefer_a"),
+		WPreferR: wpR,
+		WPreferA: wpA,
+
+		Exclude:      exclP,
+		ExcludeCodes: exclOther,
+	})
+	if err != nil {
+		var fe *flowError
+		if errors.As(err, &fe) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fe.Msg, "detail": fe.Err.Error()})
 			return
 		}
-		zoneA, zoneB = autoA, autoB
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
 
-	// 1) หา min-cut ด้วย BK
-	sqlCut := fmt.Sprintf(`
-WITH RECURSIVE
-bk AS (
-  SELECT * FROM %s(
-    $$
-WITH lm AS (
-  SELECT landmark_id::int AS id, location AS geom FROM public.landmark_gis
-),
-edges_base AS (
-  SELECT ROW_NUMBER() OVER ()::int AS id,
-         a.id::int AS source, b.id::int AS target,
-         ST_DistanceSphere(a.geom,b.geom) AS dist
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id<>a.id
-    ORDER BY b.geom <-> a.geom
-    LIMIT %d
-  ) b ON TRUE
-),
-edges_cap AS (
-  SELECT
-    id, source, target,
-    CEIL(GREATEST(1.0, 10000.0/NULLIF(dist,0)))::int AS capacity,
-    CEIL(GREATEST(1.0, 10000.0/NULLIF(dist,0)))::int AS reverse_capacity
-  FROM edges_base
-)
-SELECT id, source, target, capacity, reverse_capacity
-FROM edges_cap
-ORDER BY id
-$$::text,
-    STRING_TO_ARRAY($1, ',')::int[],
-    STRING_TO_ARRAY($2, ',')::int[]
-  )
-),
-residual_fwd AS (
-  SELECT e.source, e.target
-  FROM bk
-  JOIN (
-WITH lm AS (
-  SELECT landmark_id::int AS id, location AS geom FROM public.landmark_gis
-),
-edges_base AS (
-  SELECT ROW_NUMBER() OVER ()::int AS id,
-         a.id::int AS source, b.id::int AS target,
-         ST_DistanceSphere(a.geom,b.geom) AS dist
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id<>a.id
-    ORDER BY b.geom <-> a.geom
-    LIMIT %d
-  ) b ON TRUE
-)
-SELECT id, source, target FROM edges_base
-  ) e ON e.id = bk.edge
-  WHERE bk.residual_capacity > 0
-),
-reach(node) AS (
-  SELECT unnest(STRING_TO_ARRAY($1, ',')::int[])::int
-  UNION ALL
-  SELECT r.target FROM reach r0 JOIN residual_fwd r ON r0.node = r.source
-),
-cut_edges AS (
-  SELECT e.source, e.target
-  FROM (
-WITH lm AS (
-  SELECT landmark_id::int AS id, location AS geom FROM public.landmark_gis
-),
-edges_base AS (
-  SELECT ROW_NUMBER() OVER ()::int AS id,
-         a.id::int AS source, b.id::int AS target,
-         ST_DistanceSphere(a.geom,b.geom) AS dist
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id<>a.id
-    ORDER BY b.geom <-> a.geom
-    LIMIT %d
-  ) b ON TRUE
-)
-SELECT source, target FROM edges_base
-  ) e
-  WHERE e.source IN (SELECT node FROM reach)
-    AND e.target NOT IN (SELECT node FROM reach)
-)
-SELECT source, target FROM cut_edges ORDER BY source, target;
-`, flowFn, k, k, k)
+	c.JSON(http.StatusOK, resp)
+}
 
-	type pair struct {
-		S int `gorm:"column:source"`
-		T int `gorm:"column:target"`
+// MSTByFlow คือแกนของ /mst/byflow ที่แยกออกมาให้เรียกได้ตรง ๆ (เช่นจาก planner ของ GenTrip)
+func (ctrl *DistanceController) MSTByFlow(q MSTByFlowQuery) (*ByFlowResp, error) {
+	zoneA, zoneB := q.ZoneA, q.ZoneB
+	k, kMst := q.K, q.KMst
+	if kMst < 1 {
+		kMst = 20
 	}
-	var cuts []pair
-	if zoneA != "" && zoneB != "" {
-		if err := ctrl.PostgisDB.Raw(sqlCut, zoneA, zoneB).Scan(&cuts).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "คำนวณ min-cut ล้มเหลว", "detail": err.Error()})
-			return
+	mode := strings.ToLower(strings.TrimSpace(q.Mode))
+	if mode != "penalize" && mode != "exclude" {
+		mode = "penalize"
+	}
+	penalty := q.Penalty
+	if penalty <= 0 {
+		penalty = 1.3
+	}
+	pref1, pref2, pref3 := q.Prefer, q.Prefer2, q.Prefer3
+	clamp := func(x float64) float64 { if x <= 0 { return 0.5 }; if x > 1 { return 1 }; return x }
+	w1, w2, w3 := clamp(q.W1), clamp(q.W2), clamp(q.W3)
+
+	// ประเภทโหนด + น้ำหนักต่อประเภท (0 = ค่าเริ่มต้น)
+	kinds := parseKinds(q.Kinds)
+	orDefault := func(x, def, hi float64) float64 { if x <= 0 { return def }; return math.Min(x, hi) }
+	wR, wA := orDefault(q.WR, 1, 10), orDefault(q.WA, 1, 10)
+	wpR, wpA := orDefault(q.WPreferR, 0.8, 1), orDefault(q.WPreferA, 0.8, 1)
+
+	// สถานที่ที่ผู้ใช้ไม่อยากไป → ตัดออกจากกราฟทั้งฝั่ง flow และ MST
+	pCodes := func(ids []int) []string {
+		out := make([]string, 0, len(ids))
+		for _, id := range ids {
+			out = append(out, "P"+strconv.Itoa(id))
 		}
+		return out
 	}
+	exclude := append(pCodes(q.Exclude), q.ExcludeCodes...)
 
-	// 2) เตรียม VALUES ของ cut edges
-	cutValues := valsPairs(func() []struct{ S, T int } {
-		v := make([]struct{ S, T int }, 0, len(cuts))
-		for _, e := range cuts {
-			v = append(v, struct{ S, T int }{e.S, e.T})
+	// 1) หา min-cut บนกราฟประเภทเดียวกัน ไม่ส่งโซน = auto-zone Top-N ใกล้ root
+	nTop := q.NTop
+	if nTop < 1 {
+		nTop = 1
+	}
+	q.progress(PhaseMinCut)
+	var cuts []struct{ S, T int }
+	mc, err := ctrl.MinCut(MinCutQuery{
+		Root:       "P" + strconv.Itoa(q.Root),
+		SourceZone: parseNodeCodes(zoneA),
+		SinkZone:   parseNodeCodes(zoneB),
+		K:          k,
+		NTop:       nTop,
+		Kinds:      kinds,
+		Exclude:    exclude,
+	})
+	switch {
+	case errors.Is(err, ErrBadFlowQuery):
+		// โซนว่าง/root ไม่อยู่ในกราฟ → ไม่มีเส้นให้ penalize (เหมือนเดิม)
+	case err != nil:
+		return nil, err
+	default:
+		for _, e := range mc.CutEdgeIDs {
+			cuts = append(cuts, struct{ S, T int }{e[0], e[1]})
 		}
-		return v
-	}())
+	}
 
-	maxDist, _ := strconv.ParseFloat(c.DefaultQuery("distance", "100000"), 64)
+	// 2) เตรียม VALUES ของ cut edges
+	cutValues := valsPairs(cuts)
+
+	maxDist := q.MaxDist
+	q.progress(PhaseMST)
 
-	// 3) สร้าง SQL MST (ฝัง preferences และ cutValues)
+	// 3) SQL MST บน mst_edges (ฝัง preferences และ cutValues)
+	//    id ของ node: landmark = landmark_id, ร้าน/ที่พักบวก offset เหมือน /flow/mincut
 	p1, p2, p3 := sqlLit(pref1), sqlLit(pref2), sqlLit(pref3)
-	sqlMST := fmt.Sprintf(`
-WITH mst AS (
-  SELECT *
-  FROM pgr_primDD(
-    $$
+	pr, pa := sqlLit(q.PreferR), sqlLit(q.PreferA)
+	// edgesSQL = ราคาเส้นหลังคิด cut/ประเภท/preference (ใช้ได้ทั้ง pgr_primDD และ graphalgo.PrimDD)
+	edgesSQL := fmt.Sprintf(`
 WITH
-base AS (
-  SELECT
-    ROW_NUMBER() OVER ()::int AS id,
-    a.landmark_id::int AS source,
-    b.landmark_id::int AS target,
-    ST_DistanceSphere(a.location, b.location) AS dist
-  FROM public.landmark_gis a
-  JOIN LATERAL (
-    SELECT landmark_id, location
-    FROM public.landmark_gis b
-    WHERE b.landmark_id <> a.landmark_id
-    ORDER BY b.location <-> a.location
-    LIMIT %d
-  ) b ON TRUE
-),
-
--- --- พาร์ต preference: หา type id จากชื่อ แล้ว map เป็นชุด landmark_id
+-- --- พาร์ต preference: หา type id จากชื่อ แล้ว map เป็นชุด node id
 t1 AS (
   SELECT id FROM public.travel_types
   WHERE kind IN ('','landmark')
@@ -498,9 +333,27 @@ t3 AS (
       SELECT lower(trim(x)) FROM unnest(string_to_array('%s', ',')) AS x
     )
 ),
-fav1 AS (SELECT DISTINCT landmark_id AS id FROM public.landmark_types WHERE type_id IN (SELECT id FROM t1)),
-fav2 AS (SELECT DISTINCT landmark_id AS id FROM public.landmark_types WHERE type_id IN (SELECT id FROM t2)),
-fav3 AS (SELECT DISTINCT landmark_id AS id FROM public.landmark_types WHERE type_id IN (SELECT id FROM t3)),
+tr AS (
+  SELECT id FROM public.travel_types
+  WHERE kind = 'restaurant'
+    AND length(trim('%s')) > 0
+    AND lower(name) = ANY (
+      SELECT lower(trim(x)) FROM unnest(string_to_array('%s', ',')) AS x
+    )
+),
+ta AS (
+  SELECT id FROM public.travel_types
+  WHERE kind = 'accommodation'
+    AND length(trim('%s')) > 0
+    AND lower(name) = ANY (
+      SELECT lower(trim(x)) FROM unnest(string_to_array('%s', ',')) AS x
+    )
+),
+fav1 AS (SELECT DISTINCT landmark_id::int AS id FROM public.landmark_types WHERE type_id IN (SELECT id FROM t1)),
+fav2 AS (SELECT DISTINCT landmark_id::int AS id FROM public.landmark_types WHERE type_id IN (SELECT id FROM t2)),
+fav3 AS (SELECT DISTINCT landmark_id::int AS id FROM public.landmark_types WHERE type_id IN (SELECT id FROM t3)),
+favr AS (SELECT DISTINCT restaurant_id::int + %d AS id FROM public.restaurant_types WHERE type_id IN (SELECT id FROM tr)),
+fava AS (SELECT DISTINCT accommodation_id::int + %d AS id FROM public.accommodation_types WHERE type_id IN (SELECT id FROM ta)),
 
 cut(source, target) AS (
   %s
@@ -508,89 +361,126 @@ cut(source, target) AS (
 
 costed AS (
   SELECT
-    base.id, base.source, base.target,
+    e.id, e.source, e.target,
     CASE
-      WHEN (SELECT 1 FROM cut WHERE cut.source=base.source AND cut.target=base.target LIMIT 1) IS NOT NULL
-           AND '%s'='exclude'  THEN NULL
-      WHEN (SELECT 1 FROM cut WHERE cut.source=base.source AND cut.target=base.target LIMIT 1) IS NOT NULL
-           AND '%s'='penalize' THEN base.dist * (%.6f)::float8
-      ELSE base.dist
-    END AS base_cost
-  FROM base
+      WHEN (SELECT 1 FROM cut
+            WHERE (cut.source=e.source AND cut.target=e.target)
+               OR (cut.source=e.target AND cut.target=e.source) LIMIT 1) IS NOT NULL
+      THEN CASE WHEN '%s'='exclude' THEN NULL ELSE e.dist * (%.6f)::float8 END
+      ELSE e.dist
+    END
+    -- น้ำหนักตามประเภท: เส้นที่แตะร้าน/ที่พัก
+    * CASE WHEN (e.source >= %d AND e.source < %d) OR (e.target >= %d AND e.target < %d)
+           THEN (%.6f)::float8 ELSE 1 END
+    * CASE WHEN e.source >= %d OR e.target >= %d THEN (%.6f)::float8 ELSE 1 END
+    AS base_cost
+  FROM mst_edges e
 ),
 
 weighted AS (
   SELECT
     id, source, target,
-    CASE
-      WHEN base_cost IS NULL THEN NULL
-      WHEN source IN (SELECT id FROM fav1) OR target IN (SELECT id FROM fav1) THEN base_cost * (%.6f)::float8
-      WHEN source IN (SELECT id FROM fav2) OR target IN (SELECT id FROM fav2) THEN base_cost * (%.6f)::float8
-      WHEN source IN (SELECT id FROM fav3) OR target IN (SELECT id FROM fav3) THEN base_cost * (%.6f)::float8
-      ELSE base_cost
-    END AS cost,
-    CASE
-      WHEN base_cost IS NULL THEN NULL
-      WHEN source IN (SELECT id FROM fav1) OR target IN (SELECT id FROM fav1) THEN base_cost * (%.6f)::float8
-      WHEN source IN (SELECT id FROM fav2) OR target IN (SELECT id FROM fav2) THEN base_cost * (%.6f)::float8
-      WHEN source IN (SELECT id FROM fav3) OR target IN (SELECT id FROM fav3) THEN base_cost * (%.6f)::float8
-      ELSE base_cost
-    END AS reverse_cost
+    base_cost
+    * CASE
+        WHEN source IN (SELECT id FROM fav1) OR target IN (SELECT id FROM fav1) THEN (%.6f)::float8
+        WHEN source IN (SELECT id FROM fav2) OR target IN (SELECT id FROM fav2) THEN (%.6f)::float8
+        WHEN source IN (SELECT id FROM fav3) OR target IN (SELECT id FROM fav3) THEN (%.6f)::float8
+        ELSE 1
+      END
+    * CASE WHEN source IN (SELECT id FROM favr) OR target IN (SELECT id FROM favr) THEN (%.6f)::float8 ELSE 1 END
+    * CASE WHEN source IN (SELECT id FROM fava) OR target IN (SELECT id FROM fava) THEN (%.6f)::float8 ELSE 1 END
+    AS cost
   FROM costed
   WHERE base_cost IS NOT NULL
 )
-SELECT id, source, target, cost, reverse_cost
+SELECT id, source, target, cost, cost AS reverse_cost
 FROM weighted
-$$::text,
-    %d::int,           -- start_vid
-    %.6f::float8       -- max_distance
-  )
-),
-
-edges_for_pred AS (
-  SELECT
-    ROW_NUMBER() OVER ()::int AS id,
-    a.landmark_id::int AS source,
-    b.landmark_id::int AS target
-  FROM public.landmark_gis a
-  JOIN LATERAL (
-    SELECT landmark_id, location
-    FROM public.landmark_gis b
-    WHERE b.landmark_id <> a.landmark_id
-    ORDER BY b.location <-> a.location
-    LIMIT %d
-  ) b ON TRUE
-),
-
-preds AS (
-  SELECT m.seq, m.depth, m.start_vid, m.node, m.edge, m.cost, m.agg_cost,
-         CASE WHEN m.edge=-1 THEN NULL
-              ELSE CASE WHEN e.source=m.node THEN e.target ELSE e.source END
-         END AS pred
-  FROM mst m
-  LEFT JOIN (SELECT id, source, target FROM edges_for_pred) e ON e.id = m.edge
-)
-
-SELECT * FROM preds ORDER BY seq;
-`, kMst,
+`,
 		p1, p1,
 		p2, p2,
 		p3, p3,
+		pr, pr,
+		pa, pa,
+		restaurantNodeOffset, accommodationNodeOffset,
 		cutValues,
-		mode, mode, penalty,
+		mode, penalty,
+		restaurantNodeOffset, accommodationNodeOffset, restaurantNodeOffset, accommodationNodeOffset, wR,
+		accommodationNodeOffset, accommodationNodeOffset, wA,
 		w1, w2, w3,
-		w1, w2, w3,
-		root, maxDist,
-		kMst,
+		wpR, wpA,
 	)
+	sqlMST := fmt.Sprintf(`
+WITH mst AS (
+  SELECT *
+  FROM pgr_primDD(
+    ?::text,           -- edges_sql ส่งเป็น parameter (ไม่ฝังข้อความ preference ลง dollar quote)
+    %d::int,           -- start_vid
+    %.6f::float8       -- max_distance
+  )
+)
 
+SELECT m.seq, m.depth, m.start_vid, m.node, m.edge, m.cost, m.agg_cost,
+       CASE WHEN m.edge=-1 THEN NULL
+            ELSE CASE WHEN e.source=m.node THEN e.target ELSE e.source END
+       END AS pred
+FROM mst m
+LEFT JOIN mst_edges e ON e.id = m.edge
+ORDER BY m.seq;
+`, q.Root, maxDist)
+
+	// กราฟ KNN สร้างเป็น temp table ครั้งเดียว (id คงที่) ใช้ทั้งใน pgr_primDD และตอนหา pred
+	backend := ctrl.graphBackend()
 	var rows []MSTRow
-	if err := ctrl.PostgisDB.Raw(sqlMST).Scan(&rows).Error; err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":  "คำนวณ MST โดยใช้ flow + type preference ไม่สำเร็จ",
-			"detail": err.Error(),
-		})
-		return
+	err = ctrl.PostgisDB.Transaction(func(tx *gorm.DB) error {
+		if backend == BackendGo {
+			if err := createKNNTableGo(tx, "mst_edges", kindNodesSQL(kinds, exclude), kMst); err != nil {
+				return err
+			}
+			var edges []graphalgo.CostEdge
+			if err := tx.Raw(edgesSQL).Scan(&edges).Error; err != nil {
+				return err
+			}
+			for _, r := range graphalgo.PrimDD(edges, q.Root, maxDist) {
+				row := MSTRow{Seq: r.Seq, Depth: r.Depth, StartVID: r.StartVID, Node: r.Node,
+					EdgeID: r.Edge, Cost: r.Cost, AggCost: r.AggCost}
+				if r.Edge != -1 {
+					pred := r.Pred
+					row.Pred = &pred
+				}
+				rows = append(rows, row)
+			}
+			return nil
+		}
+
+		for _, stmt := range []string{
+			`CREATE TEMP TABLE mst_nodes ON COMMIT DROP AS ` + kindNodesSQL(kinds, exclude),
+			`CREATE INDEX ON mst_nodes USING GIST (geom)`,
+			fmt.Sprintf(`CREATE TEMP TABLE mst_edges ON COMMIT DROP AS
+SELECT ROW_NUMBER() OVER (ORDER BY a.id, b.id)::int AS id,
+       a.id AS source, b.id AS target, b.dist
+FROM mst_nodes a
+JOIN LATERAL (
+  SELECT id, ST_DistanceSphere(a.geom, b.geom) AS dist
+  FROM mst_nodes b
+  WHERE b.id <> a.id
+  ORDER BY b.geom <-> a.geom
+  LIMIT %d
+) b ON TRUE`, kMst),
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Raw(sqlMST, edgesSQL).Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, &flowError{Msg: "คำนวณ MST โดยใช้ flow + type preference ไม่สำเร็จ", Err: err}
+	}
+	for i := range rows {
+		rows[i].Code = nodeCode(rows[i].Node)
+		if rows[i].Pred != nil {
+			rows[i].PredCode = nodeCode(*rows[i].Pred)
+		}
 	}
 
 	applied := make([][2]int, 0, len(cuts))
@@ -598,10 +488,12 @@ SELECT * FROM preds ORDER BY seq;
 		applied = append(applied, [2]int{e.S, e.T})
 	}
 
-	c.JSON(http.StatusOK, ByFlowResp{
+	return &ByFlowResp{
 		MST:             rows,
 		AppliedCutEdges: applied,
 		Mode:            mode,
 		PenaltyFactor:   penalty,
-	})
+		Kinds:           kinds,
+		Backend:         backend,
+	}, nil
 }
@@ -227,7 +227,7 @@ type accRow struct {
 	TotalM      float64 `json:"total_m"`
 	NPoints     int64   `json:"n_points"`
 }
-type accOut struct {
+type AccSuggestion struct {
 	ID          int64    `json:"id"`
 	Code        string   `json:"code"`
 	Name        *string  `json:"name,omitempty"`
@@ -348,8 +348,37 @@ func (ctl *DistanceController) SuggestAccommodations(c *gin.Context) {
 		return
 	}
 
-	var pIDs, rIDs, aIDs []int
+	codes := make([]string, 0, len(codeSet))
 	for code := range codeSet {
+		codes = append(codes, code)
+	}
+	out, err := ctl.SuggestAccommodationsNear(codes, strategy, radiusM, exclude, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "spatial(" + strategy + ") failed", "detail": err.Error()})
+		return
+	}
+	if len(out) == 0 {
+		c.JSON(http.StatusOK, gin.H{"trip_id": tripID, "day": day, "strategy": strategy, "count": 0, "data": []any{}})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"trip_id":  tripID,
+		"day":      day,
+		"strategy": strategy,
+		"radius_m": radiusM,
+		"count":    len(out),
+		"data":     out,
+	})
+}
+
+// SuggestAccommodationsNear แกนของ /suggest/accommodations: ที่พักใกล้กลุ่มจุด codes (P/R/A)
+//   strategy=center → ใกล้ centroid ที่สุด, sum → ระยะเฉลี่ยไปทุกจุดน้อยสุด
+// ใช้ตรงจาก planner ได้ (ที่พักรายวันของทริปที่แบ่งกลุ่มตามพื้นที่)
+func (ctl *DistanceController) SuggestAccommodationsNear(codes []string, strategy string, radiusM float64, exclude string, limit int) ([]AccSuggestion, error) {
+	var pIDs, rIDs, aIDs []int
+	for _, code := range codes {
+		code = strings.ToUpper(strings.TrimSpace(code))
 		if code == "" {
 			continue
 		}
@@ -431,20 +460,17 @@ LIMIT ?;`
 	case "sum":
 		if err := ctl.PostgisDB.Raw(sqlSum, pIDs, rIDs, aIDs, radiusM, exclude, exclude, limit).
 			Scan(&rows).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "spatial(sum) failed", "detail": err.Error()})
-			return
+			return nil, err
 		}
 	default:
 		if err := ctl.PostgisDB.Raw(sqlCenter, pIDs, rIDs, aIDs, radiusM, exclude, exclude, limit).
 			Scan(&rows).Error; err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "spatial(center) failed", "detail": err.Error()})
-			return
+			return nil, err
 		}
 	}
 
 	if len(rows) == 0 {
-		c.JSON(http.StatusOK, gin.H{"trip_id": tripID, "day": day, "strategy": strategy, "count": 0, "data": []any{}})
-		return
+		return nil, nil
 	}
 
 	// เติมชื่อจาก SQL ปกติ
@@ -469,9 +495,9 @@ LIMIT ?;`
 		nameMap[v.ID] = v
 	}
 
-	out := make([]accOut, 0, len(rows))
+	out := make([]AccSuggestion, 0, len(rows))
 	for _, r := range rows {
-		o := accOut{
+		o := AccSuggestion{
 			ID:          r.ID,
 			Code:        fmt.Sprintf("A%d", r.ID),
 			DistCenterM: r.DistCenterM,
@@ -487,12 +513,5 @@ LIMIT ?;`
 		out = append(out, o)
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"trip_id":  tripID,
-		"day":      day,
-		"strategy": strategy,
-		"radius_m": radiusM,
-		"count":    len(out),
-		"data":     out,
-	})
+	return out, nil
 }
@@ -0,0 +1,98 @@
+package Distance
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/graphalgo"
+)
+
+// ------------------------------------------------------------
+// ตัวคำนวณกราฟ (components / min-cut / MST)
+//   pgrouting: ใช้ฟังก์ชัน pgr_* ในฐานข้อมูล
+//   go:        โหลดพิกัดมาคำนวณใน process ด้วย package graphalgo (ต้องการแค่ PostGIS)
+//   auto:      pgRouting ถ้าฐานข้อมูลมี extension ไม่งั้นใช้ go
+// เลือกด้วย env GRAPH_BACKEND (ค่าเริ่มต้น auto) หรือ DistanceController.GraphBackend
+// ------------------------------------------------------------
+
+const (
+	BackendAuto      = "auto"
+	BackendPgRouting = "pgrouting"
+	BackendGo        = "go"
+)
+
+// defaultGraphBackend ค่าจาก env GRAPH_BACKEND
+func defaultGraphBackend() string {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("GRAPH_BACKEND"))); v != "" {
+		return v
+	}
+	return BackendAuto
+}
+
+// graphBackend ตัวคำนวณที่ใช้จริงของ controller นี้ (BackendPgRouting หรือ BackendGo)
+func (ctrl *DistanceController) graphBackend() string {
+	switch ctrl.GraphBackend {
+	case BackendGo:
+		return BackendGo
+	case BackendPgRouting:
+		return BackendPgRouting
+	}
+	if hasPgRouting(ctrl.PostgisDB) {
+		return BackendPgRouting
+	}
+	return BackendGo
+}
+
+var pgr struct {
+	sync.Mutex
+	checked, ok bool
+}
+
+// hasPgRouting ตรวจครั้งแรกที่เรียกแล้วจำไว้ (ตรวจไม่สำเร็จ เช่น ctx ถูกยกเลิก → ลองใหม่ครั้งหน้า)
+func hasPgRouting(db *gorm.DB) bool {
+	pgr.Lock()
+	defer pgr.Unlock()
+	if !pgr.checked {
+		var ok bool
+		if err := db.Raw(`SELECT EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'pgrouting')`).
+			Scan(&ok).Error; err != nil {
+			return false
+		}
+		pgr.checked, pgr.ok = true, ok
+	}
+	return pgr.ok
+}
+
+// knnRow แถวของตาราง KNN ชั่วคราว (คอลัมน์เดียวกับที่สร้างด้วย SQL)
+type knnRow struct {
+	ID     int     `gorm:"column:id"`
+	Source int     `gorm:"column:source"`
+	Target int     `gorm:"column:target"`
+	Dist   float64 `gorm:"column:dist"`
+}
+
+// createKNNTableGo สร้าง temp table (id, source, target, dist) จากกราฟ KNN ที่คำนวณใน Go
+// nodesSQL ต้องคืน (id, geom) เหมือน kindNodesSQL; ตารางหายเมื่อจบ transaction
+func createKNNTableGo(tx *gorm.DB, table, nodesSQL string, k int) error {
+	var nodes []graphalgo.Node
+	if err := tx.Raw(`SELECT id, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM (` + nodesSQL + `) n`).
+		Scan(&nodes).Error; err != nil {
+		return err
+	}
+	if err := tx.Exec(`CREATE TEMP TABLE ` + table +
+		` (id int PRIMARY KEY, source int, target int, dist float8) ON COMMIT DROP`).Error; err != nil {
+		return err
+	}
+	edges := graphalgo.KNN(nodes, k, 0)
+	if len(edges) == 0 {
+		return nil
+	}
+	rows := make([]knnRow, len(edges))
+	for i, e := range edges {
+		rows[i] = knnRow{ID: e.ID, Source: e.Source, Target: e.Target, Dist: e.Dist}
+	}
+	return tx.Table(table).CreateInBatches(rows, 1000).Error
+}
@@ -0,0 +1,127 @@
+package Distance
+
+import (
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// TestBackendsAgree รันตัวคำนวณแบบ pgRouting กับแบบ Go บนโหนดชุดเดียวกัน (เหมือน cmd/graphcheck)
+// ต้องมี PostGIS + pgRouting (DSN จาก env TEST_PG_DSN หรือค่าเดียวกับ server) ไม่มี → skip
+func TestBackendsAgree(t *testing.T) {
+	if testing.Short() {
+		t.Skip("ต้องใช้ฐานข้อมูล")
+	}
+	dsn := os.Getenv("TEST_PG_DSN")
+	if dsn == "" {
+		dsn = "host=localhost user=postgres password=1 dbname=postgres port=5432 sslmode=disable TimeZone=Asia/Bangkok connect_timeout=3"
+	}
+	cfg := &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+	pg, err := gorm.Open(postgres.Open(dsn), cfg)
+	if err != nil {
+		t.Skipf("เชื่อมต่อ PostgreSQL ไม่ได้: %v", err)
+	}
+	if !hasPgRouting(pg) {
+		t.Skip("ฐานข้อมูลไม่มี pgRouting")
+	}
+	var rootID int
+	if err := pg.Raw(`SELECT landmark_id FROM landmark_gis WHERE location IS NOT NULL ORDER BY landmark_id LIMIT 1`).
+		Scan(&rootID).Error; err != nil || rootID == 0 {
+		t.Skipf("ไม่มีข้อมูลใน landmark_gis: %v", err)
+	}
+	lite, err := gorm.Open(sqlite.Open("../../final.db"), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pgrCtrl := NewDistanceController(lite, pg)
+	pgrCtrl.GraphBackend = BackendPgRouting
+	goCtrl := NewDistanceController(lite, pg)
+	goCtrl.GraphBackend = BackendGo
+
+	t.Run("components", func(t *testing.T) {
+		a, err1 := pgrCtrl.ComponentsHealth(2000, 10)
+		b, err2 := goCtrl.ComponentsHealth(2000, 10)
+		mustNil(t, err1, err2)
+		if a.Components != b.Components {
+			t.Errorf("components pgrouting=%d go=%d", a.Components, b.Components)
+		}
+	})
+
+	for _, kinds := range []string{"P", "PRA"} {
+		t.Run("min-cut "+kinds, func(t *testing.T) {
+			q := MinCutQuery{Root: "P" + strconv.Itoa(rootID), K: 10, NTop: 30, Kinds: kinds}
+			a, err1 := pgrCtrl.MinCut(q)
+			b, err2 := goCtrl.MinCut(q)
+			mustNil(t, err1, err2)
+			if a.TotalCapacity != b.TotalCapacity {
+				t.Errorf("max-flow pgrouting=%d go=%d", a.TotalCapacity, b.TotalCapacity)
+			}
+			if !sameSet(a.SourceSide, b.SourceSide) {
+				t.Errorf("ฝั่ง source ต่างกัน pgrouting=%d go=%d โหนด", len(a.SourceSide), len(b.SourceSide))
+			}
+		})
+
+		t.Run("mst "+kinds, func(t *testing.T) {
+			q := MSTByFlowQuery{Root: rootID, K: 10, KMst: 10, NTop: 30, Kinds: kinds, MaxDist: 100000}
+			a, err1 := pgrCtrl.MSTByFlow(q)
+			b, err2 := goCtrl.MSTByFlow(q)
+			mustNil(t, err1, err2)
+			// ลำดับ DFS ไม่จำเป็นต้องตรง เทียบชุดเส้นและ agg_cost ต่อโหนด
+			ea, aggA := treeOf(a.MST)
+			eb, aggB := treeOf(b.MST)
+			if !sameSet(ea, eb) {
+				t.Errorf("เส้นของต้นไม้ต่างกัน pgrouting=%d go=%d", len(ea), len(eb))
+			}
+			if len(aggA) != len(aggB) {
+				t.Fatalf("จำนวนโหนด pgrouting=%d go=%d", len(aggA), len(aggB))
+			}
+			for code, x := range aggA {
+				if y, ok := aggB[code]; !ok || math.Abs(x-y) > 0.01 {
+					t.Errorf("%s agg_cost pgrouting=%.3f go=%.3f", code, x, y)
+				}
+			}
+		})
+	}
+}
+
+func mustNil(t *testing.T, errs ...error) {
+	t.Helper()
+	for _, err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// treeOf เส้นของต้นไม้ในรูป "a-b" (เรียง a < b) และ agg_cost ของแต่ละโหนด
+func treeOf(rows []MSTRow) ([]string, map[string]float64) {
+	var edges []string
+	agg := map[string]float64{}
+	for _, r := range rows {
+		agg[r.Code] = r.AggCost
+		if r.PredCode == "" {
+			continue
+		}
+		a, b := r.PredCode, r.Code
+		if b < a {
+			a, b = b, a
+		}
+		edges = append(edges, a+"-"+b)
+	}
+	return edges, agg
+}
+
+func sameSet(a, b []string) bool {
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
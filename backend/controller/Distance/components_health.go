@@ -1,56 +1,251 @@
 package Distance
 
 import (
+	"math"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/graphalgo"
 )
 
+// ------------------------------------------------------------
+// /health/components: landmark แบ่งเป็นกี่กลุ่มที่เชื่อมถึงกันในกราฟ KNN
+//   - รายละเอียดรายกลุ่ม: ขนาด, สมาชิก, bbox, centroid, ระยะเชื่อมที่สั้นสุดไปกลุ่มใหญ่สุด
+//   - จุดที่ไม่มีเส้นเลย (ไกลเกิน maxedge) เป็นกลุ่มละ 1 จุด isolated=true
+//     แต่ไม่นับใน components (ตาม pgr_connectedComponents ที่เห็นเฉพาะจุดที่มีเส้น)
+//   - reachable = อยู่กลุ่มเดียวกับ root (ไม่ส่ง = กลุ่มใหญ่สุด) → MST ของ planner ไปถึง
+// ------------------------------------------------------------
+
 type ComponentsHealth struct {
-	Components int `gorm:"column:components" json:"components"`
+	Components int    `gorm:"column:components" json:"components"`
+	Backend    string `gorm:"-" json:"backend"` // pgrouting | go
+
+	Details []ComponentInfo `gorm:"-" json:"details"` // เรียงจากกลุ่มใหญ่สุด
+}
+
+type ComponentInfo struct {
+	ID        int        `json:"id"` // ลำดับหลังเรียงตามขนาด (0 = ใหญ่สุด)
+	Size      int        `json:"size"`
+	Members   []int      `json:"members"`  // landmark_id
+	BBox      [4]float64 `json:"bbox"`     // [minLon, minLat, maxLon, maxLat]
+	Centroid  [2]float64 `json:"centroid"` // [lon, lat]
+	Isolated  bool       `json:"isolated"`
+	Reachable bool       `json:"reachable"`
+
+	// เส้นที่สั้นสุดจากกลุ่มนี้ไปกลุ่มใหญ่สุด (ไม่มีในกลุ่มใหญ่สุด)
+	BridgeM    *float64 `json:"bridge_m,omitempty"`
+	BridgeFrom int      `json:"bridge_from,omitempty"`
+	BridgeTo   int      `json:"bridge_to,omitempty"`
 }
 
 // GET /health/components?maxedge=2000&k=10
+// &root=P29 (กลุ่มที่ถือว่า planner ไปถึง) &format=geojson (จุดทุก landmark + เส้นเชื่อมไปกลุ่มใหญ่สุด)
 func (ctrl *DistanceController) GetComponentsHealth(c *gin.Context) {
-	maxEdgeStr := c.DefaultQuery("maxedge", "2000")
-	kStr := c.DefaultQuery("k", "10")
-
-	sql := `
-WITH params AS (
-  SELECT $1::float8 AS maxedge, $2::int AS k
-),
-lm AS (
-  SELECT landmark_id::bigint AS id, location AS geom
-  FROM landmark_gis
-),
-edges AS (
-  SELECT
-    ROW_NUMBER() OVER ()::bigint AS id,
-    a.id AS source,
-    b.id AS target,
-    ST_DistanceSphere(a.geom, b.geom) AS cost,
-    ST_DistanceSphere(a.geom, b.geom) AS reverse_cost
-  FROM lm a
-  JOIN LATERAL (
-    SELECT id, geom
-    FROM lm b
-    WHERE b.id <> a.id
-      AND ST_DWithin(a.geom, b.geom, (SELECT maxedge FROM params))
-    ORDER BY b.geom <-> a.geom
-    LIMIT (SELECT k FROM params)
-  ) b ON TRUE
-),
-cc AS (
-  SELECT * FROM pgr_connectedComponents(
-    'SELECT id, source, target, cost, reverse_cost FROM edges'
-  )
-)
-SELECT COUNT(DISTINCT component) AS components FROM cc;`
+	maxEdge, err1 := strconv.ParseFloat(c.DefaultQuery("maxedge", "2000"), 64)
+	k, err2 := strconv.Atoi(c.DefaultQuery("k", "10"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "maxedge และ k ต้องเป็นตัวเลข"})
+		return
+	}
+	root := 0
+	if s := strings.TrimSpace(c.Query("root")); s != "" {
+		codes := parseNodeCodes(s)
+		if len(codes) != 1 || codes[0][0] != 'P' {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "root ต้องเป็นรหัส P หรือ landmark_id"})
+			return
+		}
+		root, _ = nodeID(codes[0])
+	}
 
-	var res ComponentsHealth
-	if err := ctrl.PostgisDB.Raw(sql, maxEdgeStr, kStr).Scan(&res).Error; err != nil {
+	res, nodes, err := ctrl.componentsDetail(maxEdge, k, root)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "ตรวจ components ล้มเหลว", "detail": err.Error()})
 		return
 	}
+	if c.Query("format") == "geojson" {
+		c.JSON(http.StatusOK, componentsGeoJSON(res, nodes))
+		return
+	}
 	c.JSON(http.StatusOK, res)
 }
+
+// ComponentsHealth จำนวนกลุ่มของ landmark ที่เชื่อมถึงกันในกราฟ KNN พร้อมรายละเอียดรายกลุ่ม
+// (maxEdge เป็นหน่วยของ geometry คือองศา ตาม ST_DWithin เดิม; root = 0 → กลุ่มใหญ่สุดคือ reachable)
+func (ctrl *DistanceController) ComponentsHealth(maxEdge float64, k int) (*ComponentsHealth, error) {
+	res, _, err := ctrl.componentsDetail(maxEdge, k, 0)
+	return res, err
+}
+
+func (ctrl *DistanceController) componentsDetail(maxEdge float64, k, root int) (*ComponentsHealth, []graphalgo.Node, error) {
+	var nodes []graphalgo.Node
+	if err := ctrl.PostgisDB.Raw(`SELECT landmark_id::int AS id, ST_X(location) AS lon, ST_Y(location) AS lat
+		FROM landmark_gis ORDER BY landmark_id`).Scan(&nodes).Error; err != nil {
+		return nil, nil, err
+	}
+
+	backend := ctrl.graphBackend()
+	var groups [][]int
+	if backend == BackendGo {
+		groups = graphalgo.Components(graphalgo.KNN(nodes, k, maxEdge))
+	} else {
+		var err error
+		if groups, err = ctrl.componentsPgRouting(maxEdge, k); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	res := &ComponentsHealth{Components: len(groups), Backend: backend}
+	res.Details = describeComponents(nodes, groups, root)
+	return res, nodes, nil
+}
+
+// componentsPgRouting สมาชิกของแต่ละกลุ่มจาก pgr_connectedComponents
+// (กราฟเป็น temp table เพราะ SQL ข้างในของ pgr_* มองไม่เห็น CTE ของ query ภายนอก)
+func (ctrl *DistanceController) componentsPgRouting(maxEdge float64, k int) ([][]int, error) {
+	var rows []struct {
+		Component int `gorm:"column:component"`
+		Node      int `gorm:"column:node"`
+	}
+	err := ctrl.PostgisDB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+CREATE TEMP TABLE cc_edges ON COMMIT DROP AS
+SELECT
+  ROW_NUMBER() OVER (ORDER BY a.id, b.id)::bigint AS id,
+  a.id AS source,
+  b.id AS target,
+  ST_DistanceSphere(a.geom, b.geom) AS cost,
+  ST_DistanceSphere(a.geom, b.geom) AS reverse_cost
+FROM (SELECT landmark_id::bigint AS id, location AS geom FROM landmark_gis) a
+JOIN LATERAL (
+  SELECT landmark_id::bigint AS id, location AS geom
+  FROM landmark_gis b
+  WHERE b.landmark_id <> a.id
+    AND ST_DWithin(a.geom, b.location, ?::float8)
+  ORDER BY b.location <-> a.geom
+  LIMIT ?::int
+) b ON TRUE`, maxEdge, k).Error; err != nil {
+			return err
+		}
+		return tx.Raw(`SELECT component::int, node::int FROM pgr_connectedComponents(
+  'SELECT id, source, target, cost, reverse_cost FROM cc_edges'
+)`).Scan(&rows).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byComp := map[int][]int{}
+	for _, r := range rows {
+		byComp[r.Component] = append(byComp[r.Component], r.Node)
+	}
+	groups := make([][]int, 0, len(byComp))
+	for _, g := range byComp {
+		sort.Ints(g)
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// describeComponents รายละเอียดรายกลุ่ม (เติมจุดที่ไม่มีเส้นเป็นกลุ่ม isolated) เรียงจากใหญ่ไปเล็ก
+func describeComponents(nodes []graphalgo.Node, groups [][]int, root int) []ComponentInfo {
+	pos := make(map[int]graphalgo.Node, len(nodes))
+	for _, n := range nodes {
+		pos[n.ID] = n
+	}
+
+	out := make([]ComponentInfo, 0, len(groups))
+	seen := map[int]bool{}
+	for _, g := range groups {
+		for _, id := range g {
+			seen[id] = true
+		}
+		out = append(out, ComponentInfo{Members: g})
+	}
+	for _, n := range nodes {
+		if !seen[n.ID] {
+			out = append(out, ComponentInfo{Members: []int{n.ID}, Isolated: true})
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if len(out[i].Members) != len(out[j].Members) {
+			return len(out[i].Members) > len(out[j].Members)
+		}
+		return out[i].Members[0] < out[j].Members[0]
+	})
+
+	reach := 0 // index ของกลุ่มที่ planner ไปถึง
+	for i := range out {
+		ci := &out[i]
+		ci.ID, ci.Size = i, len(ci.Members)
+		ci.BBox = [4]float64{math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)}
+		for _, id := range ci.Members {
+			p := pos[id]
+			ci.BBox[0], ci.BBox[1] = math.Min(ci.BBox[0], p.Lon), math.Min(ci.BBox[1], p.Lat)
+			ci.BBox[2], ci.BBox[3] = math.Max(ci.BBox[2], p.Lon), math.Max(ci.BBox[3], p.Lat)
+			ci.Centroid[0] += p.Lon / float64(ci.Size)
+			ci.Centroid[1] += p.Lat / float64(ci.Size)
+			if id == root {
+				reach = i
+			}
+		}
+	}
+	if len(out) == 0 {
+		return out
+	}
+	out[reach].Reachable = true
+
+	// เส้นเชื่อมสั้นสุดไปกลุ่มใหญ่สุด (ไล่ทุกคู่ จำนวน landmark หลักพันยังเร็วพอ)
+	largest := out[0].Members
+	for i := 1; i < len(out); i++ {
+		best := math.Inf(1)
+		for _, a := range out[i].Members {
+			pa := pos[a]
+			for _, b := range largest {
+				pb := pos[b]
+				if d := graphalgo.DistanceM(pa.Lat, pa.Lon, pb.Lat, pb.Lon); d < best {
+					best, out[i].BridgeFrom, out[i].BridgeTo = d, a, b
+				}
+			}
+		}
+		d := math.Round(best)
+		out[i].BridgeM = &d
+	}
+	return out
+}
+
+// componentsGeoJSON จุดของทุก landmark (properties บอกกลุ่ม) + เส้นเชื่อมของแต่ละกลุ่มไปกลุ่มใหญ่สุด
+func componentsGeoJSON(res *ComponentsHealth, nodes []graphalgo.Node) GeoJSONCollection {
+	pos := make(map[int]graphalgo.Node, len(nodes))
+	for _, n := range nodes {
+		pos[n.ID] = n
+	}
+	fc := newCollection()
+	for _, ci := range res.Details {
+		for _, id := range ci.Members {
+			p := pos[id]
+			fc.Features = append(fc.Features, pointFeature([2]float64{p.Lon, p.Lat}, map[string]any{
+				"code":      "P" + strconv.Itoa(id),
+				"component": ci.ID,
+				"size":      ci.Size,
+				"isolated":  ci.Isolated,
+				"reachable": ci.Reachable,
+			}))
+		}
+		if ci.BridgeM != nil {
+			a, b := pos[ci.BridgeFrom], pos[ci.BridgeTo]
+			fc.Features = append(fc.Features, lineFeature([2]float64{a.Lon, a.Lat}, [2]float64{b.Lon, b.Lat},
+				map[string]any{
+					"component": ci.ID,
+					"from":      "P" + strconv.Itoa(ci.BridgeFrom),
+					"to":        "P" + strconv.Itoa(ci.BridgeTo),
+					"bridge_m":  *ci.BridgeM,
+				}))
+		}
+	}
+	return fc
+}
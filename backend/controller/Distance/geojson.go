@@ -0,0 +1,54 @@
+package Distance
+
+import "math"
+
+// ------------------------------------------------------------
+// GeoJSON (RFC 7946) แบบย่อ สำหรับส่งเส้น/จุดให้ frontend วาดบนแผนที่
+// ------------------------------------------------------------
+
+type GeoJSONGeometry struct {
+	Type        string `json:"type"`        // Point | LineString | MultiPoint
+	Coordinates any    `json:"coordinates"` // [lon, lat] หรือ [[lon, lat], ...]
+}
+
+type GeoJSONFeature struct {
+	Type       string          `json:"type"` // "Feature"
+	Geometry   GeoJSONGeometry `json:"geometry"`
+	Properties map[string]any  `json:"properties"`
+}
+
+type GeoJSONCollection struct {
+	Type     string           `json:"type"` // "FeatureCollection"
+	Features []GeoJSONFeature `json:"features"`
+}
+
+func newCollection() GeoJSONCollection {
+	return GeoJSONCollection{Type: "FeatureCollection", Features: []GeoJSONFeature{}}
+}
+
+func lineFeature(a, b [2]float64, props map[string]any) GeoJSONFeature {
+	return GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   GeoJSONGeometry{Type: "LineString", Coordinates: [][2]float64{a, b}},
+		Properties: props,
+	}
+}
+
+func pointFeature(p [2]float64, props map[string]any) GeoJSONFeature {
+	return GeoJSONFeature{
+		Type:       "Feature",
+		Geometry:   GeoJSONGeometry{Type: "Point", Coordinates: p},
+		Properties: props,
+	}
+}
+
+// haversineM ระยะบนผิวโลก (เมตร) รัศมีเดียวกับ ST_DistanceSphere
+func haversineM(lat1, lon1, lat2, lon2 float64) float64 {
+	const r = 6370986.0
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * r * math.Asin(math.Min(1, math.Sqrt(h)))
+}
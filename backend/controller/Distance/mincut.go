@@ -0,0 +1,487 @@
+package Distance
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/graphalgo"
+)
+
+// ------------------------------------------------------------
+// Min-cut ระหว่างสองโซน (Boykov-Kolmogorov ของ pgRouting หรือ Dinic ใน Go ดู backend.go)
+//   - โหนดมาจาก landmark_gis / restaurant_gis / accommodation_gis ตาม kinds
+//   - กราฟ KNN สร้างครั้งเดียวเป็น temp table (id คงที่: เรียงตาม source, target)
+//     แล้วใช้ตารางเดียวกันทั้งใน BK และตอนหาฝั่งของ cut
+//   - ฝั่ง source = โหนดที่ยังไปถึงได้ใน residual graph (คำนวณใน Go รวมเส้นที่ไม่มี flow ด้วย)
+//   - capacity = 10000 / ระยะ(เมตร) → เส้นยาวจุน้อย ตัดง่าย = คอขวดของการเดินทาง
+// ------------------------------------------------------------
+
+// node id ในกราฟแบบหลายประเภท: landmark ใช้ landmark_id ตรง ๆ (เข้ากับ API เดิม) ประเภทอื่นบวก offset
+const (
+	restaurantNodeOffset    = 1_000_000
+	accommodationNodeOffset = 2_000_000
+)
+
+// ErrBadFlowQuery พารามิเตอร์ของ min-cut ไม่ถูกต้อง (โซนว่าง/ซ้อนกัน, root ไม่อยู่ในกราฟ)
+var ErrBadFlowQuery = errors.New("พารามิเตอร์ min-cut ไม่ถูกต้อง")
+
+// nodeID รหัส P/R/A → node id
+func nodeID(code string) (int, bool) {
+	if len(code) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(code[1:])
+	if err != nil || n <= 0 || n >= restaurantNodeOffset {
+		return 0, false
+	}
+	switch code[0] {
+	case 'P':
+		return n, true
+	case 'R':
+		return n + restaurantNodeOffset, true
+	case 'A':
+		return n + accommodationNodeOffset, true
+	}
+	return 0, false
+}
+
+// nodeCode node id → รหัส P/R/A (กลับด้านของ nodeID)
+func nodeCode(id int) string {
+	switch {
+	case id >= accommodationNodeOffset:
+		return "A" + strconv.Itoa(id-accommodationNodeOffset)
+	case id >= restaurantNodeOffset:
+		return "R" + strconv.Itoa(id-restaurantNodeOffset)
+	}
+	return "P" + strconv.Itoa(id)
+}
+
+// parseNodeCodes "P12, R3, 15" → ["P12" "R3" "P15"] (เลขล้วนถือเป็น landmark ข้ามตัวที่อ่านไม่ออก)
+func parseNodeCodes(csv string) []string {
+	var out []string
+	for _, x := range strings.Split(csv, ",") {
+		x = strings.ToUpper(strings.TrimSpace(x))
+		if x == "" {
+			continue
+		}
+		if x[0] >= '0' && x[0] <= '9' {
+			x = "P" + x
+		}
+		if _, ok := nodeID(x); ok {
+			out = append(out, x)
+		}
+	}
+	return out
+}
+
+// parseKinds "P,R" / "PRA" / "landmark,restaurant" → "PR" (ค่าว่าง = landmark อย่างเดียว)
+func parseKinds(s string) string {
+	names := map[string]byte{"LANDMARK": 'P', "RESTAURANT": 'R', "ACCOMMODATION": 'A'}
+	has := map[byte]bool{}
+	for _, tok := range strings.Split(strings.ToUpper(s), ",") {
+		tok = strings.TrimSpace(tok)
+		if k, ok := names[tok]; ok {
+			has[k] = true
+			continue
+		}
+		if strings.Trim(tok, "PRA") == "" {
+			for i := 0; i < len(tok); i++ {
+				has[tok[i]] = true
+			}
+		}
+	}
+	var b strings.Builder
+	for _, k := range []byte("PRA") {
+		if has[k] {
+			b.WriteByte(k)
+		}
+	}
+	if b.Len() == 0 {
+		return "P"
+	}
+	return b.String()
+}
+
+// kindNodesSQL SELECT (id, code, geom) ของโหนดทุกประเภทใน kinds ตัด exclude (รหัส P/R/A) ออก
+func kindNodesSQL(kinds string, exclude []string) string {
+	ex := map[byte][]int{}
+	for _, code := range exclude {
+		if n, err := strconv.Atoi(code[1:]); err == nil {
+			ex[code[0]] = append(ex[code[0]], n)
+		}
+	}
+	var parts []string
+	if strings.Contains(kinds, "P") {
+		parts = append(parts, `SELECT landmark_id::int AS id, 'P' || landmark_id AS code, location AS geom
+  FROM public.landmark_gis`+excludeClause("WHERE", "landmark_id", ex['P']))
+	}
+	if strings.Contains(kinds, "R") {
+		parts = append(parts, fmt.Sprintf(`SELECT restaurant_id::int + %d AS id, 'R' || restaurant_id AS code, location AS geom
+  FROM public.restaurant_gis`, restaurantNodeOffset)+excludeClause("WHERE", "restaurant_id", ex['R']))
+	}
+	if strings.Contains(kinds, "A") {
+		parts = append(parts, fmt.Sprintf(`SELECT acc_id::int + %d AS id, 'A' || acc_id AS code, location AS geom
+  FROM public.accommodation_gis`, accommodationNodeOffset)+excludeClause("WHERE", "acc_id", ex['A']))
+	}
+	return strings.Join(parts, "\nUNION ALL\n")
+}
+
+// ------------------------------------------------------------
+// types
+// ------------------------------------------------------------
+
+// MinCutQuery พารามิเตอร์ของ /flow/mincut (ใช้ทั้ง handler และ MSTByFlow)
+type MinCutQuery struct {
+	Root       string   // รหัสที่ใช้หาโซนอัตโนมัติ (เมื่อไม่ส่งโซนมาครบ)
+	SourceZone []string // รหัสฝั่ง source (zoneA)
+	SinkZone   []string // รหัสฝั่ง sink (zoneB)
+	K          int      // จำนวนเพื่อนบ้านต่อโหนดของกราฟ KNN
+	NTop       int      // ขนาด zoneA เมื่อหาโซนอัตโนมัติ
+	Kinds      string   // ประเภทโหนด "P", "PR", "PRA"
+	Exclude    []string // รหัสที่ตัดออกจากกราฟ
+}
+
+type CutEdge struct {
+	From      string  `json:"from"` // ฝั่ง source
+	To        string  `json:"to"`   // ฝั่ง sink
+	Capacity  int     `json:"capacity"`
+	DistanceM float64 `json:"distance_m"`
+}
+
+type MinCutResp struct {
+	CutEdgeIDs    [][2]int          `json:"cut_edge_ids"` // (source,target) node id ที่เป็นคอขวด (landmark = landmark_id)
+	CutEdges      []CutEdge         `json:"cut_edges"`
+	SourceSide    []string          `json:"source_side"` // โหนดที่ยังไปถึงได้จาก zoneA หลังตัด
+	SinkSide      []string          `json:"sink_side"`
+	TotalCapacity int64             `json:"total_capacity"` // = max flow
+	GeoJSON       GeoJSONCollection `json:"geojson"`        // LineString ของเส้นที่ถูกตัด
+	Backend       string            `json:"backend"`        // pgrouting | go
+}
+
+type flowNode struct {
+	ID   int     `gorm:"column:id"`
+	Code string  `gorm:"column:code"`
+	Lon  float64 `gorm:"column:lon"`
+	Lat  float64 `gorm:"column:lat"`
+}
+
+type flowEdge struct {
+	ID       int     `gorm:"column:id"`
+	Source   int     `gorm:"column:source"`
+	Target   int     `gorm:"column:target"`
+	Dist     float64 `gorm:"column:dist"`
+	Capacity int     `gorm:"column:capacity"`
+}
+
+type flowRow struct {
+	Edge     int `gorm:"column:edge"`
+	StartVID int `gorm:"column:start_vid"`
+	Flow     int `gorm:"column:flow"`
+}
+
+// ------------------------------------------------------------
+// /flow/mincut
+// ------------------------------------------------------------
+//
+// GET /flow/mincut?root=P29&k=20&n_top=40&kinds=P,R,A
+// หรือส่ง zoneA/zoneB เอง เช่น zoneA=P1,P2,R5&zoneB=P40,A3 (เลขล้วน = landmark_id)
+// exclude=P12,R3 ตัดโหนดออกจากกราฟ
+func (ctrl *DistanceController) GetFlowMinCut(c *gin.Context) {
+	k, _ := strconv.Atoi(c.DefaultQuery("k", "20"))
+	if k < 1 {
+		k = 20
+	}
+	nTop, _ := strconv.Atoi(c.DefaultQuery("n_top", "40"))
+
+	q := MinCutQuery{
+		Root:       strings.TrimSpace(c.Query("root")),
+		SourceZone: parseNodeCodes(c.Query("zoneA")),
+		SinkZone:   parseNodeCodes(c.Query("zoneB")),
+		K:          k,
+		NTop:       nTop,
+		Kinds:      parseKinds(c.DefaultQuery("kinds", "P")),
+		Exclude:    parseNodeCodes(c.Query("exclude")),
+	}
+	if q.Root != "" {
+		if codes := parseNodeCodes(q.Root); len(codes) == 1 {
+			q.Root = codes[0]
+		} else {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "root ต้องเป็นรหัส P/R/A หรือ landmark_id"})
+			return
+		}
+	}
+	if (len(q.SourceZone) == 0 || len(q.SinkZone) == 0) && q.Root == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ต้องระบุ root เมื่อไม่ส่ง zoneA/zoneB"})
+		return
+	}
+
+	resp, err := ctrl.MinCut(q)
+	if err != nil {
+		var fe *flowError
+		switch {
+		case errors.Is(err, ErrBadFlowQuery):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.As(err, &fe):
+			c.JSON(http.StatusInternalServerError, gin.H{"error": fe.Msg, "detail": fe.Err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// MinCut หา min-cut ระหว่าง SourceZone กับ SinkZone (ไม่ส่งโซน = Top-N ใกล้ Root เป็น zoneA ที่เหลือเป็น zoneB)
+func (ctrl *DistanceController) MinCut(q MinCutQuery) (*MinCutResp, error) {
+	if q.K < 1 {
+		q.K = 20
+	}
+	kinds := q.Kinds
+	if kinds == "" {
+		kinds = "P"
+	}
+	if ctrl.graphBackend() == BackendGo {
+		return ctrl.minCutGo(q, kinds)
+	}
+
+	var nodes []flowNode
+	var edges []flowEdge
+	var flows []flowRow
+	var srcIDs []int
+
+	err := ctrl.PostgisDB.Transaction(func(tx *gorm.DB) error {
+		for _, stmt := range []string{
+			`CREATE TEMP TABLE mc_nodes ON COMMIT DROP AS ` + kindNodesSQL(kinds, q.Exclude),
+			`CREATE INDEX ON mc_nodes USING GIST (geom)`,
+			fmt.Sprintf(`CREATE TEMP TABLE mc_edges ON COMMIT DROP AS
+SELECT ROW_NUMBER() OVER (ORDER BY a.id, b.id)::int AS id,
+       a.id AS source, b.id AS target, b.dist,
+       CEIL(GREATEST(1.0, 10000.0/NULLIF(b.dist,0)))::int AS capacity
+FROM mc_nodes a
+JOIN LATERAL (
+  SELECT id, ST_DistanceSphere(a.geom, b.geom) AS dist
+  FROM mc_nodes b
+  WHERE b.id <> a.id
+  ORDER BY b.geom <-> a.geom
+  LIMIT %d
+) b ON TRUE`, q.K),
+		} {
+			if err := tx.Exec(stmt).Error; err != nil {
+				return &flowError{Msg: "สร้างกราฟ KNN ไม่สำเร็จ", Err: err}
+			}
+		}
+		if err := tx.Raw(`SELECT id, code, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM mc_nodes ORDER BY id`).
+			Scan(&nodes).Error; err != nil {
+			return &flowError{Msg: "อ่านโหนดไม่สำเร็จ", Err: err}
+		}
+		if err := tx.Raw(`SELECT id, source, target, dist, capacity FROM mc_edges ORDER BY id`).
+			Scan(&edges).Error; err != nil {
+			return &flowError{Msg: "อ่านกราฟ KNN ไม่สำเร็จ", Err: err}
+		}
+
+		src, sink, err := resolveZones(q, nodes)
+		if err != nil {
+			return err
+		}
+		srcIDs = src
+		if err := tx.Raw(fmt.Sprintf(`
+SELECT edge, start_vid, flow
+FROM %s(
+  'SELECT id, source, target, capacity, capacity AS reverse_capacity FROM mc_edges',
+  STRING_TO_ARRAY(?, ',')::bigint[],
+  STRING_TO_ARRAY(?, ',')::bigint[]
+)`, flowFn), joinInts(src), joinInts(sink)).Scan(&flows).Error; err != nil {
+			return &flowError{Msg: "คำนวณ min-cut ล้มเหลว", Err: err}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	resp := cutFromFlow(nodes, edges, flows, srcIDs)
+	resp.Backend = BackendPgRouting
+	return resp, nil
+}
+
+// minCutGo min-cut แบบไม่ใช้ pgRouting: สร้างกราฟ KNN และหา max-flow (Dinic) ใน Go
+func (ctrl *DistanceController) minCutGo(q MinCutQuery, kinds string) (*MinCutResp, error) {
+	var nodes []flowNode
+	if err := ctrl.PostgisDB.Raw(`SELECT id, code, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM (` +
+		kindNodesSQL(kinds, q.Exclude) + `) n ORDER BY id`).Scan(&nodes).Error; err != nil {
+		return nil, &flowError{Msg: "อ่านโหนดไม่สำเร็จ", Err: err}
+	}
+	pts := make([]graphalgo.Node, len(nodes))
+	for i, n := range nodes {
+		pts[i] = graphalgo.Node{ID: n.ID, Lon: n.Lon, Lat: n.Lat}
+	}
+
+	knn := graphalgo.KNN(pts, q.K, 0)
+	edges := make([]flowEdge, len(knn))
+	fes := make([]graphalgo.FlowEdge, len(knn))
+	for i, e := range knn {
+		capacity := 1 // เหมือน CEIL(GREATEST(1.0, 10000.0/NULLIF(dist,0)))
+		if e.Dist > 0 {
+			capacity = int(math.Ceil(math.Max(1, 10000/e.Dist)))
+		}
+		edges[i] = flowEdge{ID: e.ID, Source: e.Source, Target: e.Target, Dist: e.Dist, Capacity: capacity}
+		fes[i] = graphalgo.FlowEdge{ID: e.ID, Source: e.Source, Target: e.Target, Capacity: capacity, ReverseCapacity: capacity}
+	}
+
+	src, sink, err := resolveZones(q, nodes)
+	if err != nil {
+		return nil, err
+	}
+	_, rows := graphalgo.MaxFlow(fes, src, sink)
+	flows := make([]flowRow, len(rows))
+	for i, r := range rows {
+		flows[i] = flowRow{Edge: r.Edge, StartVID: r.StartVID, Flow: r.Flow}
+	}
+
+	resp := cutFromFlow(nodes, edges, flows, src)
+	resp.Backend = BackendGo
+	return resp, nil
+}
+
+// resolveZones แปลงโซนเป็น node id (หรือหาอัตโนมัติจาก Root) และตรวจว่าใช้ได้
+func resolveZones(q MinCutQuery, nodes []flowNode) ([]int, []int, error) {
+	index := make(map[string]int, len(nodes))
+	for i, n := range nodes {
+		index[n.Code] = i
+	}
+
+	var srcCodes, sinkCodes []string
+	if len(q.SourceZone) > 0 && len(q.SinkZone) > 0 {
+		srcCodes, sinkCodes = q.SourceZone, q.SinkZone
+	} else {
+		ri, ok := index[q.Root]
+		if !ok {
+			return nil, nil, fmt.Errorf("%w: root %s ไม่อยู่ในกราฟ", ErrBadFlowQuery, q.Root)
+		}
+		// Top-N ใกล้ root (รวม root) เป็น zoneA ที่เหลือเป็น zoneB; zoneB ต้องไม่ว่าง
+		root := nodes[ri]
+		order := make([]int, len(nodes))
+		dist := make([]float64, len(nodes))
+		for i, n := range nodes {
+			order[i] = i
+			dist[i] = haversineM(root.Lat, root.Lon, n.Lat, n.Lon)
+		}
+		sort.SliceStable(order, func(a, b int) bool { return dist[order[a]] < dist[order[b]] })
+		nEff := min(max(q.NTop, 1), len(nodes)-1)
+		for i, idx := range order {
+			if i < nEff {
+				srcCodes = append(srcCodes, nodes[idx].Code)
+			} else {
+				sinkCodes = append(sinkCodes, nodes[idx].Code)
+			}
+		}
+	}
+
+	inSrc := map[string]bool{}
+	var src, sink []int
+	for _, code := range srcCodes {
+		if i, ok := index[code]; ok && !inSrc[code] {
+			inSrc[code] = true
+			src = append(src, nodes[i].ID)
+		}
+	}
+	for _, code := range sinkCodes {
+		if inSrc[code] {
+			return nil, nil, fmt.Errorf("%w: %s อยู่ทั้ง zoneA และ zoneB", ErrBadFlowQuery, code)
+		}
+		if i, ok := index[code]; ok {
+			sink = append(sink, nodes[i].ID)
+		}
+	}
+	if len(src) == 0 || len(sink) == 0 {
+		return nil, nil, fmt.Errorf("%w: zoneA และ zoneB ต้องมีโหนดที่อยู่ในกราฟอย่างน้อยฝั่งละหนึ่งจุด", ErrBadFlowQuery)
+	}
+	return src, sink, nil
+}
+
+// cutFromFlow หาฝั่ง source จาก residual graph แล้วเก็บเส้นที่ข้ามฝั่ง
+// แต่ละเส้นจุ capacity ทั้งสองทิศ: flow f จาก u→v เหลือ u→v = c-f, v→u = c+f
+func cutFromFlow(nodes []flowNode, edges []flowEdge, flows []flowRow, src []int) *MinCutResp {
+	edgeSrc := make(map[int]int, len(edges))
+	for _, e := range edges {
+		edgeSrc[e.ID] = e.Source
+	}
+	flowOf := make(map[int]int, len(flows)) // edge → flow ทิศ source→target (ติดลบ = ทิศกลับ)
+	for _, f := range flows {
+		if f.StartVID == edgeSrc[f.Edge] {
+			flowOf[f.Edge] += f.Flow
+		} else {
+			flowOf[f.Edge] -= f.Flow
+		}
+	}
+
+	adj := map[int][]int{} // node → next ที่ residual > 0
+	for _, e := range edges {
+		f := flowOf[e.ID]
+		if e.Capacity-f > 0 {
+			adj[e.Source] = append(adj[e.Source], e.Target)
+		}
+		if e.Capacity+f > 0 {
+			adj[e.Target] = append(adj[e.Target], e.Source)
+		}
+	}
+	reach := map[int]bool{}
+	queue := append([]int(nil), src...)
+	for _, s := range src {
+		reach[s] = true
+	}
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+		for _, v := range adj[u] {
+			if !reach[v] {
+				reach[v] = true
+				queue = append(queue, v)
+			}
+		}
+	}
+
+	pos := make(map[int]flowNode, len(nodes))
+	resp := &MinCutResp{CutEdgeIDs: [][2]int{}, CutEdges: []CutEdge{}, GeoJSON: newCollection()}
+	for _, n := range nodes {
+		pos[n.ID] = n
+		if reach[n.ID] {
+			resp.SourceSide = append(resp.SourceSide, n.Code)
+		} else {
+			resp.SinkSide = append(resp.SinkSide, n.Code)
+		}
+	}
+	for _, e := range edges {
+		s, t := e.Source, e.Target
+		if reach[s] == reach[t] {
+			continue
+		}
+		if !reach[s] {
+			s, t = t, s
+		}
+		a, b := pos[s], pos[t]
+		resp.CutEdgeIDs = append(resp.CutEdgeIDs, [2]int{s, t})
+		resp.CutEdges = append(resp.CutEdges, CutEdge{From: a.Code, To: b.Code, Capacity: e.Capacity, DistanceM: e.Dist})
+		resp.TotalCapacity += int64(e.Capacity)
+		resp.GeoJSON.Features = append(resp.GeoJSON.Features, lineFeature(
+			[2]float64{a.Lon, a.Lat}, [2]float64{b.Lon, b.Lat},
+			map[string]any{"from": a.Code, "to": b.Code, "capacity": e.Capacity, "distance_m": e.Dist},
+		))
+	}
+	return resp
+}
+
+func joinInts(xs []int) string {
+	parts := make([]string, len(xs))
+	for i, x := range xs {
+		parts[i] = strconv.Itoa(x)
+	}
+	return strings.Join(parts, ",")
+}
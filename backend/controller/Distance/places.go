@@ -0,0 +1,353 @@
+package Distance
+
+import (
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ------------------------------------------------------------
+// /places/search: ค้นสถานที่ทุกประเภทด้วยพื้นที่ + คุณสมบัติ + แบ่งหน้า
+//   1) PostGIS: bbox / center+radius (กรองด้วย && ก่อนเพื่อให้ใช้ GIST index ของ *_gis)
+//   2) ฐานข้อมูลหลัก: ประเภท, ช่วงราคา, จังหวัด/อำเภอ — query เดียว UNION ALL ทุกชนิด
+//   3) PostGIS: พิกัดของแถวในหน้าที่ส่งกลับ (ครั้งเดียว ไม่ยิงทีละแถว)
+//   มี center → เรียงตามระยะ, ไม่มี → เรียงตามชื่อ
+// ------------------------------------------------------------
+
+const (
+	defaultPerPage = 20
+	maxPerPage     = 100
+)
+
+type PlaceSearchQuery struct {
+	Kinds    string      // "PRA" (ดู parseKinds)
+	BBox     *[4]float64 // minLon, minLat, maxLon, maxLat
+	Center   *[2]float64 // lon, lat
+	RadiusM  float64
+	Types    []string // ชื่อหรือ code ของ travel_types (ตรงอันใดอันหนึ่ง)
+	PriceMin *int     // ช่วงราคาของสถานที่ต้องซ้อนกับ [PriceMin, PriceMax]
+	PriceMax *int
+	Province string
+	District string
+	Page     int // เริ่มที่ 1
+	PerPage  int
+}
+
+type PlaceHit struct {
+	Code         string   `json:"code"`
+	Kind         string   `json:"kind"` // landmark | restaurant | accommodation
+	ID           int      `json:"id"`
+	Name         string   `json:"name"`
+	Category     string   `json:"category"`
+	Province     string   `json:"province"`
+	District     string   `json:"district"`
+	PriceMin     int      `json:"price_min"`
+	PriceMax     int      `json:"price_max"`
+	Review       int      `json:"review"`
+	ThumbnailURL string   `json:"thumbnail_url,omitempty"`
+	Types        []string `json:"types"`
+	Lat          float64  `json:"lat"`
+	Lon          float64  `json:"lon"`
+	DistanceM    *float64 `json:"distance_m,omitempty"` // มีเมื่อส่ง center
+}
+
+type PlaceSearchResp struct {
+	Total   int        `json:"total"`
+	Page    int        `json:"page"`
+	PerPage int        `json:"per_page"`
+	Items   []PlaceHit `json:"items"`
+}
+
+// placeKind ตารางของแต่ละชนิดในฐานข้อมูลหลัก
+type placeKind struct {
+	prefix            byte
+	name, table       string
+	pivot, pivotIDCol string
+}
+
+var placeKinds = []placeKind{
+	{'P', "landmark", "landmarks", "landmark_types", "landmark_id"},
+	{'R', "restaurant", "restaurants", "restaurant_types", "restaurant_id"},
+	{'A', "accommodation", "accommodations", "accommodation_types", "accommodation_id"},
+}
+
+type placeAttrRow struct {
+	Code         string `gorm:"column:code"`
+	Kind         string `gorm:"column:kind"`
+	ID           int    `gorm:"column:id"`
+	Name         string `gorm:"column:name"`
+	Category     string `gorm:"column:category"`
+	Province     string `gorm:"column:province"`
+	District     string `gorm:"column:district"`
+	PriceMin     int    `gorm:"column:price_min"`
+	PriceMax     int    `gorm:"column:price_max"`
+	Review       int    `gorm:"column:review"`
+	ThumbnailURL string `gorm:"column:thumbnail_url"`
+	Types        string `gorm:"column:types"` // คั่นด้วย |
+}
+
+type placeGeoRow struct {
+	Code   string  `gorm:"column:code"`
+	Lon    float64 `gorm:"column:lon"`
+	Lat    float64 `gorm:"column:lat"`
+	Meters float64 `gorm:"column:meters"`
+}
+
+// GET /places/search?kinds=P,R,A
+// &bbox=minLon,minLat,maxLon,maxLat | &lat=13.75&lon=100.5&radius_m=2000
+// &type=คาเฟ่,ตลาด &price_min=0&price_max=500 &province=...&district=... &page=1&per_page=20
+func (ctrl *DistanceController) SearchPlaces(c *gin.Context) {
+	q := PlaceSearchQuery{
+		Kinds:    parseKinds(c.DefaultQuery("kinds", "P,R,A")),
+		Province: strings.TrimSpace(c.Query("province")),
+		District: strings.TrimSpace(c.Query("district")),
+	}
+	bad := func(msg string) { c.JSON(http.StatusBadRequest, gin.H{"error": msg}) }
+
+	if s := strings.TrimSpace(c.Query("bbox")); s != "" {
+		v, ok := parseFloats(s, 4)
+		if !ok || v[0] >= v[2] || v[1] >= v[3] {
+			bad("bbox ต้องเป็น minLon,minLat,maxLon,maxLat")
+			return
+		}
+		q.BBox = &[4]float64{v[0], v[1], v[2], v[3]}
+	}
+	if c.Query("lat") != "" || c.Query("lon") != "" {
+		lat, err1 := strconv.ParseFloat(c.Query("lat"), 64)
+		lon, err2 := strconv.ParseFloat(c.Query("lon"), 64)
+		r, err3 := strconv.ParseFloat(c.DefaultQuery("radius_m", "2000"), 64)
+		if err1 != nil || err2 != nil || err3 != nil || r <= 0 || math.Abs(lat) > 90 || math.Abs(lon) > 180 {
+			bad("lat, lon และ radius_m ไม่ถูกต้อง")
+			return
+		}
+		q.Center, q.RadiusM = &[2]float64{lon, lat}, r
+	}
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			q.Types = append(q.Types, t)
+		}
+	}
+	for _, p := range []struct {
+		key string
+		dst **int
+	}{{"price_min", &q.PriceMin}, {"price_max", &q.PriceMax}} {
+		if s := c.Query(p.key); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 0 {
+				bad(p.key + " ต้องเป็นจำนวนเต็มไม่ติดลบ")
+				return
+			}
+			*p.dst = &n
+		}
+	}
+	q.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	q.PerPage, _ = strconv.Atoi(c.DefaultQuery("per_page", strconv.Itoa(defaultPerPage)))
+
+	resp, err := ctrl.SearchPlacesQuery(q)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ค้นหาสถานที่ล้มเหลว", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// SearchPlacesQuery ค้นสถานที่ตาม q (page/per_page นอกช่วงถูกปรับให้อยู่ในช่วง)
+func (ctrl *DistanceController) SearchPlacesQuery(q PlaceSearchQuery) (*PlaceSearchResp, error) {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.PerPage <= 0 {
+		q.PerPage = defaultPerPage
+	}
+	if q.PerPage > maxPerPage {
+		q.PerPage = maxPerPage
+	}
+	if q.Kinds == "" {
+		q.Kinds = "PRA"
+	}
+	resp := &PlaceSearchResp{Page: q.Page, PerPage: q.PerPage, Items: []PlaceHit{}}
+
+	// 1) กรองเชิงพื้นที่ (ถ้ามี) → รหัสที่ผ่าน + พิกัด/ระยะ
+	var geo map[string]placeGeoRow
+	if q.BBox != nil || q.Center != nil {
+		rows, err := ctrl.spatialCandidates(q)
+		if err != nil {
+			return nil, err
+		}
+		if len(rows) == 0 {
+			return resp, nil
+		}
+		geo = make(map[string]placeGeoRow, len(rows))
+		for _, r := range rows {
+			geo[r.Code] = r
+		}
+	}
+
+	// 2) กรองคุณสมบัติ
+	sql, args := placeAttrSQL(q, geo)
+	if sql == "" {
+		return resp, nil
+	}
+	var attrs []placeAttrRow
+	if q.Center != nil {
+		// เรียงตามระยะ: จำนวนถูกจำกัดด้วยรัศมีแล้ว จึงดึงทั้งหมดมาเรียง/ตัดหน้าใน Go
+		if err := ctrl.MysqlDB.Raw(sql, args...).Scan(&attrs).Error; err != nil {
+			return nil, err
+		}
+		sort.SliceStable(attrs, func(i, j int) bool {
+			di, dj := geo[attrs[i].Code].Meters, geo[attrs[j].Code].Meters
+			if di != dj {
+				return di < dj
+			}
+			return attrs[i].Code < attrs[j].Code
+		})
+		resp.Total = len(attrs)
+		lo := min((q.Page-1)*q.PerPage, len(attrs))
+		attrs = attrs[lo:min(lo+q.PerPage, len(attrs))]
+	} else {
+		if err := ctrl.MysqlDB.Raw(`SELECT COUNT(*) FROM (`+sql+`) s`, args...).Scan(&resp.Total).Error; err != nil {
+			return nil, err
+		}
+		if err := ctrl.MysqlDB.Raw(`SELECT * FROM (`+sql+`) s ORDER BY name, code LIMIT ? OFFSET ?`,
+			append(args, q.PerPage, (q.Page-1)*q.PerPage)...).Scan(&attrs).Error; err != nil {
+			return nil, err
+		}
+	}
+	if len(attrs) == 0 {
+		return resp, nil
+	}
+
+	// 3) พิกัดของหน้านี้ (ถ้ายังไม่ได้จากขั้นที่ 1)
+	if geo == nil {
+		codes := make([]string, len(attrs))
+		for i, a := range attrs {
+			codes[i] = a.Code
+		}
+		var rows []placeGeoRow
+		if err := ctrl.PostgisDB.Raw(`SELECT code, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM (`+
+			kindNodesSQL(q.Kinds, nil)+`) n WHERE code IN ?`, codes).Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		geo = make(map[string]placeGeoRow, len(rows))
+		for _, r := range rows {
+			geo[r.Code] = r
+		}
+	}
+
+	for _, a := range attrs {
+		g := geo[a.Code]
+		hit := PlaceHit{
+			Code: a.Code, Kind: a.Kind, ID: a.ID, Name: a.Name, Category: a.Category,
+			Province: a.Province, District: a.District, PriceMin: a.PriceMin, PriceMax: a.PriceMax,
+			Review: a.Review, ThumbnailURL: a.ThumbnailURL, Types: []string{}, Lat: g.Lat, Lon: g.Lon,
+		}
+		if a.Types != "" {
+			hit.Types = strings.Split(a.Types, "|")
+		}
+		if q.Center != nil {
+			d := math.Round(g.Meters)
+			hit.DistanceM = &d
+		}
+		resp.Items = append(resp.Items, hit)
+	}
+	return resp, nil
+}
+
+// spatialCandidates รหัสที่อยู่ใน bbox และ/หรือรัศมีรอบ center พร้อมพิกัดและระยะจาก center
+func (ctrl *DistanceController) spatialCandidates(q PlaceSearchQuery) ([]placeGeoRow, error) {
+	where := []string{}
+	args := []any{}
+	meters := "0::float8"
+	if q.BBox != nil {
+		where = append(where, `n.geom && ST_MakeEnvelope(?, ?, ?, ?, 4326)`)
+		args = append(args, q.BBox[0], q.BBox[1], q.BBox[2], q.BBox[3])
+	}
+	if q.Center != nil {
+		lon, lat := q.Center[0], q.Center[1]
+		dLat := q.RadiusM / 111320
+		dLon := q.RadiusM / (111320 * math.Max(0.1, math.Cos(lat*math.Pi/180)))
+		// กรอบสี่เหลี่ยมก่อน (ใช้ GIST) แล้วค่อยวัดระยะจริงบนทรงกลม
+		where = append(where, `n.geom && ST_MakeEnvelope(?, ?, ?, ?, 4326)`,
+			`ST_DWithin(n.geom::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography, ?)`)
+		args = append(args, lon-dLon, lat-dLat, lon+dLon, lat+dLat, lon, lat, q.RadiusM)
+		meters = `ST_Distance(n.geom::geography, ST_SetSRID(ST_MakePoint(?, ?), 4326)::geography)`
+		args = append([]any{lon, lat}, args...)
+	}
+	var rows []placeGeoRow
+	err := ctrl.PostgisDB.Raw(`SELECT n.code, ST_X(n.geom) AS lon, ST_Y(n.geom) AS lat, `+meters+` AS meters
+FROM (`+kindNodesSQL(q.Kinds, nil)+`) n
+WHERE `+strings.Join(where, " AND "), args...).Scan(&rows).Error
+	return rows, err
+}
+
+// placeAttrSQL SELECT ... UNION ALL ของทุกชนิดใน q.Kinds พร้อมเงื่อนไขคุณสมบัติ
+// geo != nil → จำกัดเฉพาะรหัสที่ผ่านการกรองเชิงพื้นที่; ไม่มีชนิดไหนเหลือ → ""
+func placeAttrSQL(q PlaceSearchQuery, geo map[string]placeGeoRow) (string, []any) {
+	ids := map[byte][]int{}
+	for code := range geo {
+		if n, err := strconv.Atoi(code[1:]); err == nil {
+			ids[code[0]] = append(ids[code[0]], n)
+		}
+	}
+
+	var parts []string
+	var args []any
+	for _, k := range placeKinds {
+		if !strings.Contains(q.Kinds, string(k.prefix)) || (geo != nil && len(ids[k.prefix]) == 0) {
+			continue
+		}
+		typesOf := ` FROM ` + k.pivot + ` pv JOIN travel_types t ON t.id = pv.type_id WHERE pv.` + k.pivotIDCol + ` = x.id`
+		sql := `SELECT '` + string(k.prefix) + `' || x.id AS code, '` + k.name + `' AS kind, x.id,
+  x.name, x.category, x.province, x.district, x.price_min, x.price_max, x.review, x.thumbnail_url,
+  (SELECT GROUP_CONCAT(t.name, '|')` + typesOf + `) AS types
+FROM ` + k.table + ` x
+WHERE x.deleted_at IS NULL`
+		if geo != nil {
+			sql += ` AND x.id IN ?`
+			args = append(args, ids[k.prefix])
+		}
+		if len(q.Types) > 0 {
+			sql += ` AND EXISTS (SELECT 1` + typesOf + ` AND (t.name IN ? OR t.code IN ?))`
+			args = append(args, q.Types, q.Types)
+		}
+		if q.PriceMin != nil {
+			sql += ` AND x.price_max >= ?`
+			args = append(args, *q.PriceMin)
+		}
+		if q.PriceMax != nil {
+			sql += ` AND x.price_min <= ?`
+			args = append(args, *q.PriceMax)
+		}
+		if q.Province != "" {
+			sql += ` AND TRIM(x.province) = ?`
+			args = append(args, q.Province)
+		}
+		if q.District != "" {
+			sql += ` AND TRIM(x.district) = ?`
+			args = append(args, q.District)
+		}
+		parts = append(parts, sql)
+	}
+	return strings.Join(parts, "\nUNION ALL\n"), args
+}
+
+// parseFloats "a,b,c" → n ค่า (จำนวนไม่ตรง/ไม่ใช่ตัวเลข → ok=false)
+func parseFloats(s string, n int) ([]float64, bool) {
+	xs := strings.Split(s, ",")
+	if len(xs) != n {
+		return nil, false
+	}
+	out := make([]float64, n)
+	for i, x := range xs {
+		v, err := strconv.ParseFloat(strings.TrimSpace(x), 64)
+		if err != nil {
+			return nil, false
+		}
+		out[i] = v
+	}
+	return out, true
+}
@@ -0,0 +1,221 @@
+package Distance
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gtwndtl/trip-spark-builder/roads"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// ------------------------------------------------------------
+// /reachable: สถานที่ที่ไปถึงได้ภายในเวลาที่กำหนดจากจุดหนึ่ง (isochrone แบบประมาณ)
+//   - มีโครงข่ายถนน (cmd/roadimport) และ pgRouting → ระยะตามถนน (pgr_drivingDistance)
+//   - ไม่มี → วงกลมรัศมีเส้นตรงจากโมเดลเวลาเดินทาง (timeline.ReachKm) + ST_DWithin
+//   ทั้งสองแบบคิดเวลาของแต่ละจุดด้วยโมเดลเดียวกับ /distances?mode=... แล้วตัดตัวที่เกินเวลาออก
+// ------------------------------------------------------------
+
+// maxReachMinutes เวลาสูงสุดที่รับ (ไกลกว่านี้ไม่ใช่ "รอบ ๆ ที่พัก" แล้ว)
+const maxReachMinutes = 240
+
+// ErrPlaceNotFound รหัสต้นทางไม่มีในตาราง GIS
+var ErrPlaceNotFound = errors.New("ไม่พบสถานที่ต้นทาง")
+
+type ReachablePlace struct {
+	Code      string  `json:"code"`
+	Name      string  `json:"name,omitempty"`
+	Lat       float64 `json:"lat"`
+	Lon       float64 `json:"lon"`
+	DistanceM float64 `json:"distance_m"` // ระยะเส้นตรง หรือระยะถนนเมื่อ source = "road"
+
+	*timeline.Estimate
+}
+
+type ReachableResp struct {
+	From    string           `json:"from"`
+	Mode    string           `json:"mode"`
+	Minutes int              `json:"minutes"`
+	Source  string           `json:"source"`   // road | sphere
+	RadiusM float64          `json:"radius_m"` // ระยะไกลสุดตามโมเดล (เส้นตรง หรือระยะถนน)
+	Count   int              `json:"count"`
+	Places  []ReachablePlace `json:"places"` // เรียงตามเวลา
+	Area    GeoJSONGeometry  `json:"area"`   // Polygon/MultiPolygon ของพื้นที่ที่ไปถึง
+}
+
+type reachRow struct {
+	Code   string  `gorm:"column:code"`
+	Lon    float64 `gorm:"column:lon"`
+	Lat    float64 `gorm:"column:lat"`
+	Meters float64 `gorm:"column:meters"`
+}
+
+// GET /reachable?from=A171&minutes=30&mode=walk
+// &kinds=P,R (ค่าเริ่มต้น แลนด์มาร์ก + ร้าน) &format=geojson (พื้นที่ + จุดเป็น FeatureCollection)
+func (ctrl *DistanceController) GetReachable(c *gin.Context) {
+	codes := parseNodeCodes(c.Query("from"))
+	if len(codes) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from ต้องเป็นรหัส P/R/A หนึ่งแห่ง เช่น A171"})
+		return
+	}
+	minutes, err := strconv.Atoi(c.DefaultQuery("minutes", "30"))
+	if err != nil || minutes <= 0 || minutes > maxReachMinutes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minutes ต้องอยู่ระหว่าง 1-" + strconv.Itoa(maxReachMinutes)})
+		return
+	}
+	mode := strings.ToLower(strings.TrimSpace(c.DefaultQuery("mode", timeline.Walk)))
+	if mode != timeline.Walk && mode != timeline.Car && mode != timeline.Transit {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "mode ต้องเป็น walk, car หรือ transit"})
+		return
+	}
+
+	resp, err := ctrl.Reachable(codes[0], minutes, mode, parseKinds(c.DefaultQuery("kinds", "P,R")))
+	if err != nil {
+		if errors.Is(err, ErrPlaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "หาพื้นที่ที่ไปถึงไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "geojson" {
+		fc := newCollection()
+		fc.Features = append(fc.Features, GeoJSONFeature{Type: "Feature", Geometry: resp.Area, Properties: map[string]any{
+			"from": resp.From, "mode": resp.Mode, "minutes": resp.Minutes, "source": resp.Source,
+		}})
+		for _, p := range resp.Places {
+			fc.Features = append(fc.Features, pointFeature([2]float64{p.Lon, p.Lat}, map[string]any{
+				"code": p.Code, "name": p.Name, "distance_m": p.DistanceM, "duration_min": p.Minutes,
+			}))
+		}
+		c.JSON(http.StatusOK, fc)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// Reachable สถานที่ประเภท kinds ที่ไปถึงจาก from ได้ภายใน minutes ด้วย mode
+func (ctrl *DistanceController) Reachable(from string, minutes int, mode, kinds string) (*ReachableResp, error) {
+	cfg := timeline.FromEnv()
+	resp := &ReachableResp{From: from, Mode: mode, Minutes: minutes, Places: []ReachablePlace{}}
+
+	var origin []reachRow
+	if err := ctrl.PostgisDB.Raw(`SELECT code, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM (`+
+		kindNodesSQL("PRA", nil)+`) n WHERE code = ?`, from).Scan(&origin).Error; err != nil {
+		return nil, err
+	}
+	if len(origin) == 0 {
+		return nil, ErrPlaceNotFound
+	}
+
+	var rows []reachRow
+	var estimate func(km float64) timeline.Estimate
+	road := false
+	if ctrl.graphBackend() == BackendPgRouting {
+		roadM := cfg.ReachRoadKm(mode, minutes) * 1000
+		r, ok, err := roads.Reachable(ctrl.PostgisDB, from, roadM, mode != timeline.Walk)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			road = true
+			resp.Source, resp.RadiusM = "road", math.Round(roadM)
+			if r.Area != "" {
+				_ = json.Unmarshal([]byte(r.Area), &resp.Area)
+			}
+			codes := make([]string, 0, len(r.Meters))
+			for code := range r.Meters {
+				if strings.Contains(kinds, code[:1]) {
+					codes = append(codes, code)
+				}
+			}
+			if len(codes) > 0 {
+				if err := ctrl.PostgisDB.Raw(`SELECT code, ST_X(geom) AS lon, ST_Y(geom) AS lat FROM (`+
+					kindNodesSQL(kinds, nil)+`) n WHERE code IN ?`, codes).Scan(&rows).Error; err != nil {
+					return nil, err
+				}
+			}
+			for i := range rows {
+				rows[i].Meters = r.Meters[rows[i].Code]
+			}
+			estimate = func(km float64) timeline.Estimate { return cfg.EstimateRoad(mode, km) }
+		}
+	}
+
+	if !road {
+		radiusM := cfg.ReachKm(mode, minutes) * 1000
+		resp.Source, resp.RadiusM = "sphere", math.Round(radiusM)
+		if err := ctrl.PostgisDB.Raw(`
+WITH src AS (
+  SELECT geom::geography AS g FROM (`+kindNodesSQL("PRA", nil)+`) n WHERE code = ?
+)
+SELECT n.code, ST_X(n.geom) AS lon, ST_Y(n.geom) AS lat, ST_Distance(n.geom::geography, src.g) AS meters
+FROM (`+kindNodesSQL(kinds, nil)+`) n, src
+WHERE n.code <> ? AND ST_DWithin(n.geom::geography, src.g, ?)`, from, from, radiusM).
+			Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		var area string
+		if err := ctrl.PostgisDB.Raw(`SELECT ST_AsGeoJSON(ST_Buffer(geom::geography, ?, 'quad_segs=16')::geometry, 6)
+FROM (`+kindNodesSQL("PRA", nil)+`) n WHERE code = ?`, math.Max(radiusM, 1), from).Scan(&area).Error; err != nil {
+			return nil, err
+		}
+		_ = json.Unmarshal([]byte(area), &resp.Area)
+		estimate = func(km float64) timeline.Estimate { return cfg.Estimate(mode, km) }
+	}
+
+	names := ctrl.placeNames(rows)
+	for _, r := range rows {
+		est := estimate(r.Meters / 1000)
+		if est.Minutes > minutes {
+			continue
+		}
+		resp.Places = append(resp.Places, ReachablePlace{
+			Code: r.Code, Name: names[r.Code], Lat: r.Lat, Lon: r.Lon,
+			DistanceM: math.Round(r.Meters), Estimate: &est,
+		})
+	}
+	sort.SliceStable(resp.Places, func(i, j int) bool {
+		if resp.Places[i].Minutes != resp.Places[j].Minutes {
+			return resp.Places[i].Minutes < resp.Places[j].Minutes
+		}
+		return resp.Places[i].DistanceM < resp.Places[j].DistanceM
+	})
+	resp.Count = len(resp.Places)
+	return resp, nil
+}
+
+// placeNames ชื่อสถานที่จากฐานข้อมูลหลัก (อ่านไม่ได้ → ไม่มีชื่อ เหมือน SuggestPlaces)
+func (ctrl *DistanceController) placeNames(rows []reachRow) map[string]string {
+	ids := map[byte][]int{}
+	for _, r := range rows {
+		if n, err := strconv.Atoi(r.Code[1:]); err == nil {
+			ids[r.Code[0]] = append(ids[r.Code[0]], n)
+		}
+	}
+	out := map[string]string{}
+	for prefix, table := range map[byte]string{'P': "landmarks", 'R': "restaurants", 'A': "accommodations"} {
+		if len(ids[prefix]) == 0 {
+			continue
+		}
+		var info []struct {
+			ID   int
+			Name string
+		}
+		if err := ctrl.MysqlDB.Table(table).Select("id, name").Where("id IN ?", ids[prefix]).
+			Scan(&info).Error; err != nil {
+			continue
+		}
+		for _, v := range info {
+			out[string(prefix)+strconv.Itoa(v.ID)] = v.Name
+		}
+	}
+	return out
+}
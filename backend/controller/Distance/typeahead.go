@@ -0,0 +1,52 @@
+package Distance
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gtwndtl/trip-spark-builder/search"
+)
+
+// ------------------------------------------------------------
+// /places/typeahead: ค้นชื่อ/ที่อยู่/ประเภทแบบพิมพ์ไปเจอไป (ผลปน P/R/A เรียงตามความเกี่ยวข้อง)
+// ดัชนีอยู่ในหน่วยความจำ (package search) สร้างครั้งแรกที่เรียก
+// ------------------------------------------------------------
+
+const (
+	maxTypeaheadLimit = 50
+	maxTypeaheadRunes = 100
+)
+
+type TypeaheadResp struct {
+	Query string       `json:"q"`
+	Count int          `json:"count"`
+	Items []search.Hit `json:"items"`
+}
+
+// GET /places/typeahead?q=วัด&kinds=P,R,A&limit=10
+func (ctrl *DistanceController) Typeahead(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if q == "" || utf8.RuneCountInString(q) > maxTypeaheadRunes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "q ต้องมี 1-" + strconv.Itoa(maxTypeaheadRunes) + " ตัวอักษร"})
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit <= 0 {
+		limit = 10
+	}
+	if limit > maxTypeaheadLimit {
+		limit = maxTypeaheadLimit
+	}
+
+	idx, err := search.Shared(ctrl.MysqlDB)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างดัชนีค้นหาไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+	hits := idx.Search(q, parseKinds(c.DefaultQuery("kinds", "P,R,A")), limit)
+	c.JSON(http.StatusOK, TypeaheadResp{Query: q, Count: len(hits), Items: hits})
+}
@@ -1,189 +1,217 @@
 package GenTrip
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os/exec"
+	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/controller/Distance"
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/planner"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
 )
 
 type RouteController struct {
-	DB *gorm.DB
+	DB        *gorm.DB
+	PostgisDB *gorm.DB
+	Distance  *Distance.DistanceController
+
+	jobs *jobHub
 }
 
-type PythonResult struct {
-	Start           string         `json:"start"`
-	StartName       string         `json:"start_name"`
-	TripPlanByDay   []DayPlan      `json:"trip_plan_by_day"`
-	Paths           []PathInfo     `json:"paths"`
-	TotalDistanceKm float64        `json:"total_distance_km"`
-	Accommodation   *Accommodation `json:"accommodation,omitempty"`
-	Message         string         `json:"message"`
-	Error           string         `json:"error,omitempty"`
+func NewRouteController(db, postgisDB *gorm.DB, distanceCtrl *Distance.DistanceController) *RouteController {
+	rc := &RouteController{
+		DB:        db,
+		PostgisDB: postgisDB,
+		Distance:  distanceCtrl,
+		jobs:      newJobHub(),
+	}
+	rc.recoverJobs()
+	return rc
+}
 
-	TotalBudget  int `json:"total_budget"`
-	BudgetPerDay int `json:"budget_per_day"`
+// ชื่อ type เดิมตอนยังเรียก Code.py (frontend อิง shape นี้อยู่)
+type (
+	PythonResult  = planner.Result
+	Spend         = planner.Spend
+	DaySpend      = planner.DaySpend
+	Breakdown     = planner.Breakdown
+	DayPlan       = planner.DayPlan
+	PlaceInfo     = planner.PlaceInfo
+	PathInfo      = planner.PathInfo
+	Accommodation = planner.Accommodation
+	HoursNotice   = planner.HoursNotice
+)
 
-	Spend Spend `json:"spend"`
-}
+func (rc *RouteController) GenerateRoute(c *gin.Context) {
+	opt, err := parseOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-type Spend struct {
-	PerDay    []DaySpend `json:"per_day"`
-	Total     int        `json:"total"`
-	Breakdown Breakdown  `json:"breakdown"`
-}
+	result, err := planner.Plan(c.Request.Context(), newDBSource(c.Request.Context(), rc), opt)
+	if err != nil {
+		if errors.Is(err, planner.ErrBadStart) || errors.Is(err, planner.ErrBadPlaces) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างเส้นทางไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
 
-type DaySpend struct {
-	Day         int `json:"day"`
-	Hotel       int `json:"hotel"`
-	Meals       int `json:"meals"`
-	Attractions int `json:"attractions"`
-	Total       int `json:"total"`
+	c.JSON(http.StatusOK, result)
 }
 
-type Breakdown struct {
-	Hotel       int `json:"hotel"`
-	Meals       int `json:"meals"`
-	Attractions int `json:"attractions"`
-}
+// parseOptions อ่าน query ของ /gen-route (ใช้ร่วมกับ POST /gen-route/jobs)
+func parseOptions(c *gin.Context) (planner.Options, error) {
+	opt := planner.DefaultOptions()
 
-type DayPlan struct {
-	Day    int         `json:"day"`
-	Plan   []PlaceInfo `json:"plan"`
-	Budget struct {
-		PerDay      int `json:"per_day"`
-		Hotel       int `json:"hotel"`
-		MealEach    int `json:"meal_each"`
-		Attractions int `json:"attractions"`
-	} `json:"budget"`
-}
+	startNode := c.Query("start")
+	if startNode == "" {
+		return opt, errors.New("กรุณาระบุ start ผ่าน query parameters")
+	}
 
-type PlaceInfo struct {
-	ID   string  `json:"id"`
-	Name string  `json:"name"`
-	Lat  float64 `json:"lat"`
-	Lon  float64 `json:"lon"`
-}
+	daysStr := c.DefaultQuery("days", "1")
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 {
+		return opt, errors.New("days ต้องเป็นจำนวนเต็มบวก")
+	}
 
-type PathInfo struct {
-	From       string  `json:"from"`
-	FromName   string  `json:"from_name"`
-	FromLat    float64 `json:"from_lat"`
-	FromLon    float64 `json:"from_lon"`
-	To         string  `json:"to"`
-	ToName     string  `json:"to_name"`
-	ToLat      float64 `json:"to_lat"`
-	ToLon      float64 `json:"to_lon"`
-	DistanceKm float64 `json:"distance_km"`
-	Day        int     `json:"day,omitempty"`
-}
+	budgetStr := c.DefaultQuery("budget", "0")
+	budget, err := strconv.Atoi(budgetStr)
+	if err != nil {
+		return opt, errors.New("budget ต้องเป็นจำนวนเต็มไม่ติดลบ")
+	}
 
-type Accommodation struct {
-	ID   string  `json:"id"`
-	Name string  `json:"name"`
-	Lat  float64 `json:"lat"`
-	Lon  float64 `json:"lon"`
-}
+	opt.Start = startNode
+	opt.Days = days
+	opt.TotalBudget = budget
+	opt.Timeline = timeline.FromEnv()
+
+	// วันเริ่มทริป (ใช้เช็กเวลาเปิด-ปิดตามวันในสัปดาห์) ไม่ระบุ = วันนี้
+	if s := c.Query("start_date"); s != "" {
+		d, err := time.ParseInLocation("2006-01-02", s, domain.Bangkok)
+		if err != nil {
+			return opt, errors.New("start_date ต้องอยู่ในรูปแบบ YYYY-MM-DD")
+		}
+		opt.StartDate = d
+	}
 
-func (rc *RouteController) GenerateRoute(c *gin.Context) {
-    startNode := c.Query("start")
-    if startNode == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "กรุณาระบุ start ผ่าน query parameters"})
-        return
-    }
-
-    daysStr := c.DefaultQuery("days", "1")
-    days, err := strconv.Atoi(daysStr)
-    if err != nil || days < 1 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "days ต้องเป็นจำนวนเต็มบวก"})
-        return
-    }
-
-    budgetStr := c.DefaultQuery("budget", "0")
-    if _, err := strconv.Atoi(budgetStr); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "budget ต้องเป็นจำนวนเต็มไม่ติดลบ"})
-        return
-    }
-
-    // ตัวเลือกขั้นสูง
-    distance := c.DefaultQuery("distance", "4000")
-    k := c.DefaultQuery("k", "20")
-    kMst := c.DefaultQuery("k_mst", "20")
-    mode := c.DefaultQuery("mode", "penalize")
-    penalty := c.DefaultQuery("penalty", "1.3")
-    useBoykov := c.DefaultQuery("use_boykov", "1")
-
-    // ใหม่: preferences (รองรับไทย) + weights (optional)
-    prefer := c.DefaultQuery("prefer", "")
-    prefer2 := c.DefaultQuery("prefer2", "")
-    prefer3 := c.DefaultQuery("prefer3", "")
-    w1 := c.DefaultQuery("w1", "")
-    w2 := c.DefaultQuery("w2", "")
-    w3 := c.DefaultQuery("w3", "")
-
-    // ✅ ใหม่: n_top สำหรับ auto-zone Top-N ที่ฝั่ง /mst/byflow
-    nTop := c.DefaultQuery("n_top", "40")
-
-    // ส่งอาร์กิวเมนต์ไป Python (เพิ่ม n_top เป็น argv[16])
-    args := []string{
-        "Code.py",
-        startNode,
-        daysStr,
-        distance,
-        k,
-        kMst,
-        mode,
-        penalty,
-        useBoykov,
-        budgetStr, // argv[9]
-        prefer,    // argv[10]
-        prefer2,   // argv[11]
-        prefer3,   // argv[12]
-        w1,        // argv[13]
-        w2,        // argv[14]
-        w3,        // argv[15]
-        nTop,      // argv[16]  <<== เพิ่มตัวนี้
-    }
-
-    cmd := exec.Command("python", args...)
-
-	var outBuf, errBuf bytes.Buffer
-	cmd.Stdout = &outBuf
-	cmd.Stderr = &errBuf
-
-	if err := cmd.Run(); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("เรียก Python ล้มเหลว: %s, stderr: %s", err.Error(), errBuf.String()),
-		})
-		return
+	// ผู้ร่วมทริป: budget เป็นงบรวมของทั้งกลุ่ม
+	opt.Group = domain.Group{
+		Adults:   queryInt(c, "adults", 1),
+		Children: queryInt(c, "children", 0),
+		RoomSize: queryInt(c, "room_size", 0),
+	}
+	if opt.Group.Adults < 0 || opt.Group.Children < 0 || opt.Group.RoomSize < 0 || opt.Group.Adults+opt.Group.Children < 1 {
+		return opt, errors.New("adults/children/room_size ต้องไม่ติดลบ และต้องมีอย่างน้อย 1 คน")
 	}
 
-	output := outBuf.Bytes()
-	if len(output) == 0 {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("ไม่ได้รับผลลัพธ์จาก Python เลย\nstderr: %s", errBuf.String()),
-		})
-		return
+	// ตัวเลือกขั้นสูง
+	opt.Distance = queryFloat(c, "distance", opt.Distance)
+	opt.K = queryInt(c, "k", opt.K)
+	opt.KMst = queryInt(c, "k_mst", opt.KMst)
+	opt.Mode = c.DefaultQuery("mode", opt.Mode)
+	opt.Penalty = queryFloat(c, "penalty", opt.Penalty)
+	opt.UseBoykov = queryInt(c, "use_boykov", 1) != 0
+
+	// preferences (รองรับไทย) + weights (optional)
+	opt.Prefer = c.DefaultQuery("prefer", "")
+	opt.Prefer2 = c.DefaultQuery("prefer2", "")
+	opt.Prefer3 = c.DefaultQuery("prefer3", "")
+	opt.W1 = queryFloat(c, "w1", opt.W1)
+	opt.W2 = queryFloat(c, "w2", opt.W2)
+	opt.W3 = queryFloat(c, "w3", opt.W3)
+
+	// mst_kinds=P,R,A ให้ MST รวมร้าน/ที่พัก + น้ำหนักเส้นตามประเภทและประเภทร้าน/ที่พักที่ชอบ
+	opt.MSTKinds = c.DefaultQuery("mst_kinds", opt.MSTKinds)
+	opt.WR = queryFloat(c, "w_r", opt.WR)
+	opt.WA = queryFloat(c, "w_a", opt.WA)
+	opt.PreferR = c.Query("prefer_r")
+	opt.PreferA = c.Query("prefer_a")
+	opt.WPreferR = queryFloat(c, "wp_r", opt.WPreferR)
+	opt.WPreferA = queryFloat(c, "wp_a", opt.WPreferA)
+
+	// n_top สำหรับ auto-zone Top-N ของ MST
+	opt.NTop = queryInt(c, "n_top", opt.NTop)
+
+	// travel=walk|car|transit แบบการเดินทางที่ใช้คิดเวลา, walk_only=1 บังคับทุกช่วงต้องเดินได้
+	opt.TravelMode = c.DefaultQuery("travel", opt.TravelMode)
+	opt.WalkOnly = queryInt(c, "walk_only", 0) != 0
+	if opt.WalkOnly {
+		opt.TravelMode = timeline.Walk
 	}
 
-	var pyResult PythonResult
-	if err := json.Unmarshal(output, &pyResult); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error": fmt.Sprintf("แปลงผลลัพธ์จาก Python ไม่ได้: %s\nstdout: %s\nstderr: %s",
-				err.Error(), string(output), errBuf.String()),
-		})
-		return
+	// optimize=1 จัดลำดับจุดในแต่ละวันใหม่ (2-opt / Or-opt) ค่าเริ่มต้นคงลำดับตาม MST แบบเดิม
+	opt.Optimize = queryInt(c, "optimize", 0) != 0
+
+	// cluster=1 แบ่งวันตามกลุ่มพื้นที่ (ทริปหลายวัน) ค่าเริ่มต้นแบ่งวันตามลำดับ MST แบบเดิม
+	opt.Cluster = queryInt(c, "cluster", 0) != 0
+
+	// alternatives=N ขอแผนทางเลือกเพิ่ม (แผนหลักอยู่ชั้นบนสุด ที่เหลืออยู่ใน "alternatives")
+	opt.Alternatives = queryInt(c, "alternatives", 1)
+	if opt.Alternatives < 1 || opt.Alternatives > planner.MaxAlternatives {
+		return opt, fmt.Errorf("alternatives ต้องอยู่ระหว่าง 1-%d", planner.MaxAlternatives)
 	}
 
-	if pyResult.Error != "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": pyResult.Error})
-		return
+	// must=P12,R3  avoid=P40,A7 (รหัสสถานที่คั่นด้วย comma)
+	if opt.Must, err = parseCodes(c.Query("must")); err != nil {
+		return opt, fmt.Errorf("must: %w", err)
+	}
+	if opt.Avoid, err = parseCodes(c.Query("avoid")); err != nil {
+		return opt, fmt.Errorf("avoid: %w", err)
 	}
+	for _, m := range opt.Must {
+		for _, a := range opt.Avoid {
+			if m == a {
+				return opt, fmt.Errorf("%s อยู่ทั้งใน must และ avoid", m)
+			}
+		}
+	}
+
+	return opt, nil
+}
+
+var placeCodeRe = regexp.MustCompile(`^[PRA][0-9]+$`)
+
+// parseCodes แยก CSV ของรหัสสถานที่ (P/R/A ตามด้วยเลข id)
+func parseCodes(s string) ([]string, error) {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		code := strings.ToUpper(strings.TrimSpace(part))
+		if code == "" {
+			continue
+		}
+		if !placeCodeRe.MatchString(code) {
+			return nil, fmt.Errorf("รหัส %q ต้องอยู่ในรูป P12 / R3 / A7", part)
+		}
+		out = append(out, code)
+	}
+	return out, nil
+}
 
-	c.JSON(http.StatusOK, pyResult)
+// queryInt อ่าน query เป็น int ถ้าว่างหรือแปลงไม่ได้ใช้ค่า default
+func queryInt(c *gin.Context, key string, def int) int {
+	v, err := strconv.Atoi(c.Query(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// queryFloat อ่าน query เป็น float64 ถ้าว่างหรือแปลงไม่ได้ใช้ค่า default
+func queryFloat(c *gin.Context, key string, def float64) float64 {
+	v, err := strconv.ParseFloat(c.Query(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
 }
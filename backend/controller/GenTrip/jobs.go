@@ -0,0 +1,357 @@
+package GenTrip
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/planner"
+)
+
+// ------------------------------------------------------------
+// งานสร้างทริปแบบ async
+//   POST   /gen-route/jobs             (query เดียวกับ /gen-route) → job_id + cancel_token
+//   GET    /gen-route/jobs/:id         สถานะ + ผลลัพธ์ (เมื่อ done)
+//   GET    /gen-route/jobs/:id/events  Server-Sent Events ของแต่ละขั้น
+//   DELETE /gen-route/jobs/:id         ยกเลิก job ที่ยังไม่จบ (header X-Job-Token = cancel_token)
+// ------------------------------------------------------------
+
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// maxRunningJobs จำนวน job ที่รันพร้อมกันได้ (min-cut + primDD หนัก DB) ที่เหลือรอคิว
+const maxRunningJobs = 2
+
+// JobEvent หนึ่งเหตุการณ์ที่ส่งทาง SSE
+// แผนทางเลือก (alternatives > 1) ทำ min-cut/MST ซ้ำต่อ variant → Variant/Variants บอกว่า phase เป็นของรอบไหน
+type JobEvent struct {
+	Status      string    `json:"status"`
+	Phase       string    `json:"phase,omitempty"`
+	Variant     int       `json:"variant,omitempty"`
+	Variants    int       `json:"variants,omitempty"`
+	VariantName string    `json:"variant_name,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+func isTerminal(status string) bool {
+	return status == JobDone || status == JobFailed || status == JobCancelled
+}
+
+// genJob สถานะในหน่วยความจำของ job ที่ยังไม่จบ (จบแล้วอ่านจากตาราง gen_jobs)
+type genJob struct {
+	id     string
+	cancel context.CancelFunc
+
+	mu     sync.Mutex
+	events []JobEvent
+	subs   map[chan JobEvent]struct{}
+}
+
+// publish บันทึก event แล้วกระจายให้ผู้ฟังทุกคน (event สุดท้ายจะปิด channel)
+// event ระหว่างทางเว้นที่ว่างใน buffer ไว้หนึ่งช่องเสมอ event สุดท้ายจึงส่งได้โดยไม่ต้องรอและไม่หาย
+// (ส่งได้จาก publish ที่ถือ mu เท่านั้น ผู้ฟังมีแต่ดึงออก ช่องว่างที่เช็กไว้จึงไม่หาย)
+func (j *genJob) publish(ev JobEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events = append(j.events, ev)
+	for ch := range j.subs {
+		if isTerminal(ev.Status) {
+			ch <- ev
+			close(ch)
+			delete(j.subs, ch)
+			continue
+		}
+		// ผู้ฟังช้าเกิน (เหลือแค่ช่องสำรอง) ข้ามไป ยังดูย้อนหลังได้จาก GET
+		if len(ch) < cap(ch)-1 {
+			ch <- ev
+		}
+	}
+}
+
+// subscribe คืน event ที่เกิดไปแล้ว + channel ของ event ถัดไป
+func (j *genJob) subscribe() ([]JobEvent, chan JobEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	past := append([]JobEvent(nil), j.events...)
+	ch := make(chan JobEvent, 16)
+	if n := len(past); n > 0 && isTerminal(past[n-1].Status) {
+		close(ch)
+		return past, ch
+	}
+	j.subs[ch] = struct{}{}
+	return past, ch
+}
+
+func (j *genJob) unsubscribe(ch chan JobEvent) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, ok := j.subs[ch]; ok {
+		delete(j.subs, ch)
+		close(ch)
+	}
+}
+
+// jobHub job ที่ยังรันอยู่ + semaphore จำกัดจำนวนที่รันพร้อมกัน
+type jobHub struct {
+	mu   sync.Mutex
+	jobs map[string]*genJob
+	sem  chan struct{}
+}
+
+func newJobHub() *jobHub {
+	return &jobHub{jobs: map[string]*genJob{}, sem: make(chan struct{}, maxRunningJobs)}
+}
+
+func (h *jobHub) get(id string) *genJob {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.jobs[id]
+}
+
+func (h *jobHub) add(j *genJob) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.jobs[j.id] = j
+}
+
+func (h *jobHub) remove(id string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.jobs, id)
+}
+
+// recoverJobs job ที่ค้าง queued/running จากรอบก่อน (server ดับ) → failed
+func (rc *RouteController) recoverJobs() {
+	now := time.Now()
+	rc.DB.Model(&entity.GenJob{}).
+		Where("status IN ?", []string{JobQueued, JobRunning}).
+		Updates(map[string]interface{}{"status": JobFailed, "error": "server restarted", "finished_at": &now})
+}
+
+func newJobID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// newCancelToken token ของผู้สร้าง job (สุ่มไม่ได้ → error แทนการใช้ค่าที่เดาได้)
+func newCancelToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// ------------------------------------------------------------
+// handlers
+// ------------------------------------------------------------
+
+// POST /gen-route/jobs
+func (rc *RouteController) CreateJob(c *gin.Context) {
+	opt, err := parseOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	token, err := newCancelToken()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้าง job ได้"})
+		return
+	}
+	row := entity.GenJob{JobID: newJobID(), Params: c.Request.URL.RawQuery, Status: JobQueued, CancelToken: token}
+	if err := rc.DB.Create(&row).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้าง job ได้"})
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &genJob{id: row.JobID, cancel: cancel, subs: map[chan JobEvent]struct{}{}}
+	job.publish(JobEvent{Status: JobQueued, At: time.Now()})
+	rc.jobs.add(job)
+	go rc.runJob(ctx, job, opt)
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"job_id":       row.JobID,
+		"status":       JobQueued,
+		"status_url":   "/gen-route/jobs/" + row.JobID,
+		"events_url":   "/gen-route/jobs/" + row.JobID + "/events",
+		"cancel_token": token, // ใช้กับ DELETE (ไม่มีใน GET)
+	})
+}
+
+func (rc *RouteController) runJob(ctx context.Context, job *genJob, opt planner.Options) {
+	defer rc.jobs.remove(job.id)
+	defer job.cancel()
+
+	update := func(fields map[string]interface{}) {
+		if err := rc.DB.Model(&entity.GenJob{}).Where("job_id = ?", job.id).Updates(fields).Error; err != nil {
+			fmt.Printf("อัปเดต job %s ไม่สำเร็จ: %v\n", job.id, err)
+		}
+	}
+	finish := func(status, errMsg, result string) {
+		now := time.Now()
+		fields := map[string]interface{}{"status": status, "error": errMsg, "result": result, "finished_at": &now}
+		ev := JobEvent{Status: status, Error: errMsg, At: now}
+		if status == JobDone {
+			fields["phase"], ev.Phase = planner.PhaseDone, planner.PhaseDone
+		}
+		update(fields)
+		job.publish(ev)
+	}
+
+	// รอคิว
+	select {
+	case rc.jobs.sem <- struct{}{}:
+		defer func() { <-rc.jobs.sem }()
+	case <-ctx.Done():
+		finish(JobCancelled, "", "")
+		return
+	}
+
+	update(map[string]interface{}{"status": JobRunning})
+	progress := func(ev JobEvent) {
+		if ev.Phase == planner.PhaseDone {
+			return // event done ส่งตอน finish พร้อมสถานะสุดท้าย
+		}
+		update(map[string]interface{}{"phase": ev.Phase})
+		ev.Status, ev.At = JobRunning, time.Now()
+		job.publish(ev)
+	}
+	opt.Progress = func(phase string) { progress(JobEvent{Phase: phase}) }
+	opt.VariantProgress = func(phase string, index, total int, name string) {
+		progress(JobEvent{Phase: phase, Variant: index, Variants: total, VariantName: name})
+	}
+
+	res, err := planner.Plan(ctx, newDBSource(ctx, rc), opt)
+	switch {
+	case errors.Is(err, context.Canceled):
+		finish(JobCancelled, "", "")
+	case err != nil:
+		finish(JobFailed, err.Error(), "")
+	default:
+		b, _ := json.Marshal(res)
+		finish(JobDone, "", string(b))
+	}
+}
+
+// GET /gen-route/jobs/:id
+func (rc *RouteController) GetJob(c *gin.Context) {
+	var row entity.GenJob
+	if err := rc.DB.Where("job_id = ?", c.Param("id")).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบ job"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลได้"})
+		return
+	}
+
+	resp := gin.H{
+		"job_id":      row.JobID,
+		"status":      row.Status,
+		"phase":       row.Phase,
+		"params":      row.Params,
+		"created_at":  row.CreatedAt,
+		"finished_at": row.FinishedAt,
+	}
+	if row.Error != "" {
+		resp["error"] = row.Error
+	}
+	if row.Result != "" {
+		resp["result"] = json.RawMessage(row.Result)
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// GET /gen-route/jobs/:id/events
+func (rc *RouteController) JobEvents(c *gin.Context) {
+	id := c.Param("id")
+
+	var past []JobEvent
+	var ch chan JobEvent
+	if job := rc.jobs.get(id); job != nil {
+		past, ch = job.subscribe()
+		defer job.unsubscribe(ch)
+	} else {
+		// จบไปแล้ว (หรือจากรอบก่อน) → ส่งสถานะสุดท้ายครั้งเดียว
+		var row entity.GenJob
+		if err := rc.DB.Where("job_id = ?", id).First(&row).Error; err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบ job"})
+			return
+		}
+		at := row.UpdatedAt
+		if row.FinishedAt != nil {
+			at = *row.FinishedAt
+		}
+		past = []JobEvent{{Status: row.Status, Phase: row.Phase, Error: row.Error, At: at}}
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+
+	for _, ev := range past {
+		c.SSEvent("phase", ev)
+	}
+	c.Writer.Flush()
+	if ch == nil {
+		return
+	}
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case ev, ok := <-ch:
+			if !ok {
+				return false
+			}
+			c.SSEvent("phase", ev)
+			return !isTerminal(ev.Status)
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// DELETE /gen-route/jobs/:id  (X-Job-Token: <cancel_token จาก POST>)
+func (rc *RouteController) CancelJob(c *gin.Context) {
+	id := c.Param("id")
+	var row entity.GenJob
+	if err := rc.DB.Where("job_id = ?", id).First(&row).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบ job"})
+		return
+	}
+	token := c.GetHeader("X-Job-Token")
+	if row.CancelToken == "" || subtle.ConstantTimeCompare([]byte(token), []byte(row.CancelToken)) != 1 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "ไม่มีสิทธิ์ยกเลิก job นี้ (ต้องแนบ X-Job-Token ที่ได้ตอนสร้าง)"})
+		return
+	}
+
+	if job := rc.jobs.get(id); job != nil {
+		job.cancel()
+		c.JSON(http.StatusAccepted, gin.H{"job_id": id, "status": "cancelling"})
+		return
+	}
+	c.JSON(http.StatusConflict, gin.H{"error": "job จบไปแล้ว", "status": row.Status})
+}
@@ -0,0 +1,103 @@
+package GenTrip
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/planner"
+)
+
+// ผู้ฟังที่ไม่ได้อ่านเลยจน buffer เต็ม ต้องยังได้ event สุดท้ายแล้ว channel ปิด
+func TestPublishKeepsTerminalEvent(t *testing.T) {
+	for _, status := range []string{JobDone, JobFailed, JobCancelled} {
+		j := &genJob{subs: map[chan JobEvent]struct{}{}}
+		_, ch := j.subscribe()
+		for i := 0; i < 3*cap(ch); i++ {
+			j.publish(JobEvent{Status: JobRunning, Phase: planner.PhaseMST, At: time.Now()})
+		}
+		j.publish(JobEvent{Status: status, At: time.Now()})
+
+		var got []JobEvent
+		for ev := range ch {
+			got = append(got, ev)
+		}
+		if len(got) != cap(ch) {
+			t.Errorf("%s: ได้ %d event ต้องการ %d (เต็ม buffer)", status, len(got), cap(ch))
+		}
+		if last := got[len(got)-1]; last.Status != status {
+			t.Errorf("%s: event สุดท้าย = %+v", status, last)
+		}
+		if len(j.subs) != 0 || len(j.events) != 3*cap(ch)+1 {
+			t.Errorf("%s: subs=%d events=%d", status, len(j.subs), len(j.events))
+		}
+	}
+}
+
+// subscribe หลัง job จบแล้ว → ได้ประวัติทั้งหมด กับ channel ที่ปิดแล้ว
+func TestSubscribeAfterFinish(t *testing.T) {
+	j := &genJob{subs: map[chan JobEvent]struct{}{}}
+	j.publish(JobEvent{Status: JobQueued})
+	j.publish(JobEvent{Status: JobDone})
+	past, ch := j.subscribe()
+	if len(past) != 2 || past[1].Status != JobDone {
+		t.Errorf("past = %+v", past)
+	}
+	if _, ok := <-ch; ok {
+		t.Errorf("channel ต้องปิดแล้ว")
+	}
+}
+
+// รู้แค่ job_id ยกเลิกไม่ได้ ต้องมี X-Job-Token ของผู้สร้าง
+func TestCancelJobToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := openTestDB(t)
+	if err := db.AutoMigrate(&entity.GenJob{}); err != nil {
+		t.Fatal(err)
+	}
+	rc := &RouteController{DB: db, jobs: newJobHub()}
+	for _, row := range []entity.GenJob{
+		{JobID: "run", Status: JobRunning, CancelToken: "tok-run"},
+		{JobID: "done", Status: JobDone, CancelToken: "tok-done"},
+	} {
+		if err := db.Create(&row).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	cancelled := false
+	rc.jobs.add(&genJob{id: "run", cancel: func() { cancelled = true }, subs: map[chan JobEvent]struct{}{}})
+
+	tests := []struct {
+		name, id, token string
+		want            int
+	}{
+		{"ไม่แนบ token", "run", "", http.StatusForbidden},
+		{"token ของ job อื่น", "run", "tok-done", http.StatusForbidden},
+		{"ไม่พบ job", "nope", "tok-run", http.StatusNotFound},
+		{"job จบแล้ว", "done", "tok-done", http.StatusConflict},
+		{"ผู้สร้าง", "run", "tok-run", http.StatusAccepted},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodDelete, "/gen-route/jobs/"+tt.id, nil)
+			if tt.token != "" {
+				c.Request.Header.Set("X-Job-Token", tt.token)
+			}
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			before := cancelled
+			rc.CancelJob(c)
+			if w.Code != tt.want {
+				t.Errorf("status = %d ต้องการ %d (%s)", w.Code, tt.want, w.Body)
+			}
+			if got := cancelled && !before; got != (tt.want == http.StatusAccepted) {
+				t.Errorf("cancel ถูกเรียก = %v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package GenTrip
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/planner"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// ------------------------------------------------------------
+// วางแผนใหม่เฉพาะบางวันของทริปที่บันทึกแล้ว
+//   POST /trips/:id/replan
+//
+// body:
+// {
+//   "days": [3],                 // วันที่ต้องการสร้างใหม่ (1-based)
+//   "start": "P12",              // optional; ไม่ส่ง = แลนด์มาร์กที่ใกล้ที่พักที่สุดที่ยังไม่ถูกใช้
+//   "start_date": "2025-01-31",  // optional; วันแรกของทริป (ใช้เช็กเวลาเปิด-ปิด) ไม่ส่ง = วันนี้
+//   "avoid": ["P40"]             // optional
+// }
+//
+// คงที่พักเดิม, ไม่ใช้สถานที่ที่อยู่ในวันอื่นแล้ว, ใช้งบเท่ากับส่วนแบ่งต่อวันของ Condition.Price
+// แล้วแทนที่ Shortestpath ของวันนั้นทั้งหมดใน transaction เดียว
+// ------------------------------------------------------------
+
+type replanRequest struct {
+	Days      []int    `json:"days" binding:"required,min=1"`
+	Start     string   `json:"start"`
+	StartDate string   `json:"start_date"`
+	Avoid     []string `json:"avoid"`
+}
+
+// errNoPlaces ไม่มีสถานที่เหลือพอจะจัดวันนั้นใหม่
+var errNoPlaces = errors.New("ไม่มีสถานที่ที่ยังไม่ถูกใช้เหลือพอจะจัดวันนี้ใหม่")
+
+func (rc *RouteController) ReplanDays(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "id ไม่ถูกต้อง"})
+		return
+	}
+	var req replanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var trip entity.Trips
+	if err := rc.DB.Preload("Con").Preload("Acc").
+		Preload("ShortestPaths", func(db *gorm.DB) *gorm.DB { return db.Order("day, path_index") }).
+		First(&trip, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบข้อมูลทริป"})
+		return
+	}
+	// route อยู่ใน authorized แต่ต้องเป็นเจ้าของทริปเท่านั้น (เจ้าของคือ user ของ Condition)
+	uid, _ := c.Get("user_id")
+	floatID, ok := uid.(float64)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "ไม่พบ user ใน token"})
+		return
+	}
+	if trip.Con == nil || trip.Con.User_id != uint(floatID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "ไม่มีสิทธิ์แก้ไขทริปนี้"})
+		return
+	}
+	if trip.Acc == nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "ทริปนี้ยังไม่มีที่พัก"})
+		return
+	}
+
+	replan := map[int]bool{}
+	for _, d := range req.Days {
+		if d < 1 || d > trip.Days {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("day ต้องอยู่ระหว่าง 1-%d", trip.Days)})
+			return
+		}
+		replan[d] = true
+	}
+	days := make([]int, 0, len(replan))
+	for d := range replan {
+		days = append(days, d)
+	}
+	sort.Ints(days)
+
+	startDate := time.Now().In(domain.Bangkok)
+	if req.StartDate != "" {
+		if startDate, err = time.ParseInLocation("2006-01-02", req.StartDate, domain.Bangkok); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "start_date ต้องอยู่ในรูปแบบ YYYY-MM-DD"})
+			return
+		}
+	}
+	userAvoid, err := parseCodes(strings.Join(req.Avoid, ","))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "avoid: " + err.Error()})
+		return
+	}
+
+	accCode := fmt.Sprintf("A%d", trip.Acc.ID)
+	used := replanUsed(trip.ShortestPaths, replan, accCode)
+	tl := timeline.FromEnv()
+
+	ctx := c.Request.Context()
+	plans := map[int]*planner.Result{}
+	for _, d := range days {
+		start := req.Start
+		if start == "" || used[start] {
+			if start, err = rc.nearestFreeLandmark(trip.Acc, used, userAvoid); err != nil {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "day": d})
+				return
+			}
+		}
+
+		opt := replanOptions(&trip, d, start, startDate, used, userAvoid)
+		opt.Timeline = tl
+
+		res, err := planner.Plan(ctx, newDBSource(ctx, rc), opt)
+		if err != nil {
+			if errors.Is(err, planner.ErrBadStart) || errors.Is(err, planner.ErrBadPlaces) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "day": d})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "สร้างเส้นทางไม่สำเร็จ", "day": d, "detail": err.Error()})
+			return
+		}
+		if len(res.TripPlanByDay) == 0 || len(res.TripPlanByDay[0].Plan) == 0 {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": errNoPlaces.Error(), "day": d})
+			return
+		}
+		for _, p := range res.TripPlanByDay[0].Plan {
+			used[p.ID] = true // วันถัดไปที่สร้างใหม่ในคำขอเดียวกันก็ห้ามซ้ำ
+		}
+		plans[d] = res
+	}
+
+	// แทนที่ path ของวันที่สร้างใหม่ แล้วเรียง path_index ทั้งทริปใหม่
+	err = rc.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, d := range days {
+			if err := tx.Where("trip_id = ? AND day = ?", trip.ID, d).Delete(&entity.Shortestpath{}).Error; err != nil {
+				return err
+			}
+			rows := dayRows(trip.ID, d, accCode, plans[d], tl)
+			if len(rows) > 0 {
+				if err := tx.Create(&rows).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return renumberPaths(tx, trip.ID)
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "บันทึกเส้นทางใหม่ไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+
+	var paths []entity.Shortestpath
+	if err := rc.DB.Where("trip_id = ?", trip.ID).Order("day, path_index").Find(&paths).Error; err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลได้"})
+		return
+	}
+	out := make(map[string]*planner.Result, len(plans))
+	for d, r := range plans {
+		out[strconv.Itoa(d)] = r
+	}
+	c.JSON(http.StatusOK, gin.H{"trip_id": trip.ID, "days": days, "plans": out, "shortest_paths": paths})
+}
+
+// replanUsed สถานที่ในวันที่ไม่ได้สร้างใหม่ (ห้ามใช้ซ้ำ) ไม่นับที่พัก
+func replanUsed(paths []entity.Shortestpath, replan map[int]bool, accCode string) map[string]bool {
+	used := map[string]bool{}
+	for _, p := range paths {
+		if replan[p.Day] {
+			continue
+		}
+		for _, code := range []string{p.FromCode, p.ToCode} {
+			if code != "" && code != accCode {
+				used[code] = true
+			}
+		}
+	}
+	return used
+}
+
+// replanOptions ตัวเลือก planner ของวัน day: 1 วัน, งบเท่าส่วนแบ่งต่อวันของ Condition.Price,
+// บังคับที่พักเดิม และ avoid = ที่ผู้ใช้ขอ + ที่ใช้ในวันอื่นแล้ว (trip ต้องมี Acc)
+func replanOptions(trip *entity.Trips, day int, start string, startDate time.Time, used map[string]bool, userAvoid []string) planner.Options {
+	budget := 0
+	if trip.Con != nil {
+		budget = int(trip.Con.Price)
+	}
+	avoid := append([]string(nil), userAvoid...)
+	for code := range used {
+		avoid = append(avoid, code)
+	}
+	sort.Strings(avoid[len(userAvoid):])
+
+	opt := planner.DefaultOptions()
+	opt.Start = start
+	opt.Days = 1
+	opt.TotalBudget = budget / max(trip.Days, 1)
+	opt.StartDate = startDate.AddDate(0, 0, day-1)
+	opt.Must = []string{fmt.Sprintf("A%d", trip.Acc.ID)}
+	opt.Avoid = avoid
+	return opt
+}
+
+// dayRows แปลงแผน 1 วันเป็น Shortestpath: ที่พัก → จุดแรก → … → จุดสุดท้าย → ที่พัก
+func dayRows(tripID uint, day int, accCode string, res *planner.Result, tl timeline.Config) []entity.Shortestpath {
+	plan := res.TripPlanByDay[0].Plan
+	dist := map[[2]string]float64{}
+	for _, p := range res.Paths {
+		dist[[2]string{p.From, p.To}] = p.DistanceKm
+	}
+
+	codes := []string{accCode}
+	names := []string{""}
+	for _, p := range plan {
+		codes = append(codes, p.ID)
+		names = append(names, p.Name)
+	}
+	codes = append(codes, accCode)
+
+	rows := make([]entity.Shortestpath, 0, len(codes)-1)
+	legs := make([]timeline.Leg, 0, len(codes)-1)
+	for i := 0; i+1 < len(codes); i++ {
+		from, to := codes[i], codes[i+1]
+		desc := "กลับที่พัก"
+		if i+1 < len(names) {
+			desc = "เที่ยว " + names[i+1]
+		}
+		d := dist[[2]string{from, to}]
+		rows = append(rows, entity.Shortestpath{
+			TripID: tripID, Day: day, PathIndex: i,
+			FromCode: from, ToCode: to, Type: "Activity",
+			Distance: float32(d), ActivityDescription: desc,
+		})
+		legs = append(legs, timeline.Leg{ToCode: to, Type: "Activity", DistanceKm: d})
+	}
+	for i, s := range tl.Schedule(legs) {
+		rows[i].StartTime, rows[i].EndTime = domain.FormatClock(s.Start), domain.FormatClock(s.End)
+		rows[i].DurationMin, rows[i].SuggestMode = s.Travel.Minutes, s.Travel.SuggestMode
+	}
+	return rows
+}
+
+// renumberPaths เรียง path_index ของทั้งทริปเป็น 1..N ตาม (day, path_index) เหมือนตอน frontend บันทึก
+func renumberPaths(tx *gorm.DB, tripID uint) error {
+	var rows []entity.Shortestpath
+	if err := tx.Where("trip_id = ?", tripID).Order("day, path_index, id").Find(&rows).Error; err != nil {
+		return err
+	}
+	for i, r := range rows {
+		if r.PathIndex == i+1 {
+			continue
+		}
+		if err := tx.Model(&entity.Shortestpath{}).Where("id = ?", r.ID).Update("path_index", i+1).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nearestFreeLandmark แลนด์มาร์กที่ใกล้ที่พักที่สุดที่ยังไม่ถูกใช้ (ใช้เป็น start ของวันที่สร้างใหม่)
+func (rc *RouteController) nearestFreeLandmark(acc *entity.Accommodation, used map[string]bool, avoid []string) (string, error) {
+	skip := map[string]bool{}
+	for _, a := range avoid {
+		skip[a] = true
+	}
+	var lms []entity.Landmark
+	if err := rc.DB.Select("id", "lat", "lon").Find(&lms).Error; err != nil {
+		return "", err
+	}
+	best, bestD := "", math.Inf(1)
+	for _, l := range lms {
+		code := fmt.Sprintf("P%d", l.ID)
+		if used[code] || skip[code] {
+			continue
+		}
+		dx, dy := float64(l.Lat-acc.Lat), float64(l.Lon-acc.Lon)
+		if d := dx*dx + dy*dy; d < bestD {
+			best, bestD = code, d
+		}
+	}
+	if best == "" {
+		return "", errNoPlaces
+	}
+	return best, nil
+}
@@ -0,0 +1,191 @@
+package GenTrip
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/planner"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.User{}, &entity.Condition{}, &entity.Accommodation{},
+		&entity.Trips{}, &entity.Shortestpath{}); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestReplanDaysOwner(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := openTestDB(t)
+	con := entity.Condition{Price: 3000, User_id: 1}
+	acc := entity.Accommodation{}
+	if err := db.Create(&con).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&acc).Error; err != nil {
+		t.Fatal(err)
+	}
+	trip := entity.Trips{Name: "ทริป", Days: 2, Con_id: con.ID, Acc_id: acc.ID}
+	if err := db.Create(&trip).Error; err != nil {
+		t.Fatal(err)
+	}
+	rc := &RouteController{DB: db}
+
+	tests := []struct {
+		name string
+		id   string
+		user any // ค่า user_id ใน context (JWT claims เป็น float64)
+		want int
+	}{
+		{"ไม่ใช่เจ้าของ", "1", float64(2), http.StatusForbidden},
+		{"ไม่มี user ใน token", "1", nil, http.StatusUnauthorized},
+		{"ไม่พบทริป", "99", float64(1), http.StatusNotFound},
+		// เจ้าของผ่านการตรวจสิทธิ์ แล้วไปติดที่ day เกินจำนวนวัน (ไม่ต้องใช้ PostGIS)
+		{"เจ้าของ", "1", float64(1), http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodPost, "/trips/"+tt.id+"/replan", strings.NewReader(`{"days":[5]}`))
+			c.Params = gin.Params{{Key: "id", Value: tt.id}}
+			if tt.user != nil {
+				c.Set("user_id", tt.user)
+			}
+			rc.ReplanDays(c)
+			if w.Code != tt.want {
+				t.Errorf("status = %d ต้องการ %d (%s)", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+
+	var n int64
+	db.Model(&entity.Shortestpath{}).Count(&n)
+	if n != 0 {
+		t.Errorf("คำขอที่ถูกปฏิเสธต้องไม่แตะ path (%d แถว)", n)
+	}
+}
+
+func TestReplanUsed(t *testing.T) {
+	paths := []entity.Shortestpath{
+		{Day: 1, FromCode: "A1", ToCode: "P1"},
+		{Day: 1, FromCode: "P1", ToCode: "R2"},
+		{Day: 1, FromCode: "R2", ToCode: "A1"},
+		{Day: 2, FromCode: "A1", ToCode: "P5"},
+		{Day: 3, FromCode: "A1", ToCode: "P7"},
+	}
+	got := replanUsed(paths, map[int]bool{2: true}, "A1")
+	want := map[string]bool{"P1": true, "R2": true, "P7": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("replanUsed = %v ต้องการ %v", got, want)
+	}
+}
+
+func TestReplanOptions(t *testing.T) {
+	trip := &entity.Trips{Days: 3, Con: &entity.Condition{Price: 3100}, Acc: &entity.Accommodation{}}
+	trip.Acc.ID = 7
+	start := time.Date(2026, 1, 5, 0, 0, 0, 0, domain.Bangkok)
+	used := map[string]bool{"P3": true, "R1": true}
+
+	opt := replanOptions(trip, 2, "P4", start, used, []string{"P9"})
+	if opt.Days != 1 || opt.Start != "P4" {
+		t.Errorf("Days/Start = %d/%s", opt.Days, opt.Start)
+	}
+	if opt.TotalBudget != 1033 {
+		t.Errorf("งบต่อวัน = %d ต้องการ 1033 (3100/3)", opt.TotalBudget)
+	}
+	if !opt.StartDate.Equal(start.AddDate(0, 0, 1)) {
+		t.Errorf("StartDate = %v ต้องเป็นวันที่ 2 ของทริป", opt.StartDate)
+	}
+	if !reflect.DeepEqual(opt.Must, []string{"A7"}) {
+		t.Errorf("Must = %v ต้องบังคับที่พักเดิม", opt.Must)
+	}
+	if want := []string{"P9", "P3", "R1"}; !reflect.DeepEqual(opt.Avoid, want) {
+		t.Errorf("Avoid = %v ต้องการ %v", opt.Avoid, want)
+	}
+
+	// ไม่มี Condition → ไม่จำกัดงบ
+	trip.Con = nil
+	if opt := replanOptions(trip, 1, "P4", start, nil, nil); opt.TotalBudget != 0 || len(opt.Avoid) != 0 {
+		t.Errorf("ไม่มี Condition: งบ %d avoid %v", opt.TotalBudget, opt.Avoid)
+	}
+}
+
+func TestDayRows(t *testing.T) {
+	res := &planner.Result{
+		TripPlanByDay: []planner.DayPlan{{Day: 1, Plan: []planner.PlaceInfo{{ID: "P1", Name: "วัด"}, {ID: "R2", Name: "ร้าน"}}}},
+		Paths: []planner.PathInfo{
+			{From: "A1", To: "P1", DistanceKm: 0.5},
+			{From: "P1", To: "R2", DistanceKm: 2},
+			{From: "R2", To: "A1", DistanceKm: 1.5},
+		},
+	}
+	rows := dayRows(9, 2, "A1", res, timeline.DefaultConfig())
+	want := []struct {
+		from, to, desc string
+		km             float32
+	}{
+		{"A1", "P1", "เที่ยว วัด", 0.5},
+		{"P1", "R2", "เที่ยว ร้าน", 2},
+		{"R2", "A1", "กลับที่พัก", 1.5},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("ได้ %d แถว ต้องการ %d", len(rows), len(want))
+	}
+	for i, w := range want {
+		r := rows[i]
+		if r.TripID != 9 || r.Day != 2 || r.PathIndex != i || r.FromCode != w.from || r.ToCode != w.to ||
+			r.ActivityDescription != w.desc || r.Distance != w.km {
+			t.Errorf("แถว %d = %+v", i, r)
+		}
+		if r.StartTime == "" || r.EndTime == "" {
+			t.Errorf("แถว %d ไม่มีเวลา", i)
+		}
+	}
+}
+
+func TestRenumberPaths(t *testing.T) {
+	db := openTestDB(t)
+	rows := []entity.Shortestpath{
+		{TripID: 1, Day: 2, PathIndex: 0, FromCode: "A1", ToCode: "P5"}, // วันที่สร้างใหม่เริ่มที่ 0
+		{TripID: 1, Day: 2, PathIndex: 1, FromCode: "P5", ToCode: "A1"},
+		{TripID: 1, Day: 1, PathIndex: 1, FromCode: "A1", ToCode: "P1"},
+		{TripID: 1, Day: 1, PathIndex: 2, FromCode: "P1", ToCode: "A1"},
+		{TripID: 2, Day: 1, PathIndex: 7, FromCode: "A2", ToCode: "P9"}, // ทริปอื่นห้ามแตะ
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := renumberPaths(db, 1); err != nil {
+		t.Fatal(err)
+	}
+	var got []entity.Shortestpath
+	if err := db.Order("trip_id, path_index").Find(&got).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1/1 A1-P1", "1/2 P1-A1", "1/3 A1-P5", "1/4 P5-A1", "2/7 A2-P9"}
+	for i, r := range got {
+		if s := fmt.Sprintf("%d/%d %s-%s", r.TripID, r.PathIndex, r.FromCode, r.ToCode); s != want[i] {
+			t.Errorf("แถว %d = %s ต้องการ %s", i, s, want[i])
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package GenTrip
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gtwndtl/trip-spark-builder/controller/Distance"
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/planner"
+)
+
+// dbSource ดึงข้อมูลให้ planner ตรงจาก DB + DistanceController (ไม่ยิง HTTP วนกลับ localhost)
+// ทุก query ผูกกับ ctx เพื่อให้ยกเลิก job ได้กลางทาง
+type dbSource struct {
+	rc  *RouteController
+	ctx context.Context
+}
+
+func newDBSource(ctx context.Context, rc *RouteController) dbSource {
+	return dbSource{rc: rc, ctx: ctx}
+}
+
+func (s dbSource) Places() (landmarks, restaurants, accommodations []planner.Place, err error) {
+	var lms []entity.Landmark
+	if err = s.rc.DB.WithContext(s.ctx).Find(&lms).Error; err != nil {
+		return
+	}
+	var rss []entity.Restaurant
+	if err = s.rc.DB.WithContext(s.ctx).Find(&rss).Error; err != nil {
+		return
+	}
+	var accs []entity.Accommodation
+	if err = s.rc.DB.WithContext(s.ctx).Find(&accs).Error; err != nil {
+		return
+	}
+
+	landmarks = make([]planner.Place, 0, len(lms))
+	for _, p := range lms {
+		landmarks = append(landmarks, planner.Place{
+			ID: fmt.Sprintf("P%d", p.ID), Name: p.Name,
+			Lat: float64(p.Lat), Lon: float64(p.Lon),
+			PriceMin: p.PriceMin, PriceMax: p.PriceMax,
+			Hours: hoursOf(&p.OpeningHours), Price: priceOf(&p.PriceInfo),
+		})
+	}
+	restaurants = make([]planner.Place, 0, len(rss))
+	for _, r := range rss {
+		restaurants = append(restaurants, planner.Place{
+			ID: fmt.Sprintf("R%d", r.ID), Name: r.Name,
+			Lat: float64(r.Lat), Lon: float64(r.Lon),
+			PriceMin: r.PriceMin, PriceMax: r.PriceMax,
+			Hours: hoursOf(&r.OpeningHours), Price: priceOf(&r.PriceInfo),
+		})
+	}
+	accommodations = make([]planner.Place, 0, len(accs))
+	for _, a := range accs {
+		accommodations = append(accommodations, planner.Place{
+			ID: fmt.Sprintf("A%d", a.ID), Name: a.Name,
+			Lat: float64(a.Lat), Lon: float64(a.Lon),
+			PriceMin: a.PriceMin, PriceMax: a.PriceMax,
+			Hours: hoursOf(&a.OpeningHours), Price: priceOf(&a.PriceInfo),
+		})
+	}
+	return
+}
+
+// hoursOf เวลาเปิด-ปิด (แถวที่ไม่เคย parse มีค่า zero ซึ่งแปลว่าปิดทุกวัน → nil ให้ planner ถือว่าเปิดตลอด)
+func hoursOf(h *domain.Hours) *domain.Hours {
+	if h.IsZero() {
+		return nil
+	}
+	return h
+}
+
+// priceOf ราคาแบบมีโครงสร้าง (แถวเก่าที่ยังไม่เคย parse มี Unit ว่าง → nil ให้ planner ใช้ PriceMin)
+func priceOf(p *domain.Price) *domain.Price {
+	if p.Unit == "" {
+		return nil
+	}
+	return p
+}
+
+// Distances อ่านจาก matrix ที่แคชไว้ (distance_cache) คำนวณเฉพาะคู่ที่ยังไม่มี
+func (s dbSource) Distances(ids []string) (*planner.Graph, error) {
+	m, err := s.rc.Distance.WithContext(s.ctx).Matrix(ids)
+	if err != nil {
+		return nil, err
+	}
+	g := planner.NewGraph()
+	for i, from := range m.Codes {
+		for j, to := range m.Codes {
+			if i == j {
+				continue
+			}
+			if km, ok := m.At(i, j); ok {
+				g.Add(from, to, km)
+			}
+		}
+	}
+	return g, nil
+}
+
+func (s dbSource) MSTByFlow(q planner.MSTQuery) (*planner.MSTResult, error) {
+	resp, err := s.rc.Distance.WithContext(s.ctx).MSTByFlow(Distance.MSTByFlowQuery{
+		Root:    q.Root,
+		ZoneA:   q.ZoneA,
+		ZoneB:   q.ZoneB,
+		K:       q.K,
+		KMst:    q.KMst,
+		NTop:    q.NTop,
+		Mode:    q.Mode,
+		Penalty: q.Penalty,
+		MaxDist: q.Distance,
+		Prefer:  q.Prefer,
+		Prefer2: q.Prefer2,
+		Prefer3: q.Prefer3,
+		W1:      q.W1,
+		W2:      q.W2,
+		W3:      q.W3,
+		Exclude: q.Exclude,
+
+		Kinds:        q.Kinds,
+		WR:           q.WR,
+		WA:           q.WA,
+		PreferR:      q.PreferR,
+		PreferA:      q.PreferA,
+		WPreferR:     q.WPreferR,
+		WPreferA:     q.WPreferA,
+		ExcludeCodes: q.ExcludeCodes,
+
+		Progress: q.Progress,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &planner.MSTResult{
+		Rows:            make([]planner.MSTRow, 0, len(resp.MST)),
+		AppliedCutEdges: resp.AppliedCutEdges,
+	}
+	for _, r := range resp.MST {
+		out.Rows = append(out.Rows, planner.MSTRow{
+			Seq:    r.Seq,
+			Depth:  r.Depth,
+			Node:   r.Node,
+			EdgeID: r.EdgeID,
+			Pred:   r.Pred,
+
+			Code:     r.Code,
+			PredCode: r.PredCode,
+		})
+	}
+	return out, nil
+}
+
+// SuggestAccommodations ที่พักใกล้กลุ่มจุดจาก PostGIS (ตรรกะเดียวกับ /suggest/accommodations?strategy=sum)
+func (s dbSource) SuggestAccommodations(codes []string) ([]string, error) {
+	rows, err := s.rc.Distance.WithContext(s.ctx).SuggestAccommodationsNear(codes, "sum", 5000, "", 20)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(rows))
+	for _, r := range rows {
+		out = append(out, r.Code)
+	}
+	return out, nil
+}
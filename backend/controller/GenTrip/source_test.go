@@ -0,0 +1,19 @@
+package GenTrip
+
+import (
+	"testing"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+)
+
+func TestHoursOf(t *testing.T) {
+	// แถวที่ opening_hours ว่าง (ที่พัก/แถวเก่า) ต้องไม่กลายเป็นปิดทุกวัน
+	if h := hoursOf(&domain.Hours{}); h != nil {
+		t.Errorf("zero Hours → %+v ต้องการ nil", h)
+	}
+	for _, h := range []domain.Hours{{Unknown: true}, {Closed: true}, domain.AlwaysOpen()} {
+		if got := hoursOf(&h); got != &h {
+			t.Errorf("%+v → %+v ต้องคืนตัวเดิม", h, got)
+		}
+	}
+}
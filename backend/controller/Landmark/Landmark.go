@@ -2,15 +2,17 @@ package Landmark
 
 import (
 	"fmt"
+	"log"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/domain"
 	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/search"
 )
 
 type LandmarkController struct {
@@ -25,48 +27,6 @@ func NewLandmarkController(db *gorm.DB, gisDB *gorm.DB) *LandmarkController {
 	}
 }
 
-// ------------------------ helpers ------------------------
-
-var reDigits = regexp.MustCompile(`\d[\d,]*`)
-
-func parsePriceRange(s string) (int, int) {
-	if s == "" { return 0, 0 }
-	t := strings.ToLower(strings.TrimSpace(s))
-	t = strings.NewReplacer("–", "-", "—", "-", "−", "-").Replace(t)
-
-	nums := reDigits.FindAllString(t, -1)
-	toInt := func(x string) int {
-		x = strings.ReplaceAll(x, ",", "")
-		n, _ := strconv.Atoi(x)
-		return n
-	}
-	hasFree := strings.Contains(t, "ฟรี") || strings.Contains(t, "free")
-
-	if len(nums) == 0 {
-		if hasFree { return 0, 0 }
-		return 0, 0
-	}
-
-	minV := int(^uint(0) >> 1)
-	maxV := 0
-	for _, ns := range nums {
-		v := toInt(ns)
-		if v < minV { minV = v }
-		if v > maxV { maxV = v }
-	}
-	if minV == int(^uint(0)>>1) { minV = 0 }
-
-	if hasFree {
-		if maxV == 0 { return 0, 0 }
-		return 0, maxV
-	}
-	if len(nums) == 1 {
-		return maxV, maxV
-	}
-	if minV > maxV { minV, maxV = maxV, minV }
-	return minV, maxV
-}
-
 // ------------------------ handlers ------------------------
 
 // Create Landmark + LandmarkGis
@@ -85,8 +45,10 @@ func (ctl *LandmarkController) Create(c *gin.Context) {
 		ThumbnailURL string    `json:"thumbnail_url"`
 		TimeOpen     time.Time `json:"time_open"`
 		TimeClose    time.Time `json:"time_close"`
+		OpenDays     string    `json:"open_days"`
 		TotalPeople  string    `json:"total_people"`
 		Price        string    `json:"price"`
+		PriceChild   string    `json:"price_child"`
 		Review       int       `json:"review"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -94,7 +56,21 @@ func (ctl *LandmarkController) Create(c *gin.Context) {
 		return
 	}
 
-	pmin, pmax := parsePriceRange(input.Price)
+	// ค่าที่อ่านไม่ออกไม่ทำให้ request ล้ม: เก็บข้อความดิบไว้ (ค่าที่ parse เป็น Unknown) แล้วแจ้งใน warnings แบบเดียวกับ import
+	var warnings []string
+	warn := func(field string, err error) {
+		if err != nil {
+			warnings = append(warnings, field+": "+err.Error())
+		}
+	}
+
+	hours, err := domain.HoursFromClock(input.TimeOpen, input.TimeClose, input.OpenDays)
+	warn("open_days", err)
+	price, err := domain.ParsePriceTiers(input.Price, input.PriceChild, domain.PerPerson)
+	warn("price", err)
+	capacity, err := domain.ParseCapacity(input.TotalPeople, "")
+	warn("capacity", err)
+	pmin, pmax := price.Bounds()
 
 	landmark := entity.Landmark{
 		PlaceID:      input.PlaceID,
@@ -110,8 +86,13 @@ func (ctl *LandmarkController) Create(c *gin.Context) {
 		ThumbnailURL: input.ThumbnailURL,
 		Time_open:    input.TimeOpen,
 		Time_close:   input.TimeClose,
+		Open_days:    input.OpenDays,
+		OpeningHours: hours,
 		Total_people: input.TotalPeople,
 		Price:        input.Price,
+		PriceChild:   input.PriceChild,
+		PriceInfo:    price,
+		Capacity:     capacity,
 		Review:       input.Review,
 		PriceMin:     pmin,
 		PriceMax:     pmax,
@@ -133,7 +114,19 @@ func (ctl *LandmarkController) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Landmark created", "id": landmark.ID})
+	// เคยถูกถามระยะก่อนมีจุด → แคชจำไว้ว่าไม่มีจุด ต้องล้างทิ้ง
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("P%d", landmark.ID)); err != nil {
+		// เขียนสองฐานสำเร็จแล้ว: ไม่ตอบ 500 (client จะส่งซ้ำ) แค่เตือนว่าระยะที่แคชไว้อาจเก่า
+		log.Printf("landmark %d: invalidate distance cache: %v", landmark.ID, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
+
+	search.Invalidate() // ดัชนี typeahead สร้างใหม่ตอนค้นครั้งถัดไป
+	resp := gin.H{"message": "Landmark created", "id": landmark.ID}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 // Get all landmarks with location WKT
@@ -149,18 +142,25 @@ func (ctl *LandmarkController) GetAll(c *gin.Context) {
 		Location string `json:"location"`
 	}
 
+	// WKT ของทุกแถวใน query เดียว (ไม่มีใน GIS → location ว่าง)
+	var locs []struct {
+		ID  uint
+		WKT string
+	}
+	if err := ctl.PostgisDB.Raw(
+		"SELECT landmark_id AS id, ST_AsText(location) AS wkt FROM landmark_gis").Scan(&locs).Error; err != nil {
+		locs = nil
+	}
+	wkt := make(map[uint]string, len(locs))
+	for _, l := range locs {
+		wkt[l.ID] = l.WKT
+	}
+
 	results := make([]LandmarkWithLocation, 0, len(landmarks))
 	for _, lm := range landmarks {
-		var location string
-		err := ctl.PostgisDB.Raw(
-			"SELECT ST_AsText(location) FROM landmark_gis WHERE landmark_id = ?",
-			lm.ID).Scan(&location).Error
-		if err != nil {
-			location = ""
-		}
 		results = append(results, LandmarkWithLocation{
 			Landmark: lm,
-			Location: location,
+			Location: wkt[lm.ID],
 		})
 	}
 
@@ -215,8 +215,10 @@ func (ctl *LandmarkController) Update(c *gin.Context) {
 		ThumbnailURL string    `json:"thumbnail_url"`
 		TimeOpen     time.Time `json:"time_open"`
 		TimeClose    time.Time `json:"time_close"`
+		OpenDays     string    `json:"open_days"`
 		TotalPeople  string    `json:"total_people"`
 		Price        string    `json:"price"`
+		PriceChild   string    `json:"price_child"`
 		Review       int       `json:"review"`
 	}
 	if err := c.ShouldBindJSON(&input); err != nil {
@@ -230,7 +232,21 @@ func (ctl *LandmarkController) Update(c *gin.Context) {
 		return
 	}
 
-	pmin, pmax := parsePriceRange(input.Price)
+	// ค่าที่อ่านไม่ออกไม่ทำให้ request ล้ม: เก็บข้อความดิบไว้ (ค่าที่ parse เป็น Unknown) แล้วแจ้งใน warnings แบบเดียวกับ import
+	var warnings []string
+	warn := func(field string, err error) {
+		if err != nil {
+			warnings = append(warnings, field+": "+err.Error())
+		}
+	}
+
+	hours, err := domain.HoursFromClock(input.TimeOpen, input.TimeClose, input.OpenDays)
+	warn("open_days", err)
+	price, err := domain.ParsePriceTiers(input.Price, input.PriceChild, domain.PerPerson)
+	warn("price", err)
+	capacity, err := domain.ParseCapacity(input.TotalPeople, "")
+	warn("capacity", err)
+	pmin, pmax := price.Bounds()
 
 	landmark.PlaceID = input.PlaceID
 	landmark.Name = input.Name
@@ -245,8 +261,13 @@ func (ctl *LandmarkController) Update(c *gin.Context) {
 	landmark.ThumbnailURL = input.ThumbnailURL
 	landmark.Time_open = input.TimeOpen
 	landmark.Time_close = input.TimeClose
+	landmark.Open_days = input.OpenDays
+	landmark.OpeningHours = hours
 	landmark.Total_people = input.TotalPeople
 	landmark.Price = input.Price
+	landmark.PriceChild = input.PriceChild
+	landmark.PriceInfo = price
+	landmark.Capacity = capacity
 	landmark.Review = input.Review
 	landmark.PriceMin = pmin
 	landmark.PriceMax = pmax
@@ -264,7 +285,18 @@ func (ctl *LandmarkController) Update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Landmark updated"})
+	// พิกัดเปลี่ยน → ระยะที่แคชไว้ของที่นี่ใช้ไม่ได้แล้ว
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("P%d", landmark.ID)); err != nil {
+		log.Printf("landmark %d: invalidate distance cache: %v", landmark.ID, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
+
+	search.Invalidate()
+	resp := gin.H{"message": "Landmark updated"}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // Delete landmark + GIS
@@ -280,11 +312,21 @@ func (ctl *LandmarkController) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete landmark GIS"})
 		return
 	}
+	var warnings []string
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("P%d", id)); err != nil {
+		log.Printf("landmark %d: invalidate distance cache: %v", id, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
 
 	if err := ctl.MysqlDB.Delete(&entity.Landmark{}, id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete landmark"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Landmark deleted"})
+	search.Invalidate()
+	resp := gin.H{"message": "Landmark deleted"}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
 }
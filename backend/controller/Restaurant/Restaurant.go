@@ -2,15 +2,17 @@ package Restaurant
 
 import (
 	"fmt"
+	"log"
 	"net/http"
-	"regexp"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
+	"github.com/gtwndtl/trip-spark-builder/distcache"
+	"github.com/gtwndtl/trip-spark-builder/domain"
 	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/search"
 )
 
 type RestaurantController struct {
@@ -25,48 +27,6 @@ func NewRestaurantController(db *gorm.DB, gisDB *gorm.DB) *RestaurantController
 	}
 }
 
-// ------------------------ helpers ------------------------
-
-var reDigits = regexp.MustCompile(`\d[\d,]*`)
-
-func parsePriceRange(s string) (int, int) {
-	if s == "" { return 0, 0 }
-	t := strings.ToLower(strings.TrimSpace(s))
-	t = strings.NewReplacer("–", "-", "—", "-", "−", "-").Replace(t)
-
-	nums := reDigits.FindAllString(t, -1)
-	toInt := func(x string) int {
-		x = strings.ReplaceAll(x, ",", "")
-		n, _ := strconv.Atoi(x)
-		return n
-	}
-	hasFree := strings.Contains(t, "ฟรี") || strings.Contains(t, "free")
-
-	if len(nums) == 0 {
-		if hasFree { return 0, 0 }
-		return 0, 0
-	}
-
-	minV := int(^uint(0) >> 1)
-	maxV := 0
-	for _, ns := range nums {
-		v := toInt(ns)
-		if v < minV { minV = v }
-		if v > maxV { maxV = v }
-	}
-	if minV == int(^uint(0)>>1) { minV = 0 }
-
-	if hasFree {
-		if maxV == 0 { return 0, 0 }
-		return 0, maxV
-	}
-	if len(nums) == 1 {
-		return maxV, maxV
-	}
-	if minV > maxV { minV, maxV = maxV, minV }
-	return minV, maxV
-}
-
 // ------------------------ handlers ------------------------
 
 // Create Restaurant + GIS
@@ -85,6 +45,7 @@ func (ctl *RestaurantController) Create(c *gin.Context) {
 		ThumbnailURL string    `json:"thumbnail_url"`
 		TimeOpen     time.Time `json:"time_open"`
 		TimeClose    time.Time `json:"time_close"`
+		OpenDays     string    `json:"open_days"`
 		TotalPeople  string    `json:"total_people"`
 		Price        string    `json:"price"`
 		Review       int       `json:"review"`
@@ -94,7 +55,21 @@ func (ctl *RestaurantController) Create(c *gin.Context) {
 		return
 	}
 
-	pmin, pmax := parsePriceRange(input.Price)
+	// ค่าที่อ่านไม่ออกไม่ทำให้ request ล้ม: เก็บข้อความดิบไว้ (ค่าที่ parse เป็น Unknown) แล้วแจ้งใน warnings แบบเดียวกับ import
+	var warnings []string
+	warn := func(field string, err error) {
+		if err != nil {
+			warnings = append(warnings, field+": "+err.Error())
+		}
+	}
+
+	hours, err := domain.HoursFromClock(input.TimeOpen, input.TimeClose, input.OpenDays)
+	warn("open_days", err)
+	price, err := domain.ParsePrice(input.Price, domain.PerPerson)
+	warn("price", err)
+	capacity, err := domain.ParseCapacity(input.TotalPeople, "")
+	warn("capacity", err)
+	pmin, pmax := price.Bounds()
 
 	res := entity.Restaurant{
 		PlaceID:      input.PlaceID,
@@ -110,8 +85,12 @@ func (ctl *RestaurantController) Create(c *gin.Context) {
 		ThumbnailURL: input.ThumbnailURL,
 		Time_open:    input.TimeOpen,
 		Time_close:   input.TimeClose,
+		Open_days:    input.OpenDays,
+		OpeningHours: hours,
 		Total_people: input.TotalPeople,
 		Price:        input.Price,
+		PriceInfo:    price,
+		Capacity:     capacity,
 		Review:       input.Review,
 		PriceMin:     pmin,
 		PriceMax:     pmax,
@@ -131,7 +110,18 @@ func (ctl *RestaurantController) Create(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "Restaurant created", "id": res.ID})
+	// เคยถูกถามระยะก่อนมีจุด → แคชจำไว้ว่าไม่มีจุด ต้องล้างทิ้ง
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("R%d", res.ID)); err != nil {
+		log.Printf("restaurant %d: invalidate distance cache: %v", res.ID, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
+
+	search.Invalidate() // ดัชนี typeahead สร้างใหม่ตอนค้นครั้งถัดไป
+	resp := gin.H{"message": "Restaurant created", "id": res.ID}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusCreated, resp)
 }
 
 // Get all restaurants + location
@@ -147,17 +137,25 @@ func (ctl *RestaurantController) GetAll(c *gin.Context) {
 		Location string `json:"location"`
 	}
 
+	// WKT ของทุกแถวใน query เดียว (ไม่มีใน GIS → location ว่าง)
+	var locs []struct {
+		ID  uint
+		WKT string
+	}
+	if err := ctl.PostgisDB.Raw(
+		"SELECT restaurant_id AS id, ST_AsText(location) AS wkt FROM restaurant_gis").Scan(&locs).Error; err != nil {
+		locs = nil
+	}
+	wkt := make(map[uint]string, len(locs))
+	for _, l := range locs {
+		wkt[l.ID] = l.WKT
+	}
+
 	results := make([]ResWithLocation, 0, len(ress))
 	for _, res := range ress {
-		var location string
-		err := ctl.PostgisDB.Raw(
-			"SELECT ST_AsText(location) FROM restaurant_gis WHERE restaurant_id = ?", res.ID).Scan(&location).Error
-		if err != nil {
-			location = ""
-		}
 		results = append(results, ResWithLocation{
 			Restaurant: res,
-			Location:   location,
+			Location:   wkt[res.ID],
 		})
 	}
 
@@ -211,6 +209,7 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 		ThumbnailURL string    `json:"thumbnail_url"`
 		TimeOpen     time.Time `json:"time_open"`
 		TimeClose    time.Time `json:"time_close"`
+		OpenDays     string    `json:"open_days"`
 		TotalPeople  string    `json:"total_people"`
 		Price        string    `json:"price"`
 		Review       int       `json:"review"`
@@ -226,7 +225,21 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 		return
 	}
 
-	pmin, pmax := parsePriceRange(input.Price)
+	// ค่าที่อ่านไม่ออกไม่ทำให้ request ล้ม: เก็บข้อความดิบไว้ (ค่าที่ parse เป็น Unknown) แล้วแจ้งใน warnings แบบเดียวกับ import
+	var warnings []string
+	warn := func(field string, err error) {
+		if err != nil {
+			warnings = append(warnings, field+": "+err.Error())
+		}
+	}
+
+	hours, err := domain.HoursFromClock(input.TimeOpen, input.TimeClose, input.OpenDays)
+	warn("open_days", err)
+	price, err := domain.ParsePrice(input.Price, domain.PerPerson)
+	warn("price", err)
+	capacity, err := domain.ParseCapacity(input.TotalPeople, "")
+	warn("capacity", err)
+	pmin, pmax := price.Bounds()
 
 	res.PlaceID = input.PlaceID
 	res.Name = input.Name
@@ -241,8 +254,12 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 	res.ThumbnailURL = input.ThumbnailURL
 	res.Time_open = input.TimeOpen
 	res.Time_close = input.TimeClose
+	res.Open_days = input.OpenDays
+	res.OpeningHours = hours
 	res.Total_people = input.TotalPeople
 	res.Price = input.Price
+	res.PriceInfo = price
+	res.Capacity = capacity
 	res.Review = input.Review
 	res.PriceMin = pmin
 	res.PriceMax = pmax
@@ -260,7 +277,18 @@ func (ctl *RestaurantController) Update(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Restaurant updated"})
+	// พิกัดเปลี่ยน → ระยะที่แคชไว้ของที่นี่ใช้ไม่ได้แล้ว
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("R%d", res.ID)); err != nil {
+		log.Printf("restaurant %d: invalidate distance cache: %v", res.ID, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
+
+	search.Invalidate()
+	resp := gin.H{"message": "Restaurant updated"}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // Delete restaurant + GIS
@@ -276,11 +304,21 @@ func (ctl *RestaurantController) Delete(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete restaurant GIS"})
 		return
 	}
+	var warnings []string
+	if err := distcache.Invalidate(ctl.PostgisDB, fmt.Sprintf("R%d", id)); err != nil {
+		log.Printf("restaurant %d: invalidate distance cache: %v", id, err)
+		warnings = append(warnings, "Distance cache not cleared; cached distances may be stale")
+	}
 
 	if err := ctl.MysqlDB.Delete(&entity.Restaurant{}, id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete restaurant"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Restaurant deleted"})
+	search.Invalidate()
+	resp := gin.H{"message": "Restaurant deleted"}
+	if len(warnings) > 0 {
+		resp["warnings"] = warnings
+	}
+	c.JSON(http.StatusOK, resp)
 }
@@ -10,18 +10,21 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
 	"gorm.io/gorm"
 )
 
 type ShortestPathController struct {
 	DB        *gorm.DB // MySQL
 	PostgisDB *gorm.DB // PostGIS
+	Timeline  timeline.Config
 }
 
 func NewShortestPathController(db *gorm.DB, postgisDB *gorm.DB) *ShortestPathController {
 	return &ShortestPathController{
 		DB:        db,
 		PostgisDB: postgisDB,
+		Timeline:  timeline.FromEnv(),
 	}
 }
 
@@ -41,6 +44,7 @@ func (ctrl *ShortestPathController) CreateShortestPath(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างข้อมูลได้"})
 		return
 	}
+	ctrl.retimeAfterChange(&path)
 	c.JSON(http.StatusOK, path)
 }
 
@@ -81,6 +85,8 @@ func (ctrl *ShortestPathController) UpdateShortestPath(c *gin.Context) {
 		return
 	}
 
+	oldTripID, oldDay := path.TripID, path.Day
+
 	fmt.Printf("Old ToCode: %s, New ToCode: %s\n", path.ToCode, input.ToCode)
 	toCodeChanged := path.ToCode != input.ToCode
 	fmt.Printf("toCodeChanged = %v\n", toCodeChanged)
@@ -138,6 +144,14 @@ func (ctrl *ShortestPathController) UpdateShortestPath(c *gin.Context) {
 		}
 	}
 
+	// คำนวณเวลาใหม่ทั้งวัน (ถ้าย้ายไปวันอื่น วันเดิมก็ต้องคำนวณใหม่ด้วย)
+	if oldTripID != path.TripID || oldDay != path.Day {
+		if _, err := ctrl.retimeDay(ctrl.DB, oldTripID, oldDay); err != nil {
+			fmt.Printf("คำนวณเวลาใหม่ของวันเดิมไม่สำเร็จ: %v\n", err)
+		}
+	}
+	ctrl.retimeAfterChange(&path)
+
 	c.JSON(http.StatusOK, path)
 }
 
@@ -226,10 +240,16 @@ func getDescriptionFromCode(db *gorm.DB, code string) string {
 // DELETE /shortest-paths/:id
 func (ctrl *ShortestPathController) DeleteShortestPath(c *gin.Context) {
 	id := c.Param("id")
+	var path entity.Shortestpath
+	if err := ctrl.DB.First(&path, id).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "ไม่พบข้อมูล"})
+		return
+	}
 	if err := ctrl.DB.Delete(&entity.Shortestpath{}, id).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถลบข้อมูลได้"})
 		return
 	}
+	ctrl.retimeAfterChange(&path)
 	c.JSON(http.StatusOK, gin.H{"message": "ลบข้อมูลสำเร็จ"})
 }
 
@@ -347,6 +367,7 @@ func (ctrl *ShortestPathController) BulkUpdateAccommodation(c *gin.Context) {
 	}
 
 	updated := 0
+	touchedDays := map[int]bool{}
 
 	for i := range rows {
 		p := &rows[i]
@@ -384,6 +405,7 @@ func (ctrl *ShortestPathController) BulkUpdateAccommodation(c *gin.Context) {
 				return
 			}
 			updated++
+			touchedDays[p.Day] = true
 
 			// ถ้า ToCode เปลี่ยน → อัปเดต FromCode ของ path ถัดไป + คำนวณระยะ
 			if toChanged {
@@ -405,6 +427,15 @@ func (ctrl *ShortestPathController) BulkUpdateAccommodation(c *gin.Context) {
 		}
 	}
 
+	// ระยะเปลี่ยน → เวลาเดินทางเปลี่ยน คำนวณเวลาใหม่ของวันที่ถูกแก้
+	for day := range touchedDays {
+		if _, err := ctrl.retimeDay(tx, req.TripID, day); err != nil {
+			tx.Rollback()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "retime failed", "detail": err.Error()})
+			return
+		}
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "commit failed", "detail": err.Error()})
 		return
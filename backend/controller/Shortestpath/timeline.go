@@ -0,0 +1,121 @@
+package Shortestpath
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// retimeDay คำนวณ StartTime/EndTime/DurationMin/SuggestMode ของทุก path ในวันนั้นใหม่ตามลำดับ path_index
+// (เวลาเริ่มวัน + เวลาเดินทางตาม Type/Distance + เวลาเที่ยวตามหมวดของ ToCode)
+func (ctrl *ShortestPathController) retimeDay(db *gorm.DB, tripID uint, day int) ([]entity.Shortestpath, error) {
+	return ctrl.retimeDayFrom(db, tripID, day, ctrl.Timeline.DayStart)
+}
+
+func (ctrl *ShortestPathController) retimeDayFrom(db *gorm.DB, tripID uint, day, start int) ([]entity.Shortestpath, error) {
+	var rows []entity.Shortestpath
+	if err := db.Where("trip_id = ? AND day = ?", tripID, day).Order("path_index").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	legs := make([]timeline.Leg, len(rows))
+	for i, r := range rows {
+		legs[i] = timeline.Leg{ToCode: r.ToCode, Type: r.Type, DistanceKm: float64(r.Distance)}
+	}
+	slots := ctrl.Timeline.ScheduleFrom(start, legs)
+
+	for i := range rows {
+		st, et := domain.FormatClock(slots[i].Start), domain.FormatClock(slots[i].End)
+		dur, sug := slots[i].Travel.Minutes, slots[i].Travel.SuggestMode
+		if rows[i].StartTime == st && rows[i].EndTime == et && rows[i].DurationMin == dur && rows[i].SuggestMode == sug {
+			continue
+		}
+		rows[i].StartTime, rows[i].EndTime, rows[i].DurationMin, rows[i].SuggestMode = st, et, dur, sug
+		if err := db.Model(&rows[i]).Updates(map[string]interface{}{
+			"start_time": st, "end_time": et, "duration_min": dur, "suggest_mode": sug,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+	return rows, nil
+}
+
+// retimeAfterChange เรียกหลังบันทึก path แล้ว: คำนวณเวลาใหม่ของวันนั้น และเติมเวลาใหม่กลับลง path
+// error แค่ log ไว้ เพราะตัวข้อมูลหลักบันทึกไปแล้ว
+func (ctrl *ShortestPathController) retimeAfterChange(path *entity.Shortestpath) {
+	rows, err := ctrl.retimeDay(ctrl.DB, path.TripID, path.Day)
+	if err != nil {
+		fmt.Printf("คำนวณเวลาใหม่ของวันที่ %d (trip %d) ไม่สำเร็จ: %v\n", path.Day, path.TripID, err)
+		return
+	}
+	for _, r := range rows {
+		if r.ID == path.ID {
+			path.StartTime, path.EndTime = r.StartTime, r.EndTime
+			path.DurationMin, path.SuggestMode = r.DurationMin, r.SuggestMode
+			return
+		}
+	}
+}
+
+// POST /shortest-paths/retime
+// body:
+// {
+//   "trip_id": 1,
+//   "day": 2,              // optional; ไม่ส่ง = ทุกวันของทริป
+//   "day_start": "08:30"   // optional; ไม่ส่ง = ค่าตั้งของ server
+// }
+func (ctrl *ShortestPathController) RetimeShortestPaths(c *gin.Context) {
+	var req struct {
+		TripID   uint   `json:"trip_id" binding:"required"`
+		Day      int    `json:"day"`
+		DayStart string `json:"day_start"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start := ctrl.Timeline.DayStart
+	if req.DayStart != "" {
+		m, _, err := domain.ParseClock(req.DayStart)
+		if err != nil || m < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "day_start ต้องอยู่ในรูปแบบ HH:MM"})
+			return
+		}
+		start = m
+	}
+
+	days := []int{req.Day}
+	if req.Day == 0 {
+		days = nil
+		if err := ctrl.DB.Model(&entity.Shortestpath{}).
+			Where("trip_id = ?", req.TripID).
+			Distinct().Order("day").Pluck("day", &days).Error; err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถดึงข้อมูลได้"})
+			return
+		}
+	}
+
+	var out []entity.Shortestpath
+	err := ctrl.DB.Transaction(func(tx *gorm.DB) error {
+		for _, d := range days {
+			rows, err := ctrl.retimeDayFrom(tx, req.TripID, d, start)
+			if err != nil {
+				return err
+			}
+			out = append(out, rows...)
+		}
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "คำนวณเวลาใหม่ไม่สำเร็จ", "detail": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, out)
+}
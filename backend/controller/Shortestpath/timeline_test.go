@@ -0,0 +1,61 @@
+package Shortestpath
+
+import (
+	"testing"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// suggest_mode ต้องบันทึกลงแถว ไม่ใช่มีแค่ใน response แรก
+func TestRetimeDayPersistsSuggestMode(t *testing.T) {
+	db, err := gorm.Open(sqlite.Open("file::memory:"), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&entity.Shortestpath{}); err != nil {
+		t.Fatal(err)
+	}
+	rows := []entity.Shortestpath{
+		{TripID: 1, Day: 1, PathIndex: 0, FromCode: "A1", ToCode: "P1", Type: "เดิน", Distance: 0.5},
+		{TripID: 1, Day: 1, PathIndex: 1, FromCode: "P1", ToCode: "P2", Type: "เดิน", Distance: 6},
+	}
+	if err := db.Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+	ctrl := &ShortestPathController{DB: db, Timeline: timeline.DefaultConfig()}
+
+	stored := func() []entity.Shortestpath {
+		var out []entity.Shortestpath
+		if err := db.Where("trip_id = 1 AND day = 1").Order("path_index").Find(&out).Error; err != nil {
+			t.Fatal(err)
+		}
+		return out
+	}
+
+	if _, err := ctrl.retimeDay(db, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	got := stored()
+	if got[0].SuggestMode != "" || got[1].SuggestMode == "" {
+		t.Fatalf("suggest_mode = %q, %q ต้องการ \"\", แบบอื่นที่ไม่ใช่เดิน", got[0].SuggestMode, got[1].SuggestMode)
+	}
+	if got[1].StartTime == "" || got[1].DurationMin == 0 {
+		t.Errorf("เวลาไม่ถูกบันทึก: %+v", got[1])
+	}
+
+	// เปลี่ยนเป็นรถแล้ว retime → คำแนะนำต้องหายจากแถวด้วย
+	if err := db.Model(&got[1]).Update("type", "รถยนต์").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ctrl.retimeDay(db, 1, 1); err != nil {
+		t.Fatal(err)
+	}
+	if s := stored()[1].SuggestMode; s != "" {
+		t.Errorf("suggest_mode หลังเปลี่ยนเป็นรถ = %q", s)
+	}
+}
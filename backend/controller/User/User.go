@@ -9,6 +9,7 @@ import (
 	"github.com/gtwndtl/trip-spark-builder/config"
 	"golang.org/x/crypto/bcrypt"
 
+	"github.com/gtwndtl/trip-spark-builder/middlewares"
 	"github.com/gtwndtl/trip-spark-builder/services"
 )
 
@@ -42,6 +43,9 @@ func (ctrl *UserController) CreateUser(c *gin.Context) {
 	}
 	user.Password = string(hashedPassword)
 
+	// ✅ สมัครเองได้แค่ผู้ใช้ทั่วไป (Type เปลี่ยนได้เฉพาะ admin ผ่าน PUT /users/:id)
+	user.Type = "user"
+
 	// ✅ สร้างผู้ใช้
 	if err := ctrl.DB.Create(&user).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "ไม่สามารถสร้างผู้ใช้ได้"})
@@ -81,15 +85,36 @@ func (ctrl *UserController) UpdateUser(c *gin.Context) {
 		return
 	}
 
+	id0, type0 := user.ID, user.Type
 	if err := c.ShouldBindJSON(&user); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	// body เปลี่ยน id ของแถวไม่ได้ และเปลี่ยน Type ได้เฉพาะ admin
+	user.ID = id0
+	if user.Type != type0 && !ctrl.isAdmin(c) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "เปลี่ยนประเภทผู้ใช้ได้เฉพาะผู้ดูแลระบบ"})
+		return
+	}
 
 	ctrl.DB.Save(&user)
 	c.JSON(http.StatusOK, user)
 }
 
+// isAdmin ผู้ใช้ใน token เป็นผู้ดูแลระบบหรือไม่ (อ่าน Type จากฐานข้อมูล ไม่เชื่อค่าจาก body)
+func (ctrl *UserController) isAdmin(c *gin.Context) bool {
+	uid, _ := c.Get("user_id")
+	floatID, ok := uid.(float64)
+	if !ok {
+		return false
+	}
+	var me entity.User
+	if err := ctrl.DB.Select("id", "type").First(&me, uint(floatID)).Error; err != nil {
+		return false
+	}
+	return me.Type == middlewares.UserTypeAdmin
+}
+
 // DELETE /users/:id
 func (ctrl *UserController) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
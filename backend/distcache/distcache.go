@@ -0,0 +1,259 @@
+package distcache
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+// ------------------------------------------------------------
+// แคชระยะเส้นตรงระหว่างสถานที่ (ตาราง distance_cache ใน PostGIS)
+//   - key คือคู่รหัส (P/R/A) เก็บแถวเดียวต่อคู่: from_code < to_code (เทียบแบบไบต์ เหมือน COLLATE "C")
+//   - เติมแบบ lazy จาก /distances หรือทั้งหมดทีเดียวด้วย Warm (cmd/distcache)
+//   - distance_cache_points จำพิกัด ณ ตอนคำนวณ: พิกัดเปลี่ยน → Invalidate/Reconcile ลบคู่ที่เกี่ยวข้อง
+//   - รหัสที่ไม่มีจุดใน GIS จำไว้เป็นแถว location = NULL (ไม่ต้องถาม PostGIS ซ้ำทุกครั้ง)
+//     เพิ่มจุดภายหลัง → Invalidate ลบแถวนี้ทิ้งเหมือนพิกัดเปลี่ยน
+// ------------------------------------------------------------
+
+// storeBatch แถวต่อ INSERT (3 placeholder ต่อแถว)
+const storeBatch = 1000
+
+// placePoints จุดทั้งหมดจากตาราง GIS ในรูป (code, location)
+const placePoints = `
+	SELECT 'P' || landmark_id AS code, location FROM landmark_gis
+	UNION ALL
+	SELECT 'R' || restaurant_id, location FROM restaurant_gis
+	UNION ALL
+	SELECT 'A' || acc_id, location FROM accommodation_gis`
+
+// Key คู่รหัสแบบเรียงแล้ว (ใช้เป็น key ของแคช)
+func Key(a, b string) [2]string {
+	if b < a {
+		a, b = b, a
+	}
+	return [2]string{a, b}
+}
+
+// Lookup ระยะ (เมตร) ที่แคชไว้ระหว่าง codes ทุกคู่ และรหัสที่เคยบันทึกไว้แล้ว
+// (known[code] = มีจุดใน GIS; false = บันทึกไว้ว่าไม่มีจุด; ไม่มี key = ยังไม่เคยคำนวณ)
+func Lookup(db *gorm.DB, codes []string) (map[[2]string]float64, map[string]bool, error) {
+	pairs := map[[2]string]float64{}
+	known := map[string]bool{}
+	if len(codes) == 0 {
+		return pairs, known, nil
+	}
+
+	var pts []struct {
+		Code    string
+		Located bool
+	}
+	if err := db.Model(&entity.DistanceCachePoint{}).Select("code, location IS NOT NULL AS located").
+		Where("code IN ?", codes).Scan(&pts).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, p := range pts {
+		known[p.Code] = p.Located
+	}
+
+	var rows []entity.DistanceCache
+	if err := db.Select("from_code", "to_code", "meters").
+		Where("from_code IN ? AND to_code IN ?", codes, codes).Find(&rows).Error; err != nil {
+		return nil, nil, err
+	}
+	for _, r := range rows {
+		pairs[Key(r.FromCode, r.ToCode)] = r.Meters
+	}
+	return pairs, known, nil
+}
+
+// Store บันทึกระยะที่คำนวณใหม่ และจำพิกัดปัจจุบันของ codes (upsert ทั้งคู่)
+// รหัสที่ไม่มีจุดใน GIS บันทึกเป็น location = NULL
+func Store(db *gorm.DB, pairs map[[2]string]float64, codes []string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if len(codes) > 0 {
+			if err := tx.Exec(`
+				INSERT INTO distance_cache_points (code, location)
+				SELECT code, location FROM (`+placePoints+`) p
+				WHERE code IN ?
+				ON CONFLICT (code) DO UPDATE SET location = EXCLUDED.location`, codes).Error; err != nil {
+				return err
+			}
+			absent := make([]entity.DistanceCachePoint, len(codes))
+			for i, c := range codes {
+				absent[i] = entity.DistanceCachePoint{Code: c}
+			}
+			if err := tx.Omit("location").Clauses(clause.OnConflict{DoNothing: true}).
+				CreateInBatches(absent, storeBatch).Error; err != nil {
+				return err
+			}
+		}
+
+		rows := make([]entity.DistanceCache, 0, len(pairs))
+		for k, m := range pairs {
+			rows = append(rows, entity.DistanceCache{FromCode: k[0], ToCode: k[1], Meters: m})
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "from_code"}, {Name: "to_code"}},
+			DoUpdates: clause.AssignmentColumns([]string{"meters", "updated_at"}),
+		}).CreateInBatches(rows, storeBatch).Error
+	})
+}
+
+// Invalidate ลบทุกคู่ที่เกี่ยวกับ codes (เรียกหลังแก้/ลบพิกัดของสถานที่)
+func Invalidate(db *gorm.DB, codes ...string) error {
+	if len(codes) == 0 {
+		return nil
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("from_code IN ? OR to_code IN ?", codes, codes).
+			Delete(&entity.DistanceCache{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("code IN ?", codes).Delete(&entity.DistanceCachePoint{}).Error
+	})
+}
+
+// Reconcile ลบแคชของสถานที่ที่พิกัดไม่ตรงกับตาราง GIS แล้ว (หรือถูกลบไป / เพิ่งมีจุด)
+// ใช้หลังโหลดข้อมูล GIS ใหม่ทั้งชุดตอนเริ่ม server คืนจำนวนสถานที่ที่ถูกล้าง
+func Reconcile(db *gorm.DB) (int, error) {
+	var stale []string
+	if err := db.Raw(`
+		SELECT c.code
+		FROM distance_cache_points c
+		LEFT JOIN (` + placePoints + `) p ON p.code = c.code
+		WHERE CASE WHEN p.location IS NULL OR c.location IS NULL
+			THEN (p.location IS NULL) <> (c.location IS NULL)
+			ELSE NOT ST_Equals(p.location, c.location) END`).Scan(&stale).Error; err != nil {
+		return 0, err
+	}
+	for lo := 0; lo < len(stale); lo += storeBatch {
+		if err := Invalidate(db, stale[lo:min(lo+storeBatch, len(stale))]...); err != nil {
+			return 0, err
+		}
+	}
+	return len(stale), nil
+}
+
+// Warm คำนวณทุกคู่ล่วงหน้า (maxKm > 0 = เฉพาะคู่ที่ไม่ไกลกว่านี้) คืนจำนวนคู่ที่บันทึก
+// คู่ที่ไม่ได้คำนวณยังเติมแบบ lazy ได้ตามปกติ
+func Warm(db *gorm.DB, maxKm float64) (int64, error) {
+	var n int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`
+			INSERT INTO distance_cache_points (code, location)
+			SELECT code, location FROM (` + placePoints + `) p
+			ON CONFLICT (code) DO UPDATE SET location = EXCLUDED.location`).Error; err != nil {
+			return err
+		}
+
+		cond, args := "", []any{}
+		if maxKm > 0 {
+			cond, args = "AND ST_DWithin(a.location::geography, b.location::geography, ?)", []any{maxKm * 1000}
+		}
+		res := tx.Exec(fmt.Sprintf(`
+			WITH pts AS (%s)
+			INSERT INTO distance_cache (from_code, to_code, meters, updated_at)
+			SELECT a.code, b.code, ST_DistanceSphere(a.location, b.location), NOW()
+			FROM pts a
+			JOIN pts b ON a.code < b.code COLLATE "C" %s
+			ON CONFLICT (from_code, to_code) DO UPDATE
+				SET meters = EXCLUDED.meters, updated_at = EXCLUDED.updated_at`, placePoints, cond), args...)
+		n = res.RowsAffected
+		return res.Error
+	})
+	return n, err
+}
+
+// Clear ล้างแคชทั้งหมด
+func Clear(db *gorm.DB) error {
+	return db.Exec(`TRUNCATE distance_cache, distance_cache_points`).Error
+}
+
+// ComputeFunc คำนวณระยะ (เมตร) ทุกคู่ใน codes ที่มีรหัสอย่างน้อยหนึ่งฝั่งอยู่ใน missing
+type ComputeFunc func(codes, missing []string) (map[[2]string]float64, error)
+
+// Fill matrix ของ codes จากแคช คำนวณเฉพาะรหัสที่ยังขาดคู่ด้วย compute แล้วบันทึกกลับ
+// รหัสซ้ำ/รูปแบบผิดถูกตัดทิ้ง; รหัสที่ไม่มีในตาราง GIS ได้ทั้งแถวเป็น Missing (และจำไว้ ไม่คำนวณซ้ำ)
+func Fill(db *gorm.DB, codes []string, compute ComputeFunc) (*Matrix, error) {
+	uniq := make([]string, 0, len(codes))
+	seen := map[string]bool{}
+	for _, c := range codes {
+		c, ok := normalizeCode(c)
+		if ok && !seen[c] {
+			seen[c] = true
+			uniq = append(uniq, c)
+		}
+	}
+
+	pairs, known, err := Lookup(db, uniq)
+	if err != nil {
+		return nil, err
+	}
+
+	if missing := missingCodes(uniq, pairs, known); len(missing) > 0 {
+		fresh, err := compute(uniq, missing)
+		if err != nil {
+			return nil, err
+		}
+		if err := Store(db, fresh, missing); err != nil {
+			return nil, err
+		}
+		for k, m := range fresh {
+			pairs[k] = m
+		}
+	}
+
+	m := NewMatrix(uniq)
+	idx := make(map[string]int, len(uniq))
+	for i, c := range uniq {
+		idx[c] = i
+	}
+	for k, meters := range pairs {
+		m.set(idx[k[0]], idx[k[1]], meters)
+	}
+	return m, nil
+}
+
+// missingCodes รหัสที่ต้องคำนวณใหม่: ยังไม่เคยบันทึก หรือมีจุดแต่คู่กับรหัสที่มีจุดอื่นยังไม่ครบ
+// รหัสที่บันทึกไว้ว่าไม่มีจุดถือว่าครบแล้ว (ไม่มีคู่ให้คำนวณ)
+func missingCodes(codes []string, pairs map[[2]string]float64, known map[string]bool) []string {
+	located := 0
+	for _, ok := range known {
+		if ok {
+			located++
+		}
+	}
+	count := map[string]int{}
+	for k := range pairs {
+		count[k[0]]++
+		count[k[1]]++
+	}
+	var missing []string
+	for _, c := range codes {
+		if ok, seen := known[c]; !seen || ok && count[c] < located-1 {
+			missing = append(missing, c)
+		}
+	}
+	return missing
+}
+
+// normalizeCode รหัส P/R/A ตามด้วยตัวเลข ตัดช่องว่างและเลข 0 นำหน้า ("P012" → "P12")
+func normalizeCode(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || !strings.ContainsRune("PRA", rune(s[0])) {
+		return "", false
+	}
+	n, err := strconv.ParseUint(s[1:], 10, 32)
+	if err != nil {
+		return "", false
+	}
+	return s[:1] + strconv.FormatUint(n, 10), true
+}
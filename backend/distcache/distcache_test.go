@@ -0,0 +1,54 @@
+package distcache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMissingCodes(t *testing.T) {
+	codes := []string{"P1", "P2", "R1", "A9"}
+	full := map[[2]string]float64{
+		Key("P1", "P2"): 100,
+		Key("P1", "R1"): 200,
+		Key("P2", "R1"): 300,
+	}
+	tests := []struct {
+		name  string
+		pairs map[[2]string]float64
+		known map[string]bool
+		want  []string
+	}{
+		{"ยังไม่เคยคำนวณ", map[[2]string]float64{}, map[string]bool{}, codes},
+		// A9 ไม่มีจุดใน GIS และจำไว้แล้ว → ไม่ต้องถาม PostGIS ซ้ำ
+		{"ครบ", full, map[string]bool{"P1": true, "P2": true, "R1": true, "A9": false}, nil},
+		{"ไม่มีจุดแต่ยังไม่เคยบันทึก", full, map[string]bool{"P1": true, "P2": true, "R1": true}, []string{"A9"}},
+		{"คู่ขาด", map[[2]string]float64{Key("P1", "P2"): 100},
+			map[string]bool{"P1": true, "P2": true, "R1": true, "A9": false}, []string{"P1", "P2", "R1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := missingCodes(codes, tt.pairs, tt.known); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("missingCodes = %v ต้องการ %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+		ok       bool
+	}{
+		{"P12", "P12", true},
+		{" R007 ", "R7", true},
+		{"A0", "A0", true},
+		{"X1", "", false},
+		{"P", "", false},
+		{"P-1", "", false},
+	}
+	for _, tt := range tests {
+		if got, ok := normalizeCode(tt.in); got != tt.want || ok != tt.ok {
+			t.Errorf("normalizeCode(%q) = %q, %v", tt.in, got, ok)
+		}
+	}
+}
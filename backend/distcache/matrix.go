@@ -0,0 +1,66 @@
+package distcache
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// ------------------------------------------------------------
+// Matrix ระยะแบบ dense สำหรับ planner / สคริปต์ภายนอก
+//   JSON:   {"codes": [...], "n": N, "km": [N*N ค่า เรียงทีละแถว]}   (-1 = ไม่มีข้อมูล)
+//   binary: "DMX1" | uint32 N | N × (uint8 ความยาว + รหัส) | N*N × float32 (km)   little-endian
+// ------------------------------------------------------------
+
+// Missing ค่าของช่องที่ไม่มีข้อมูล (รหัสไม่มีในตาราง GIS)
+const Missing = -1
+
+// Matrix ระยะ (km) ระหว่าง Codes ทุกคู่ เรียงทีละแถว: KM[i*N+j] = ระยะจาก Codes[i] ไป Codes[j]
+type Matrix struct {
+	Codes []string  `json:"codes"`
+	N     int       `json:"n"`
+	KM    []float32 `json:"km"`
+}
+
+// NewMatrix matrix ขนาด len(codes) ที่ทุกช่องเป็น Missing ยกเว้นแนวทแยงเป็น 0
+func NewMatrix(codes []string) *Matrix {
+	n := len(codes)
+	m := &Matrix{Codes: codes, N: n, KM: make([]float32, n*n)}
+	for i := range m.KM {
+		if i%(n+1) != 0 {
+			m.KM[i] = Missing
+		}
+	}
+	return m
+}
+
+// At ระยะ (km) จากแถว i ไปคอลัมน์ j; ok=false เมื่อไม่มีข้อมูล
+func (m *Matrix) At(i, j int) (float64, bool) {
+	v := m.KM[i*m.N+j]
+	return float64(v), v >= 0
+}
+
+// set ใส่ระยะทั้งสองทิศ (ระยะเส้นตรงสมมาตร) ปัดที่ 1 เมตร
+func (m *Matrix) set(i, j int, meters float64) {
+	km := float32(math.Round(meters) / 1000)
+	m.KM[i*m.N+j] = km
+	m.KM[j*m.N+i] = km
+}
+
+// MarshalBinary รูปแบบ binary ตามหัวไฟล์ (ขนาดราว 4·N² ไบต์ เล็กกว่า JSON หลายเท่า)
+func (m *Matrix) MarshalBinary() ([]byte, error) {
+	size := 8 + 4*len(m.KM)
+	for _, c := range m.Codes {
+		size += 1 + len(c)
+	}
+	b := make([]byte, 0, size)
+	b = append(b, "DMX1"...)
+	b = binary.LittleEndian.AppendUint32(b, uint32(m.N))
+	for _, c := range m.Codes {
+		b = append(b, byte(len(c)))
+		b = append(b, c...)
+	}
+	for _, v := range m.KM {
+		b = binary.LittleEndian.AppendUint32(b, math.Float32bits(v))
+	}
+	return b, nil
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ------------------------------------------------------------
+// types
+// ------------------------------------------------------------
+
+// Capacity จำนวนคนที่รับได้ (และจำนวนห้องของที่พัก)
+// People เป็นช่วงเพราะข้อมูลดิบมักเป็นค่าประมาณ เช่น "~50–100 คน/รอบ"
+type Capacity struct {
+	Unknown bool   `json:"unknown,omitempty"`
+	People  Range  `json:"people"`
+	Approx  bool   `json:"approx,omitempty"`
+	Per     string `json:"per,omitempty"`   // "รอบ" / "วัน" ... (ว่าง = พร้อมกันครั้งเดียว)
+	Note    string `json:"note,omitempty"`  // ข้อความในวงเล็บ เช่น "หมู่คณะนัดล่วงหน้า"
+	Rooms   int    `json:"rooms,omitempty"` // จำนวนห้อง (ที่พัก; 0 = ไม่รู้)
+}
+
+// ------------------------------------------------------------
+// queries
+// ------------------------------------------------------------
+
+// Fits รับกลุ่ม n คนได้หรือไม่ (ไม่มีข้อมูล → ถือว่าได้)
+func (c Capacity) Fits(n int) bool {
+	if c.Unknown || c.People.OpenEnded {
+		return true
+	}
+	return n <= c.People.Max
+}
+
+// FitsRooms มีห้องพอสำหรับกลุ่มหรือไม่ (ไม่รู้จำนวนห้อง → ถือว่าพอ)
+func (c Capacity) FitsRooms(g Group) bool {
+	return c.Rooms <= 0 || g.Rooms() <= c.Rooms
+}
+
+// ------------------------------------------------------------
+// parsing
+// ------------------------------------------------------------
+
+// capacityPer หน่วยช่วงเวลาที่ตามหลัง "/" หรืออยู่ในวงเล็บ
+var capacityPer = []struct{ word, per string }{
+	{"รอบ", "รอบ"}, {"ต่อวัน", "วัน"}, {"/วัน", "วัน"}, {"ชั่วโมง", "ชั่วโมง"}, {"/ชม", "ชั่วโมง"},
+}
+
+// ParseCapacity แปลงข้อความจำนวนคนดิบ เช่น "250", "~300 คน (กลางแจ้ง)", "300-600",
+// "~1,000+ (พื้นที่เปิด)", "~50–100 คน/รอบ (หมู่คณะนัดล่วงหน้า)"
+// rooms คือคอลัมน์จำนวนห้องของที่พัก ("" ได้); อ่านไม่ออก → Unknown + error
+func ParseCapacity(people, rooms string) (Capacity, error) {
+	var c Capacity
+	var errs []string
+
+	t := normalizeText(people)
+	if noData(t) {
+		c.Unknown = true
+	} else {
+		r, ok := parseRange(reParen.ReplaceAllString(t, ""))
+		if ok {
+			c.People = r
+		} else {
+			c.Unknown = true
+			errs = append(errs, fmt.Sprintf("อ่านจำนวนคนไม่ออก: %q", strings.TrimSpace(people)))
+		}
+		c.Approx = strings.Contains(t, "~") || strings.Contains(t, "ประมาณ")
+		for _, p := range capacityPer {
+			if strings.Contains(t, p.word) {
+				c.Per = p.per
+				break
+			}
+		}
+		c.Note = strings.Join(notes(t), "; ")
+	}
+
+	if rt := normalizeText(rooms); !noData(rt) {
+		if r, ok := parseRange(rt); ok {
+			c.Rooms = r.Max
+		} else {
+			errs = append(errs, fmt.Sprintf("อ่านจำนวนห้องไม่ออก: %q", strings.TrimSpace(rooms)))
+		}
+	}
+
+	if len(errs) > 0 {
+		return c, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return c, nil
+}
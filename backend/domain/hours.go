@@ -0,0 +1,363 @@
+// Package domain โมเดลข้อมูลสถานที่แบบมีโครงสร้าง (เวลาเปิด-ปิด ฯลฯ) ที่ใช้ร่วมกันทั้ง loader, controller และ planner
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ------------------------------------------------------------
+// types
+// ------------------------------------------------------------
+
+// Window ช่วงเวลาเปิดในหนึ่งวัน หน่วยเป็นนาทีนับจากเที่ยงคืน
+// Close อาจเกิน 1440 ได้ถ้าปิดหลังเที่ยงคืน (เช่น 17:00–02:00 → 1020–1560)
+type Window struct {
+	Open  int `json:"open"`
+	Close int `json:"close"`
+}
+
+// Hours กฎเวลาเปิด-ปิดรายวัน (index ตาม time.Weekday: 0=อาทิตย์ … 6=เสาร์)
+// วันที่ไม่มี Window เลย = ปิดวันนั้น
+type Hours struct {
+	Unknown bool        `json:"unknown,omitempty"` // ไม่มีข้อมูล → ถือว่าเปิดตลอด
+	Closed  bool        `json:"closed,omitempty"`  // ปิดถาวร/ปิดปรับปรุง
+	Week    [7][]Window `json:"week"`
+}
+
+const dayMinutes = 24 * 60
+
+// ------------------------------------------------------------
+// queries
+// ------------------------------------------------------------
+
+// IsZero ยังไม่เคยตั้งค่า (คอลัมน์ opening_hours ว่าง เช่นที่พักหรือแถวเก่าก่อนมีการ parse)
+// ต่างจาก Hours ที่ parse แล้วซึ่งต้องเป็น Unknown, Closed หรือมีวันที่เปิดอย่างน้อยหนึ่งวัน
+func (h Hours) IsZero() bool {
+	if h.Unknown || h.Closed {
+		return false
+	}
+	for _, ws := range h.Week {
+		if len(ws) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// OpenOn เปิดในวันนั้นหรือไม่ (ไม่สนเวลา)
+func (h Hours) OpenOn(wd time.Weekday) bool {
+	if h.Unknown {
+		return true
+	}
+	if h.Closed {
+		return false
+	}
+	return len(h.Week[wd]) > 0
+}
+
+// WindowsOn ช่วงเวลาที่เปิดของวันนั้น (รวมช่วงที่ล้นมาจากเมื่อวานหลังเที่ยงคืน)
+func (h Hours) WindowsOn(wd time.Weekday) []Window {
+	if h.Unknown {
+		return []Window{{0, dayMinutes}}
+	}
+	if h.Closed {
+		return nil
+	}
+	var out []Window
+	prev := (wd + 6) % 7
+	for _, w := range h.Week[prev] {
+		if w.Close > dayMinutes {
+			out = append(out, Window{0, w.Close - dayMinutes})
+		}
+	}
+	out = append(out, h.Week[wd]...)
+	return out
+}
+
+// OpenFor เปิดตลอดช่วง [at, at+dur) ของวันนั้นหรือไม่ (at เป็นนาทีนับจากเที่ยงคืน)
+func (h Hours) OpenFor(wd time.Weekday, at, dur int) bool {
+	for _, w := range h.WindowsOn(wd) {
+		if at >= w.Open && at+dur <= w.Close {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpenAt เปิดอยู่ ณ เวลานั้นหรือไม่
+func (h Hours) IsOpenAt(t time.Time) bool {
+	return h.OpenFor(t.Weekday(), t.Hour()*60+t.Minute(), 0)
+}
+
+// NextOpen เวลาเปิดรอบถัดไปในวันเดียวกันที่ยังทันเที่ยวได้ dur นาที (ตั้งแต่ at)
+func (h Hours) NextOpen(wd time.Weekday, at, dur int) (int, bool) {
+	best, ok := 0, false
+	for _, w := range h.WindowsOn(wd) {
+		start := max(at, w.Open)
+		if start+dur <= w.Close && (!ok || start < best) {
+			best, ok = start, true
+		}
+	}
+	return best, ok
+}
+
+// ------------------------------------------------------------
+// constructors / parsing
+// ------------------------------------------------------------
+
+// AlwaysOpen เปิดทุกวันตลอด 24 ชม.
+func AlwaysOpen() Hours {
+	var h Hours
+	for d := range h.Week {
+		h.Week[d] = []Window{{0, dayMinutes}}
+	}
+	return h
+}
+
+// ParseHours แปลงคอลัมน์ดิบจาก Excel (TimeOpen, TimeClose, Date, TimeOpen2, TimeClose2) เป็น Hours
+// ถ้าอ่านบางส่วนไม่ออกจะคืน error พร้อมค่าที่ดีที่สุดที่อ่านได้ (เช่นไม่รู้วัน → ถือว่าทุกวัน)
+func ParseHours(open, close, days, open2, close2 string) (Hours, error) {
+	var errs []string
+
+	windows, err := parseWindow(open, close)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+	if w2, err := parseWindow(open2, close2); err != nil {
+		errs = append(errs, err.Error())
+	} else {
+		windows = append(windows, w2...)
+	}
+
+	week, closed, err := ParseDays(days)
+	if err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	var h Hours
+	switch {
+	case closed:
+		h.Closed = true
+	case len(windows) == 0:
+		h.Unknown = true
+	default:
+		for d := range h.Week {
+			if week[d] {
+				h.Week[d] = append([]Window(nil), windows...)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return h, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return h, nil
+}
+
+// HoursFromClock สร้าง Hours จากเวลาเปิด-ปิด (ใช้เฉพาะชั่วโมง:นาที) + ข้อความวันเปิด
+// ถ้าเวลาเป็น zero ทั้งคู่ ถือว่าไม่มีข้อมูล
+func HoursFromClock(open, close time.Time, days string) (Hours, error) {
+	if open.IsZero() && close.IsZero() {
+		return ParseHours("", "", days, "", "")
+	}
+	return ParseHours(open.In(Bangkok).Format("15:04"), close.In(Bangkok).Format("15:04"), days, "", "")
+}
+
+// ClockTime แปลงนาทีนับจากเที่ยงคืนเป็น time.Time (ใช้เก็บลง Time_open/Time_close)
+func ClockTime(min int) time.Time {
+	return time.Date(2000, 1, 1, 0, 0, 0, 0, Bangkok).Add(time.Duration(min) * time.Minute)
+}
+
+// FormatClock นาที → "HH:MM" (เกิน 24 ชม. จะวนกลับ)
+func FormatClock(min int) string {
+	min = ((min % dayMinutes) + dayMinutes) % dayMinutes
+	return fmt.Sprintf("%02d:%02d", min/60, min%60)
+}
+
+var thaiWeekdays = [7]string{"อาทิตย์", "จันทร์", "อังคาร", "พุธ", "พฤหัสบดี", "ศุกร์", "เสาร์"}
+
+// ThaiWeekday ชื่อวันภาษาไทย เช่น time.Monday → "จันทร์"
+func ThaiWeekday(wd time.Weekday) string { return thaiWeekdays[wd] }
+
+// Bangkok เขตเวลาที่ใช้กับข้อมูลสถานที่ทั้งหมด
+var Bangkok = time.FixedZone("Asia/Bangkok", 7*60*60)
+
+var reClock = regexp.MustCompile(`^(\d{1,2})[:.](\d{2})(?::\d{2})?$`)
+
+// ParseClock "07:00" / "7.30" / "24:00:00" → นาที; คืน allDay=true ถ้าเป็น "24 ชม." / "ตลอดวัน"
+func ParseClock(s string) (min int, allDay bool, err error) {
+	t := strings.ToLower(strings.TrimSpace(s))
+	if t == "" {
+		return -1, false, nil
+	}
+	if nt := strings.ReplaceAll(t, " ", ""); strings.Contains(nt, "24ชม") || strings.Contains(nt, "24ชั่วโมง") ||
+		strings.Contains(nt, "ตลอดวัน") || nt == "24h" {
+		return 0, true, nil
+	}
+	if m := reClock.FindStringSubmatch(t); m != nil {
+		hh, _ := strconv.Atoi(m[1])
+		mm, _ := strconv.Atoi(m[2])
+		if hh > 24 || mm > 59 {
+			return -1, false, fmt.Errorf("เวลาไม่ถูกต้อง: %q", s)
+		}
+		return hh*60 + mm, false, nil
+	}
+	// Excel อาจเก็บเวลาเป็นเศษส่วนของวัน เช่น 0.375 = 09:00
+	if f, err := strconv.ParseFloat(t, 64); err == nil && f >= 0 && f <= 1 {
+		return int(f*dayMinutes + 0.5), false, nil
+	}
+	return -1, false, fmt.Errorf("อ่านเวลาไม่ออก: %q", s)
+}
+
+func parseWindow(open, close string) ([]Window, error) {
+	o, oAll, err := ParseClock(open)
+	if err != nil {
+		return nil, err
+	}
+	c, cAll, err := ParseClock(close)
+	if err != nil {
+		return nil, err
+	}
+	// เวลาเปิด = เวลาปิด (เช่น 18:00–18:00) ถือว่าเปิด 24 ชม.
+	if oAll || cAll || (o >= 0 && o == c) {
+		return []Window{{0, dayMinutes}}, nil
+	}
+	if o < 0 && c < 0 {
+		return nil, nil
+	}
+	if o < 0 || c < 0 {
+		return nil, fmt.Errorf("มีเวลาเปิดหรือปิดแค่ฝั่งเดียว: %q–%q", open, close)
+	}
+	// ปิดหลังเที่ยงคืน (หรือ 00:00 = เที่ยงคืน)
+	if c <= o {
+		c += dayMinutes
+	}
+	return []Window{{o, c}}, nil
+}
+
+// ------------------------------------------------------------
+// วันเปิด (ไทย/อังกฤษ)
+// ------------------------------------------------------------
+
+var thaiDays = []struct {
+	name string
+	wd   time.Weekday
+}{
+	// ยาวก่อน กัน "พฤหัส" ชน "พฤหัสบดี"
+	{"พฤหัสบดี", time.Thursday},
+	{"พฤหัส", time.Thursday},
+	{"อาทิตย์", time.Sunday},
+	{"จันทร์", time.Monday},
+	{"อังคาร", time.Tuesday},
+	{"พุธ", time.Wednesday},
+	{"ศุกร์", time.Friday},
+	{"เสาร์", time.Saturday},
+	{"sun", time.Sunday},
+	{"mon", time.Monday},
+	{"tue", time.Tuesday},
+	{"wed", time.Wednesday},
+	{"thu", time.Thursday},
+	{"fri", time.Friday},
+	{"sat", time.Saturday},
+}
+
+var reParen = regexp.MustCompile(`\(([^)]*)\)`)
+
+// ParseDays แปลงข้อความวันเปิด เช่น "ทุกวัน (เว้นวันจันทร์)", "จันทร์–ศุกร์", "ศุกร์, เสาร์–อาทิตย์"
+// คืน week[weekday]=true ถ้าเปิด และ closed=true ถ้าปิดปรับปรุง/ปิดถาวร
+// อ่านไม่ออก → ถือว่าเปิดทุกวันพร้อม error
+func ParseDays(s string) (week [7]bool, closed bool, err error) {
+	all := [7]bool{true, true, true, true, true, true, true}
+
+	t := strings.ToLower(strings.TrimSpace(s))
+	t = strings.NewReplacer("–", "-", "—", "-", "−", "-", "~", "-", "ถึง", "-").Replace(t)
+	if t == "" || t == "all" || t == "everyday" || t == "daily" {
+		return all, false, nil
+	}
+	if strings.Contains(t, "ปิดปรับปรุง") || strings.Contains(t, "ปิดถาวร") || strings.Contains(t, "ปิดชั่วคราว") {
+		return week, true, nil
+	}
+
+	// วงเล็บ: เก็บเฉพาะข้อยกเว้น "เว้น…"/"ยกเว้น…" นอกนั้นเป็นหมายเหตุ ตัดทิ้ง
+	var except []time.Weekday
+	for _, m := range reParen.FindAllStringSubmatch(t, -1) {
+		if strings.Contains(m[1], "เว้น") {
+			except = append(except, findDays(m[1])...)
+		}
+	}
+	t = reParen.ReplaceAllString(t, "")
+	if i := strings.Index(t, "เว้น"); i >= 0 {
+		except = append(except, findDays(t[i:])...)
+		t = t[:i]
+	}
+
+	if strings.Contains(t, "ทุกวัน") || strings.Contains(t, "every") {
+		week = all
+	} else {
+		found := false
+		t = strings.ReplaceAll(t, "และ", ",")
+		for _, seg := range strings.FieldsFunc(t, func(r rune) bool { return r == ',' || r == '/' }) {
+			parts := strings.Split(seg, "-")
+			if len(parts) == 2 {
+				a, b := findDays(parts[0]), findDays(parts[1])
+				if len(a) == 1 && len(b) == 1 {
+					for d := a[0]; ; d = (d + 1) % 7 {
+						week[d] = true
+						if d == b[0] {
+							break
+						}
+					}
+					found = true
+					continue
+				}
+			}
+			for _, d := range findDays(seg) {
+				week[d] = true
+				found = true
+			}
+		}
+		if !found {
+			return all, false, fmt.Errorf("อ่านวันเปิดไม่ออก: %q", s)
+		}
+	}
+
+	for _, d := range except {
+		week[d] = false
+	}
+	return week, false, nil
+}
+
+// findDays หาชื่อวันทั้งหมดในข้อความตามลำดับที่เจอ
+func findDays(s string) []time.Weekday {
+	s = strings.ReplaceAll(s, "วัน", "")
+	var out []time.Weekday
+	for len(s) > 0 {
+		matched := false
+		for _, d := range thaiDays {
+			if strings.HasPrefix(s, d.name) {
+				out = append(out, d.wd)
+				s = s[len(d.name):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			_, size := firstRune(s)
+			s = s[size:]
+		}
+	}
+	return out
+}
+
+func firstRune(s string) (rune, int) {
+	for i, r := range s {
+		if i > 0 {
+			return r, i
+		}
+	}
+	return 0, len(s)
+}
@@ -0,0 +1,161 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseDays(t *testing.T) {
+	const (
+		sun = 1 << time.Sunday
+		mon = 1 << time.Monday
+		tue = 1 << time.Tuesday
+		wed = 1 << time.Wednesday
+		thu = 1 << time.Thursday
+		fri = 1 << time.Friday
+		sat = 1 << time.Saturday
+		all = sun | mon | tue | wed | thu | fri | sat
+	)
+	tests := []struct {
+		in      string
+		want    int // bitmask ตาม time.Weekday
+		closed  bool
+		wantErr bool
+	}{
+		{"", all, false, false},
+		{"ทุกวัน", all, false, false},
+		{"Everyday", all, false, false},
+		{"ทุกวัน (เว้นวันจันทร์)", all &^ mon, false, false},
+		{"ทุกวัน ยกเว้นวันพุธและวันพฤหัสบดี", all &^ (wed | thu), false, false},
+		{"ทุกวัน (หยุดนักขัตฤกษ์)", all, false, false}, // วงเล็บที่ไม่ใช่ข้อยกเว้นเป็นหมายเหตุ
+		{"จันทร์–ศุกร์", mon | tue | wed | thu | fri, false, false},
+		{"วันจันทร์ ถึง วันศุกร์", mon | tue | wed | thu | fri, false, false},
+		{"ศุกร์-จันทร์", fri | sat | sun | mon, false, false}, // ช่วงข้ามสัปดาห์
+		{"ศุกร์, เสาร์–อาทิตย์", fri | sat | sun, false, false},
+		{"พฤหัส/เสาร์", thu | sat, false, false},
+		{"เสาร์และอาทิตย์", sat | sun, false, false},
+		{"Mon-Fri", mon | tue | wed | thu | fri, false, false},
+		{"ปิดปรับปรุง", 0, true, false},
+		{"ปิดถาวร", 0, true, false},
+		{"ตามนัดหมาย", all, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			week, closed, err := ParseDays(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			got := 0
+			for d, open := range week {
+				if open {
+					got |= 1 << d
+				}
+			}
+			if got != tt.want || closed != tt.closed {
+				t.Errorf("ParseDays(%q) = %07b closed=%v ต้องการ %07b closed=%v", tt.in, got, closed, tt.want, tt.closed)
+			}
+		})
+	}
+}
+
+func TestParseHours(t *testing.T) {
+	every := func(ws ...Window) Hours {
+		var h Hours
+		for d := range h.Week {
+			h.Week[d] = ws
+		}
+		return h
+	}
+	weekdays := func(ws ...Window) Hours {
+		var h Hours
+		for d := time.Monday; d <= time.Friday; d++ {
+			h.Week[d] = ws
+		}
+		return h
+	}
+	tests := []struct {
+		name                             string
+		open, close, days, open2, close2 string
+		want                             Hours
+		wantErr                          bool
+	}{
+		{"ปกติ", "08:30", "16:30", "ทุกวัน", "", "", every(Window{510, 990}), false},
+		{"จุด/ตัดวินาที", "9.00", "17:00:00", "", "", "", every(Window{540, 1020}), false},
+		{"เศษส่วนของวันจาก Excel", "0.375", "0.708333", "", "", "", every(Window{540, 1020}), false},
+		{"สองช่วง", "10:00", "14:00", "จันทร์-ศุกร์", "17:00", "22:00", weekdays(Window{600, 840}, Window{1020, 1320}), false},
+		{"ข้ามเที่ยงคืน", "17:00", "02:00", "ทุกวัน", "", "", every(Window{1020, 1560}), false},
+		{"ปิดเที่ยงคืนพอดี", "18:00", "00:00", "ทุกวัน", "", "", every(Window{1080, 1440}), false},
+		{"เปิด = ปิด คือ 24 ชม.", "18:00", "18:00", "ทุกวัน", "", "", every(Window{0, 1440}), false},
+		{"24 ชม.", "24 ชม.", "", "", "", "", every(Window{0, 1440}), false},
+		{"ไม่มีเวลา", "", "", "ทุกวัน", "", "", Hours{Unknown: true}, false},
+		{"ปิดถาวรชนะเวลา", "08:00", "17:00", "ปิดถาวร", "", "", Hours{Closed: true}, false},
+		{"เว้นวัน", "08:00", "17:00", "ทุกวัน (เว้นวันจันทร์)", "", "", func() Hours {
+			h := every(Window{480, 1020})
+			h.Week[time.Monday] = nil
+			return h
+		}(), false},
+		// อ่านไม่ออกบางส่วน → ค่าที่ดีที่สุด + error
+		{"มีแค่เวลาเปิด", "08:00", "", "ทุกวัน", "", "", Hours{Unknown: true}, true},
+		{"เวลาเสีย", "25:00", "17:00", "ทุกวัน", "", "", Hours{Unknown: true}, true},
+		{"วันอ่านไม่ออก", "08:00", "17:00", "ตามนัดหมาย", "", "", every(Window{480, 1020}), true},
+		{"ช่วงที่สองเสีย", "08:00", "12:00", "", "13:00", "", every(Window{480, 720}), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHours(tt.open, tt.close, tt.days, tt.open2, tt.close2)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseHours = %+v ต้องการ %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHoursWindows(t *testing.T) {
+	// เปิดศุกร์-เสาร์ 17:00–02:00 → ช่วงหลังเที่ยงคืนไปอยู่ต้นวันเสาร์/อาทิตย์
+	h, err := ParseHours("17:00", "02:00", "ศุกร์-เสาร์", "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		wd   time.Weekday
+		want []Window
+	}{
+		{time.Thursday, nil},
+		{time.Friday, []Window{{1020, 1560}}},
+		{time.Saturday, []Window{{0, 120}, {1020, 1560}}},
+		{time.Sunday, []Window{{0, 120}}},
+	}
+	for _, tt := range tests {
+		if got := h.WindowsOn(tt.wd); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("WindowsOn(%v) = %v ต้องการ %v", tt.wd, got, tt.want)
+		}
+	}
+	if !h.OpenFor(time.Sunday, 60, 60) || h.OpenFor(time.Sunday, 90, 60) {
+		t.Errorf("OpenFor ช่วงหลังเที่ยงคืนผิด")
+	}
+	if at, ok := h.NextOpen(time.Saturday, 600, 90); !ok || at != 1020 {
+		t.Errorf("NextOpen(เสาร์ 10:00) = %d, %v ต้องการ 1020", at, ok)
+	}
+	if _, ok := h.NextOpen(time.Sunday, 100, 60); ok {
+		t.Errorf("NextOpen(อาทิตย์ 01:40, 60 นาที) ต้องไม่ทัน")
+	}
+}
+
+func TestHoursIsZero(t *testing.T) {
+	if !(Hours{}).IsZero() {
+		t.Errorf("Hours{} ต้องเป็น zero")
+	}
+	for _, h := range []Hours{{Unknown: true}, {Closed: true}, AlwaysOpen()} {
+		if h.IsZero() {
+			t.Errorf("%+v ไม่ใช่ zero", h)
+		}
+	}
+	// zero value แปลตรง ๆ คือปิดทุกวัน จึงต้องเช็ก IsZero ก่อนใช้
+	if (Hours{}).OpenOn(time.Monday) {
+		t.Errorf("Hours{} เปิดวันจันทร์?")
+	}
+}
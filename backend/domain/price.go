@@ -0,0 +1,337 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------------
+// types
+// ------------------------------------------------------------
+
+// PriceUnit ราคานี้คิดต่ออะไร
+type PriceUnit string
+
+const (
+	PerPerson    PriceUnit = "person"     // ต่อคน (บัตรเข้า, อาหาร)
+	PerRoomNight PriceUnit = "room_night" // ต่อห้องต่อคืน (ที่พัก)
+	PerSession   PriceUnit = "session"    // ต่อรอบ/คลาส จ่ายครั้งเดียวทั้งกลุ่ม
+	PerHour      PriceUnit = "hour"       // ต่อชั่วโมง (คิด 1 ชม.)
+)
+
+// DefaultRoomSize คนต่อห้องเมื่อ Group ไม่ระบุ
+const DefaultRoomSize = 2
+
+// Range ช่วงตัวเลข (บาท หรือ คน) เช่น "1,000 - 1,400" → {1000, 1400}
+type Range struct {
+	Min       int  `json:"min"`
+	Max       int  `json:"max"`
+	OpenEnded bool `json:"open_ended,omitempty"` // "10,000+" / "เริ่ม 650" → Max เป็นแค่ขั้นต่ำ
+}
+
+// Price ราคาแบบมีโครงสร้าง แยกตามกลุ่มผู้จ่าย
+// Adult คือราคาหลัก (ผู้ใหญ่คนไทย); Child/Foreign = nil แปลว่าเท่ากับ Adult
+type Price struct {
+	Unknown bool      `json:"unknown,omitempty"` // ไม่มีข้อมูล
+	Unit    PriceUnit `json:"unit"`
+	Free    bool      `json:"free,omitempty"`   // ผู้ใหญ่เข้าฟรี
+	Approx  bool      `json:"approx,omitempty"` // มี "~" (ราคาโดยประมาณ)
+	Adult   Range     `json:"adult"`
+	Child   *Range    `json:"child,omitempty"`   // เด็ก/นักเรียน
+	Foreign *Range    `json:"foreign,omitempty"` // ชาวต่างชาติ
+	Note    string    `json:"note,omitempty"`    // ข้อความในวงเล็บ เช่น "ต้องจองล่วงหน้า"
+}
+
+// Group ผู้ร่วมทริป ใช้คูณราคา
+type Group struct {
+	Adults   int `json:"adults"`
+	Children int `json:"children"`
+	RoomSize int `json:"room_size,omitempty"` // คนต่อห้อง (0 = DefaultRoomSize)
+}
+
+// ------------------------------------------------------------
+// queries
+// ------------------------------------------------------------
+
+// Size จำนวนคนทั้งหมด (อย่างน้อย 1)
+func (g Group) Size() int { return max(1, g.Adults+g.Children) }
+
+// Rooms จำนวนห้องที่ต้องใช้
+func (g Group) Rooms() int {
+	size := g.RoomSize
+	if size <= 0 {
+		size = DefaultRoomSize
+	}
+	return (g.Size() + size - 1) / size
+}
+
+// Bounds ช่วงราคาหลักต่อหน่วย (ใช้เก็บลง PriceMin/PriceMax; ไม่มีข้อมูล → 0, 0)
+func (p Price) Bounds() (int, int) {
+	if p.Unknown {
+		return 0, 0
+	}
+	return p.Adult.Min, p.Adult.Max
+}
+
+// Cost ราคารวมของทั้งกลุ่ม nights คืน (nights ใช้กับ PerRoomNight เท่านั้น, < 1 ถือว่า 1)
+//   - PerPerson:    ผู้ใหญ่ × Adult + เด็ก × Child (ไม่มี Child ใช้ Adult)
+//   - PerRoomNight: ห้อง × คืน × Adult
+//   - PerSession / PerHour: Adult ครั้งเดียว
+func (p Price) Cost(g Group, nights int) Range {
+	if p.Unknown {
+		return Range{}
+	}
+	if nights < 1 {
+		nights = 1
+	}
+	switch p.Unit {
+	case PerRoomNight:
+		return p.Adult.times(g.Rooms() * nights)
+	case PerSession, PerHour:
+		return p.Adult
+	}
+	adults, children := g.Adults, g.Children
+	if adults+children <= 0 {
+		adults = 1
+	}
+	child := p.Adult
+	if p.Child != nil {
+		child = *p.Child
+	}
+	return p.Adult.times(adults).plus(child.times(children))
+}
+
+func (r Range) times(n int) Range {
+	return Range{Min: r.Min * n, Max: r.Max * n, OpenEnded: r.OpenEnded}
+}
+
+func (r Range) plus(o Range) Range {
+	return Range{Min: r.Min + o.Min, Max: r.Max + o.Max, OpenEnded: r.OpenEnded || o.OpenEnded}
+}
+
+// merge ช่วงที่ครอบทั้งสองช่วง (ช่วงว่าง = ยังไม่มีค่า)
+func (r *Range) merge(o Range, empty bool) {
+	if empty {
+		*r = o
+		return
+	}
+	r.Min, r.Max = min(r.Min, o.Min), max(r.Max, o.Max)
+	r.OpenEnded = r.OpenEnded || o.OpenEnded
+}
+
+// ------------------------------------------------------------
+// parsing
+// ------------------------------------------------------------
+
+var (
+	reNumber   = regexp.MustCompile(`\d[\d,]*(\.\d+)?\s*\+?`)
+	reSegments = regexp.MustCompile(`\s+/\s*|\s*/\s+|,\s+|\s+หรือ\s+`)
+	unitWords  = []struct {
+		unit  PriceUnit
+		words []string
+	}{
+		{PerPerson, []string{"/คน", "คนละ", "/ท่าน", "per person"}},
+		{PerRoomNight, []string{"/คืน", "/ห้อง", "per night"}},
+		{PerSession, []string{"/คลาส", "/รอบ", "/กลุ่ม", "/คณะ"}},
+		{PerHour, []string{"/ชม", "ชั่วโมง", "/hr"}},
+	}
+	foreignWords = []string{"ต่างชาติ", "foreign"}
+	childWords   = []string{"เด็ก", "นักเรียน", "นักศึกษา", "child", "teen", "student"}
+	tierLabels   = []string{"ชาวต่างชาติ", "ต่างชาติ", "คนไทย", "ไทย"}
+)
+
+// normalizeText ช่องว่างแปลก ๆ จาก Excel → ช่องว่างปกติ, ขีดทุกแบบ → "-"
+func normalizeText(s string) string {
+	s = strings.NewReplacer("\u202f", " ", "\u00a0", " ", "\t", " ",
+		"–", "-", "—", "-", "−", "-").Replace(s)
+	return strings.ToLower(strings.TrimSpace(s))
+}
+
+// noData ค่าที่แปลว่าไม่มีข้อมูล (ไม่ใช่ error)
+func noData(s string) bool {
+	switch s {
+	case "", "-", "--", "ไม่ระบุ", "ไม่มีข้อมูล", "n/a", "na":
+		return true
+	}
+	return false
+}
+
+func containsAny(s string, words []string) bool {
+	for _, w := range words {
+		if strings.Contains(s, w) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRange ตัวเลขทั้งหมดในข้อความเป็นช่วง min..max ("+" หรือ "เริ่ม"/"ขึ้นไป" → OpenEnded)
+func parseRange(s string) (Range, bool) {
+	nums := reNumber.FindAllString(s, -1)
+	if len(nums) == 0 {
+		return Range{}, false
+	}
+	r := Range{Min: int(^uint(0) >> 1)}
+	for _, n := range nums {
+		n = strings.TrimSpace(n)
+		if strings.HasSuffix(n, "+") {
+			r.OpenEnded = true
+			n = strings.TrimSpace(strings.TrimSuffix(n, "+"))
+		}
+		f, err := strconv.ParseFloat(strings.ReplaceAll(n, ",", ""), 64)
+		if err != nil {
+			return Range{}, false
+		}
+		v := int(f + 0.5)
+		r.Min, r.Max = min(r.Min, v), max(r.Max, v)
+	}
+	if strings.Contains(s, "เริ่ม") || strings.Contains(s, "ขึ้นไป") {
+		r.OpenEnded = true
+	}
+	return r, true
+}
+
+// notes ข้อความในวงเล็บที่ไม่ใช่ป้ายกลุ่มผู้จ่าย (คนไทย/ต่างชาติ)
+func notes(s string) []string {
+	var out []string
+	for _, m := range reParen.FindAllStringSubmatch(s, -1) {
+		t := strings.TrimSpace(m[1])
+		for _, l := range tierLabels {
+			t = strings.TrimSpace(strings.ReplaceAll(t, l, ""))
+		}
+		if t != "" {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// ParsePrice แปลงข้อความราคาดิบเป็น Price; unit คือหน่วยเมื่อข้อความไม่ระบุ
+// รองรับ "800 - 1,300", "฿60-150/คน", "ฟรี", "~650+ บาท", "เริ่ม 650 บาท/ชม.",
+// "30 บาท (คนไทย) / 200 บาท (ชาวต่างชาติ)", "10 บาท/นักเรียน หรือ 50 บาท/เด็ก", "100 บาท/คน (เด็ก 50 บาท)"
+// ส่วนที่อ่านไม่ออกจะคืน error พร้อมค่าที่ดีที่สุดที่อ่านได้ (อ่านไม่ออกเลย → Unknown)
+func ParsePrice(s string, unit PriceUnit) (Price, error) {
+	p := Price{Unit: unit}
+	t := normalizeText(s)
+	if noData(t) {
+		p.Unknown = true
+		return p, nil
+	}
+
+	var bad, note []string
+	hasAdult, hasChild, hasForeign := false, false, false
+	var child, foreign Range
+	// add ราคาหนึ่งกลุ่ม: label ใช้แยกกลุ่ม (ผู้ใหญ่/เด็ก/ต่างชาติ), num คือข้อความที่มีตัวเลข
+	add := func(label, num string) bool {
+		free := strings.Contains(label, "ฟรี") || strings.Contains(label, "free")
+		r, ok := parseRange(num)
+		switch {
+		case ok && free: // "ฟรี - 100" → 0..100
+			r.Min = 0
+		case free:
+			r = Range{}
+		case !ok:
+			return false
+		}
+
+		switch {
+		case containsAny(label, foreignWords):
+			foreign.merge(r, !hasForeign)
+			hasForeign = true
+		case containsAny(label, childWords):
+			child.merge(r, !hasChild)
+			hasChild = true
+		default:
+			p.Adult.merge(r, !hasAdult)
+			hasAdult = true
+			p.Free = p.Free || (free && r.Max == 0)
+		}
+		return true
+	}
+	for _, seg := range reSegments.Split(t, -1) {
+		seg = strings.TrimSpace(seg)
+		if seg == "" {
+			continue
+		}
+		for _, u := range unitWords {
+			if containsAny(seg, u.words) {
+				p.Unit = u.unit
+				break
+			}
+		}
+		if strings.Contains(seg, "~") || strings.Contains(seg, "ประมาณ") {
+			p.Approx = true
+		}
+
+		seg, tiers := tierParens(seg)
+		note = append(note, notes(seg)...)
+		if !add(seg, reParen.ReplaceAllString(seg, "")) {
+			bad = append(bad, seg)
+		}
+		for _, tier := range tiers {
+			if !add(tier, tier) {
+				bad = append(bad, tier)
+			}
+		}
+	}
+
+	if hasChild {
+		p.Child = &child
+	}
+	if hasForeign {
+		p.Foreign = &foreign
+	}
+	switch {
+	case hasAdult:
+	case hasForeign: // มีแต่ราคาต่างชาติ → ใช้เป็นราคาหลักไปก่อน (เผื่องบไว้)
+		p.Adult = foreign
+	case hasChild:
+		p.Adult = child
+	default:
+		p.Unknown = true
+	}
+	p.Free = p.Free && p.Adult.Max == 0
+	p.Note = strings.Join(note, "; ")
+
+	if len(bad) > 0 {
+		return p, fmt.Errorf("อ่านราคาไม่ออก: %q", strings.Join(bad, " / "))
+	}
+	return p, nil
+}
+
+// tierParens แยกวงเล็บที่เป็นราคาของอีกกลุ่มออกจาก seg: "100 บาท/คน (เด็ก 50 บาท)" → "100 บาท/คน", ["เด็ก 50 บาท"]
+// วงเล็บที่เป็นแค่ป้าย เช่น "(ชาวต่างชาติ)" หรือหมายเหตุ ยังอยู่ใน seg
+func tierParens(seg string) (string, []string) {
+	var tiers []string
+	rest := reParen.ReplaceAllStringFunc(seg, func(m string) string {
+		in := strings.TrimSpace(m[1 : len(m)-1])
+		if reNumber.MatchString(in) && (containsAny(in, childWords) || containsAny(in, foreignWords)) {
+			tiers = append(tiers, in)
+			return ""
+		}
+		return m
+	})
+	return strings.TrimSpace(rest), tiers
+}
+
+// ParsePriceTiers ราคาผู้ใหญ่ + คอลัมน์ราคาเด็ก/นักเรียนแยก (เช่น PriceAdult, PriceTeen ของแลนด์มาร์ก)
+func ParsePriceTiers(adult, child string, unit PriceUnit) (Price, error) {
+	p, err := ParsePrice(adult, unit)
+	c, errC := ParsePrice(child, unit)
+	if !c.Unknown {
+		r := c.Adult
+		p.Child = &r
+		if p.Unknown { // รู้แค่ราคาเด็ก: ผู้ใหญ่ยังไม่รู้
+			p.Adult = Range{}
+		}
+	}
+	switch {
+	case err != nil && errC != nil:
+		return p, fmt.Errorf("%v; ราคาเด็ก: %v", err, errC)
+	case errC != nil:
+		return p, fmt.Errorf("ราคาเด็ก: %v", errC)
+	}
+	return p, err
+}
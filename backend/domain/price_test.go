@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePrice(t *testing.T) {
+	rng := func(lo, hi int) *Range { return &Range{Min: lo, Max: hi} }
+	tests := []struct {
+		in      string
+		unit    PriceUnit // หน่วยตั้งต้น
+		want    Price
+		wantErr bool
+	}{
+		{"800 - 1,300", PerRoomNight, Price{Unit: PerRoomNight, Adult: Range{800, 1300, false}}, false},
+		{"฿60-150/คน", PerSession, Price{Unit: PerPerson, Adult: Range{60, 150, false}}, false},
+		{"ฟรี", PerPerson, Price{Unit: PerPerson, Free: true}, false},
+		{"ฟรี (ต้องจองล่วงหน้า)", PerPerson, Price{Unit: PerPerson, Free: true, Note: "ต้องจองล่วงหน้า"}, false},
+		{"~650+ บาท", PerPerson, Price{Unit: PerPerson, Approx: true, Adult: Range{650, 650, true}}, false},
+		{"เริ่ม 650 บาท/ชม.", PerPerson, Price{Unit: PerHour, Adult: Range{650, 650, true}}, false},
+		{"30 บาท (คนไทย) / 200 บาท (ชาวต่างชาติ)", PerPerson,
+			Price{Unit: PerPerson, Adult: Range{30, 30, false}, Foreign: rng(200, 200)}, false},
+		// มีแต่ราคาเด็ก/นักเรียน → ใช้เป็นราคาหลักด้วย
+		{"10 บาท/นักเรียน หรือ 50 บาท/เด็ก", PerPerson,
+			Price{Unit: PerPerson, Adult: Range{10, 50, false}, Child: rng(10, 50)}, false},
+		// ราคาเด็กในวงเล็บเป็นอีกกลุ่ม ราคาผู้ใหญ่ต้องไม่ถูกนับเป็นราคาเด็ก
+		{"100 บาท/คน (เด็ก 50 บาท)", PerSession,
+			Price{Unit: PerPerson, Adult: Range{100, 100, false}, Child: rng(50, 50)}, false},
+		{"200 บาท (ต่างชาติ 500 บาท)", PerPerson,
+			Price{Unit: PerPerson, Adult: Range{200, 200, false}, Foreign: rng(500, 500)}, false},
+		{"-", PerPerson, Price{Unit: PerPerson, Unknown: true}, false},
+		{"ไม่ระบุ", PerPerson, Price{Unit: PerPerson, Unknown: true}, false},
+		// อ่านไม่ออก → error พร้อมค่าที่ดีที่สุด
+		{"ตามจริง", PerPerson, Price{Unit: PerPerson, Unknown: true}, true},
+		{"100 บาท / สอบถาม", PerPerson, Price{Unit: PerPerson, Adult: Range{100, 100, false}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParsePrice(tt.in, tt.unit)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePrice(%q) = %+v ต้องการ %+v", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePriceTiers(t *testing.T) {
+	tests := []struct {
+		name, adult, child string
+		want               Price
+		wantErr            bool
+	}{
+		{"สองคอลัมน์", "50", "20", Price{Unit: PerPerson, Adult: Range{50, 50, false}, Child: &Range{20, 20, false}}, false},
+		{"ไม่มีราคาเด็ก", "50", "", Price{Unit: PerPerson, Adult: Range{50, 50, false}}, false},
+		{"รู้แค่ราคาเด็ก", "", "20", Price{Unit: PerPerson, Unknown: true, Child: &Range{20, 20, false}}, false},
+		{"ราคาเด็กอ่านไม่ออก", "50", "ครึ่งราคา", Price{Unit: PerPerson, Adult: Range{50, 50, false}}, true},
+		{"อ่านไม่ออกทั้งคู่", "สอบถาม", "ครึ่งราคา", Price{Unit: PerPerson, Unknown: true}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePriceTiers(tt.adult, tt.child, PerPerson)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParsePriceTiers = %+v ต้องการ %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceCost(t *testing.T) {
+	p, _ := ParsePrice("100 บาท/คน (เด็ก 50 บาท)", PerPerson)
+	if got := p.Cost(Group{Adults: 2, Children: 1}, 1); got != (Range{250, 250, false}) {
+		t.Errorf("ผู้ใหญ่ 2 เด็ก 1 = %+v ต้องการ 250", got)
+	}
+	room, _ := ParsePrice("800 - 1,300", PerRoomNight)
+	if got := room.Cost(Group{Adults: 3}, 2); got != (Range{3200, 5200, false}) {
+		t.Errorf("3 คน 2 คืน = %+v ต้องการ 2 ห้อง × 2 คืน", got)
+	}
+}
+
+func TestParseCapacity(t *testing.T) {
+	tests := []struct {
+		people, rooms string
+		want          Capacity
+		wantErr       bool
+	}{
+		{"250", "", Capacity{People: Range{250, 250, false}}, false},
+		{"~300 คน (กลางแจ้ง)", "", Capacity{People: Range{300, 300, false}, Approx: true, Note: "กลางแจ้ง"}, false},
+		{"300-600", "", Capacity{People: Range{300, 600, false}}, false},
+		{"~1,000+ (พื้นที่เปิด)", "", Capacity{People: Range{1000, 1000, true}, Approx: true, Note: "พื้นที่เปิด"}, false},
+		{"~50–100 คน/รอบ (หมู่คณะนัดล่วงหน้า)", "",
+			Capacity{People: Range{50, 100, false}, Approx: true, Per: "รอบ", Note: "หมู่คณะนัดล่วงหน้า"}, false},
+		{"4", "120", Capacity{People: Range{4, 4, false}, Rooms: 120}, false},
+		{"", "", Capacity{Unknown: true}, false},
+		{"ไม่จำกัด", "", Capacity{Unknown: true}, true},
+		{"10", "หลายห้อง", Capacity{People: Range{10, 10, false}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.people+"|"+tt.rooms, func(t *testing.T) {
+			got, err := ParseCapacity(tt.people, tt.rooms)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCapacity(%q, %q) = %+v ต้องการ %+v", tt.people, tt.rooms, got, tt.want)
+			}
+		})
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
 )
 
 // entity/accommodation.go
@@ -23,14 +25,31 @@ type Accommodation struct {
 	ThumbnailURL string    `binding:"omitempty,url"`
 	Time_open    time.Time `binding:"required"`
 	Time_close   time.Time `binding:"required"`
+	Open_days    string // ข้อความวันเปิดดิบ เช่น "ทุกวัน (เว้นวันจันทร์)"
+
+	// เวลาเปิด-ปิดรายวัน (parse จาก Time_open/Time_close/Open_days)
+	OpeningHours domain.Hours `gorm:"serializer:json;type:text" json:"opening_hours"`
 
 	Total_people string `binding:"required"` // เก็บดิบตามไฟล์
 	Price        string `binding:"required"` // เก็บดิบ เช่น "1,000 - 1,400"
 	Review       int    `binding:"omitempty,gte=0"`
 
+	// ราคาต่อห้องต่อคืน + จำนวนคน/ห้องที่ parse แล้ว (ดู entity.Landmark)
+	PriceInfo domain.Price    `gorm:"serializer:json;type:text" json:"price_info"`
+	Capacity  domain.Capacity `gorm:"serializer:json;type:text" json:"capacity"`
+
 	// ใช้คิวรี/คำนวณ
 	PriceMin int `gorm:"index"`
 	PriceMax int `gorm:"index"`
 
+	// SourceHash แฮชแถวของ places_data_3.xlsx ที่ import ล่าสุด (ว่าง = เพิ่มผ่าน API)
+	SourceHash string `gorm:"size:64" json:"-"`
+
+	// ImportSource ไฟล์ที่ import มา หรือ "upload" (prune ของ import ไม่ลบแถวที่อัปโหลด)
+	ImportSource string `gorm:"size:255" json:"-"`
+
+	// SourceRecord ค่าในไฟล์ตอน import ล่าสุด (JSON) export เขียนเวลา check-in/out ตามที่ไฟล์เขียนไว้
+	SourceRecord string `gorm:"type:text" json:"-"`
+
 	Types []TravelType `gorm:"many2many:accommodation_types;constraint:OnDelete:CASCADE;" json:"types,omitempty"`
 }
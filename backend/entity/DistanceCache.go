@@ -0,0 +1,20 @@
+package entity
+
+import "time"
+
+// DistanceCache ระยะเส้นตรงระหว่างสถานที่สองแห่ง (PostGIS) เก็บคู่ละแถวเดียวโดย FromCode < ToCode
+type DistanceCache struct {
+	FromCode  string  `gorm:"primaryKey;size:16"`
+	ToCode    string  `gorm:"primaryKey;size:16;index"`
+	Meters    float64 `gorm:"not null"`
+	UpdatedAt time.Time
+}
+
+// TableName ชื่อเดียวกับที่ SQL ใน distcache ใช้ (ค่าเริ่มต้นของ gorm คือ distance_caches)
+func (DistanceCache) TableName() string { return "distance_cache" }
+
+// DistanceCachePoint พิกัดของสถานที่ ณ ตอนที่คำนวณระยะไว้ (ใช้ตรวจว่าพิกัดเปลี่ยนไปหรือยัง)
+type DistanceCachePoint struct {
+	Code     string `gorm:"primaryKey;size:16"`
+	Location string `gorm:"type:geometry(Point,4326)"`
+}
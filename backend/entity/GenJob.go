@@ -0,0 +1,25 @@
+package entity
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// GenJob งานสร้างทริปแบบ async (POST /gen-route/jobs) เก็บสถานะ + ผลไว้ดึงย้อนหลัง
+type GenJob struct {
+	gorm.Model
+
+	JobID  string `gorm:"uniqueIndex"`
+	Params string // query string ที่ส่งมา เช่น "start=P1&days=2"
+
+	Status string `gorm:"index"` // queued | running | done | failed | cancelled
+	Phase  string // ขั้นล่าสุด เช่น "computing min-cut"
+	Error  string
+
+	Result     string     `gorm:"type:text"` // JSON ของผลลัพธ์ (เฉพาะ done)
+	FinishedAt *time.Time
+
+	// CancelToken ส่งให้ผู้สร้าง job ครั้งเดียวตอน POST; DELETE ต้องแนบมา (รู้แค่ job_id ยกเลิกไม่ได้)
+	CancelToken string `gorm:"size:64" json:"-"`
+}
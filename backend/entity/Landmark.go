@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
 )
 
 // entity/landmark.go
@@ -23,13 +25,36 @@ type Landmark struct {
 	ThumbnailURL string    `binding:"omitempty,url"`
 	Time_open    time.Time `binding:"required"`
 	Time_close   time.Time `binding:"required"`
+	Open_days    string // ข้อความวันเปิดดิบ เช่น "ทุกวัน (เว้นวันจันทร์)"
+
+	// เวลาเปิด-ปิดรายวัน (parse จาก Time_open/Time_close/Open_days)
+	OpeningHours domain.Hours `gorm:"serializer:json;type:text" json:"opening_hours"`
 
 	Total_people string `binding:"required"`
 	Price        string `binding:"required"`
+	PriceChild   string // ราคาเด็ก/นักเรียนดิบ (คอลัมน์ PriceTeen)
 	Review       int    `binding:"omitempty,gte=0"`
 
+	// ราคา/ความจุแบบมีโครงสร้าง (parse จาก Price/Total_people ด้วย domain.ParsePrice/ParseCapacity)
+	// PriceMin/PriceMax = PriceInfo.Bounds() เก็บแยกไว้ให้ query/index ได้
+	// Unit ว่าง = แถวเก่าที่ยังไม่ได้ parse
+	PriceInfo domain.Price    `gorm:"serializer:json;type:text" json:"price_info"`
+	Capacity  domain.Capacity `gorm:"serializer:json;type:text" json:"capacity"`
+
 	PriceMin int `gorm:"index"`
 	PriceMax int `gorm:"index"`
 
+	// SourceHash แฮชของแถวใน Attraction_data_4.xlsx ตอน import ล่าสุด
+	// ว่าง = สร้างผ่าน API (import ไม่ลบแถวแบบนี้แม้ไม่มีในไฟล์)
+	SourceHash string `gorm:"size:64" json:"-"`
+
+	// ImportSource ไฟล์ที่ import แถวนี้มา (config.UploadSource = อัปโหลดผ่าน /admin/imports)
+	// ว่าง = import ก่อนมีคอลัมน์นี้ ถือว่ามาจากไฟล์ของ profile มาตรฐาน; prune ลบเฉพาะแถวของไฟล์ที่กำลัง import
+	ImportSource string `gorm:"size:255" json:"-"`
+
+	// SourceRecord ค่าของแถวตอน import ล่าสุด (JSON field → ข้อความ) ให้ export เขียนกลับได้ตรงตัว
+	// แถวแก้ผ่าน API หลัง import → export ใช้ค่าในตารางแทนค่าที่ไม่ตรงแล้ว
+	SourceRecord string `gorm:"type:text" json:"-"`
+
 	Types []TravelType `gorm:"many2many:landmark_types;constraint:OnDelete:CASCADE;" json:"types,omitempty"`
 }
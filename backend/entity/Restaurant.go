@@ -3,6 +3,8 @@ package entity
 import (
 	"gorm.io/gorm"
 	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
 )
 
 // entity/restaurant.go
@@ -22,13 +24,30 @@ type Restaurant struct {
 	ThumbnailURL string    `binding:"omitempty,url"`
 	Time_open    time.Time
 	Time_close   time.Time
+	Open_days    string // ข้อความวันเปิดดิบ เช่น "ทุกวัน (เว้นวันจันทร์)"
+
+	// เวลาเปิด-ปิดรายวัน (parse จาก Time_open/Time_close/Open_days)
+	OpeningHours domain.Hours `gorm:"serializer:json;type:text" json:"opening_hours"`
 
 	Total_people string `binding:"required"`
 	Price        string `binding:"required"` // เช่น "฿60-150/คน"
 	Review       int    `binding:"omitempty,gte=0"`
 
+	// ราคาต่อคน/จำนวนที่นั่งที่ parse แล้ว (ดู entity.Landmark)
+	PriceInfo domain.Price    `gorm:"serializer:json;type:text" json:"price_info"`
+	Capacity  domain.Capacity `gorm:"serializer:json;type:text" json:"capacity"`
+
 	PriceMin int `gorm:"index"`
 	PriceMax int `gorm:"index"`
 
+	// SourceHash แฮชแถวของ rharn.xlsx (import ข้ามแถวที่แฮชเท่าเดิม; ว่าง = ไม่ได้มาจาก import)
+	SourceHash string `gorm:"size:64" json:"-"`
+
+	// ImportSource ที่มาของแถว (rharn.xlsx / "upload"; ดู entity.Landmark)
+	ImportSource string `gorm:"size:255" json:"-"`
+
+	// SourceRecord แถวดิบตอน import (JSON) ใช้ตอน export
+	SourceRecord string `gorm:"type:text" json:"-"`
+
 	Types []TravelType `gorm:"many2many:restaurant_types;constraint:OnDelete:CASCADE;" json:"types,omitempty"`
 }
@@ -20,6 +20,9 @@ type Shortestpath struct {
 	Distance float32 `binding:"gte=0"`   // ระยะทาง (>= 0)
 
 	ActivityDescription string `binding:"omitempty,max=1000"` // คำบรรยายกิจกรรม ไม่บังคับ
-	StartTime           string `binding:"required"`     // เวลาเริ่ม เช่น "08:00"
-	EndTime             string `binding:"required"`       // เวลาเลิก เช่น "09:00"
+	StartTime           string // เวลาเริ่ม เช่น "08:00" (server คำนวณใหม่ทุกครั้งที่วันนั้นมีการแก้ไข)
+	EndTime             string // เวลาเลิก เช่น "09:00"
+
+	DurationMin int    // เวลาเดินทางของช่วงนี้ (นาที) ตาม Type + ระยะ (server คำนวณ)
+	SuggestMode string // ไกลเกินกว่าจะใช้ Type นี้ → แบบที่แนะนำ (server คำนวณพร้อม DurationMin)
 }
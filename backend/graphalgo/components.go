@@ -0,0 +1,42 @@
+package graphalgo
+
+import "sort"
+
+// Components กลุ่มจุดที่เชื่อมถึงกัน (แทน pgr_connectedComponents)
+// นับเฉพาะจุดที่อยู่ในเส้นอย่างน้อยหนึ่งเส้น แต่ละกลุ่มเรียง id และกลุ่มเรียงตาม id แรก
+func Components(edges []Edge) [][]int {
+	parent := map[int]int{}
+	var find func(x int) int
+	find = func(x int) int {
+		if parent[x] != x {
+			parent[x] = find(parent[x])
+		}
+		return parent[x]
+	}
+	for _, e := range edges {
+		for _, v := range []int{e.Source, e.Target} {
+			if _, ok := parent[v]; !ok {
+				parent[v] = v
+			}
+		}
+		if a, b := find(e.Source), find(e.Target); a != b {
+			if b < a {
+				a, b = b, a
+			}
+			parent[b] = a
+		}
+	}
+
+	groups := map[int][]int{}
+	for v := range parent {
+		root := find(v)
+		groups[root] = append(groups[root], v)
+	}
+	out := make([][]int, 0, len(groups))
+	for _, g := range groups {
+		sort.Ints(g)
+		out = append(out, g)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i][0] < out[j][0] })
+	return out
+}
@@ -0,0 +1,33 @@
+package graphalgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestComponents(t *testing.T) {
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  [][]int
+	}{
+		{"ว่าง", nil, [][]int{}},
+		{
+			"สามกลุ่ม",
+			[]Edge{{Source: 3, Target: 2}, {Source: 1, Target: 2}, {Source: 9, Target: 4}, {Source: 7, Target: 7}},
+			[][]int{{1, 2, 3}, {4, 9}, {7}},
+		},
+		{
+			"เส้นที่มาทีหลังเชื่อมสองกลุ่ม",
+			[]Edge{{Source: 5, Target: 6}, {Source: 1, Target: 2}, {Source: 6, Target: 2}},
+			[][]int{{1, 2, 5, 6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Components(tt.edges); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Components = %v ต้องการ %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+// Package graphalgo อัลกอริทึมกราฟแบบ Go ล้วน ใช้แทน pgRouting เมื่อฐานข้อมูลไม่มี extension
+// (KNN, connected components, max-flow/min-cut, Prim MST) ผลลัพธ์ออกแบบให้ตรงกับฟังก์ชัน pgr_* ที่ใช้อยู่
+package graphalgo
+
+import (
+	"math"
+	"sort"
+)
+
+// ------------------------------------------------------------
+// KNN graph (ตรงกับ JOIN LATERAL ... ORDER BY b.geom <-> a.geom LIMIT k)
+//   - เรียงเพื่อนบ้านด้วยระยะบนระนาบ (องศา) เหมือน <-> ของ geometry 4326
+//   - Dist เป็นเมตรแบบ ST_DistanceSphere
+//   - id ของเส้นเรียงตาม (source, target) เหมือน ROW_NUMBER() OVER (ORDER BY a.id, b.id)
+// ------------------------------------------------------------
+
+// Node จุดในกราฟ (id แบบเดียวกับ node id ของ controller: landmark = landmark_id, ร้าน/ที่พักบวก offset)
+type Node struct {
+	ID  int     `gorm:"column:id"`
+	Lon float64 `gorm:"column:lon"`
+	Lat float64 `gorm:"column:lat"`
+}
+
+// Edge เส้นระหว่างสองจุด (ทิศ source → target ตามลำดับที่หาเพื่อนบ้าน)
+type Edge struct {
+	ID     int
+	Source int
+	Target int
+	Dist   float64 // เมตร
+}
+
+// KNN เส้นจากทุกจุดไปยังเพื่อนบ้านใกล้สุด k จุด
+// maxDeg > 0 = เฉพาะเพื่อนบ้านที่ห่างบนระนาบไม่เกิน maxDeg องศา (เหมือน ST_DWithin บน geometry)
+func KNN(nodes []Node, k int, maxDeg float64) []Edge {
+	if k < 1 || len(nodes) < 2 {
+		return nil
+	}
+	sorted := append([]Node(nil), nodes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	type cand struct {
+		i int
+		d float64
+	}
+	edges := make([]Edge, 0, len(sorted)*min(k, len(sorted)-1))
+	cands := make([]cand, 0, len(sorted))
+	for ai, a := range sorted {
+		cands = cands[:0]
+		for bi, b := range sorted {
+			if bi == ai {
+				continue
+			}
+			d := math.Hypot(a.Lon-b.Lon, a.Lat-b.Lat)
+			if maxDeg > 0 && d > maxDeg {
+				continue
+			}
+			cands = append(cands, cand{bi, d})
+		}
+		// เสมอกัน → id น้อยก่อน (sorted เรียงตาม id อยู่แล้ว)
+		sort.SliceStable(cands, func(i, j int) bool { return cands[i].d < cands[j].d })
+		nb := cands[:min(k, len(cands))]
+		sort.Slice(nb, func(i, j int) bool { return nb[i].i < nb[j].i })
+		for _, c := range nb {
+			b := sorted[c.i]
+			edges = append(edges, Edge{
+				ID:     len(edges) + 1,
+				Source: a.ID,
+				Target: b.ID,
+				Dist:   DistanceM(a.Lat, a.Lon, b.Lat, b.Lon),
+			})
+		}
+	}
+	return edges
+}
+
+// DistanceM ระยะบนผิวโลก (เมตร) รัศมีเดียวกับ ST_DistanceSphere
+func DistanceM(lat1, lon1, lat2, lon2 float64) float64 {
+	const r = 6370986.0
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * r * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,55 @@
+package graphalgo
+
+import (
+	"math"
+	"testing"
+)
+
+func TestKNN(t *testing.T) {
+	// จุดบนเส้นศูนย์สูตร: 1-2-3 ห่างกัน 1° และ 4 อยู่ไกลออกไป
+	nodes := []Node{{ID: 4, Lon: 10}, {ID: 2, Lon: 1}, {ID: 1, Lon: 0}, {ID: 3, Lon: 2}}
+
+	tests := []struct {
+		name   string
+		k      int
+		maxDeg float64
+		want   [][2]int // (source, target) ตามลำดับ id ของเส้น
+	}{
+		{"k=1 เสมอกันเลือก id น้อย", 1, 0, [][2]int{{1, 2}, {2, 1}, {3, 2}, {4, 3}}},
+		{"k=2 เพื่อนบ้านเรียงตาม id", 2, 0, [][2]int{{1, 2}, {1, 3}, {2, 1}, {2, 3}, {3, 1}, {3, 2}, {4, 2}, {4, 3}}},
+		{"maxDeg ตัดจุดไกล", 1, 5, [][2]int{{1, 2}, {2, 1}, {3, 2}}},
+		{"k ใหญ่กว่าจำนวนจุด", 10, 1.5, [][2]int{{1, 2}, {2, 1}, {2, 3}, {3, 2}}},
+		{"k=0", 0, 0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := KNN(nodes, tt.k, tt.maxDeg)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ได้ %d เส้น ต้องการ %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, e := range got {
+				if e.ID != i+1 || e.Source != tt.want[i][0] || e.Target != tt.want[i][1] {
+					t.Errorf("เส้นที่ %d = %+v ต้องการ id=%d %v", i, e, i+1, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestKNNDistance(t *testing.T) {
+	e := KNN([]Node{{ID: 1, Lon: 100, Lat: 0}, {ID: 2, Lon: 101, Lat: 0}}, 1, 0)[0]
+	// 1° บนเส้นศูนย์สูตรด้วยรัศมีของ ST_DistanceSphere
+	if want := 6370986.0 * math.Pi / 180; math.Abs(e.Dist-want) > 0.01 {
+		t.Errorf("Dist = %.3f ต้องการ %.3f", e.Dist, want)
+	}
+}
+
+func TestDistanceM(t *testing.T) {
+	if d := DistanceM(13.75, 100.5, 13.75, 100.5); d != 0 {
+		t.Errorf("จุดเดียวกัน = %v", d)
+	}
+	// ขั้วโลกเหนือ → ใต้ = ครึ่งเส้นรอบวง
+	if d, want := DistanceM(90, 0, -90, 0), 6370986.0*math.Pi; math.Abs(d-want) > 0.01 {
+		t.Errorf("ขั้วถึงขั้ว = %.3f ต้องการ %.3f", d, want)
+	}
+}
@@ -0,0 +1,140 @@
+package graphalgo
+
+import "math"
+
+// ------------------------------------------------------------
+// Max-flow แบบ Dinic (แทน pgr_boykovkolmogorov)
+//   - หลาย source/sink ต่อผ่าน super source/sink ที่จุไม่จำกัด
+//   - ผลเป็นแถว (edge, start_vid, end_vid, flow) ของเส้นที่มี flow เหมือน pgRouting
+//     ค่า max-flow เท่ากันทุกอัลกอริทึม และฝั่ง source ของ min-cut ที่หาจาก residual ก็เหมือนกัน
+// ------------------------------------------------------------
+
+// FlowEdge เส้นที่จุได้ทั้งสองทิศ (ReverseCapacity = ทิศ target → source)
+type FlowEdge struct {
+	ID              int
+	Source, Target  int
+	Capacity        int
+	ReverseCapacity int
+}
+
+// FlowRow flow บนเส้น Edge จาก StartVID ไป EndVID
+type FlowRow struct {
+	Edge     int
+	StartVID int
+	EndVID   int
+	Flow     int
+}
+
+type arc struct {
+	to, rev int // rev = index ของ arc ทิศกลับใน g[to]
+	cap     int64
+}
+
+// MaxFlow flow สูงสุดจาก sources ไป sinks และ flow ของแต่ละเส้น
+// จุดที่อยู่ทั้งใน sources และ sinks ไม่ได้ตรวจ ผู้เรียกต้องกันเอง
+func MaxFlow(edges []FlowEdge, sources, sinks []int) (int64, []FlowRow) {
+	idx := map[int]int{}
+	vid := []int{0, 0} // 0 = super source, 1 = super sink
+	at := func(v int) int {
+		if i, ok := idx[v]; ok {
+			return i
+		}
+		idx[v] = len(vid)
+		vid = append(vid, v)
+		return len(vid) - 1
+	}
+
+	var g [][]arc
+	grow := func() {
+		for len(g) < len(vid) {
+			g = append(g, nil)
+		}
+	}
+	addArc := func(u, v int, c, rc int64) (int, int) {
+		grow()
+		g[u] = append(g[u], arc{to: v, rev: len(g[v]), cap: c})
+		g[v] = append(g[v], arc{to: u, rev: len(g[u]) - 1, cap: rc})
+		return u, len(g[u]) - 1
+	}
+
+	type ref struct{ u, i int }
+	refs := make([]ref, len(edges))
+	for k, e := range edges {
+		u, v := at(e.Source), at(e.Target)
+		grow()
+		ru, ri := addArc(u, v, int64(max(e.Capacity, 0)), int64(max(e.ReverseCapacity, 0)))
+		refs[k] = ref{ru, ri}
+	}
+	const inf = math.MaxInt64 / 4
+	for _, s := range sources {
+		addArc(0, at(s), inf, 0)
+	}
+	for _, t := range sinks {
+		addArc(at(t), 1, inf, 0)
+	}
+	grow()
+
+	n := len(vid)
+	level := make([]int, n)
+	iter := make([]int, n)
+	bfs := func() bool {
+		for i := range level {
+			level[i] = -1
+		}
+		level[0] = 0
+		queue := []int{0}
+		for len(queue) > 0 {
+			u := queue[0]
+			queue = queue[1:]
+			for _, a := range g[u] {
+				if a.cap > 0 && level[a.to] < 0 {
+					level[a.to] = level[u] + 1
+					queue = append(queue, a.to)
+				}
+			}
+		}
+		return level[1] >= 0
+	}
+	var dfs func(u int, f int64) int64
+	dfs = func(u int, f int64) int64 {
+		if u == 1 {
+			return f
+		}
+		for ; iter[u] < len(g[u]); iter[u]++ {
+			a := &g[u][iter[u]]
+			if a.cap <= 0 || level[a.to] != level[u]+1 {
+				continue
+			}
+			if d := dfs(a.to, min(f, a.cap)); d > 0 {
+				a.cap -= d
+				g[a.to][a.rev].cap += d
+				return d
+			}
+		}
+		return 0
+	}
+
+	var total int64
+	for bfs() {
+		for i := range iter {
+			iter[i] = 0
+		}
+		for f := dfs(0, inf); f > 0; f = dfs(0, inf) {
+			total += f
+		}
+	}
+
+	// flow สุทธิของเส้น = ความจุเดิม - ความจุที่เหลือ (ติดลบ = ไหลทิศกลับ)
+	var rows []FlowRow
+	for k, e := range edges {
+		r := refs[k]
+		f := int64(max(e.Capacity, 0)) - g[r.u][r.i].cap
+		switch {
+		case f > 0:
+			rows = append(rows, FlowRow{Edge: e.ID, StartVID: e.Source, EndVID: e.Target, Flow: int(f)})
+		case f < 0:
+			rows = append(rows, FlowRow{Edge: e.ID, StartVID: e.Target, EndVID: e.Source, Flow: int(-f)})
+		}
+	}
+	return total, rows
+}
@@ -0,0 +1,140 @@
+package graphalgo
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// clrsNetwork ตัวอย่างจาก CLRS (s = 1, t = 6) max-flow = 23
+// min-cut ฝั่ง source = {1, 2, 3, 5}: เส้น 2→4 (12), 5→4 (7), 5→6 (4)
+var clrsNetwork = []FlowEdge{
+	{ID: 1, Source: 1, Target: 2, Capacity: 16},
+	{ID: 2, Source: 1, Target: 3, Capacity: 13},
+	{ID: 3, Source: 2, Target: 4, Capacity: 12},
+	{ID: 4, Source: 3, Target: 2, Capacity: 4},
+	{ID: 5, Source: 3, Target: 5, Capacity: 14},
+	{ID: 6, Source: 4, Target: 3, Capacity: 9},
+	{ID: 7, Source: 4, Target: 6, Capacity: 20},
+	{ID: 8, Source: 5, Target: 4, Capacity: 7},
+	{ID: 9, Source: 5, Target: 6, Capacity: 4},
+}
+
+func TestMaxFlow(t *testing.T) {
+	tests := []struct {
+		name           string
+		edges          []FlowEdge
+		sources, sinks []int
+		want           int64
+		sourceSide     []int
+	}{
+		{"CLRS", clrsNetwork, []int{1}, []int{6}, 23, []int{1, 2, 3, 5}},
+		{
+			// เส้นไม่มีทิศ (จุได้ทั้งสองทาง) ไหลทิศกลับได้
+			"reverse capacity",
+			[]FlowEdge{
+				{ID: 1, Source: 2, Target: 1, Capacity: 0, ReverseCapacity: 5},
+				{ID: 2, Source: 2, Target: 3, Capacity: 3, ReverseCapacity: 3},
+			},
+			[]int{1}, []int{3}, 3, []int{1, 2},
+		},
+		{
+			"หลาย source/sink",
+			[]FlowEdge{
+				{ID: 1, Source: 1, Target: 3, Capacity: 2},
+				{ID: 2, Source: 2, Target: 3, Capacity: 2},
+				{ID: 3, Source: 3, Target: 4, Capacity: 3},
+				{ID: 4, Source: 3, Target: 5, Capacity: 10},
+				{ID: 5, Source: 4, Target: 6, Capacity: 10},
+			},
+			[]int{1, 2}, []int{5, 6}, 4, []int{1, 2},
+		},
+		{"ไม่มีทางไปถึง", []FlowEdge{{ID: 1, Source: 1, Target: 2, Capacity: 5}}, []int{1}, []int{3}, 0, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, rows := MaxFlow(tt.edges, tt.sources, tt.sinks)
+			if total != tt.want {
+				t.Fatalf("max-flow = %d ต้องการ %d", total, tt.want)
+			}
+			checkFlow(t, tt.edges, tt.sources, tt.sinks, total, rows)
+			if got := sourceSide(tt.edges, tt.sources, rows); !reflect.DeepEqual(got, tt.sourceSide) {
+				t.Errorf("ฝั่ง source ของ min-cut = %v ต้องการ %v", got, tt.sourceSide)
+			}
+		})
+	}
+}
+
+// checkFlow flow ไม่เกินความจุ, เข้า = ออก ทุกจุดกลาง, ออกจาก source รวม = max-flow
+func checkFlow(t *testing.T, edges []FlowEdge, sources, sinks []int, total int64, rows []FlowRow) {
+	t.Helper()
+	byID := map[int]FlowEdge{}
+	for _, e := range edges {
+		byID[e.ID] = e
+	}
+	net := map[int]int64{}
+	for _, r := range rows {
+		e := byID[r.Edge]
+		limit := e.Capacity
+		if r.StartVID == e.Target {
+			limit = e.ReverseCapacity
+		}
+		if r.Flow <= 0 || r.Flow > limit {
+			t.Errorf("เส้น %d flow %d เกินความจุ %d", r.Edge, r.Flow, limit)
+		}
+		net[r.StartVID] -= int64(r.Flow)
+		net[r.EndVID] += int64(r.Flow)
+	}
+	ends := map[int]bool{}
+	var out int64
+	for _, s := range sources {
+		ends[s] = true
+		out -= net[s]
+	}
+	for _, s := range sinks {
+		ends[s] = true
+	}
+	for v, f := range net {
+		if !ends[v] && f != 0 {
+			t.Errorf("จุด %d flow ไม่สมดุล (%d)", v, f)
+		}
+	}
+	if out != total {
+		t.Errorf("flow ออกจาก source = %d แต่ max-flow = %d", out, total)
+	}
+}
+
+// sourceSide จุดที่ไปถึงได้จาก sources ใน residual graph (ฝั่ง source ของ min-cut)
+func sourceSide(edges []FlowEdge, sources []int, rows []FlowRow) []int {
+	flow := map[[2]int]int{}
+	for _, r := range rows {
+		flow[[2]int{r.Edge, r.StartVID}] = r.Flow
+	}
+	residual := map[int][]int{}
+	for _, e := range edges {
+		fwd, back := flow[[2]int{e.ID, e.Source}], flow[[2]int{e.ID, e.Target}]
+		if e.Capacity-fwd+back > 0 {
+			residual[e.Source] = append(residual[e.Source], e.Target)
+		}
+		if e.ReverseCapacity-back+fwd > 0 {
+			residual[e.Target] = append(residual[e.Target], e.Source)
+		}
+	}
+	seen := map[int]bool{}
+	stack := append([]int(nil), sources...)
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seen[v] {
+			continue
+		}
+		seen[v] = true
+		stack = append(stack, residual[v]...)
+	}
+	out := make([]int, 0, len(seen))
+	for v := range seen {
+		out = append(out, v)
+	}
+	sort.Ints(out)
+	return out
+}
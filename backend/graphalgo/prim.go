@@ -0,0 +1,131 @@
+package graphalgo
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// ------------------------------------------------------------
+// Prim MST + driving distance (แทน pgr_primDD)
+//   - กราฟไม่มีทิศ: เส้นที่ cost < 0 ใช้ reverse_cost แทน ทั้งคู่ < 0 = ไม่ใช้
+//   - ได้ MST ของ component ที่มี root แล้วไล่ DFS จาก root
+//     เก็บเฉพาะจุดที่ระยะตามต้นไม้ (agg_cost) ไม่เกิน maxDist
+//   - ลูกของแต่ละจุดเรียงตาม cost น้อยก่อน (pgRouting ไม่กำหนดลำดับ ให้เทียบเป็นชุดของเส้น)
+//   - root ไม่อยู่ในกราฟ → ได้แถว root แถวเดียว เหมือน pgRouting
+// ------------------------------------------------------------
+
+// CostEdge เส้นที่มีราคา (ReverseCost < 0 = ใช้ Cost ทั้งสองทิศ)
+type CostEdge struct {
+	ID          int
+	Source      int
+	Target      int
+	Cost        float64
+	ReverseCost float64
+}
+
+// MSTRow แถวผลลัพธ์แบบเดียวกับ pgr_primDD (Edge = -1 ที่ root)
+type MSTRow struct {
+	Seq      int
+	Depth    int
+	StartVID int
+	Node     int
+	Edge     int
+	Cost     float64
+	AggCost  float64
+	Pred     int // จุดก่อนหน้าในต้นไม้ (root = root)
+}
+
+type primItem struct {
+	node, from, edge int
+	cost             float64
+}
+
+type primQueue []primItem
+
+func (q primQueue) Len() int { return len(q) }
+func (q primQueue) Less(i, j int) bool {
+	if q[i].cost != q[j].cost {
+		return q[i].cost < q[j].cost
+	}
+	return q[i].edge < q[j].edge
+}
+func (q primQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *primQueue) Push(x any)   { *q = append(*q, x.(primItem)) }
+func (q *primQueue) Pop() any {
+	old := *q
+	x := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return x
+}
+
+// PrimDD MST จาก root ที่ตัดตามระยะ maxDist (ลำดับ DFS)
+func PrimDD(edges []CostEdge, root int, maxDist float64) []MSTRow {
+	type nb struct {
+		to, edge int
+		cost     float64
+	}
+	adj := map[int][]nb{}
+	for _, e := range edges {
+		c := e.Cost
+		if c < 0 {
+			c = e.ReverseCost
+		}
+		if c < 0 {
+			continue
+		}
+		adj[e.Source] = append(adj[e.Source], nb{e.Target, e.ID, c})
+		adj[e.Target] = append(adj[e.Target], nb{e.Source, e.ID, c})
+	}
+
+	// Prim
+	type treeEdge struct {
+		child, edge int
+		cost        float64
+	}
+	children := map[int][]treeEdge{}
+	inTree := map[int]bool{root: true}
+	q := &primQueue{}
+	for _, n := range adj[root] {
+		heap.Push(q, primItem{n.to, root, n.edge, n.cost})
+	}
+	for q.Len() > 0 {
+		it := heap.Pop(q).(primItem)
+		if inTree[it.node] {
+			continue
+		}
+		inTree[it.node] = true
+		children[it.from] = append(children[it.from], treeEdge{it.node, it.edge, it.cost})
+		for _, n := range adj[it.node] {
+			if !inTree[n.to] {
+				heap.Push(q, primItem{n.to, it.node, n.edge, n.cost})
+			}
+		}
+	}
+	for _, ch := range children {
+		sort.Slice(ch, func(i, j int) bool {
+			if ch[i].cost != ch[j].cost {
+				return ch[i].cost < ch[j].cost
+			}
+			return ch[i].child < ch[j].child
+		})
+	}
+
+	// DFS ตัดตามระยะ
+	rows := []MSTRow{{Seq: 1, StartVID: root, Node: root, Edge: -1, Pred: root}}
+	var walk func(u, depth int, agg float64)
+	walk = func(u, depth int, agg float64) {
+		for _, c := range children[u] {
+			a := agg + c.cost
+			if a > maxDist {
+				continue
+			}
+			rows = append(rows, MSTRow{
+				Seq: len(rows) + 1, Depth: depth + 1, StartVID: root,
+				Node: c.child, Edge: c.edge, Cost: c.cost, AggCost: a, Pred: u,
+			})
+			walk(c.child, depth+1, a)
+		}
+	}
+	walk(root, 0, 0)
+	return rows
+}
@@ -0,0 +1,52 @@
+package graphalgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPrimDD(t *testing.T) {
+	// สามเหลี่ยม 1-2-3 (เส้น 1-3 แพงสุดไม่อยู่ในต้นไม้) + หาง 3-4 และเส้นที่ใช้ไม่ได้ 4-5
+	edges := []CostEdge{
+		{ID: 1, Source: 1, Target: 2, Cost: 1, ReverseCost: -1},
+		{ID: 2, Source: 2, Target: 3, Cost: 2, ReverseCost: -1},
+		{ID: 3, Source: 1, Target: 3, Cost: 5, ReverseCost: -1},
+		{ID: 4, Source: 3, Target: 4, Cost: -1, ReverseCost: 1}, // cost < 0 → ใช้ reverse_cost
+		{ID: 5, Source: 4, Target: 5, Cost: -1, ReverseCost: -1},
+		{ID: 6, Source: 2, Target: 6, Cost: 0.5, ReverseCost: -1},
+	}
+	root := MSTRow{Seq: 1, StartVID: 1, Node: 1, Edge: -1, Pred: 1}
+
+	tests := []struct {
+		name    string
+		root    int
+		maxDist float64
+		want    []MSTRow
+	}{
+		{"ทั้งต้น (DFS ลูก cost น้อยก่อน)", 1, 100, []MSTRow{
+			root,
+			{Seq: 2, Depth: 1, StartVID: 1, Node: 2, Edge: 1, Cost: 1, AggCost: 1, Pred: 1},
+			{Seq: 3, Depth: 2, StartVID: 1, Node: 6, Edge: 6, Cost: 0.5, AggCost: 1.5, Pred: 2},
+			{Seq: 4, Depth: 2, StartVID: 1, Node: 3, Edge: 2, Cost: 2, AggCost: 3, Pred: 2},
+			{Seq: 5, Depth: 3, StartVID: 1, Node: 4, Edge: 4, Cost: 1, AggCost: 4, Pred: 3},
+		}},
+		{"ตัดตามระยะ", 1, 3.5, []MSTRow{
+			root,
+			{Seq: 2, Depth: 1, StartVID: 1, Node: 2, Edge: 1, Cost: 1, AggCost: 1, Pred: 1},
+			{Seq: 3, Depth: 2, StartVID: 1, Node: 6, Edge: 6, Cost: 0.5, AggCost: 1.5, Pred: 2},
+			{Seq: 4, Depth: 2, StartVID: 1, Node: 3, Edge: 2, Cost: 2, AggCost: 3, Pred: 2},
+		}},
+		{"root ไม่อยู่ในกราฟ", 99, 100, []MSTRow{{Seq: 1, StartVID: 99, Node: 99, Edge: -1, Pred: 99}}},
+		{"root อยู่กลางต้นไม้", 3, 1, []MSTRow{
+			{Seq: 1, StartVID: 3, Node: 3, Edge: -1, Pred: 3},
+			{Seq: 2, Depth: 1, StartVID: 3, Node: 4, Edge: 4, Cost: 1, AggCost: 1, Pred: 3},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PrimDD(edges, tt.root, tt.maxDist); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PrimDD =\n%+v\nต้องการ\n%+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/gtwndtl/trip-spark-builder/config"
 	"github.com/gtwndtl/trip-spark-builder/controller/Accommodation"
 	"github.com/gtwndtl/trip-spark-builder/controller/Condition"
+	"github.com/gtwndtl/trip-spark-builder/controller/Dataset"
 	"github.com/gtwndtl/trip-spark-builder/controller/Distance"
 	"github.com/gtwndtl/trip-spark-builder/controller/Forgetpassword"
 	"github.com/gtwndtl/trip-spark-builder/controller/GenTrip"
@@ -27,8 +28,7 @@ import (
 func main() {
 	// Database setup
 	config.ConnectionDB()
-	config.SetupDatabase()
-	config.LoadExcelData(config.DB())
+	config.SetupDatabase() // ข้อมูลสถานที่ import แยกด้วย go run ./cmd/dataimport
 	
 	db := config.DB()
 	postgresDB := config.PGDB()
@@ -38,7 +38,7 @@ func main() {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"http://localhost:5173"}, // เปลี่ยนให้ตรงกับ origin frontend
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "X-Job-Token"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
@@ -53,9 +53,10 @@ func main() {
 	distanceCtrl := Distance.NewDistanceController(db, postgresDB)
 	tripsCtrl := Trips.NewTripsController(db)
 	shortestpathCtrl := Shortestpath.NewShortestPathController(db, postgresDB)
-	routeCtrl := &GenTrip.RouteController{}
+	routeCtrl := GenTrip.NewRouteController(db, postgresDB, distanceCtrl)
 	reviewCtrl := Review.ReviewController{DB: db}
 	recommendCtrl := Recommend.RecommendController{DB: db}
+	datasetCtrl := Dataset.NewDatasetController()
 	
 	// Public routes (ไม่ต้องตรวจสอบ token)
 	r.POST("/signinuser", userCtrl.SignInUser)
@@ -68,6 +69,22 @@ func main() {
 	authorized := r.Group("/")
 	authorized.Use(middlewares.AuthMiddleware())
 
+	// admin (ต้องล็อกอิน + User.Type = "admin")
+	admin := authorized.Group("/admin")
+	admin.Use(middlewares.AdminMiddleware(db))
+
+	// นำเข้าชุดข้อมูลสถานที่ (.xlsx/.csv): อัปโหลด → ดู dry-run → ยืนยัน
+	admin.POST("/imports", datasetCtrl.Preview)
+	admin.POST("/imports/:id/apply", datasetCtrl.Apply)
+	admin.DELETE("/imports/:id", datasetCtrl.Discard)
+
+	// ตรวจ/ซ่อมความสอดคล้องระหว่าง SQLite กับ PostGIS
+	admin.GET("/reconcile", datasetCtrl.CheckConsistency)
+	admin.POST("/reconcile", datasetCtrl.Reconcile)
+
+	// ส่งออกชุดข้อมูลสถานที่ (.xlsx/.csv ตามคอลัมน์ของ import, .geojson)
+	r.GET("/exports/:kind", datasetCtrl.Export)
+
 	// Accommodation routes (ต้องล็อกอิน)
 	authorized.POST("/accommodations", accommodationCtrl.Create)
 	r.GET("/accommodations", accommodationCtrl.GetAll)
@@ -111,6 +128,7 @@ func main() {
 	r.GET("/trips/:id", tripsCtrl.GetTripByID)
 	authorized.PUT("/trips/:id", tripsCtrl.UpdateTrip)
 	authorized.DELETE("/trips/:id", tripsCtrl.DeleteTrip)
+	authorized.POST("/trips/:id/replan", routeCtrl.ReplanDays)
 
 	// Shortest Path routes
 	r.POST("/shortest-paths", shortestpathCtrl.CreateShortestPath)
@@ -119,16 +137,26 @@ func main() {
 	r.PUT("/shortest-paths/:id", shortestpathCtrl.UpdateShortestPath)
 	r.DELETE("/shortest-paths/:id", shortestpathCtrl.DeleteShortestPath)
 	r.PUT("/shortest-paths/accommodation/bulk", shortestpathCtrl.BulkUpdateAccommodation)
+	r.POST("/shortest-paths/retime", shortestpathCtrl.RetimeShortestPaths)
 
 	r.GET("/distances", distanceCtrl.GetDistances)
+	r.GET("/distances/matrix", distanceCtrl.GetMatrix)
+	r.POST("/distances/matrix", distanceCtrl.GetMatrix)
 
     r.GET("/gen-route", routeCtrl.GenerateRoute)
+	r.POST("/gen-route/jobs", routeCtrl.CreateJob)
+	r.GET("/gen-route/jobs/:id", routeCtrl.GetJob)
+	r.GET("/gen-route/jobs/:id/events", routeCtrl.JobEvents)
+	r.DELETE("/gen-route/jobs/:id", routeCtrl.CancelJob)
 	r.POST("/api/groq", GroqApi.PostGroq)
 	r.GET("/suggest", distanceCtrl.SuggestPlaces)
 	r.GET("/suggest/accommodations", distanceCtrl.SuggestAccommodations)
+	r.GET("/reachable", distanceCtrl.GetReachable)
+	r.GET("/places/search", distanceCtrl.SearchPlaces)
+	r.GET("/places/typeahead", distanceCtrl.Typeahead)
 	r.GET("/health/components", distanceCtrl.GetComponentsHealth)
 	// 
-	// r.GET("/flow/mincut", distanceCtrl.GetFlowMinCut)
+	r.GET("/flow/mincut", distanceCtrl.GetFlowMinCut)
 	r.GET("/mst/byflow",  distanceCtrl.GetMSTByFlow)
 	r.GET("/mst",        distanceCtrl.GetMSTByFlow) // เพิ่มบรรทัดนี้
 
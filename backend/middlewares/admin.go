@@ -0,0 +1,39 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/gtwndtl/trip-spark-builder/entity"
+)
+
+// UserTypeAdmin ค่า User.Type ของผู้ดูแลระบบ
+const UserTypeAdmin = "admin"
+
+// AdminMiddleware ใช้ต่อจาก AuthMiddleware: ผ่านเฉพาะผู้ใช้ที่ Type = "admin"
+func AdminMiddleware(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		uid, _ := c.Get("user_id")
+		floatID, ok := uid.(float64)
+		if !ok {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "ไม่พบ user ใน token"})
+			c.Abort()
+			return
+		}
+
+		var user entity.User
+		if err := db.Select("id", "type").First(&user, uint(floatID)).Error; err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "ไม่พบผู้ใช้งาน"})
+			c.Abort()
+			return
+		}
+		if user.Type != UserTypeAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "ต้องเป็นผู้ดูแลระบบ"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,187 @@
+package planner
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ------------------------------------------------------------
+// แผนทางเลือก (Options.Alternatives > 1)
+// วางแผนซ้ำด้วยตัวเลือก MST ที่ต่างกัน (penalize/exclude, การแบ่งโซน, น้ำหนัก preference)
+// ตัดแผนที่ซ้ำกัน แล้วเลือก N แผนที่ต่างกันมากที่สุด (เริ่มจากแผนตามที่ขอ)
+// ------------------------------------------------------------
+
+// MaxAlternatives จำนวนแผนสูงสุดต่อคำขอ (แต่ละแผนคือ min-cut + MST หนึ่งรอบ)
+const MaxAlternatives = 5
+
+type variant struct {
+	name  string
+	apply func(*Options)
+}
+
+// variants ลำดับการลองตัวเลือก ตัวแรกคือแผนตามที่ผู้ใช้ขอ
+var variants = []variant{
+	{"requested", func(*Options) {}},
+	{"exclude-cut", func(o *Options) {
+		if o.Mode == "exclude" {
+			o.Mode = "penalize"
+		} else {
+			o.Mode = "exclude"
+		}
+	}},
+	{"auto-zone-top-n", func(o *Options) { o.UseBoykov = false }},
+	{"auto-zone-half", func(o *Options) {
+		o.UseBoykov = false
+		o.NTop = max(o.NTop/2, 1)
+	}},
+	{"no-preference", func(o *Options) { o.Prefer, o.Prefer2, o.Prefer3 = "", "", "" }},
+	{"strong-preference", func(o *Options) { o.W1, o.W2, o.W3 = 0.5, 0.6, 0.7 }},
+	{"mst-order", func(o *Options) { o.Cluster = !o.Cluster }},
+	{"high-penalty", func(o *Options) {
+		o.Mode = "penalize"
+		o.Penalty = math.Max(o.Penalty*2, 2)
+	}},
+}
+
+// candidatesPerAlternative ได้แผนไม่ซ้ำครบ n × ค่านี้แล้วหยุดลอง variant ที่เหลือ
+// (แต่ละ variant คือ min-cut + MST หนึ่งรอบ; ผู้สมัครเท่านี้พอให้ pickDiverse เลือกแผนที่ต่างกันได้)
+const candidatesPerAlternative = 2
+
+// planAlternatives วางแผนตาม variant จากข้อมูลชุดเดียวกันจนได้ผู้สมัครพอ แล้วคืนแผนหลักพร้อม Alternatives
+func planAlternatives(ctx context.Context, opt Options, landmarks, restaurants, accommodations []Place,
+	graph *Graph, fetchMST func(MSTQuery) (*MSTResult, error)) (*Result, error) {
+
+	n := min(opt.Alternatives, MaxAlternatives)
+	var cands []*Result
+	seen := map[string]bool{}
+	for i, v := range variants {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		o := opt
+		v.apply(&o)
+		// แต่ละ variant ผ่าน min-cut/MST/assign ซ้ำ ผู้ฟังต้องแยกได้ว่าเป็นรอบไหน
+		if opt.VariantProgress != nil {
+			o.Progress = func(phase string) { opt.VariantProgress(phase, i+1, len(variants), v.name) }
+		}
+		res, err := PlanTrip(o, landmarks, restaurants, accommodations, graph, fetchMST)
+		if err != nil {
+			if len(cands) == 0 {
+				return nil, err // แผนตามที่ขอยังล้ม แสดงว่าตัวเลือกผิด
+			}
+			continue
+		}
+		sig := itinerarySig(res)
+		if seen[sig] {
+			continue
+		}
+		seen[sig] = true
+		res.Variant = v.name
+		cands = append(cands, res)
+		if len(cands) >= candidatesPerAlternative*n {
+			break
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	picked := pickDiverse(cands, n)
+	for i, r := range picked {
+		sum := 0.0
+		for j, o := range picked {
+			if i != j {
+				sum += itineraryDistance(r, o)
+			}
+		}
+		if len(picked) > 1 {
+			r.Diversity = round3(sum / float64(len(picked)-1))
+		}
+	}
+
+	best := picked[0]
+	best.Alternatives = picked[1:]
+	if len(picked) < n {
+		best.Message = fmt.Sprintf("สร้างเส้นทางสำเร็จ (ได้แผนที่ไม่ซ้ำกัน %d จาก %d แผน)", len(picked), n)
+	}
+	return best, nil
+}
+
+// pickDiverse เลือกแผนแรก แล้วเติมทีละแผนที่ห่างจากที่เลือกไว้มากที่สุด (max-min)
+// เสมอกัน → ระยะรวมสั้นกว่า
+func pickDiverse(cands []*Result, n int) []*Result {
+	if len(cands) <= n {
+		return cands
+	}
+	picked := []*Result{cands[0]}
+	used := map[int]bool{0: true}
+	for len(picked) < n {
+		bestI, bestD := -1, -1.0
+		for i, c := range cands {
+			if used[i] {
+				continue
+			}
+			d := math.Inf(1)
+			for _, p := range picked {
+				d = math.Min(d, itineraryDistance(c, p))
+			}
+			if d > bestD || (d == bestD && c.TotalDistanceKm < cands[bestI].TotalDistanceKm) {
+				bestI, bestD = i, d
+			}
+		}
+		used[bestI] = true
+		picked = append(picked, cands[bestI])
+	}
+	return picked
+}
+
+// itinerarySig ลายเซ็นของแผน (ลำดับจุดรายวัน + ที่พัก) ใช้ตัดแผนซ้ำ
+func itinerarySig(r *Result) string {
+	var b strings.Builder
+	for _, d := range r.TripPlanByDay {
+		for _, p := range d.Plan {
+			b.WriteString(p.ID)
+			b.WriteByte(',')
+		}
+		b.WriteByte('|')
+	}
+	if r.Accommodation != nil {
+		b.WriteString(r.Accommodation.ID)
+	}
+	return b.String()
+}
+
+// itineraryDistance ความต่างของสองแผน 0..1
+// เฉลี่ยของ (1 - Jaccard ของชุดสถานที่) กับ (1 - Jaccard ของคู่ วัน/สถานที่)
+func itineraryDistance(a, b *Result) float64 {
+	placesA, placesB := map[string]bool{}, map[string]bool{}
+	slotsA, slotsB := map[string]bool{}, map[string]bool{}
+	collect := func(r *Result, places, slots map[string]bool) {
+		for _, d := range r.TripPlanByDay {
+			for _, p := range d.Plan {
+				places[p.ID] = true
+				slots[fmt.Sprintf("%d/%s", d.Day, p.ID)] = true
+			}
+		}
+	}
+	collect(a, placesA, slotsA)
+	collect(b, placesB, slotsB)
+	return (2 - jaccard(placesA, placesB) - jaccard(slotsA, slotsB)) / 2
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	inter := 0
+	for k := range a {
+		if b[k] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+func round3(x float64) float64 { return math.Round(x*1000) / 1000 }
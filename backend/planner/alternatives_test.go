@@ -0,0 +1,126 @@
+package planner
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPlanAlternativesStopsEarly(t *testing.T) {
+	src := newFakeSource()
+
+	// MST ต่างกันทุกครั้งที่เรียก (สลับลำดับ P2..P8) → ทุก variant ได้แผนไม่ซ้ำ
+	calls := 0
+	rotating := func(q MSTQuery) (*MSTResult, error) {
+		calls++
+		rows := []MSTRow{{Seq: 1, Node: 1, EdgeID: -1}}
+		pred := 1
+		for i := 0; i < 7; i++ {
+			n := 2 + (i+calls)%7
+			p := pred
+			rows = append(rows, MSTRow{Seq: i + 2, Depth: i + 1, Node: n, EdgeID: i + 1, Pred: &p})
+			pred = n
+		}
+		return &MSTResult{Rows: rows}, nil
+	}
+
+	tests := []struct {
+		name      string
+		n         int
+		fetch     func(MSTQuery) (*MSTResult, error)
+		wantCalls int
+		wantPlans int
+	}{
+		{"ผู้สมัครครบ 2n แล้วหยุด", 2, rotating, 4, 2},
+		{"n สูงสุด", MaxAlternatives, rotating, len(variants), MaxAlternatives},
+		// แผนซ้ำกันหมด → ต้องลองครบทุก variant แล้วได้แผนเดียว
+		{"ซ้ำทั้งหมด", 3, src.MSTByFlow, len(variants), 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls, src.queries = 0, nil
+			opt := testOptions()
+			opt.Days = 1
+			opt.Alternatives = tt.n
+			g, _ := src.Distances(allIDs(src))
+			res, err := planAlternatives(context.Background(), opt, src.landmarks, src.restaurants, src.accommodations, g, tt.fetch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := calls + len(src.queries); got != tt.wantCalls {
+				t.Errorf("เรียก MST %d ครั้ง ต้องการ %d", got, tt.wantCalls)
+			}
+			if got := 1 + len(res.Alternatives); got != tt.wantPlans {
+				t.Errorf("ได้ %d แผน ต้องการ %d", got, tt.wantPlans)
+			}
+			if res.Variant != "requested" {
+				t.Errorf("แผนหลัก = %q ต้องเป็นแผนตามที่ขอ", res.Variant)
+			}
+		})
+	}
+}
+
+// ทุก phase ระหว่างวางแผนแต่ละ variant ต้องระบุว่าเป็นรอบไหน ไม่ปนกันใน Progress
+func TestPlanAlternativesVariantProgress(t *testing.T) {
+	src := newFakeSource()
+	opt := testOptions()
+	opt.Days = 1
+	opt.Alternatives = 2
+	var plain, tagged []string
+	opt.Progress = func(phase string) { plain = append(plain, phase) }
+	opt.VariantProgress = func(phase string, index, total int, name string) {
+		if total != len(variants) || name != variants[index-1].name {
+			t.Errorf("variant %d/%d %q ไม่ตรงกับ variants", index, total, name)
+		}
+		if phase == PhaseAssign {
+			tagged = append(tagged, name)
+		}
+	}
+	g, _ := src.Distances(allIDs(src))
+	if _, err := planAlternatives(context.Background(), opt, src.landmarks, src.restaurants, src.accommodations, g, src.MSTByFlow); err != nil {
+		t.Fatal(err)
+	}
+	if len(plain) != 0 {
+		t.Errorf("Progress ได้ %v ต้องส่งผ่าน VariantProgress ทั้งหมด", plain)
+	}
+	// แผนซ้ำกันหมด → ลองครบทุก variant ตามลำดับ
+	if len(tagged) != len(variants) || tagged[0] != "requested" || tagged[len(tagged)-1] != variants[len(variants)-1].name {
+		t.Errorf("PhaseAssign ของ variant = %v", tagged)
+	}
+}
+
+func allIDs(s *fakeSource) []string {
+	var ids []string
+	for _, group := range [][]Place{s.landmarks, s.restaurants, s.accommodations} {
+		for _, p := range group {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+func TestItineraryDistance(t *testing.T) {
+	plan := func(acc string, days ...[]string) *Result {
+		r := &Result{}
+		if acc != "" {
+			r.Accommodation = &Accommodation{ID: acc}
+		}
+		for i, d := range days {
+			dp := DayPlan{Day: i + 1}
+			for _, id := range d {
+				dp.Plan = append(dp.Plan, PlaceInfo{ID: id})
+			}
+			r.TripPlanByDay = append(r.TripPlanByDay, dp)
+		}
+		return r
+	}
+	a := plan("A1", []string{"P1", "P2"}, []string{"P3"})
+	if d := itineraryDistance(a, a); d != 0 {
+		t.Errorf("แผนเดียวกัน = %v", d)
+	}
+	if sa, sb := itinerarySig(a), itinerarySig(plan("A1", []string{"P2", "P1"}, []string{"P3"})); sa == sb {
+		t.Errorf("ลำดับต่างกันต้องได้ลายเซ็นต่างกัน: %s", sa)
+	}
+	if d := itineraryDistance(a, plan("A2", []string{"P4"})); d <= 0 || d > 1 {
+		t.Errorf("แผนไม่มีจุดร่วม = %v ต้องอยู่ใน (0, 1]", d)
+	}
+}
@@ -0,0 +1,117 @@
+package planner
+
+import (
+	"math"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+)
+
+// ------------------------------------------------------------
+// Budget helpers
+// ------------------------------------------------------------
+
+// splitDailyBudget แบ่งงบรวมเป็นงบต่อวัน: ที่พัก ~55%, อาหาร 2 มื้อ มื้อละ ~12%, ที่เหลือค่าเข้าแลนด์มาร์ก
+func splitDailyBudget(totalBudget, days int) DayBudget {
+	if days <= 0 {
+		days = 1
+	}
+	perDay := totalBudget / days
+	hotel := int(float64(perDay) * 0.55)
+	mealEach := int(float64(perDay) * 0.12)
+	attractions := perDay - hotel - 2*mealEach
+	if attractions < 0 {
+		attractions = 0
+	}
+	return DayBudget{PerDay: perDay, Hotel: hotel, MealEach: mealEach, Attractions: attractions}
+}
+
+// relax ผ่อนงบขึ้น pct% (ปัดแบบ banker's rounding ให้ตรงกับ round() ของ Python)
+func relax(amount, pct int) int {
+	return int(math.RoundToEven(float64(amount) * (1.0 + float64(pct)/100.0)))
+}
+
+// groupPrices สำเนาของ places ที่ PriceMin/PriceMax เป็นราคาที่ทั้งกลุ่มจ่ายต่อครั้ง (ที่พัก = ต่อคืน)
+// ทุกจุดที่เทียบกับงบจึงใช้ PriceMin ได้ตรง ๆ; ผู้ใหญ่ 1 คนได้ราคาเดิม
+func groupPrices(places []Place, g domain.Group) []Place {
+	out := make([]Place, len(places))
+	for i, p := range places {
+		switch {
+		case p.Price != nil && !p.Price.Unknown:
+			c := p.Price.Cost(g, 1)
+			p.PriceMin, p.PriceMax = c.Min, c.Max
+		case isAccommodation(p.ID):
+			p.PriceMin, p.PriceMax = p.PriceMin*g.Rooms(), p.PriceMax*g.Rooms()
+		default:
+			p.PriceMin, p.PriceMax = p.PriceMin*g.Size(), p.PriceMax*g.Size()
+		}
+		out[i] = p
+	}
+	return out
+}
+
+// ------------------------------------------------------------
+// Budget-aware selectors
+// ------------------------------------------------------------
+
+// nearestAccommodationUnderBudget หาที่พักใกล้ centroid ที่ราคาไม่เกินงบ (ผ่อนงบทีละ 10% สูงสุด 5 ครั้ง)
+func nearestAccommodationUnderBudget(accs []Place, lat, lon float64, hotelBudget int) *Place {
+	if len(accs) == 0 {
+		return nil
+	}
+
+	under := func(limit int) []Place {
+		var pool []Place
+		for _, a := range accs {
+			if a.PriceMin <= limit {
+				pool = append(pool, a)
+			}
+		}
+		return pool
+	}
+
+	pool := under(hotelBudget)
+	for step := 0; len(pool) == 0 && step < 5; step++ {
+		hotelBudget = relax(hotelBudget, 10)
+		pool = under(hotelBudget)
+	}
+	if len(pool) == 0 {
+		return nil
+	}
+
+	var nearest *Place
+	minDist := math.Inf(1)
+	for i := range pool {
+		dx := lat - pool[i].Lat
+		dy := lon - pool[i].Lon
+		d := math.Sqrt(dx*dx + dy*dy)
+		if d < minDist {
+			minDist = d
+			nearest = &pool[i]
+		}
+	}
+	return nearest
+}
+
+// ------------------------------------------------------------
+// Spend helpers
+// ------------------------------------------------------------
+
+// computeSpendForDay คำนวณค่าใช้จ่ายจริงของวันจาก nodes ที่เลือกแล้ว
+func computeSpendForDay(day int, nodes []string, lookup map[string]Place, hotelPricePerDay int) DaySpend {
+	s := DaySpend{Day: day}
+	for _, id := range nodes {
+		p, ok := lookup[id]
+		if !ok {
+			continue
+		}
+		switch {
+		case isRestaurant(id):
+			s.Meals += max(0, p.PriceMin)
+		case isLandmark(id):
+			s.Attractions += max(0, p.PriceMin)
+		}
+	}
+	s.Hotel = max(0, hotelPricePerDay)
+	s.Total = s.Hotel + s.Meals + s.Attractions
+	return s
+}
@@ -0,0 +1,333 @@
+package planner
+
+import (
+	"math"
+	"sort"
+)
+
+// ------------------------------------------------------------
+// แบ่งแลนด์มาร์กเป็นกลุ่มตามพื้นที่ ก่อนจัดเส้นทาง (Options.Cluster, ทริปหลายวัน)
+//   - k-means แบบจำกัดขนาด: แต่ละกลุ่มได้จำนวนจุด (และราคาบัตรรวม) ใกล้เคียงกัน
+//   - กลุ่มแรกมี start เสมอ กลุ่มถัดไปเรียงตามกลุ่มที่ใกล้กลุ่มก่อนหน้า
+//   - แต่ละวันเดินในกลุ่มของตัวเอง (ใกล้สุดก่อน) และได้ที่พักของวันนั้นเอง
+// ------------------------------------------------------------
+
+const clusterRounds = 20
+
+type latLon struct{ lat, lon float64 }
+
+// clusterLandmarks แบ่ง ids เป็น k กลุ่ม คืนตามลำดับวัน
+func clusterLandmarks(ids []string, lookup map[string]Place, start string, k int) [][]string {
+	if k <= 1 || len(ids) == 0 {
+		return [][]string{ids}
+	}
+	k = min(k, len(ids))
+
+	pos := func(id string) latLon { p := lookup[id]; return latLon{p.Lat, p.Lon} }
+	dist := func(a, b latLon) float64 { return haversineKm(a.lat, a.lon, b.lat, b.lon) }
+
+	// เริ่มศูนย์กลางแบบ farthest-point (ตัวแรกคือ start) ให้ผลเหมือนเดิมทุกครั้ง
+	cents := []latLon{pos(ids[0])}
+	if _, ok := lookup[start]; ok {
+		cents[0] = pos(start)
+	}
+	for len(cents) < k {
+		far, farD := ids[0], -1.0
+		for _, id := range ids {
+			d := math.Inf(1)
+			for _, c := range cents {
+				d = math.Min(d, dist(pos(id), c))
+			}
+			if d > farD {
+				far, farD = id, d
+			}
+		}
+		cents = append(cents, pos(far))
+	}
+
+	// เพดานต่อกลุ่ม: จำนวนจุด และราคาบัตรรวม (เผื่อจุดที่แพงสุดหนึ่งจุด)
+	capN := (len(ids) + k - 1) / k
+	totalPrice, maxPrice := 0, 0
+	for _, id := range ids {
+		p := max(0, lookup[id].PriceMin)
+		totalPrice += p
+		maxPrice = max(maxPrice, p)
+	}
+	capP := math.MaxInt
+	if totalPrice > 0 {
+		capP = (totalPrice+k-1)/k + maxPrice
+	}
+
+	assign := map[string]int{}
+	for round := 0; round < clusterRounds; round++ {
+		next := assignBalanced(ids, cents, start, capN, capP, lookup, pos, dist)
+
+		changed := len(next) != len(assign)
+		for id, c := range next {
+			if assign[id] != c {
+				changed = true
+			}
+		}
+		assign = next
+		if !changed {
+			break
+		}
+
+		// ศูนย์กลางใหม่ = ค่าเฉลี่ยพิกัดของกลุ่ม
+		sum := make([]latLon, k)
+		cnt := make([]int, k)
+		for id, c := range assign {
+			p := pos(id)
+			sum[c].lat += p.lat
+			sum[c].lon += p.lon
+			cnt[c]++
+		}
+		for c := range cents {
+			if cnt[c] > 0 {
+				cents[c] = latLon{sum[c].lat / float64(cnt[c]), sum[c].lon / float64(cnt[c])}
+			}
+		}
+	}
+
+	groups := make([][]string, k)
+	for _, id := range ids {
+		groups[assign[id]] = append(groups[assign[id]], id)
+	}
+
+	// เรียงวัน: กลุ่มของ start ก่อน แล้วไปกลุ่มที่ศูนย์กลางใกล้กลุ่มก่อนหน้าที่สุด
+	first := 0
+	if c, ok := assign[start]; ok {
+		first = c
+	}
+	order := []int{first}
+	used := map[int]bool{first: true}
+	for len(order) < k {
+		last := cents[order[len(order)-1]]
+		best, bestD := -1, math.Inf(1)
+		for c := range cents {
+			if !used[c] && dist(last, cents[c]) < bestD {
+				best, bestD = c, dist(last, cents[c])
+			}
+		}
+		used[best] = true
+		order = append(order, best)
+	}
+	out := make([][]string, 0, k)
+	for _, c := range order {
+		out = append(out, groups[c])
+	}
+	return out
+}
+
+// assignBalanced จับคู่จุดกับกลุ่มจากคู่ที่ใกล้สุดก่อน ภายใต้เพดานจำนวน/ราคา (start อยู่กลุ่ม 0 เสมอ)
+func assignBalanced(ids []string, cents []latLon, start string, capN, capP int, lookup map[string]Place,
+	pos func(string) latLon, dist func(a, b latLon) float64) map[string]int {
+
+	type pair struct {
+		id string
+		c  int
+		d  float64
+	}
+	pairs := make([]pair, 0, len(ids)*len(cents))
+	for _, id := range ids {
+		for c := range cents {
+			pairs = append(pairs, pair{id, c, dist(pos(id), cents[c])})
+		}
+	}
+	sort.SliceStable(pairs, func(i, j int) bool { return pairs[i].d < pairs[j].d })
+
+	assign := map[string]int{}
+	n := make([]int, len(cents))
+	price := make([]int, len(cents))
+	put := func(id string, c int) {
+		assign[id] = c
+		n[c]++
+		price[c] += max(0, lookup[id].PriceMin)
+	}
+	for _, id := range ids {
+		if id == start {
+			put(id, 0)
+		}
+	}
+	for _, p := range pairs {
+		if _, done := assign[p.id]; done {
+			continue
+		}
+		if n[p.c] < capN && price[p.c]+max(0, lookup[p.id].PriceMin) <= capP {
+			put(p.id, p.c)
+		}
+	}
+	// ที่เหลือ (ติดเพดานราคา) → กลุ่มใกล้สุดที่จำนวนยังไม่เต็ม
+	for _, p := range pairs {
+		if _, done := assign[p.id]; !done && n[p.c] < capN {
+			put(p.id, p.c)
+		}
+	}
+	return assign
+}
+
+// clusterCandidates แลนด์มาร์กที่ใช้แบ่งกลุ่ม: โหนดใน MST (ผ่าน flow/preference มาแล้ว)
+// ถ้าน้อยเกินไปใช้แลนด์มาร์กทั้งหมด
+func (tp *tripPlanner) clusterCandidates(landmarks []Place) []string {
+	var ids []string
+	for _, n := range tp.adj.order {
+		if isLandmark(n) && !tp.visited[n] {
+			if _, ok := tp.lookup[n]; ok {
+				ids = append(ids, n)
+			}
+		}
+	}
+	if len(ids) >= 2*tp.opt.Days {
+		return ids
+	}
+	ids = ids[:0]
+	for _, p := range landmarks {
+		if !tp.visited[p.ID] {
+			ids = append(ids, p.ID)
+		}
+	}
+	return ids
+}
+
+// walkClusters เหมือน walk แต่แต่ละวันเดินเฉพาะในกลุ่มของวันนั้น (ใกล้สุดก่อน)
+func (tp *tripPlanner) walkClusters(groups [][]string) [][]string {
+	for d, group := range groups {
+		if tp.dayCount >= tp.opt.Days {
+			break
+		}
+		for _, id := range tp.nearestOrder(group, d) {
+			if tp.dayCount != d {
+				break // take ตัดวันเมื่อครบ 6 จุด
+			}
+			if tp.visited[id] {
+				continue
+			}
+			tp.visited[id] = true
+			if !tp.canTakeLandmark(id) {
+				continue
+			}
+			at, st := tp.fit(id)
+			if st != slotOpen {
+				tp.deferStop(id, at, st)
+				continue
+			}
+			tp.take(id, at)
+			tp.takeDeferred()
+		}
+		if tp.dayCount == d {
+			if len(tp.current) == 0 {
+				tp.days = append(tp.days, []string{}) // กลุ่มนี้เข้าไม่ได้เลย
+				tp.dayCount++
+			} else {
+				tp.flushDay()
+			}
+		}
+	}
+	return tp.finishDays()
+}
+
+// nearestOrder เรียง group แบบ nearest-neighbor เริ่มจาก start (วันแรก) หรือจุดที่ใกล้จุดสุดท้ายของเมื่อวาน
+func (tp *tripPlanner) nearestOrder(group []string, d int) []string {
+	if len(group) == 0 {
+		return nil
+	}
+	left := append([]string(nil), group...)
+	cur := ""
+	switch {
+	case d == 0:
+		cur = tp.opt.Start
+	case len(tp.days) > 0 && len(tp.days[len(tp.days)-1]) > 0:
+		prev := tp.days[len(tp.days)-1]
+		cur = prev[len(prev)-1]
+	}
+
+	out := make([]string, 0, len(left))
+	for len(left) > 0 {
+		bi := 0
+		if cur != "" {
+			bestD := math.Inf(1)
+			for i, id := range left {
+				if id == cur {
+					bi = i
+					break
+				}
+				if d := tp.km(cur, id); d < bestD {
+					bi, bestD = i, d
+				}
+			}
+		}
+		cur = left[bi]
+		out = append(out, cur)
+		left = append(left[:bi], left[bi+1:]...)
+	}
+	return out
+}
+
+// ------------------------------------------------------------
+// ที่พักรายวัน
+// ------------------------------------------------------------
+
+// chooseAccommodations ที่พักของแต่ละวัน
+//   - must มีที่พัก → ใช้ที่นั้นทุกวัน
+//   - ไม่ได้แบ่งกลุ่ม → ที่เดียวทั้งทริป (centroid ของทุกจุด)
+//   - แบ่งกลุ่ม → แนะนำจาก Source (PostGIS) ต่อวัน ไม่มี/ไม่ผ่านงบ → ใกล้ centroid ของวัน
+func (tp *tripPlanner) chooseAccommodations(days [][]string) []*Place {
+	out := make([]*Place, len(days))
+	if tp.mustAcc != nil || !tp.clustered {
+		acc := tp.chooseAccommodation(days)
+		for i := range out {
+			out[i] = acc
+		}
+		return out
+	}
+
+	for i, day := range days {
+		if len(day) == 0 {
+			if i > 0 {
+				out[i] = out[i-1] // วันว่าง → พักที่เดิม
+			}
+			continue
+		}
+		out[i] = tp.mstAccommodation([][]string{day})
+		if out[i] == nil {
+			out[i] = tp.suggestedAccommodation(day)
+		}
+		if out[i] == nil {
+			out[i] = tp.chooseAccommodation([][]string{day})
+		}
+	}
+	// วันแรก ๆ ที่ว่าง → ใช้ที่พักของวันแรกที่มี
+	for i := len(out) - 2; i >= 0; i-- {
+		if out[i] == nil {
+			out[i] = out[i+1]
+		}
+	}
+	return out
+}
+
+// suggestedAccommodation ที่พักแรกจาก Source ที่ราคาไม่เกินงบ (ผ่อนงบทีละ 10% สูงสุด 5 ครั้ง)
+func (tp *tripPlanner) suggestedAccommodation(day []string) *Place {
+	if tp.opt.suggestAcc == nil {
+		return nil
+	}
+	codes, err := tp.opt.suggestAcc(day)
+	if err != nil {
+		return nil
+	}
+	var cands []Place
+	for _, code := range codes {
+		if p, ok := tp.lookup[code]; ok && isAccommodation(code) {
+			cands = append(cands, p)
+		}
+	}
+	limit := tp.budget.Hotel
+	for step := 0; step <= 5; step++ {
+		for i := range cands {
+			if cands[i].PriceMin <= limit {
+				return &cands[i]
+			}
+		}
+		limit = relax(limit, 10)
+	}
+	return nil
+}
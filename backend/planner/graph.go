@@ -0,0 +1,242 @@
+package planner
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ------------------------------------------------------------
+// Graph ระยะทาง (km) ระหว่างจุด ตามผลของ /distances
+// ------------------------------------------------------------
+
+type Neighbor struct {
+	To       string
+	Distance float64 // km
+}
+
+type Graph struct {
+	adj  map[string][]Neighbor
+	dist map[string]map[string]float64
+}
+
+func NewGraph() *Graph {
+	return &Graph{
+		adj:  map[string][]Neighbor{},
+		dist: map[string]map[string]float64{},
+	}
+}
+
+// Add เพิ่มเส้น from→to (ทิศเดียว; /distances ส่งมาทั้งสองทิศอยู่แล้ว)
+func (g *Graph) Add(from, to string, km float64) {
+	g.adj[from] = append(g.adj[from], Neighbor{To: to, Distance: km})
+	m, ok := g.dist[from]
+	if !ok {
+		m = map[string]float64{}
+		g.dist[from] = m
+	}
+	if _, seen := m[to]; !seen {
+		m[to] = km
+	}
+}
+
+// Dist คืนระยะ from→to ถ้าไม่มีเส้นให้ def (เหมือน edge_dist เดิม)
+func (g *Graph) Dist(from, to string, def float64) float64 {
+	if d, ok := g.dist[from][to]; ok {
+		return d
+	}
+	return def
+}
+
+// Nearest เพื่อนบ้านเรียงจากใกล้ไปไกล (stable ตามลำดับที่ใส่)
+func (g *Graph) Nearest(from string) []Neighbor {
+	out := append([]Neighbor(nil), g.adj[from]...)
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Distance < out[j].Distance })
+	return out
+}
+
+// Without สำเนาของกราฟที่ตัดโหนดใน drop ออก (ทั้งต้นทางและปลายทาง)
+func (g *Graph) Without(drop map[string]bool) *Graph {
+	out := NewGraph()
+	for from, nbrs := range g.adj {
+		if drop[from] {
+			continue
+		}
+		for _, n := range nbrs {
+			if !drop[n.To] {
+				out.Add(from, n.To, n.Distance)
+			}
+		}
+	}
+	return out
+}
+
+// Nodes รายชื่อโหนดต้นทางทั้งหมด (เรียงตามรหัสเพื่อให้ผลคงที่)
+func (g *Graph) Nodes() []string {
+	out := make([]string, 0, len(g.adj))
+	for k := range g.adj {
+		out = append(out, k)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// ------------------------------------------------------------
+// adjacency ของ MST (จำลำดับการใส่ key เหมือน defaultdict ของ Python)
+// ------------------------------------------------------------
+
+type adjacency struct {
+	nbrs  map[string][]string
+	order []string
+}
+
+func newAdjacency() *adjacency {
+	return &adjacency{nbrs: map[string][]string{}}
+}
+
+func (a *adjacency) touch(u string) {
+	if _, ok := a.nbrs[u]; !ok {
+		a.nbrs[u] = []string{}
+		a.order = append(a.order, u)
+	}
+}
+
+func (a *adjacency) has(u, v string) bool {
+	for _, x := range a.nbrs[u] {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *adjacency) link(u, v string) {
+	a.touch(u)
+	a.touch(v)
+	if !a.has(u, v) {
+		a.nbrs[u] = append(a.nbrs[u], v)
+	}
+	if !a.has(v, u) {
+		a.nbrs[v] = append(a.nbrs[v], u)
+	}
+}
+
+func (a *adjacency) neighbors(u string) []string { return a.nbrs[u] }
+func (a *adjacency) degree(u string) int         { return len(a.nbrs[u]) }
+
+// buildMSTAdj สร้าง adjacency ของ MST จากแถว pgr_primDD
+// ถ้าไม่มี pred ให้กู้ parent จากลำดับ DFS (depth)
+// แถวที่ไม่มีรหัส (Source แบบเดิม) ถือว่า node เป็น landmark_id
+func buildMSTAdj(rows []MSTRow) *adjacency {
+	adj := newAdjacency()
+
+	sorted := append([]MSTRow(nil), rows...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Seq < sorted[j].Seq })
+
+	codeOf := func(n int, code string) string {
+		if code != "" {
+			return code
+		}
+		return "P" + strconv.Itoa(n)
+	}
+
+	type frame struct {
+		depth int
+		code  string
+	}
+	var stack []frame
+
+	for _, r := range sorted {
+		d, n := r.Depth, codeOf(r.Node, r.Code)
+
+		// root
+		if r.EdgeID == -1 {
+			stack = []frame{{d, n}}
+			continue
+		}
+
+		var pred string
+		if r.Pred == nil {
+			for len(stack) > 0 && stack[len(stack)-1].depth >= d {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) > 0 && stack[len(stack)-1].depth == d-1 {
+				pred = stack[len(stack)-1].code
+			} else {
+				stack = append(stack, frame{d, n})
+				continue
+			}
+		} else {
+			pred = codeOf(*r.Pred, r.PredCode)
+		}
+
+		adj.link(pred, n)
+		stack = append(stack, frame{d, n})
+	}
+	return adj
+}
+
+// ensureSeedPoints ถ้า start ไม่มีเพื่อนใน MST เลย ให้เติม P ที่ใกล้สุดจากกราฟระยะ
+func ensureSeedPoints(start string, adj *adjacency, g *Graph, needP int) {
+	if adj.degree(start) > 0 {
+		return
+	}
+	added := 0
+	for _, nb := range g.Nearest(start) {
+		if !isLandmark(nb.To) {
+			continue
+		}
+		adj.link(start, nb.To)
+		added++
+		if added >= needP {
+			break
+		}
+	}
+}
+
+// backfillKNNEdges เติมเส้น P–P จากกราฟระยะ เพื่อให้ traversal ไปต่อได้
+// - โหนด P ที่มีเพื่อน < minDegree → เติมได้สูงสุด perNode จาก KNN
+// - จำกัดจำนวนเส้นใหม่ทั้งหมดไม่เกิน maxNewEdges
+func backfillKNNEdges(adj *adjacency, g *Graph, minDegree, perNode, maxNewEdges int) {
+	seen := map[string]bool{}
+	var pNodes []string
+	for _, n := range adj.order {
+		if isLandmark(n) && !seen[n] {
+			seen[n] = true
+			pNodes = append(pNodes, n)
+		}
+	}
+	for _, n := range g.Nodes() {
+		if isLandmark(n) && !seen[n] {
+			seen[n] = true
+			pNodes = append(pNodes, n)
+		}
+	}
+
+	newEdges := 0
+	for _, u := range pNodes {
+		if adj.degree(u) >= minDegree {
+			continue
+		}
+		addedHere := 0
+		for _, nb := range g.Nearest(u) {
+			v := nb.To
+			if !isLandmark(v) || u == v || adj.has(u, v) {
+				continue
+			}
+			adj.link(u, v)
+			addedHere++
+			newEdges++
+			if addedHere >= perNode || newEdges >= maxNewEdges {
+				break
+			}
+		}
+		if newEdges >= maxNewEdges {
+			break
+		}
+	}
+}
+
+func isLandmark(id string) bool      { return strings.HasPrefix(id, "P") }
+func isRestaurant(id string) bool    { return strings.HasPrefix(id, "R") }
+func isAccommodation(id string) bool { return strings.HasPrefix(id, "A") }
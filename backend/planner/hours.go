@@ -0,0 +1,150 @@
+package planner
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// ------------------------------------------------------------
+// นาฬิการายวัน (ใช้เช็กเวลาเปิด-ปิดตอนเดิน MST)
+// เวลาเริ่มวัน/เวลาเที่ยวต่อจุด/ความเร็ว มาจาก opt.Timeline (ตัวเดียวกับที่ Shortestpath ใช้)
+// ------------------------------------------------------------
+
+const maxWaitMin = 30 // รอให้ร้าน/สถานที่เปิดได้ไม่เกินนี้
+
+// slotStatus ผลการเช็กว่าจุดหนึ่งเข้าได้ ณ เวลาปัจจุบันหรือไม่
+type slotStatus int
+
+const (
+	slotOpen       slotStatus = iota // เข้าได้ (อาจรอไม่เกิน maxWaitMin)
+	slotLater                        // เปิดอีกทีช้ากว่าที่รอไหว
+	slotClosedDay                    // ปิดทั้งวัน
+	slotClosedRest                   // วันนี้ไม่มีช่วงที่เปิดพอให้เที่ยวแล้ว
+	slotTooFar                       // ไกลเกินแบบการเดินทางที่บังคับ (เดินอย่างเดียว)
+)
+
+// date วันที่ของวันที่กำลังจัดอยู่
+func (tp *tripPlanner) date() time.Time {
+	return tp.opt.StartDate.AddDate(0, 0, tp.dayCount)
+}
+
+// travelMin เวลาเดินทาง (นาที) จากจุดก่อนหน้าไป id (จุดแรกของวันไม่คิด)
+func (tp *tripPlanner) travelMin(id string) int {
+	if tp.last == "" {
+		return 0
+	}
+	return tp.opt.Timeline.TravelMin(tp.opt.TravelMode, tp.km(tp.last, id))
+}
+
+// km ระยะจากกราฟ ถ้าไม่มีเส้นใช้ระยะตรง
+func (tp *tripPlanner) km(from, to string) float64 {
+	if d := tp.graph.Dist(from, to, -1); d >= 0 {
+		return d
+	}
+	a, b := tp.lookup[from], tp.lookup[to]
+	return haversineKm(a.Lat, a.Lon, b.Lat, b.Lon)
+}
+
+// fit หาเวลาเริ่มที่เข้า id ได้ตามเวลาเปิด-ปิดของวันนั้น
+func (tp *tripPlanner) fit(id string) (int, slotStatus) {
+	dur := tp.opt.Timeline.VisitMin(id)
+	arrive := tp.clock + tp.travelMin(id)
+	if tp.opt.WalkOnly && tp.last != "" && !tp.opt.Timeline.Fits(timeline.Walk, tp.km(tp.last, id)) {
+		return arrive, slotTooFar
+	}
+	h := tp.lookup[id].Hours
+	if h == nil {
+		return arrive, slotOpen
+	}
+	wd := tp.date().Weekday()
+	start, ok := h.NextOpen(wd, arrive, dur)
+	switch {
+	case ok && start-arrive <= maxWaitMin:
+		return start, slotOpen
+	case ok:
+		return start, slotLater
+	case len(h.WindowsOn(wd)) == 0:
+		return arrive, slotClosedDay
+	default:
+		return arrive, slotClosedRest
+	}
+}
+
+// hoursReason ข้อความอธิบายว่าทำไมเข้าไม่ได้
+func (tp *tripPlanner) hoursReason(at int, st slotStatus) string {
+	switch st {
+	case slotLater:
+		return fmt.Sprintf("ยังไม่เปิด (เปิด %s)", domain.FormatClock(at))
+	case slotClosedDay:
+		return "ปิดวัน" + domain.ThaiWeekday(tp.date().Weekday())
+	case slotTooFar:
+		return "ไกลเกินกว่าจะเดินต่อจากจุดก่อนหน้า"
+	default:
+		return fmt.Sprintf("ปิดก่อนเที่ยวเสร็จ (ถึง %s)", domain.FormatClock(at))
+	}
+}
+
+// ------------------------------------------------------------
+// จุดที่ต้องเลื่อน เพราะเวลาเปิด-ปิด
+// ------------------------------------------------------------
+
+type deferredStop struct {
+	id      string
+	fromDay int
+	reason  string
+}
+
+func (tp *tripPlanner) deferStop(id string, at int, st slotStatus) {
+	tp.deferred = append(tp.deferred, deferredStop{id: id, fromDay: tp.dayCount + 1, reason: tp.hoursReason(at, st)})
+}
+
+// takeDeferred ใส่จุดที่เลื่อนไว้ ถ้าตอนนี้เปิดแล้ว (วนจนไม่มีจุดไหนเข้าได้)
+func (tp *tripPlanner) takeDeferred() {
+	for progress := true; progress && tp.dayCount < tp.opt.Days; {
+		progress = false
+		for i, d := range tp.deferred {
+			if !tp.canTakeLandmark(d.id) {
+				continue
+			}
+			at, st := tp.fit(d.id)
+			if st != slotOpen {
+				continue
+			}
+			tp.deferred = append(tp.deferred[:i], tp.deferred[i+1:]...)
+			tp.notices = append(tp.notices, HoursNotice{
+				ID: d.id, Name: nameOr(tp.lookup[d.id], d.id),
+				Day: tp.dayCount + 1, FromDay: d.fromDay,
+				Action: "moved", Reason: d.reason,
+			})
+			tp.take(d.id, at)
+			progress = true
+			break
+		}
+	}
+}
+
+// dropDeferred จุดที่เลื่อนแล้วยังไม่ได้ลงจนจบทริป
+func (tp *tripPlanner) dropDeferred() {
+	for _, d := range tp.deferred {
+		tp.notices = append(tp.notices, HoursNotice{
+			ID: d.id, Name: nameOr(tp.lookup[d.id], d.id),
+			Day: d.fromDay, Action: "dropped", Reason: d.reason,
+		})
+	}
+	tp.deferred = nil
+}
+
+// haversineKm ระยะทางตรงบนผิวโลก (km) ใช้ตอนกราฟระยะไม่มีเส้น
+func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
+	const r = 6371.0
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * r * math.Asin(math.Sqrt(a))
+}
@@ -0,0 +1,69 @@
+package planner
+
+import "math"
+
+// ------------------------------------------------------------
+// MST แบบรวมร้าน/ที่พัก (Options.MSTKinds มี R/A)
+//   - DFS ผ่านโหนดร้าน/ที่พักได้แต่ไม่หยิบเป็นจุดเที่ยว (เป็นทางเชื่อมในโครงสร้าง)
+//   - มื้ออาหาร: ร้านที่ติดกับจุดล่าสุดใน MST ก่อน แล้วค่อยร้านใกล้สุดแบบเดิม
+//   - ที่พัก: ตัวที่ติดกับจุดในแผนมากสุดใน MST (ภายในงบ) ก่อนวิธีเดิม
+// MST แบบแลนด์มาร์กอย่างเดียวไม่มีโหนด R/A จึงได้ผลเหมือนเดิม
+// ------------------------------------------------------------
+
+// mstNeighbors กรอง candidates ให้เหลือเฉพาะตัวที่ติดกับ node ใน MST (ไม่มีเลย → nil)
+func (tp *tripPlanner) mstNeighbors(node string, candidates []string) []string {
+	near := map[string]bool{}
+	for _, nb := range tp.adj.neighbors(node) {
+		near[nb] = true
+	}
+	var out []string
+	for _, id := range candidates {
+		if near[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// mstAccommodation ที่พักในงบที่ติดกับจุดใน days มากสุดใน MST
+// เสมอกัน → ใกล้ centroid กว่า; ไม่มีที่พักใดติดกับแผนเลย → nil
+func (tp *tripPlanner) mstAccommodation(days [][]string) *Place {
+	inPlan := map[string]bool{}
+	var latSum, lonSum float64
+	for _, day := range days {
+		for _, id := range day {
+			if p, ok := tp.lookup[id]; ok && !inPlan[id] {
+				inPlan[id] = true
+				latSum += p.Lat
+				lonSum += p.Lon
+			}
+		}
+	}
+	if len(inPlan) == 0 {
+		return nil
+	}
+	lat, lon := latSum/float64(len(inPlan)), lonSum/float64(len(inPlan))
+
+	var best *Place
+	bestN, bestD := 0, math.Inf(1)
+	for i := range tp.accommodations {
+		a := &tp.accommodations[i]
+		if a.PriceMin > tp.budget.Hotel {
+			continue
+		}
+		n := 0
+		for _, nb := range tp.adj.neighbors(a.ID) {
+			if inPlan[nb] {
+				n++
+			}
+		}
+		if n == 0 {
+			continue
+		}
+		d := math.Hypot(a.Lat-lat, a.Lon-lon)
+		if n > bestN || (n == bestN && d < bestD) {
+			best, bestN, bestD = a, n, d
+		}
+	}
+	return best
+}
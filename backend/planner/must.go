@@ -0,0 +1,247 @@
+package planner
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+)
+
+// ErrBadPlaces must/avoid อ้างรหัสที่ไม่มีในข้อมูล หรือขัดกันเอง
+var ErrBadPlaces = errors.New("รายการ must/avoid ไม่ถูกต้อง")
+
+// ------------------------------------------------------------
+// must / avoid
+//   - avoid: ตัดออกจาก landmarks/restaurants/accommodations + กราฟระยะ + MST
+//   - must:  จุดที่ MST walk ไม่ได้หยิบ จะถูกแทรกลงวันที่เพิ่มระยะน้อยสุด
+// ------------------------------------------------------------
+
+func codeSet(codes []string) map[string]bool {
+	set := make(map[string]bool, len(codes))
+	for _, c := range codes {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			set[c] = true
+		}
+	}
+	return set
+}
+
+// normalizeCodes ตัดช่องว่าง/ตัวพิมพ์ใหญ่ และตัดรหัสซ้ำ (คงลำดับเดิม)
+func normalizeCodes(codes []string) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, c := range codes {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c != "" && !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func withoutCodes(places []Place, drop map[string]bool) []Place {
+	if len(drop) == 0 {
+		return places
+	}
+	out := make([]Place, 0, len(places))
+	for _, p := range places {
+		if !drop[p.ID] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// checkMustAvoid ตรวจว่า must/avoid ใช้ได้กับข้อมูลที่โหลดมา (lookup = หลังตัด avoid แล้ว)
+func checkMustAvoid(opt Options, avoid map[string]bool, lookup map[string]Place) error {
+	if avoid[opt.Start] {
+		return fmt.Errorf("%w: start %s อยู่ใน avoid", ErrBadPlaces, opt.Start)
+	}
+	for _, id := range opt.Must {
+		switch {
+		case avoid[id]:
+			return fmt.Errorf("%w: %s อยู่ทั้งใน must และ avoid", ErrBadPlaces, id)
+		case !isLandmark(id) && !isRestaurant(id) && !isAccommodation(id):
+			return fmt.Errorf("%w: รหัส %s ต้องขึ้นต้นด้วย P/R/A", ErrBadPlaces, id)
+		}
+		if _, ok := lookup[id]; !ok {
+			return fmt.Errorf("%w: ไม่พบสถานที่ %s", ErrBadPlaces, id)
+		}
+	}
+	return nil
+}
+
+// avoidedLandmarkNums landmark_id ของ P ใน avoid (ส่งให้ MST ตัดออก)
+func avoidedLandmarkNums(avoid map[string]bool) []int {
+	var out []int
+	for id := range avoid {
+		if n, err := landmarkNum(id); err == nil {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// avoidedOtherCodes รหัสร้าน/ที่พักใน avoid (ส่งให้ MST แบบรวมทุกประเภท) เรียงเพื่อให้ query คงที่
+func avoidedOtherCodes(avoid map[string]bool) []string {
+	var out []string
+	for id := range avoid {
+		if isRestaurant(id) || isAccommodation(id) {
+			out = append(out, id)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// placeMust แทรกจุด must (P/R) ที่ยังไม่อยู่ในแผน
+// เลือกวันที่สถานที่เปิดก่อน แล้วเลือกตำแหน่งที่ระยะเพิ่มน้อยสุด (เสมอกัน → วันที่จุดน้อยกว่า)
+// ระยะนับขาไป-กลับที่พักด้วย วันว่างจึงเสียระยะ ที่พัก → จุด → ที่พัก ไม่ได้ฟรี
+func (tp *tripPlanner) placeMust() {
+	in := map[string]bool{}
+	for _, day := range tp.days {
+		for _, id := range day {
+			in[id] = true
+		}
+	}
+	anchors := tp.mustAnchors()
+
+	for _, id := range tp.opt.Must {
+		if in[id] || isAccommodation(id) || len(tp.days) == 0 {
+			continue
+		}
+		tp.undefer(id)
+
+		bestDay, bestPos, bestCost, bestOpen := -1, 0, math.Inf(1), false
+		for d, day := range tp.days {
+			open := tp.openOnDay(id, d)
+			if bestDay >= 0 && bestOpen && !open {
+				continue
+			}
+			for pos := 0; pos <= len(day); pos++ {
+				cost := tp.insertCost(anchors[d], day, pos, id)
+				better := bestDay < 0 || (open && !bestOpen) || cost < bestCost ||
+					(cost == bestCost && len(day) < len(tp.days[bestDay]))
+				if better {
+					bestDay, bestPos, bestCost, bestOpen = d, pos, cost, open
+				}
+			}
+		}
+
+		day := tp.days[bestDay]
+		day = append(day[:bestPos], append([]string{id}, day[bestPos:]...)...)
+		tp.days[bestDay] = day
+		in[id] = true
+		delete(tp.remainingR, id)
+
+		if !bestOpen {
+			wd := tp.opt.StartDate.AddDate(0, 0, bestDay).Weekday()
+			tp.notices = append(tp.notices, HoursNotice{
+				ID: id, Name: nameOr(tp.lookup[id], id), Day: bestDay + 1,
+				Action: "forced", Reason: "ปิดวัน" + domain.ThaiWeekday(wd) + " แต่อยู่ในรายการ must",
+			})
+		}
+		tp.retimeDay(bestDay)
+	}
+}
+
+// undefer เอา id ออกจากจุดที่เลื่อนไว้ (must จะถูกแทรกเองจึงไม่นับว่าตกหล่น)
+func (tp *tripPlanner) undefer(id string) {
+	for i, d := range tp.deferred {
+		if d.id == id {
+			tp.deferred = append(tp.deferred[:i], tp.deferred[i+1:]...)
+			return
+		}
+	}
+}
+
+// openOnDay id มีช่วงเปิดในวันที่ d (0-based) ของทริปหรือไม่
+func (tp *tripPlanner) openOnDay(id string, d int) bool {
+	h := tp.lookup[id].Hours
+	if h == nil {
+		return true
+	}
+	return len(h.WindowsOn(tp.opt.StartDate.AddDate(0, 0, d).Weekday())) > 0
+}
+
+// mustAnchors ที่พักโดยประมาณของแต่ละวันก่อนเลือกจริง (ใช้คิดระยะตอนแทรก must; "" = ไม่รู้ที่พัก)
+// must มีที่พัก/ไม่ได้แบ่งกลุ่ม → ที่เดียวทั้งทริป, แบ่งกลุ่ม → ใกล้ centroid ของวัน (วันว่างใช้ของทั้งทริป)
+func (tp *tripPlanner) mustAnchors() []string {
+	id := func(p *Place) string {
+		if p == nil {
+			return ""
+		}
+		return p.ID
+	}
+	trip := id(tp.chooseAccommodation(tp.days))
+	out := make([]string, len(tp.days))
+	for d, day := range tp.days {
+		out[d] = trip
+		if tp.clustered && tp.mustAcc == nil && len(day) > 0 {
+			if a := id(tp.chooseAccommodation([][]string{day})); a != "" {
+				out[d] = a
+			}
+		}
+	}
+	return out
+}
+
+// insertCost ระยะที่เพิ่มขึ้นเมื่อแทรก id ที่ตำแหน่ง pos ของ day (หัว/ท้ายวันต่อกับที่พัก acc)
+func (tp *tripPlanner) insertCost(acc string, day []string, pos int, id string) float64 {
+	prev, next := acc, acc
+	if pos > 0 {
+		prev = day[pos-1]
+	}
+	if pos < len(day) {
+		next = day[pos]
+	}
+	cost := 0.0
+	if prev != "" {
+		cost += tp.km(prev, id)
+	}
+	if next != "" {
+		cost += tp.km(id, next)
+	}
+	if prev != "" && next != "" {
+		cost -= tp.km(prev, next)
+	}
+	return cost
+}
+
+// retimeDay คำนวณเวลาถึง/ออกของทั้งวันใหม่ (หลังแทรกจุด) โดยรอให้เปิดถ้าจำเป็น
+func (tp *tripPlanner) retimeDay(d int) {
+	times, _ := tp.scheduleDay(d, tp.days[d])
+	for id, t := range times {
+		tp.times[id] = t
+	}
+}
+
+// scheduleDay จำลองเวลาของ day (วันที่ d ของทริป) ตามลำดับ
+// คืนเวลาของแต่ละจุด + จำนวนจุดที่ไม่มีช่วงเปิดพอให้เที่ยว
+func (tp *tripPlanner) scheduleDay(d int, day []string) (map[string][2]int, int) {
+	wd := tp.opt.StartDate.AddDate(0, 0, d).Weekday()
+	times := make(map[string][2]int, len(day))
+	miss := 0
+	clock, last := tp.opt.Timeline.DayStart, ""
+	for _, id := range day {
+		at := clock
+		if last != "" {
+			at += tp.opt.Timeline.TravelMin(tp.opt.TravelMode, tp.km(last, id))
+		}
+		dur := tp.opt.Timeline.VisitMin(id)
+		if h := tp.lookup[id].Hours; h != nil {
+			if start, ok := h.NextOpen(wd, at, dur); ok {
+				at = start
+			} else {
+				miss++
+			}
+		}
+		times[id] = [2]int{at, at + dur}
+		clock, last = at+dur, id
+	}
+	return times, miss
+}
@@ -0,0 +1,158 @@
+package planner
+
+// ------------------------------------------------------------
+// จัดลำดับจุดในแต่ละวันใหม่ (หลัง MST walk ซึ่งมักวกไปวนมา)
+//   - ที่พักเป็นจุดเริ่ม/จบของวันเสมอ
+//   - ร้านอาหารอยู่ตำแหน่งเดิม (มื้อไม่เลื่อน) แลนด์มาร์กสลับกันได้เฉพาะในช่วงระหว่างมื้อ
+//   - ระยะจากกราฟระยะ (GetDistances) ถ้าไม่มีเส้นใช้ระยะตรง
+//   - ลำดับใหม่ที่ทำให้เข้าไม่ทันเวลาเปิด-ปิดมากกว่าเดิม → คงลำดับเดิม
+// ------------------------------------------------------------
+
+// maxOptRounds กันวนไม่จบ (ปกติจบใน 2-3 รอบ)
+const maxOptRounds = 50
+
+func (tp *tripPlanner) optimizeDays(days [][]string, accs []*Place) *OptimizeReport {
+	rep := &OptimizeReport{PerDay: make([]DayOptimizeRep, 0, len(days))}
+
+	for d, day := range days {
+		accID := ""
+		if accs[d] != nil {
+			accID = accs[d].ID
+		}
+		before := tp.routeKm(accID, day)
+		dr := DayOptimizeRep{Day: d + 1, BeforeKm: round2(before), AfterKm: round2(before)}
+
+		if len(day) > 2 {
+			next := append([]string(nil), day...)
+			// แบ่งช่วงตามจุดที่ตรึงไว้ (ที่พัก/ร้าน) แล้วจัดลำดับภายในแต่ละช่วง
+			lo := 0
+			for i := 0; i <= len(next); i++ {
+				if i < len(next) && !isRestaurant(next[i]) {
+					continue
+				}
+				from, to := accID, accID
+				if lo > 0 {
+					from = next[lo-1]
+				}
+				if i < len(next) {
+					to = next[i]
+				}
+				tp.optimizeSegment(next[lo:i], from, to)
+				lo = i + 1
+			}
+
+			after := tp.routeKm(accID, next)
+			_, oldMiss := tp.scheduleDay(d, day)
+			times, newMiss := tp.scheduleDay(d, next)
+			switch {
+			case after >= before-1e-9:
+				// ไม่ดีขึ้น
+			case newMiss > oldMiss:
+				dr.Kept = true
+			default:
+				copy(day, next)
+				for id, t := range times {
+					tp.times[id] = t
+				}
+				dr.AfterKm = round2(after)
+			}
+		}
+
+		rep.BeforeKm += dr.BeforeKm
+		rep.AfterKm += dr.AfterKm
+		rep.PerDay = append(rep.PerDay, dr)
+	}
+	rep.BeforeKm, rep.AfterKm = round2(rep.BeforeKm), round2(rep.AfterKm)
+	return rep
+}
+
+// routeKm ระยะรวมของวัน: ที่พัก → จุดแรก → … → จุดสุดท้าย → ที่พัก (accID "" = ไม่นับขาไป-กลับ)
+func (tp *tripPlanner) routeKm(accID string, day []string) float64 {
+	if len(day) == 0 {
+		return 0
+	}
+	total := 0.0
+	for i := 0; i+1 < len(day); i++ {
+		total += tp.km(day[i], day[i+1])
+	}
+	if accID != "" {
+		total += tp.km(accID, day[0]) + tp.km(day[len(day)-1], accID)
+	}
+	return total
+}
+
+// optimizeSegment จัดลำดับ seg (แก้ใน slice เดิม) โดยหัว-ท้ายต่อกับ from/to ที่ตรึงไว้ ("" = ปลายเปิด)
+// 2-opt (กลับด้านช่วง) สลับกับ Or-opt (ย้ายก้อน 1-3 จุด) จนไม่มีอะไรดีขึ้น
+func (tp *tripPlanner) optimizeSegment(seg []string, from, to string) {
+	if len(seg) < 2 {
+		return
+	}
+	cost := func(s []string) float64 {
+		total := 0.0
+		for i := 0; i+1 < len(s); i++ {
+			total += tp.km(s[i], s[i+1])
+		}
+		if from != "" {
+			total += tp.km(from, s[0])
+		}
+		if to != "" {
+			total += tp.km(s[len(s)-1], to)
+		}
+		return total
+	}
+
+	best := cost(seg)
+	for round := 0; round < maxOptRounds; round++ {
+		improved := false
+
+		// 2-opt
+		for i := 0; i < len(seg)-1; i++ {
+			for j := i + 1; j < len(seg); j++ {
+				reverse(seg[i : j+1])
+				if c := cost(seg); c < best-1e-9 {
+					best, improved = c, true
+				} else {
+					reverse(seg[i : j+1])
+				}
+			}
+		}
+
+		// Or-opt
+		for n := 1; n <= 3 && n < len(seg); n++ {
+			for i := 0; i+n <= len(seg); i++ {
+				for j := 0; j <= len(seg)-n; j++ {
+					if j == i {
+						continue
+					}
+					cand := moveChunk(seg, i, n, j)
+					if c := cost(cand); c < best-1e-9 {
+						copy(seg, cand)
+						best, improved = c, true
+					}
+				}
+			}
+		}
+
+		if !improved {
+			return
+		}
+	}
+}
+
+func reverse(s []string) {
+	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+		s[i], s[j] = s[j], s[i]
+	}
+}
+
+// moveChunk สำเนาของ s ที่ย้าย s[i:i+n] ไปเริ่มที่ตำแหน่ง j (นับหลังตัดก้อนออกแล้ว)
+func moveChunk(s []string, i, n, j int) []string {
+	rest := make([]string, 0, len(s)-n)
+	rest = append(rest, s[:i]...)
+	rest = append(rest, s[i+n:]...)
+	out := make([]string, 0, len(s))
+	out = append(out, rest[:j]...)
+	out = append(out, s[i:i+n]...)
+	out = append(out, rest[j:]...)
+	return out
+}
@@ -0,0 +1,673 @@
+// Package planner วางแผนทริปรายวัน (MST walk + แทรกร้านอาหาร + คุมงบต่อวัน)
+// พอร์ตมาจาก plan_trip ใน Code.py เดิม ให้ GenTrip เรียกได้ในโปรเซสเดียวกัน
+package planner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// ErrBadStart start ต้องเป็นรหัสแลนด์มาร์ก เช่น P151
+var ErrBadStart = errors.New("start ต้องเป็นรหัสสถานที่ท่องเที่ยว เช่น P151")
+
+// Plan โหลดข้อมูลจาก Source แล้ววางแผนทริป
+// ctx ถูกยกเลิกระหว่างทาง → คืน ctx.Err() (ตรวจระหว่างแต่ละขั้น)
+func Plan(ctx context.Context, src Source, opt Options) (*Result, error) {
+	if _, err := landmarkNum(opt.Start); err != nil {
+		return nil, err
+	}
+
+	if s, ok := src.(AccommodationSuggester); ok {
+		opt.suggestAcc = s.SuggestAccommodations
+	}
+
+	opt.progress(PhaseLoading)
+	landmarks, restaurants, accommodations, err := src.Places()
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("โหลดข้อมูลสถานที่ไม่สำเร็จ: %w", err)
+	}
+
+	// รวม id ทั้งหมดไปขอกราฟระยะ
+	ids := []string{opt.Start}
+	for _, group := range [][]Place{landmarks, restaurants, accommodations} {
+		for _, p := range group {
+			if p.ID != opt.Start {
+				ids = append(ids, p.ID)
+			}
+		}
+	}
+	opt.progress(PhaseDistances)
+	graph, err := src.Distances(ids)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("โหลดกราฟระยะไม่สำเร็จ: %w", err)
+	}
+
+	var res *Result
+	if opt.Alternatives > 1 {
+		res, err = planAlternatives(ctx, opt, landmarks, restaurants, accommodations, graph, src.MSTByFlow)
+	} else {
+		res, err = PlanTrip(opt, landmarks, restaurants, accommodations, graph, src.MSTByFlow)
+	}
+	// PlanTrip ไม่ถือว่า MST ล้มเป็น error (ไป KNN ต่อ) จึงต้องเช็กการยกเลิกซ้ำตรงนี้
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	opt.progress(PhaseDone)
+	return res, nil
+}
+
+// PlanTrip วางแผนจากข้อมูลที่โหลดมาแล้ว
+//   - ที่พัก: เลือกที่ราคาไม่เกิน budget ต่อวัน
+//   - ร้าน: เลือก 2 ร้าน/วัน ราคาไม่เกิน budget ต่อมื้อ (fallback เป็นใกล้สุด)
+//   - แลนด์มาร์ก: ฟรีก่อน แล้วค่อยเสียเงิน แต่จำกัดรวมไม่เกิน budget attractions/วัน
+//   - ส่ง prefer/prefer2/prefer3 + w1/w2/w3 ไปยัง MST เพื่อ bias เส้นทาง
+//
+// ถ้า fetchMST ล้มเหลว จะวางแผนต่อจาก KNN backfill อย่างเดียว (เหมือน Code.py)
+func PlanTrip(opt Options, landmarks, restaurants, accommodations []Place, graph *Graph,
+	fetchMST func(MSTQuery) (*MSTResult, error)) (*Result, error) {
+
+	root, err := landmarkNum(opt.Start)
+	if err != nil {
+		return nil, err
+	}
+	if opt.Days < 1 {
+		opt.Days = 1
+	}
+	if opt.TotalBudget < 0 {
+		opt.TotalBudget = 0
+	}
+	if graph == nil {
+		graph = NewGraph()
+	}
+	if opt.Timeline.Visit == nil {
+		opt.Timeline = timeline.DefaultConfig()
+	}
+	if opt.WalkOnly {
+		opt.TravelMode = timeline.Walk
+	}
+	if opt.TravelMode == "" {
+		opt.TravelMode = timeline.Car
+	}
+	if opt.StartDate.IsZero() {
+		opt.StartDate = time.Now().In(domain.Bangkok)
+	}
+	y, m, d := opt.StartDate.Date()
+	opt.StartDate = time.Date(y, m, d, 0, 0, 0, 0, domain.Bangkok)
+
+	// avoid: ตัดออกตั้งแต่ต้น ทั้งรายการและกราฟระยะ
+	avoid := codeSet(opt.Avoid)
+	opt.Must = normalizeCodes(opt.Must)
+	if len(avoid) > 0 {
+		landmarks = withoutCodes(landmarks, avoid)
+		restaurants = withoutCodes(restaurants, avoid)
+		accommodations = withoutCodes(accommodations, avoid)
+		graph = graph.Without(avoid)
+	}
+
+	// ราคาต่อกลุ่ม (จำนวนคน/ห้อง) ก่อนเทียบงบทุกขั้น
+	landmarks = groupPrices(landmarks, opt.Group)
+	restaurants = groupPrices(restaurants, opt.Group)
+	accommodations = groupPrices(accommodations, opt.Group)
+
+	lookup := make(map[string]Place, len(landmarks)+len(restaurants)+len(accommodations))
+	for _, group := range [][]Place{landmarks, restaurants, accommodations} {
+		for _, p := range group {
+			lookup[p.ID] = p
+		}
+	}
+
+	if err := checkMustAvoid(opt, avoid, lookup); err != nil {
+		return nil, err
+	}
+
+	// แบ่งงบต่อวัน
+	budget := splitDailyBudget(opt.TotalBudget, opt.Days)
+
+	// 1) โซน
+	zoneA, zoneB := "", ""
+	if opt.UseBoykov {
+		zoneA, zoneB = pickZonesFromCoords(landmarks, opt.Start, 4, 4)
+	}
+
+	// 2) MST แบบ flow (พร้อม preferences)
+	var mstRows []MSTRow
+	if fetchMST != nil {
+		res, err := fetchMST(MSTQuery{
+			Root:     root,
+			ZoneA:    zoneA,
+			ZoneB:    zoneB,
+			Distance: opt.Distance,
+			K:        opt.K,
+			KMst:     opt.KMst,
+			Mode:     opt.Mode,
+			Penalty:  opt.Penalty,
+			NTop:     opt.NTop,
+			Prefer:   opt.Prefer,
+			Prefer2:  opt.Prefer2,
+			Prefer3:  opt.Prefer3,
+			W1:       opt.W1,
+			W2:       opt.W2,
+			W3:       opt.W3,
+			Kinds:    opt.MSTKinds,
+			WR:       opt.WR,
+			WA:       opt.WA,
+			PreferR:  opt.PreferR,
+			PreferA:  opt.PreferA,
+			WPreferR: opt.WPreferR,
+			WPreferA: opt.WPreferA,
+			Exclude:  avoidedLandmarkNums(avoid),
+			Progress: opt.Progress,
+
+			ExcludeCodes: avoidedOtherCodes(avoid),
+		})
+		if err == nil && res != nil {
+			mstRows = res.Rows
+		}
+	}
+	adj := buildMSTAdj(mstRows)
+	ensureSeedPoints(opt.Start, adj, graph, 4)
+	backfillKNNEdges(adj, graph, 2, 3, 800)
+
+	// 3) เดิน MST แบ่งวัน
+	opt.progress(PhaseAssign)
+	tp := &tripPlanner{
+		opt:         opt,
+		lookup:      lookup,
+		graph:       graph,
+		adj:         adj,
+		budget:      budget,
+		restaurants: restaurants,
+		remainingR:  make(map[string]bool, len(restaurants)),
+		visited:     map[string]bool{},
+		clock:       opt.Timeline.DayStart,
+		times:       map[string][2]int{},
+
+		accommodations: accommodations,
+	}
+	for _, r := range restaurants {
+		tp.remainingR[r.ID] = true
+	}
+	for id := range avoid {
+		tp.visited[id] = true // MST ที่ไม่ได้กรอง (Source อื่น) ก็ไม่เดินเข้า
+	}
+	for _, id := range opt.Must {
+		if isAccommodation(id) {
+			p := lookup[id]
+			tp.mustAcc = &p
+			break
+		}
+	}
+	var days [][]string
+	if opt.Cluster && opt.Days > 1 {
+		// แบ่งกลุ่มตามพื้นที่ก่อน แล้วเดินทีละกลุ่ม
+		tp.clustered = true
+		groups := clusterLandmarks(tp.clusterCandidates(landmarks), lookup, opt.Start, opt.Days)
+		days = tp.walkClusters(groups)
+	} else {
+		days = tp.walk()
+	}
+	accs := tp.chooseAccommodations(days)
+
+	// 4) จัดลำดับในแต่ละวันใหม่ (2-opt / Or-opt) ให้ระยะสั้นลง
+	var optRep *OptimizeReport
+	if opt.Optimize {
+		optRep = tp.optimizeDays(days, accs)
+	}
+
+	res := tp.summarize(days, accs)
+	res.Optimization = optRep
+	return res, nil
+}
+
+// ------------------------------------------------------------
+// MST walk
+// ------------------------------------------------------------
+
+type tripPlanner struct {
+	opt         Options
+	lookup      map[string]Place
+	graph       *Graph
+	adj         *adjacency
+	budget      DayBudget
+	restaurants []Place
+	remainingR  map[string]bool
+
+	accommodations []Place
+	mustAcc        *Place // ที่พักใน must (ใช้แทนการเลือกตามงบ)
+	clustered      bool   // แบ่งวันตามกลุ่มพื้นที่ → ที่พักรายวัน
+
+	days             [][]string
+	current          []string
+	pCount           int
+	dayCount         int
+	attractionsSpent int
+	visited          map[string]bool
+
+	// เวลาเปิด-ปิด
+	clock    int               // นาทีนับจากเที่ยงคืนของวันปัจจุบัน
+	last     string            // จุดล่าสุดของวัน ("" = ยังไม่ออกจากที่พัก)
+	times    map[string][2]int // id → [ถึง, ออก]
+	deferred []deferredStop
+	notices  []HoursNotice
+}
+
+func (tp *tripPlanner) walk() [][]string {
+	// เริ่มจาก start
+	tp.dfs(tp.opt.Start)
+
+	// ถ้ายังไม่ครบวัน ลองเริ่มจาก P อื่น ๆ (degree มากก่อน)
+	if tp.dayCount < tp.opt.Days {
+		var rest []string
+		for _, n := range tp.adj.order {
+			if isLandmark(n) && !tp.visited[n] {
+				rest = append(rest, n)
+			}
+		}
+		sort.SliceStable(rest, func(i, j int) bool { return tp.adj.degree(rest[i]) > tp.adj.degree(rest[j]) })
+		for _, p := range rest {
+			if tp.dayCount >= tp.opt.Days {
+				break
+			}
+			if !tp.visited[p] {
+				tp.dfs(p)
+			}
+		}
+	}
+
+	if len(tp.current) > 0 && tp.dayCount < tp.opt.Days {
+		tp.flushDay()
+	}
+	return tp.finishDays()
+}
+
+// finishDays เติมวันว่างให้ครบ แทรกจุด must แล้วสรุปจุดที่เลื่อนแต่ไม่ได้ลง
+func (tp *tripPlanner) finishDays() [][]string {
+	for tp.dayCount < tp.opt.Days {
+		tp.days = append(tp.days, []string{})
+		tp.dayCount++
+	}
+	tp.placeMust()
+	tp.dropDeferred()
+	return tp.days
+}
+
+func (tp *tripPlanner) dfs(node string) {
+	if tp.dayCount >= tp.opt.Days {
+		return
+	}
+	tp.visited[node] = true
+
+	// ร้าน/ที่พักใน MST แบบรวมทุกประเภทเป็นทางผ่าน (ร้านยังใช้เป็นมื้ออาหารได้)
+	if !isLandmark(node) {
+		tp.descend(node)
+		return
+	}
+
+	if !tp.canTakeLandmark(node) {
+		tp.descend(node)
+		return
+	}
+
+	// ยังไม่เปิด/ปิดวันนั้น → เลื่อนไว้ก่อน แล้วเดินต่อ
+	at, st := tp.fit(node)
+	if st != slotOpen {
+		tp.deferStop(node, at, st)
+		tp.descend(node)
+		return
+	}
+
+	tp.take(node, at)
+	tp.takeDeferred()
+	if tp.dayCount >= tp.opt.Days {
+		return
+	}
+
+	tp.descend(node)
+}
+
+// take ใส่ node ลงวันปัจจุบันที่เวลา at (+ ร้านตามจังหวะ, ตัดวันเมื่อครบ)
+func (tp *tripPlanner) take(node string, at int) {
+	tp.current = append(tp.current, node)
+	tp.advance(node, at)
+	if isLandmark(node) {
+		tp.pCount++
+		tp.afterTakeLandmark(node)
+	}
+
+	// ใส่ร้านเมื่อครบจังหวะ (2 และ 4 สถานที่)
+	if tp.pCount == 2 || tp.pCount == 4 {
+		if r, rAt := tp.insertRestaurant(node); r != "" {
+			tp.current = append(tp.current, r)
+			tp.advance(r, rAt)
+		}
+	}
+
+	// จำกัดกิจกรรม/วันให้พอเหมาะ
+	if len(tp.current) >= 6 {
+		tp.flushDay()
+	}
+}
+
+// advance เดินนาฬิกาหลังเข้า id ที่เวลา at (อยู่นานตาม Timeline.VisitMin)
+func (tp *tripPlanner) advance(id string, at int) {
+	dur := tp.opt.Timeline.VisitMin(id)
+	tp.times[id] = [2]int{at, at + dur}
+	tp.clock = at + dur
+	tp.last = id
+}
+
+// descend ไปต่อ DFS ตามเพื่อนบ้านใน MST
+func (tp *tripPlanner) descend(node string) {
+	for _, nxt := range tp.adj.neighbors(node) {
+		if !tp.visited[nxt] && tp.dayCount < tp.opt.Days {
+			tp.dfs(nxt)
+		}
+	}
+}
+
+func (tp *tripPlanner) flushDay() {
+	if len(tp.current) == 0 {
+		return
+	}
+	tp.days = append(tp.days, tp.current)
+	tp.current = nil
+	tp.pCount = 0
+	tp.dayCount++
+	tp.attractionsSpent = 0
+	tp.clock = tp.opt.Timeline.DayStart
+	tp.last = ""
+}
+
+func (tp *tripPlanner) canTakeLandmark(id string) bool {
+	p, ok := tp.lookup[id]
+	if !ok || p.PriceMin <= 0 {
+		return true
+	}
+	return tp.attractionsSpent+p.PriceMin <= tp.budget.Attractions
+}
+
+func (tp *tripPlanner) afterTakeLandmark(id string) {
+	if p, ok := tp.lookup[id]; ok && p.PriceMin > 0 {
+		tp.attractionsSpent += p.PriceMin
+	}
+}
+
+// insertRestaurant เลือกร้านที่ใกล้ current ที่สุดที่ราคาไม่เกินงบต่อมื้อ (ถ้าไม่มีเลย ใช้ร้านใกล้สุด)
+// ร้านที่ติดกับ current ใน MST (แบบรวมร้าน) ได้ก่อน
+// เลือกเฉพาะร้านที่เปิดตอนไปถึง; ไม่มีร้านเปิดเลย → ข้ามมื้อนั้นพร้อม notice
+func (tp *tripPlanner) insertRestaurant(current string) (string, int) {
+	var all, affordable []string
+	at := map[string]int{}
+	for _, r := range tp.restaurants {
+		if !tp.remainingR[r.ID] {
+			continue
+		}
+		t, st := tp.fit(r.ID)
+		if st != slotOpen {
+			continue
+		}
+		at[r.ID] = t
+		all = append(all, r.ID)
+		if r.PriceMin <= tp.budget.MealEach {
+			affordable = append(affordable, r.ID)
+		}
+	}
+	candidates := affordable
+	if len(candidates) == 0 {
+		candidates = all
+	}
+	if len(candidates) == 0 {
+		if len(tp.remainingR) > 0 {
+			tp.notices = append(tp.notices, HoursNotice{
+				Day: tp.dayCount + 1, Action: "dropped",
+				Reason: fmt.Sprintf("ไม่มีร้านอาหารเปิดช่วง %s", domain.FormatClock(tp.clock)),
+			})
+		}
+		return "", 0
+	}
+
+	if near := tp.mstNeighbors(current, candidates); len(near) > 0 {
+		candidates = near
+	}
+
+	best, bestD := candidates[0], math.Inf(1)
+	for _, rid := range candidates {
+		if d := tp.graph.Dist(current, rid, math.Inf(1)); d < bestD {
+			best, bestD = rid, d
+		}
+	}
+	delete(tp.remainingR, best)
+	return best, at[best]
+}
+
+// ------------------------------------------------------------
+// summary (รายวัน + ที่พัก + เส้นทาง + ค่าใช้จ่าย)
+// ------------------------------------------------------------
+
+// chooseAccommodation ที่พักใน must, ที่พักที่ติดกับแผนใน MST (แบบรวมที่พัก)
+// หรือที่พักใกล้ centroid ของทุกจุดที่ราคาไม่เกินงบ
+func (tp *tripPlanner) chooseAccommodation(days [][]string) *Place {
+	if tp.mustAcc != nil {
+		return tp.mustAcc
+	}
+	if a := tp.mstAccommodation(days); a != nil {
+		return a
+	}
+	var latSum, lonSum float64
+	var n int
+	for _, day := range days {
+		for _, id := range day {
+			if p, ok := tp.lookup[id]; ok {
+				latSum += p.Lat
+				lonSum += p.Lon
+				n++
+			}
+		}
+	}
+	if n == 0 {
+		return nil
+	}
+	return nearestAccommodationUnderBudget(tp.accommodations, latSum/float64(n), lonSum/float64(n), tp.budget.Hotel)
+}
+
+func (tp *tripPlanner) summarize(days [][]string, accs []*Place) *Result {
+	budget := tp.budget
+
+	// สรุปรายวัน
+	detailed := make([]DayPlan, 0, len(days))
+	for i, day := range days {
+		plan := make([]PlaceInfo, 0, len(day))
+		for _, id := range day {
+			p, ok := tp.lookup[id]
+			if !ok {
+				continue
+			}
+			info := PlaceInfo{ID: id, Name: nameOr(p, id), Lat: p.Lat, Lon: p.Lon}
+			if t, ok := tp.times[id]; ok {
+				info.Arrive, info.Leave = domain.FormatClock(t[0]), domain.FormatClock(t[1])
+			}
+			plan = append(plan, info)
+		}
+		date := tp.opt.StartDate.AddDate(0, 0, i).Format("2006-01-02")
+		dp := DayPlan{Day: i + 1, Date: date, Plan: plan, Budget: budget}
+		if tp.clustered && accs[i] != nil {
+			dp.Accommodation = toAccommodation(accs[i])
+		}
+		detailed = append(detailed, dp)
+	}
+
+	// ที่พักหลัก (วันแรกที่มีที่พัก) คงไว้ให้ frontend เดิม
+	var acc *Place
+	for _, a := range accs {
+		if a != nil {
+			acc = a
+			break
+		}
+	}
+
+	res := &Result{
+		Start:         tp.opt.Start,
+		StartName:     nameOr(tp.lookup[tp.opt.Start], tp.opt.Start),
+		TripPlanByDay: detailed,
+		Paths:         []PathInfo{},
+		Message:       "สร้างเส้นทางสำเร็จ",
+		TotalBudget:   tp.opt.TotalBudget,
+		BudgetPerDay:  budget.PerDay,
+		StartDate:     tp.opt.StartDate.Format("2006-01-02"),
+		HoursNotices:  tp.notices,
+	}
+
+	// 5) เส้นทางรวม (A → … → A)
+	if acc != nil {
+		res.Accommodation = toAccommodation(acc)
+
+		var full []string
+		for i, day := range days {
+			if len(day) == 0 || accs[i] == nil {
+				// ว่างทั้งวัน → ข้าม แต่ยังคงที่พักไว้วันถัดไป
+				continue
+			}
+			full = append(full, accs[i].ID)
+			full = append(full, day...)
+			full = append(full, accs[i].ID)
+		}
+
+		total := 0.0
+		for i := 0; i+1 < len(full); i++ {
+			from, to := full[i], full[i+1]
+			d := tp.graph.Dist(from, to, 0)
+			total += d
+			fp, tpl := tp.lookup[from], tp.lookup[to]
+			est := tp.opt.Timeline.Estimate(tp.opt.TravelMode, d)
+			res.Paths = append(res.Paths, PathInfo{
+				From:       from,
+				FromName:   nameOr(fp, from),
+				FromLat:    fp.Lat,
+				FromLon:    fp.Lon,
+				To:         to,
+				ToName:     nameOr(tpl, to),
+				ToLat:      tpl.Lat,
+				ToLon:      tpl.Lon,
+				DistanceKm: round2(d),
+
+				Mode:        est.Mode,
+				DurationMin: est.Minutes,
+				SuggestMode: est.SuggestMode,
+			})
+		}
+		res.TotalDistanceKm = round2(total)
+	}
+
+	// ---- Spend summary (จริงตามที่เลือก) ----
+	res.Spend.PerDay = make([]DaySpend, 0, len(days))
+	for i, day := range days {
+		hotelPrice := 0
+		if accs[i] != nil {
+			hotelPrice = accs[i].PriceMin
+		}
+		s := computeSpendForDay(i+1, day, tp.lookup, hotelPrice)
+		res.Spend.PerDay = append(res.Spend.PerDay, s)
+		res.Spend.Breakdown.Hotel += s.Hotel
+		res.Spend.Breakdown.Meals += s.Meals
+		res.Spend.Breakdown.Attractions += s.Attractions
+		res.Spend.Total += s.Total
+	}
+
+	return res
+}
+
+// ------------------------------------------------------------
+// zones
+// ------------------------------------------------------------
+
+// pickZonesFromCoords เลือก zoneA/zoneB จากพิกัด
+//   - zoneA = start + แลนด์มาร์กใกล้ start (takeNear จุด)
+//   - zoneB = แลนด์มาร์กไกลสุด (takeFar จุด)
+//
+// คืนค่า CSV ของ landmark_id (ตัวเลขดิบ)
+func pickZonesFromCoords(landmarks []Place, start string, takeNear, takeFar int) (string, string) {
+	startNum, err := landmarkNum(start)
+	if err != nil {
+		return "", ""
+	}
+
+	var sp *Place
+	var others []Place
+	for i := range landmarks {
+		switch {
+		case landmarks[i].ID == start:
+			sp = &landmarks[i]
+		case isLandmark(landmarks[i].ID):
+			others = append(others, landmarks[i])
+		}
+	}
+	if sp == nil || len(others) == 0 {
+		return strconv.Itoa(startNum), ""
+	}
+
+	dist := func(p Place) float64 {
+		dx, dy := sp.Lat-p.Lat, sp.Lon-p.Lon
+		return math.Sqrt(dx*dx + dy*dy)
+	}
+	sort.SliceStable(others, func(i, j int) bool { return dist(others[i]) < dist(others[j]) })
+
+	zoneA := []string{strconv.Itoa(startNum)}
+	for i := 0; i < takeNear && i < len(others); i++ {
+		if n, err := landmarkNum(others[i].ID); err == nil {
+			zoneA = append(zoneA, strconv.Itoa(n))
+		}
+	}
+	var zoneB []string
+	for i := 0; i < takeFar && i < len(others); i++ {
+		if n, err := landmarkNum(others[len(others)-1-i].ID); err == nil {
+			zoneB = append(zoneB, strconv.Itoa(n))
+		}
+	}
+	return strings.Join(zoneA, ","), strings.Join(zoneB, ",")
+}
+
+// ------------------------------------------------------------
+// small helpers
+// ------------------------------------------------------------
+
+func landmarkNum(code string) (int, error) {
+	if !isLandmark(code) {
+		return 0, ErrBadStart
+	}
+	n, err := strconv.Atoi(code[1:])
+	if err != nil || n <= 0 {
+		return 0, ErrBadStart
+	}
+	return n, nil
+}
+
+func toAccommodation(p *Place) *Accommodation {
+	return &Accommodation{ID: p.ID, Name: nameOr(*p, p.ID), Lat: p.Lat, Lon: p.Lon}
+}
+
+func nameOr(p Place, fallback string) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return fallback
+}
+
+func round2(x float64) float64 { return math.Round(x*100) / 100 }
@@ -0,0 +1,390 @@
+package planner
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+)
+
+// ------------------------------------------------------------
+// fakeSource: แลนด์มาร์ก P1..P8 เรียงเป็นเส้นตรง (ห่างกันราว 1 km) และ MST เป็นสายโซ่ P1-P2-…-P8
+// ร้าน R1 ใกล้ P2, R3 ใกล้ P4 (ราคาในงบต่อมื้อ), R2 ใกล้ P6 (เกินงบ)
+// ที่พัก A1 ในงบแต่ไกล, A2 ใกล้ centroid แต่เกินงบ
+// ------------------------------------------------------------
+
+type fakeSource struct {
+	landmarks, restaurants, accommodations []Place
+
+	distanceIDs []string
+	queries     []MSTQuery
+}
+
+func newFakeSource() *fakeSource {
+	const lat, lon = 13.75, 100.50
+	s := &fakeSource{}
+	for i := 1; i <= 8; i++ {
+		p := Place{ID: "P" + strconv.Itoa(i), Name: "แลนด์มาร์ก " + strconv.Itoa(i), Lat: lat, Lon: lon + float64(i)*0.01}
+		if i == 3 {
+			p.PriceMin, p.PriceMax = 300, 300
+		}
+		s.landmarks = append(s.landmarks, p)
+	}
+	s.restaurants = []Place{
+		{ID: "R1", Name: "ร้าน 1", Lat: lat + 0.001, Lon: lon + 0.02, PriceMin: 100, PriceMax: 150},
+		{ID: "R2", Name: "ร้าน 2", Lat: lat + 0.001, Lon: lon + 0.06, PriceMin: 1000, PriceMax: 1200},
+		{ID: "R3", Name: "ร้าน 3", Lat: lat + 0.001, Lon: lon + 0.04, PriceMin: 200, PriceMax: 250},
+	}
+	s.accommodations = []Place{
+		{ID: "A1", Name: "ที่พัก 1", Lat: lat, Lon: lon + 0.20, PriceMin: 1500, PriceMax: 1500},
+		{ID: "A2", Name: "ที่พัก 2", Lat: lat, Lon: lon + 0.045, PriceMin: 5000, PriceMax: 5000},
+	}
+	return s
+}
+
+func (s *fakeSource) Places() ([]Place, []Place, []Place, error) {
+	return s.landmarks, s.restaurants, s.accommodations, nil
+}
+
+// Distances กราฟสมบูรณ์ด้วยระยะเส้นตรง
+func (s *fakeSource) Distances(ids []string) (*Graph, error) {
+	s.distanceIDs = ids
+	all := map[string]Place{}
+	for _, group := range [][]Place{s.landmarks, s.restaurants, s.accommodations} {
+		for _, p := range group {
+			all[p.ID] = p
+		}
+	}
+	g := NewGraph()
+	for _, a := range ids {
+		for _, b := range ids {
+			if a != b {
+				g.Add(a, b, haversineKm(all[a].Lat, all[a].Lon, all[b].Lat, all[b].Lon))
+			}
+		}
+	}
+	return g, nil
+}
+
+func (s *fakeSource) MSTByFlow(q MSTQuery) (*MSTResult, error) {
+	s.queries = append(s.queries, q)
+	rows := []MSTRow{{Seq: 1, Node: 1, EdgeID: -1}}
+	for i := 2; i <= len(s.landmarks); i++ {
+		pred := i - 1
+		rows = append(rows, MSTRow{Seq: i, Depth: i - 1, Node: i, EdgeID: i, Pred: &pred})
+	}
+	return &MSTResult{Rows: rows}, nil
+}
+
+func testOptions() Options {
+	opt := DefaultOptions()
+	opt.Start = "P1"
+	opt.Days = 2
+	opt.TotalBudget = 10000
+	opt.Optimize = false
+	opt.Cluster = false
+	opt.StartDate = time.Date(2026, 1, 5, 0, 0, 0, 0, domain.Bangkok)
+	return opt
+}
+
+func dayIDs(res *Result) [][]string {
+	out := make([][]string, len(res.TripPlanByDay))
+	for i, d := range res.TripPlanByDay {
+		out[i] = []string{}
+		for _, p := range d.Plan {
+			out[i] = append(out[i], p.ID)
+		}
+	}
+	return out
+}
+
+// ------------------------------------------------------------
+// tests
+// ------------------------------------------------------------
+
+func TestSplitDailyBudget(t *testing.T) {
+	tests := []struct {
+		total, days int
+		want        DayBudget
+	}{
+		{10000, 2, DayBudget{PerDay: 5000, Hotel: 2750, MealEach: 600, Attractions: 1050}},
+		{3000, 1, DayBudget{PerDay: 3000, Hotel: 1650, MealEach: 360, Attractions: 630}},
+		{1001, 3, DayBudget{PerDay: 333, Hotel: 183, MealEach: 39, Attractions: 72}},
+		{5000, 0, DayBudget{PerDay: 5000, Hotel: 2750, MealEach: 600, Attractions: 1050}}, // days ≤ 0 → 1 วัน
+		{0, 2, DayBudget{}},
+	}
+	for _, tt := range tests {
+		if got := splitDailyBudget(tt.total, tt.days); got != tt.want {
+			t.Errorf("splitDailyBudget(%d, %d) = %+v ต้องการ %+v", tt.total, tt.days, got, tt.want)
+		}
+	}
+}
+
+func TestComputeSpendForDay(t *testing.T) {
+	lookup := map[string]Place{
+		"P1": {ID: "P1", PriceMin: 300},
+		"P2": {ID: "P2"},
+		"P3": {ID: "P3", PriceMin: -1}, // ราคาติดลบ (ข้อมูลเสีย) ไม่นับ
+		"R1": {ID: "R1", PriceMin: 120},
+		"R2": {ID: "R2", PriceMin: 80},
+		"A1": {ID: "A1", PriceMin: 999}, // ที่พักในรายการวันไม่นับซ้ำ (ใช้ hotelPricePerDay)
+	}
+	tests := []struct {
+		name  string
+		nodes []string
+		hotel int
+		want  DaySpend
+	}{
+		{"ครบทุกหมวด", []string{"P1", "R1", "P2", "R2", "P3"}, 1500,
+			DaySpend{Day: 1, Hotel: 1500, Meals: 200, Attractions: 300, Total: 2000}},
+		{"ไม่รู้จัก id / ที่พักในรายการ", []string{"P9", "A1", "R1"}, 0,
+			DaySpend{Day: 1, Meals: 120, Total: 120}},
+		{"ราคาที่พักติดลบ", nil, -5, DaySpend{Day: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := computeSpendForDay(1, tt.nodes, lookup, tt.hotel); got != tt.want {
+				t.Errorf("computeSpendForDay = %+v ต้องการ %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(*Options)
+		wantDays [][]string
+		wantAcc  string
+		wantDay  []DaySpend
+	}{
+		{
+			// ร้านแทรกหลังแลนด์มาร์กที่ 2 และ 4; ครบ 6 จุดตัดวัน; มื้อที่เหลือไม่มีร้านในงบ → ใช้ร้านที่เหลือ
+			name:     "สองวัน",
+			wantDays: [][]string{{"P1", "P2", "R1", "P3", "P4", "R3"}, {"P5", "P6", "R2", "P7", "P8"}},
+			wantAcc:  "A1",
+			wantDay: []DaySpend{
+				{Day: 1, Hotel: 1500, Meals: 300, Attractions: 300, Total: 2100},
+				{Day: 2, Hotel: 1500, Meals: 1000, Total: 2500},
+			},
+		},
+		{
+			// วันเดียว: หยุดเดินเมื่อครบวัน ไม่มีวันที่สอง
+			name:     "วันเดียว",
+			edit:     func(o *Options) { o.Days = 1; o.TotalBudget = 5000 },
+			wantDays: [][]string{{"P1", "P2", "R1", "P3", "P4", "R3"}},
+			wantAcc:  "A1",
+			wantDay:  []DaySpend{{Day: 1, Hotel: 1500, Meals: 300, Attractions: 300, Total: 2100}},
+		},
+		{
+			// งบแลนด์มาร์ก/วัน = 0 → ข้าม P3 ที่เสียเงิน; งบที่พัก 0 ผ่อน 5 ครั้งยังไม่พอ → ไม่มีที่พัก
+			name:     "งบเป็นศูนย์",
+			edit:     func(o *Options) { o.TotalBudget = 0 },
+			wantDays: [][]string{{"P1", "P2", "R1", "P4", "P5", "R3"}, {"P6", "P7", "R2", "P8"}},
+			wantDay: []DaySpend{
+				{Day: 1, Meals: 300, Total: 300},
+				{Day: 2, Meals: 1000, Total: 1000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src := newFakeSource()
+			opt := testOptions()
+			if tt.edit != nil {
+				tt.edit(&opt)
+			}
+			res, err := Plan(context.Background(), src, opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := dayIDs(res); !reflect.DeepEqual(got, tt.wantDays) {
+				t.Errorf("แผนรายวัน = %v ต้องการ %v", got, tt.wantDays)
+			}
+			gotAcc := ""
+			if res.Accommodation != nil {
+				gotAcc = res.Accommodation.ID
+			}
+			if gotAcc != tt.wantAcc {
+				t.Errorf("ที่พัก = %q ต้องการ %q", gotAcc, tt.wantAcc)
+			}
+			if !reflect.DeepEqual(res.Spend.PerDay, tt.wantDay) {
+				t.Errorf("ค่าใช้จ่ายรายวัน = %+v ต้องการ %+v", res.Spend.PerDay, tt.wantDay)
+			}
+			total := 0
+			for _, s := range tt.wantDay {
+				total += s.Total
+			}
+			if res.Spend.Total != total {
+				t.Errorf("ค่าใช้จ่ายรวม = %d ต้องการ %d", res.Spend.Total, total)
+			}
+
+			budget := splitDailyBudget(opt.TotalBudget, opt.Days)
+			for _, d := range res.TripPlanByDay {
+				if d.Budget != budget {
+					t.Errorf("งบวันที่ %d = %+v ต้องการ %+v", d.Day, d.Budget, budget)
+				}
+			}
+			if len(src.queries) != 1 || src.queries[0].Root != 1 {
+				t.Errorf("MST query = %+v", src.queries)
+			}
+			if len(src.distanceIDs) != 13 || src.distanceIDs[0] != "P1" {
+				t.Errorf("ขอระยะของ %v", src.distanceIDs)
+			}
+		})
+	}
+}
+
+func TestPlanPaths(t *testing.T) {
+	res, err := Plan(context.Background(), newFakeSource(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ที่พัก → จุดของวัน → ที่พัก ทุกวัน
+	var want []string
+	for _, d := range dayIDs(res) {
+		want = append(want, "A1")
+		want = append(want, d...)
+		want = append(want, "A1")
+	}
+	var got []string
+	sum := 0.0
+	for i, p := range res.Paths {
+		if i == 0 {
+			got = append(got, p.From)
+		} else if p.From != res.Paths[i-1].To {
+			t.Errorf("เส้นที่ %d เริ่มจาก %s แต่เส้นก่อนหน้าจบที่ %s", i, p.From, res.Paths[i-1].To)
+		}
+		got = append(got, p.To)
+		sum += p.DistanceKm
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("paths = %v ต้องการ %v", got, want)
+	}
+	if d := res.TotalDistanceKm - sum; d > 0.05 || d < -0.05 {
+		t.Errorf("total_distance_km = %.2f แต่รวม paths ได้ %.2f", res.TotalDistanceKm, sum)
+	}
+}
+
+func TestPlanBadStart(t *testing.T) {
+	opt := testOptions()
+	for _, start := range []string{"", "R1", "P0", "Pabc"} {
+		opt.Start = start
+		if _, err := Plan(context.Background(), newFakeSource(), opt); err != ErrBadStart {
+			t.Errorf("start %q: err = %v ต้องการ ErrBadStart", start, err)
+		}
+	}
+}
+
+// TestResultJSONShape ทุก key ของ PythonResult เดิมยังอยู่ด้วยชนิดเดิม (เพิ่ม key ใหม่ได้)
+func TestResultJSONShape(t *testing.T) {
+	res, err := Plan(context.Background(), newFakeSource(), testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(b, &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	const (
+		str = "string"
+		num = "number"
+		obj = "object"
+		arr = "array"
+	)
+	kindOf := func(v any) string {
+		switch v.(type) {
+		case string:
+			return str
+		case float64:
+			return num
+		case map[string]any:
+			return obj
+		case []any:
+			return arr
+		}
+		return "?"
+	}
+	check := func(where string, m map[string]any, keys map[string]string) {
+		t.Helper()
+		for k, kind := range keys {
+			v, ok := m[k]
+			if !ok {
+				t.Errorf("%s ไม่มี key %q", where, k)
+				continue
+			}
+			if got := kindOf(v); got != kind {
+				t.Errorf("%s.%s เป็น %s ต้องการ %s", where, k, got, kind)
+			}
+		}
+	}
+
+	check("result", doc, map[string]string{
+		"start": str, "start_name": str, "trip_plan_by_day": arr, "paths": arr,
+		"total_distance_km": num, "accommodation": obj, "message": str,
+		"total_budget": num, "budget_per_day": num, "spend": obj,
+	})
+	if _, ok := doc["error"]; ok {
+		t.Errorf("error ต้องไม่มีเมื่อสำเร็จ (omitempty)")
+	}
+	check("accommodation", doc["accommodation"].(map[string]any), map[string]string{
+		"id": str, "name": str, "lat": num, "lon": num,
+	})
+
+	day := doc["trip_plan_by_day"].([]any)[0].(map[string]any)
+	check("trip_plan_by_day[0]", day, map[string]string{"day": num, "plan": arr, "budget": obj})
+	check("budget", day["budget"].(map[string]any), map[string]string{
+		"per_day": num, "hotel": num, "meal_each": num, "attractions": num,
+	})
+	check("plan[0]", day["plan"].([]any)[0].(map[string]any), map[string]string{
+		"id": str, "name": str, "lat": num, "lon": num,
+	})
+	check("paths[0]", doc["paths"].([]any)[0].(map[string]any), map[string]string{
+		"from": str, "from_name": str, "from_lat": num, "from_lon": num,
+		"to": str, "to_name": str, "to_lat": num, "to_lon": num, "distance_km": num,
+	})
+
+	spend := doc["spend"].(map[string]any)
+	check("spend", spend, map[string]string{"per_day": arr, "total": num, "breakdown": obj})
+	check("spend.per_day[0]", spend["per_day"].([]any)[0].(map[string]any), map[string]string{
+		"day": num, "hotel": num, "meals": num, "attractions": num, "total": num,
+	})
+	check("spend.breakdown", spend["breakdown"].(map[string]any), map[string]string{
+		"hotel": num, "meals": num, "attractions": num,
+	})
+}
+
+// must ที่อยู่ติดเส้นทางของวันที่มีจุดแล้ว ต้องไม่ถูกแยกไปอยู่วันว่างคนเดียว
+// (วันว่างต้องเสียระยะ ที่พัก → จุด → ที่พัก)
+func TestPlaceMustNextToRoute(t *testing.T) {
+	const lat = 13.75
+	acc := Place{ID: "A1", Lat: lat, Lon: 100.50}
+	lookup := map[string]Place{"A1": acc}
+	for id, lon := range map[string]float64{"P1": 100.51, "P2": 100.52, "P3": 100.525} {
+		lookup[id] = Place{ID: id, Lat: lat, Lon: lon}
+	}
+	opt := testOptions()
+	opt.Must = []string{"A1", "P3"}
+	tp := &tripPlanner{
+		opt: opt, lookup: lookup, graph: NewGraph(), mustAcc: &acc,
+		remainingR: map[string]bool{}, times: map[string][2]int{},
+		days: [][]string{{"P1", "P2"}, {}},
+	}
+	tp.placeMust()
+
+	if want := [][]string{{"P1", "P2", "P3"}, {}}; !reflect.DeepEqual(tp.days, want) {
+		t.Errorf("days = %v ต้องการ %v", tp.days, want)
+	}
+	if _, ok := tp.times["P3"]; !ok {
+		t.Errorf("ต้องคำนวณเวลาของ P3 ใหม่หลังแทรก")
+	}
+}
@@ -0,0 +1,310 @@
+package planner
+
+import (
+	"time"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+	"github.com/gtwndtl/trip-spark-builder/timeline"
+)
+
+// ------------------------------------------------------------
+// input
+// ------------------------------------------------------------
+
+// Place คือจุดที่ planner ใช้ (ID มี prefix: P=landmark, R=restaurant, A=accommodation)
+type Place struct {
+	ID       string
+	Name     string
+	Lat      float64
+	Lon      float64
+	PriceMin int
+	PriceMax int
+
+	Hours *domain.Hours // nil = ไม่มีข้อมูล ถือว่าเปิดตลอด
+	Price *domain.Price // nil = ใช้ PriceMin/PriceMax เป็นราคาต่อคน (ที่พัก: ต่อห้อง)
+}
+
+// MSTRow แถวผลลัพธ์ของ pgr_primDD (ตาม /mst/byflow)
+type MSTRow struct {
+	Seq    int
+	Depth  int
+	Node   int
+	EdgeID int
+	Pred   *int
+
+	// รหัส P/R/A ของ Node/Pred เมื่อ MST รวมร้าน/ที่พัก ("" = Node เป็น landmark_id)
+	Code, PredCode string
+}
+
+// MSTQuery พารามิเตอร์ที่ส่งต่อให้ MST แบบ flow (Boykov + preferences)
+type MSTQuery struct {
+	Root     int
+	ZoneA    string
+	ZoneB    string
+	Distance float64
+	K        int
+	KMst     int
+	Mode     string
+	Penalty  float64
+	NTop     int
+
+	Prefer, Prefer2, Prefer3 string
+	W1, W2, W3               float64
+
+	// MST แบบรวมร้าน/ที่พัก (Options.MSTKinds ฯลฯ)
+	Kinds              string
+	WR, WA             float64
+	PreferR, PreferA   string
+	WPreferR, WPreferA float64
+
+	Exclude      []int    // landmark_id ที่ต้องตัดออกจากกราฟ (avoid)
+	ExcludeCodes []string // รหัส R/A ใน avoid
+
+	Progress func(phase string) // ส่งต่อ Options.Progress ให้ Source แจ้ง PhaseMinCut/PhaseMST
+}
+
+// MSTResult ผลของ MST แบบ flow
+type MSTResult struct {
+	Rows            []MSTRow
+	AppliedCutEdges [][2]int
+}
+
+// Source แหล่งข้อมูลของ planner (เดิม Code.py ยิง /landmarks, /distances, /mst/byflow ผ่าน HTTP)
+type Source interface {
+	Places() (landmarks, restaurants, accommodations []Place, err error)
+	Distances(ids []string) (*Graph, error)
+	MSTByFlow(q MSTQuery) (*MSTResult, error)
+}
+
+// AccommodationSuggester Source ที่แนะนำที่พักใกล้กลุ่มจุดได้ (เรียงจากเหมาะสุด)
+// ใช้กับที่พักรายวันตอนแบ่งกลุ่มตามพื้นที่; Source ที่ไม่มีจะใช้ที่พักใกล้ centroid ของวันแทน
+type AccommodationSuggester interface {
+	SuggestAccommodations(codes []string) ([]string, error)
+}
+
+// Options ตัวเลือกของการวางแผน (ตรงกับ argv เดิมของ Code.py)
+type Options struct {
+	Start       string
+	Days        int
+	Distance    float64
+	K           int
+	KMst        int
+	Mode        string
+	Penalty     float64
+	UseBoykov   bool
+	TotalBudget int
+
+	// ผู้ร่วมทริป: TotalBudget เป็นงบของทั้งกลุ่ม ราคาทุกจุดคูณตามจำนวนคน/ห้อง (zero = ผู้ใหญ่ 1 คน)
+	Group domain.Group
+
+	Prefer, Prefer2, Prefer3 string
+	W1, W2, W3               float64
+
+	NTop int
+
+	// รหัสสถานที่ (P/R/A) ที่ต้องมีในแผนแน่ ๆ และที่ไม่เอาเลย
+	Must  []string
+	Avoid []string
+
+	// MSTKinds ประเภทสถานที่ในโครงสร้าง MST: "P" (แลนด์มาร์กอย่างเดียว) หรือ "PRA"
+	// เมื่อมีร้าน/ที่พัก มื้ออาหารเลือกร้านที่ติดกับจุดล่าสุดใน MST ก่อน และที่พักเลือกตัวที่ติดกับจุดในแผนมากสุด
+	// WR/WA ตัวคูณ cost ของเส้นที่แตะร้าน/ที่พัก, PreferR/PreferA + WPreferR/WPreferA ประเภทร้าน/ที่พักที่ชอบ
+	MSTKinds           string
+	WR, WA             float64
+	PreferR, PreferA   string
+	WPreferR, WPreferA float64
+
+	// แบบการเดินทางระหว่างจุด (timeline.Walk/Car/Transit) และบังคับเดินอย่างเดียว
+	// WalkOnly: ไม่เลือกจุดที่ไกลเกินเดินจากจุดก่อนหน้า (ตาม Profile.MaxKm ของ walk)
+	TravelMode string
+	WalkOnly   bool
+
+	// จัดลำดับจุดในแต่ละวันใหม่ด้วย 2-opt / Or-opt หลังแบ่งวันแล้ว (ค่าเริ่มต้นปิด ได้ลำดับเดียวกับ Code.py)
+	Optimize bool
+
+	// ทริปหลายวัน: แบ่งแลนด์มาร์กเป็นกลุ่มตามพื้นที่ วันละกลุ่ม (false = ค่าเริ่มต้น เดิน MST แบ่งวันตามลำดับ DFS)
+	Cluster bool
+
+	// จำนวนแผนทางเลือกที่ต้องการ (≤1 = แผนเดียวเหมือนเดิม, สูงสุด MaxAlternatives)
+	Alternatives int
+
+	// วันแรกของทริป ใช้เช็กเวลาเปิด-ปิดตามวันในสัปดาห์ (zero = วันนี้ เวลาไทย)
+	StartDate time.Time
+
+	// เวลาเริ่มวัน/เวลาเที่ยวต่อจุด/ความเร็วเดินทาง
+	Timeline timeline.Config
+
+	// Progress ถูกเรียกเมื่อเริ่มแต่ละขั้น (Phase*) ใช้กับ job แบบ async; nil = ไม่แจ้ง
+	Progress func(phase string)
+
+	// VariantProgress ใช้แทน Progress ระหว่างวางแผนแต่ละ variant เมื่อ Alternatives > 1
+	// index เริ่มที่ 1 จาก total variant ที่อาจลอง (หยุดก่อนได้เมื่อผู้สมัครพอ); nil = ส่งเข้า Progress ตรง ๆ
+	VariantProgress func(phase string, index, total int, name string)
+
+	// ตั้งโดย Plan เมื่อ Source เป็น AccommodationSuggester
+	suggestAcc func(codes []string) ([]string, error)
+}
+
+// ขั้นตอนของการวางแผน (ส่งออกผ่าน Options.Progress)
+const (
+	PhaseLoading   = "loading places"
+	PhaseDistances = "computing distances"
+	PhaseMinCut    = "computing min-cut"
+	PhaseMST       = "building MST"
+	PhaseAssign    = "assigning days"
+	PhaseDone      = "done"
+)
+
+func (o Options) progress(phase string) {
+	if o.Progress != nil {
+		o.Progress(phase)
+	}
+}
+
+// DefaultOptions ค่า default เดียวกับ Code.py
+func DefaultOptions() Options {
+	return Options{
+		Days:       1,
+		Distance:   4000,
+		K:          20,
+		KMst:       20,
+		Mode:       "penalize",
+		Penalty:    1.3,
+		UseBoykov:  true,
+		W1:         0.75,
+		W2:         0.85,
+		W3:         0.95,
+		NTop:       40,
+		MSTKinds:   "P",
+		WR:         1,
+		WA:         1,
+		WPreferR:   0.8,
+		WPreferA:   0.8,
+		TravelMode: timeline.Car,
+		Timeline:   timeline.DefaultConfig(),
+	}
+}
+
+// ------------------------------------------------------------
+// output (shape เดียวกับ PythonResult เดิม)
+// ------------------------------------------------------------
+
+type Result struct {
+	Start           string         `json:"start"`
+	StartName       string         `json:"start_name"`
+	TripPlanByDay   []DayPlan      `json:"trip_plan_by_day"`
+	Paths           []PathInfo     `json:"paths"`
+	TotalDistanceKm float64        `json:"total_distance_km"`
+	Accommodation   *Accommodation `json:"accommodation,omitempty"`
+	Message         string         `json:"message"`
+	Error           string         `json:"error,omitempty"`
+
+	TotalBudget  int `json:"total_budget"`
+	BudgetPerDay int `json:"budget_per_day"`
+
+	Spend Spend `json:"spend"`
+
+	StartDate    string        `json:"start_date,omitempty"`
+	HoursNotices []HoursNotice `json:"hours_notices,omitempty"`
+
+	Optimization *OptimizeReport `json:"optimization,omitempty"`
+
+	// เฉพาะเมื่อขอหลายแผน: ชื่อชุดตัวเลือกที่ใช้, ความต่างเฉลี่ยจากแผนอื่น (0..1), แผนที่เหลือ
+	Variant      string    `json:"variant,omitempty"`
+	Diversity    float64   `json:"diversity,omitempty"`
+	Alternatives []*Result `json:"alternatives,omitempty"`
+}
+
+// OptimizeReport ระยะรวม (ที่พัก → … → ที่พัก) ก่อน/หลังจัดลำดับใหม่
+type OptimizeReport struct {
+	BeforeKm float64          `json:"before_km"`
+	AfterKm  float64          `json:"after_km"`
+	PerDay   []DayOptimizeRep `json:"per_day"`
+}
+
+type DayOptimizeRep struct {
+	Day      int     `json:"day"`
+	BeforeKm float64 `json:"before_km"`
+	AfterKm  float64 `json:"after_km"`
+	Kept     bool    `json:"kept,omitempty"` // ลำดับใหม่ติดเวลาเปิด-ปิด จึงคงลำดับเดิม
+}
+
+// HoursNotice จุดที่ต้องตัดทิ้ง (dropped) หรือเลื่อนไปช่วง/วันอื่น (moved) เพราะเวลาเปิด-ปิด
+type HoursNotice struct {
+	ID      string `json:"id,omitempty"`
+	Name    string `json:"name,omitempty"`
+	Day     int    `json:"day"`
+	FromDay int    `json:"from_day,omitempty"`
+	Action  string `json:"action"`
+	Reason  string `json:"reason"`
+}
+
+type Spend struct {
+	PerDay    []DaySpend `json:"per_day"`
+	Total     int        `json:"total"`
+	Breakdown Breakdown  `json:"breakdown"`
+}
+
+type DaySpend struct {
+	Day         int `json:"day"`
+	Hotel       int `json:"hotel"`
+	Meals       int `json:"meals"`
+	Attractions int `json:"attractions"`
+	Total       int `json:"total"`
+}
+
+type Breakdown struct {
+	Hotel       int `json:"hotel"`
+	Meals       int `json:"meals"`
+	Attractions int `json:"attractions"`
+}
+
+type DayBudget struct {
+	PerDay      int `json:"per_day"`
+	Hotel       int `json:"hotel"`
+	MealEach    int `json:"meal_each"`
+	Attractions int `json:"attractions"`
+}
+
+type DayPlan struct {
+	Day    int         `json:"day"`
+	Date   string      `json:"date,omitempty"`
+	Plan   []PlaceInfo `json:"plan"`
+	Budget DayBudget   `json:"budget"`
+
+	Accommodation *Accommodation `json:"accommodation,omitempty"` // เฉพาะตอนแบ่งกลุ่มตามพื้นที่ (ที่พักรายวัน)
+}
+
+type PlaceInfo struct {
+	ID     string  `json:"id"`
+	Name   string  `json:"name"`
+	Lat    float64 `json:"lat"`
+	Lon    float64 `json:"lon"`
+	Arrive string  `json:"arrive,omitempty"` // "HH:MM" ตามนาฬิกาที่ใช้เช็กเวลาเปิด-ปิด
+	Leave  string  `json:"leave,omitempty"`
+}
+
+type PathInfo struct {
+	From       string  `json:"from"`
+	FromName   string  `json:"from_name"`
+	FromLat    float64 `json:"from_lat"`
+	FromLon    float64 `json:"from_lon"`
+	To         string  `json:"to"`
+	ToName     string  `json:"to_name"`
+	ToLat      float64 `json:"to_lat"`
+	ToLon      float64 `json:"to_lon"`
+	DistanceKm float64 `json:"distance_km"`
+	Day        int     `json:"day,omitempty"`
+
+	// เวลาเดินทางตาม TravelMode (ไกลเกินแบบนั้น → suggest_mode)
+	Mode        string `json:"mode,omitempty"`
+	DurationMin int    `json:"duration_min,omitempty"`
+	SuggestMode string `json:"suggest_mode,omitempty"`
+}
+
+type Accommodation struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	Lat  float64 `json:"lat"`
+	Lon  float64 `json:"lon"`
+}
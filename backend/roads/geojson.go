@@ -0,0 +1,121 @@
+package roads
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ------------------------------------------------------------
+// ตัวอ่าน GeoJSON (เช่นไฟล์ที่ export จาก QGIS/osmium)
+// รับ FeatureCollection ของ LineString/MultiLineString พิกัด EPSG:4326
+// ไม่มี node id → จุดที่พิกัดตรงกัน (ปัดที่ 1e-7 องศา) ถือเป็น node เดียวกัน
+// ไม่มีแท็ก highway เลย → ถือว่าทุกเส้นเป็นถนน ("road")
+// ------------------------------------------------------------
+
+type geoFeature struct {
+	Geometry struct {
+		Type        string          `json:"type"`
+		Coordinates json.RawMessage `json:"coordinates"`
+	} `json:"geometry"`
+	Properties map[string]any `json:"properties"`
+}
+
+// ReadGeoJSON อ่านถนนจาก GeoJSON FeatureCollection
+func ReadGeoJSON(r io.Reader) (*Extract, error) {
+	var fc struct {
+		Type     string       `json:"type"`
+		Features []geoFeature `json:"features"`
+	}
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, fmt.Errorf("อ่าน GeoJSON ไม่ได้: %w", err)
+	}
+	if fc.Type != "FeatureCollection" {
+		return nil, fmt.Errorf("GeoJSON ต้องเป็น FeatureCollection (ได้ %q)", fc.Type)
+	}
+
+	ex := &Extract{Nodes: map[int64][2]float64{}}
+	ids := map[[2]int64]int64{}
+	nodeID := func(c [2]float64) int64 {
+		key := [2]int64{int64(math.Round(c[0] * 1e7)), int64(math.Round(c[1] * 1e7))}
+		id, ok := ids[key]
+		if !ok {
+			id = int64(len(ids) + 1)
+			ids[key] = id
+			ex.Nodes[id] = c
+		}
+		return id
+	}
+
+	for i, f := range fc.Features {
+		var lines [][][]float64
+		switch f.Geometry.Type {
+		case "LineString":
+			var line [][]float64
+			if err := json.Unmarshal(f.Geometry.Coordinates, &line); err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+			lines = [][][]float64{line}
+		case "MultiLineString":
+			if err := json.Unmarshal(f.Geometry.Coordinates, &lines); err != nil {
+				return nil, fmt.Errorf("feature %d: %w", i, err)
+			}
+		default:
+			continue
+		}
+
+		highway, name := prop(f.Properties, "highway"), prop(f.Properties, "name")
+		if _, tagged := f.Properties["highway"]; !tagged {
+			highway = "road"
+		}
+		if !keepHighway(highway) {
+			continue
+		}
+		oneway, rev := parseOneway(highway, prop(f.Properties, "oneway"), prop(f.Properties, "junction"))
+		osmID := int64(i + 1)
+		if v, ok := f.Properties["osm_id"].(float64); ok {
+			osmID = int64(v)
+		}
+
+		for _, line := range lines {
+			w := Way{ID: osmID, Highway: highway, Name: name, Oneway: oneway}
+			for _, c := range line {
+				if len(c) < 2 {
+					return nil, fmt.Errorf("feature %d: พิกัดไม่ครบ", i)
+				}
+				id := nodeID([2]float64{c[0], c[1]})
+				if n := len(w.Refs); n > 0 && w.Refs[n-1] == id {
+					continue // จุดซ้ำติดกัน
+				}
+				w.Refs = append(w.Refs, id)
+			}
+			if len(w.Refs) < 2 {
+				continue
+			}
+			if rev {
+				for a, b := 0, len(w.Refs)-1; a < b; a, b = a+1, b-1 {
+					w.Refs[a], w.Refs[b] = w.Refs[b], w.Refs[a]
+				}
+			}
+			ex.Ways = append(ex.Ways, w)
+		}
+	}
+	return ex, nil
+}
+
+// prop ค่า property เป็นข้อความ (ตัวเลข/boolean แปลงเป็นข้อความ)
+func prop(p map[string]any, key string) string {
+	switch v := p[key].(type) {
+	case string:
+		return v
+	case float64:
+		return fmt.Sprint(v)
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	}
+	return ""
+}
@@ -0,0 +1,170 @@
+package roads
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ------------------------------------------------------------
+// ตาราง PostGIS แบบเดียวกับที่ osm2pgrouting สร้าง (pgr_dijkstra ใช้ได้ทันที)
+//   ways(gid, osm_id, source, target, length_m, cost, reverse_cost, x1, y1, x2, y2, highway, name, oneway, the_geom)
+//   ways_vertices_pgr(id, osm_id, the_geom)
+//   place_snap(code, vertex_id, snap_m)   -- P/R/A → vertex ที่ใกล้สุด
+// cost เป็นเมตร; reverse_cost = -1 สำหรับทางเดียว (pgRouting ถือว่าไม่มีเส้นกลับ)
+// ------------------------------------------------------------
+
+// DefaultSnapM ระยะ snap สูงสุด (เมตร) ไกลกว่านี้ถือว่าสถานที่อยู่นอกโครงข่าย
+const DefaultSnapM = 1000
+
+// insertBatch จำนวนแถวต่อ INSERT (Postgres รับ placeholder ได้ไม่เกิน 65535)
+const insertBatch = 500
+
+// Load แทนที่ ways/ways_vertices_pgr ทั้งหมดด้วย net ใน transaction เดียว
+// (คำขอ /distances?mode=road ที่เข้ามาระหว่าง import จะรอจน commit ไม่เห็นตารางที่เติมได้ครึ่งเดียว)
+// vertex id เปลี่ยนทุกครั้งที่ import ต้องเรียก Snap ต่อเสมอ
+func Load(db *gorm.DB, net *Network) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, q := range []string{
+			`DROP TABLE IF EXISTS ways`,
+			`DROP TABLE IF EXISTS ways_vertices_pgr`,
+			`CREATE TABLE ways_vertices_pgr (
+				id       bigint PRIMARY KEY,
+				osm_id   bigint,
+				the_geom geometry(Point, 4326) NOT NULL
+			)`,
+			`CREATE TABLE ways (
+				gid          bigint PRIMARY KEY,
+				osm_id       bigint,
+				source       bigint NOT NULL,
+				target       bigint NOT NULL,
+				length_m     double precision NOT NULL,
+				cost         double precision NOT NULL,
+				reverse_cost double precision NOT NULL,
+				x1 double precision, y1 double precision,
+				x2 double precision, y2 double precision,
+				highway      text,
+				name         text,
+				oneway       boolean NOT NULL DEFAULT false,
+				the_geom     geometry(LineString, 4326) NOT NULL
+			)`,
+		} {
+			if err := tx.Exec(q).Error; err != nil {
+				return err
+			}
+		}
+
+		for lo := 0; lo < len(net.Vertices); lo += insertBatch {
+			batch := net.Vertices[lo:min(lo+insertBatch, len(net.Vertices))]
+			rows := make([]string, 0, len(batch))
+			args := make([]any, 0, len(batch)*4)
+			for _, v := range batch {
+				rows = append(rows, "(?, ?, ST_SetSRID(ST_MakePoint(?, ?), 4326))")
+				args = append(args, v.ID, v.OsmID, v.Lon, v.Lat)
+			}
+			if err := tx.Exec(`INSERT INTO ways_vertices_pgr (id, osm_id, the_geom) VALUES `+
+				strings.Join(rows, ","), args...).Error; err != nil {
+				return fmt.Errorf("เพิ่ม vertex ไม่สำเร็จ: %w", err)
+			}
+		}
+
+		for lo := 0; lo < len(net.Edges); lo += insertBatch {
+			batch := net.Edges[lo:min(lo+insertBatch, len(net.Edges))]
+			rows := make([]string, 0, len(batch))
+			args := make([]any, 0, len(batch)*15)
+			for i, e := range batch {
+				reverse := e.LengthM
+				if e.Oneway {
+					reverse = -1
+				}
+				first, last := e.Coords[0], e.Coords[len(e.Coords)-1]
+				rows = append(rows, "(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ST_GeomFromText(?, 4326))")
+				args = append(args, int64(lo+i+1), e.OsmID, e.Source, e.Target, e.LengthM, e.LengthM, reverse,
+					first[0], first[1], last[0], last[1], e.Highway, e.Name, e.Oneway, lineWKT(e.Coords))
+			}
+			if err := tx.Exec(`INSERT INTO ways (gid, osm_id, source, target, length_m, cost, reverse_cost,
+				x1, y1, x2, y2, highway, name, oneway, the_geom) VALUES `+
+				strings.Join(rows, ","), args...).Error; err != nil {
+				return fmt.Errorf("เพิ่มเส้นทางไม่สำเร็จ: %w", err)
+			}
+		}
+
+		for _, q := range []string{
+			`CREATE INDEX ways_source_idx ON ways (source)`,
+			`CREATE INDEX ways_target_idx ON ways (target)`,
+			`CREATE INDEX ways_geom_idx ON ways USING GIST (the_geom)`,
+			`CREATE INDEX ways_vertices_pgr_geom_idx ON ways_vertices_pgr USING GIST (the_geom)`,
+			`ANALYZE ways`,
+			`ANALYZE ways_vertices_pgr`,
+		} {
+			if err := tx.Exec(q).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Snap จับคู่ทุกสถานที่ใน landmark_gis/restaurant_gis/accommodation_gis กับ vertex ที่ใกล้สุดภายใน maxM เมตร
+// สร้าง place_snap ใหม่ทั้งหมด คืนจำนวนสถานที่ที่ snap ได้
+func Snap(db *gorm.DB, maxM float64) (int64, error) {
+	if maxM <= 0 {
+		maxM = DefaultSnapM
+	}
+	var n int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`CREATE TABLE IF NOT EXISTS place_snap (
+			code      text PRIMARY KEY,
+			vertex_id bigint NOT NULL,
+			snap_m    double precision NOT NULL
+		)`).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec(`TRUNCATE place_snap`).Error; err != nil {
+			return err
+		}
+		res := tx.Exec(`
+			INSERT INTO place_snap (code, vertex_id, snap_m)
+			SELECT p.code, v.id, ST_DistanceSphere(p.location, v.the_geom)
+			FROM (
+				SELECT 'P' || landmark_id AS code, location FROM landmark_gis
+				UNION ALL
+				SELECT 'R' || restaurant_id, location FROM restaurant_gis
+				UNION ALL
+				SELECT 'A' || acc_id, location FROM accommodation_gis
+			) p
+			CROSS JOIN LATERAL (
+				SELECT id, the_geom FROM ways_vertices_pgr
+				ORDER BY the_geom <-> p.location
+				LIMIT 1
+			) v
+			WHERE ST_DistanceSphere(p.location, v.the_geom) <= ?`, maxM)
+		n = res.RowsAffected
+		return res.Error
+	})
+	return n, err
+}
+
+// HasNetwork มีตาราง ways และ place_snap พร้อมใช้หรือยัง
+func HasNetwork(db *gorm.DB) bool {
+	var ok bool
+	db.Raw(`SELECT to_regclass('ways') IS NOT NULL AND to_regclass('place_snap') IS NOT NULL`).Scan(&ok)
+	return ok
+}
+
+func lineWKT(coords [][2]float64) string {
+	var b strings.Builder
+	b.WriteString("LINESTRING(")
+	for i, c := range coords {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(c[0], 'f', 7, 64))
+		b.WriteByte(' ')
+		b.WriteString(strconv.FormatFloat(c[1], 'f', 7, 64))
+	}
+	b.WriteByte(')')
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package roads
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ------------------------------------------------------------
+// โครงข่ายถนนจากไฟล์ OpenStreetMap (ทำงาน offline จากไฟล์ล้วน)
+//   - อ่าน .osm.pbf หรือ GeoJSON (LineString/MultiLineString) เป็น Extract
+//   - ตัดเส้นทางที่จุดตัด (Edges) ให้ได้ topology แบบที่ pgRouting ใช้
+//   - Load ลงตาราง ways / ways_vertices_pgr แล้ว Snap สถานที่ P/R/A เข้ากับโครงข่าย
+// ------------------------------------------------------------
+
+// Way เส้นทางหนึ่งเส้น (ลำดับ node อ้างอิง Extract.Nodes)
+type Way struct {
+	ID      int64
+	Refs    []int64
+	Highway string
+	Name    string
+	Oneway  bool // ขับได้ทางเดียวตามลำดับ Refs (แปลง oneway=-1 กลับด้านแล้ว)
+}
+
+// Extract ข้อมูลถนนที่อ่านจากไฟล์
+type Extract struct {
+	Nodes map[int64][2]float64 // id → (lon, lat)
+	Ways  []Way
+}
+
+// skipHighway ประเภท highway ที่ไม่ใช่ถนนใช้งานได้จริง
+var skipHighway = map[string]bool{
+	"proposed": true, "construction": true, "abandoned": true, "disused": true,
+	"platform": true, "raceway": true, "razed": true, "bus_stop": true,
+}
+
+// ReadFile อ่านไฟล์ตามนามสกุล: .pbf → OSM PBF, .geojson/.json → GeoJSON
+func ReadFile(path string) (*Extract, error) {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".pbf":
+		return ReadPBF(path)
+	case ".geojson", ".json":
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		return ReadGeoJSON(f)
+	default:
+		return nil, fmt.Errorf("ไม่รองรับไฟล์ %q (ใช้ .osm.pbf หรือ .geojson)", ext)
+	}
+}
+
+// keepHighway ใช้ way นี้หรือไม่ (ต้องมี highway และไม่อยู่ในรายการที่ข้าม)
+func keepHighway(highway string) bool {
+	return highway != "" && !skipHighway[highway]
+}
+
+// parseOneway แปลงแท็ก oneway/junction เป็น (ทางเดียว, กลับด้าน)
+// motorway และวงเวียนถือเป็นทางเดียวตามธรรมเนียมของ OSM
+func parseOneway(highway, oneway, junction string) (bool, bool) {
+	switch strings.ToLower(oneway) {
+	case "yes", "1", "true":
+		return true, false
+	case "-1", "reverse":
+		return true, true
+	case "no", "0", "false":
+		return false, false
+	}
+	return highway == "motorway" || junction == "roundabout" || junction == "circular", false
+}
+
+// haversineM ระยะทรงกลม (เมตร) ให้ตรงกับ ST_DistanceSphere
+func haversineM(a, b [2]float64) float64 {
+	const r = 6370986.0 // รัศมีเดียวกับ ST_DistanceSphere
+	toRad := math.Pi / 180
+	dLat := (b[1] - a[1]) * toRad
+	dLon := (b[0] - a[0]) * toRad
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(a[1]*toRad)*math.Cos(b[1]*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * r * math.Asin(math.Min(1, math.Sqrt(h)))
+}
@@ -0,0 +1,37 @@
+package roads
+
+import "testing"
+
+func TestParseOneway(t *testing.T) {
+	tests := []struct {
+		highway, oneway, junction string
+		want, rev                 bool
+	}{
+		{"residential", "", "", false, false},
+		{"residential", "yes", "", true, false},
+		{"residential", "1", "", true, false},
+		{"residential", "-1", "", true, true},
+		{"residential", "reverse", "", true, true},
+		{"primary", "YES", "", true, false},
+		{"motorway", "", "", true, false},           // motorway ทางเดียวโดยปริยาย
+		{"motorway", "no", "", false, false},        // เว้นแต่ติดแท็ก no
+		{"tertiary", "", "roundabout", true, false}, // วงเวียน
+		{"tertiary", "false", "roundabout", false, false},
+		{"tertiary", "alternating", "", false, false}, // ค่าที่ไม่รู้จัก → สองทาง
+	}
+	for _, tt := range tests {
+		got, rev := parseOneway(tt.highway, tt.oneway, tt.junction)
+		if got != tt.want || rev != tt.rev {
+			t.Errorf("parseOneway(%q, %q, %q) = %v, %v ต้องการ %v, %v",
+				tt.highway, tt.oneway, tt.junction, got, rev, tt.want, tt.rev)
+		}
+	}
+}
+
+func TestKeepHighway(t *testing.T) {
+	for hw, want := range map[string]bool{"": false, "residential": true, "footway": true, "proposed": false, "construction": false} {
+		if got := keepHighway(hw); got != want {
+			t.Errorf("keepHighway(%q) = %v ต้องการ %v", hw, got, want)
+		}
+	}
+}
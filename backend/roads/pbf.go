@@ -0,0 +1,397 @@
+package roads
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ------------------------------------------------------------
+// ตัวอ่าน .osm.pbf (https://wiki.openstreetmap.org/wiki/PBF_Format)
+// ไฟล์คือชุดของ [ความยาว 4 ไบต์][BlobHeader][Blob] โดย Blob เป็น raw หรือ zlib
+// อ่านสองรอบ: รอบแรกเก็บ way ที่เป็นถนน รอบสองเก็บพิกัดเฉพาะ node ที่ถนนใช้
+// (ไฟล์ระดับประเทศมี node หลายสิบล้าน เก็บทั้งหมดไม่ไหว)
+// ------------------------------------------------------------
+
+// maxBlobSize ตามสเปก PBF (32 MiB)
+const maxBlobSize = 32 << 20
+
+// ErrBadPBF ไฟล์ไม่ใช่ PBF หรือเสียหาย
+var ErrBadPBF = errors.New("ไฟล์ .osm.pbf ไม่ถูกต้อง")
+
+// ReadPBF อ่านถนนทั้งหมดจากไฟล์ .osm.pbf
+func ReadPBF(path string) (*Extract, error) {
+	ex := &Extract{Nodes: map[int64][2]float64{}}
+	need := map[int64]bool{}
+
+	err := eachBlock(path, func(blk *block) error {
+		for _, g := range blk.groups {
+			if err := eachField(g, func(num protowire.Number, v []byte) error {
+				if num != 3 { // PrimitiveGroup.ways
+					return nil
+				}
+				w, ok, err := blk.way(v)
+				if err != nil || !ok {
+					return err
+				}
+				for _, id := range w.Refs {
+					need[id] = true
+				}
+				ex.Ways = append(ex.Ways, w)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = eachBlock(path, func(blk *block) error {
+		for _, g := range blk.groups {
+			if err := eachField(g, func(num protowire.Number, v []byte) error {
+				switch num {
+				case 1: // PrimitiveGroup.nodes
+					return blk.node(v, need, ex.Nodes)
+				case 2: // PrimitiveGroup.dense
+					return blk.dense(v, need, ex.Nodes)
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ex, nil
+}
+
+// block PrimitiveBlock ที่ถอดแล้ว (string table + ค่าแปลงพิกัด)
+type block struct {
+	strings     [][]byte
+	groups      [][]byte
+	granularity int64
+	latOffset   int64
+	lonOffset   int64
+}
+
+func (b *block) str(i uint64) string {
+	if i < uint64(len(b.strings)) {
+		return string(b.strings[i])
+	}
+	return ""
+}
+
+func (b *block) coord(lat, lon int64) [2]float64 {
+	return [2]float64{
+		1e-9 * float64(b.lonOffset+b.granularity*lon),
+		1e-9 * float64(b.latOffset+b.granularity*lat),
+	}
+}
+
+// eachBlock เรียก fn กับทุก OSMData block ในไฟล์
+func eachBlock(path string, fn func(*block) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	r := bufio.NewReaderSize(f, 1<<20)
+
+	var lenBuf [4]byte
+	for {
+		if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("%w: %v", ErrBadPBF, err)
+		}
+		hdrLen := binary.BigEndian.Uint32(lenBuf[:])
+		if hdrLen > 64<<10 {
+			return fmt.Errorf("%w: BlobHeader ใหญ่ผิดปกติ", ErrBadPBF)
+		}
+		hdr := make([]byte, hdrLen)
+		if _, err := io.ReadFull(r, hdr); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadPBF, err)
+		}
+
+		var typ string
+		var size int64
+		if err := eachField(hdr, func(num protowire.Number, v []byte) error {
+			if num == 1 {
+				typ = string(v)
+			}
+			return nil
+		}, func(num protowire.Number, x uint64) {
+			if num == 3 {
+				size = int64(x)
+			}
+		}); err != nil {
+			return err
+		}
+		if size <= 0 || size > maxBlobSize {
+			return fmt.Errorf("%w: ขนาด blob %d", ErrBadPBF, size)
+		}
+		raw := make([]byte, size)
+		if _, err := io.ReadFull(r, raw); err != nil {
+			return fmt.Errorf("%w: %v", ErrBadPBF, err)
+		}
+		if typ != "OSMData" {
+			continue // OSMHeader และ blob ที่ไม่รู้จัก
+		}
+
+		data, err := blobData(raw)
+		if err != nil {
+			return err
+		}
+		blk, err := parseBlock(data)
+		if err != nil {
+			return err
+		}
+		if err := fn(blk); err != nil {
+			return err
+		}
+	}
+}
+
+// blobData ถอด Blob เป็นข้อมูลดิบ (รองรับ raw และ zlib)
+func blobData(b []byte) ([]byte, error) {
+	var raw, zdata []byte
+	var rawSize int
+	compressed := false
+	if err := eachField(b, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1:
+			raw = v
+		case 3:
+			zdata = v
+		case 4, 5, 6, 7: // lzma, bzip2, lz4, zstd
+			compressed = true
+		}
+		return nil
+	}, func(num protowire.Number, x uint64) {
+		if num == 2 {
+			rawSize = int(x)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case raw != nil:
+		return raw, nil
+	case zdata != nil:
+		zr, err := zlib.NewReader(bytes.NewReader(zdata))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadPBF, err)
+		}
+		defer zr.Close()
+		out := bytes.NewBuffer(make([]byte, 0, rawSize))
+		if _, err := io.Copy(out, io.LimitReader(zr, maxBlobSize+1)); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBadPBF, err)
+		}
+		return out.Bytes(), nil
+	case compressed:
+		return nil, fmt.Errorf("%w: รองรับเฉพาะ blob แบบ raw/zlib", ErrBadPBF)
+	}
+	return nil, fmt.Errorf("%w: blob ว่าง", ErrBadPBF)
+}
+
+func parseBlock(data []byte) (*block, error) {
+	blk := &block{granularity: 100}
+	err := eachField(data, func(num protowire.Number, v []byte) error {
+		switch num {
+		case 1: // StringTable
+			return eachField(v, func(num protowire.Number, s []byte) error {
+				if num == 1 {
+					blk.strings = append(blk.strings, s)
+				}
+				return nil
+			})
+		case 2:
+			blk.groups = append(blk.groups, v)
+		}
+		return nil
+	}, func(num protowire.Number, x uint64) {
+		switch num {
+		case 17:
+			blk.granularity = int64(x)
+		case 19:
+			blk.latOffset = int64(x)
+		case 20:
+			blk.lonOffset = int64(x)
+		}
+	})
+	return blk, err
+}
+
+// way ถอด Way; ok=false เมื่อไม่ใช่ถนนที่ใช้
+func (b *block) way(v []byte) (Way, bool, error) {
+	var w Way
+	var keys, vals []uint64
+	err := eachField(v, func(num protowire.Number, p []byte) error {
+		var err error
+		switch num {
+		case 2:
+			keys, err = appendVarints(keys, p)
+		case 3:
+			vals, err = appendVarints(vals, p)
+		case 8:
+			var refs []uint64
+			if refs, err = appendVarints(nil, p); err == nil {
+				id := int64(0)
+				for _, d := range refs {
+					id += protowire.DecodeZigZag(d)
+					w.Refs = append(w.Refs, id)
+				}
+			}
+		}
+		return err
+	}, func(num protowire.Number, x uint64) {
+		switch num {
+		case 1:
+			w.ID = int64(x)
+		case 2:
+			keys = append(keys, x)
+		case 3:
+			vals = append(vals, x)
+		}
+	})
+	if err != nil {
+		return w, false, err
+	}
+
+	tags := map[string]string{}
+	for i := 0; i < len(keys) && i < len(vals); i++ {
+		tags[b.str(keys[i])] = b.str(vals[i])
+	}
+	w.Highway, w.Name = tags["highway"], tags["name"]
+	if !keepHighway(w.Highway) || len(w.Refs) < 2 {
+		return w, false, nil
+	}
+	oneway, rev := parseOneway(w.Highway, tags["oneway"], tags["junction"])
+	w.Oneway = oneway
+	if rev {
+		for i, j := 0, len(w.Refs)-1; i < j; i, j = i+1, j-1 {
+			w.Refs[i], w.Refs[j] = w.Refs[j], w.Refs[i]
+		}
+	}
+	return w, true, nil
+}
+
+// node ถอด Node แบบไม่บีบอัด (ไฟล์ส่วนใหญ่ใช้ dense)
+func (b *block) node(v []byte, need map[int64]bool, out map[int64][2]float64) error {
+	var id, lat, lon int64
+	err := eachField(v, nil, func(num protowire.Number, x uint64) {
+		switch num {
+		case 1:
+			id = protowire.DecodeZigZag(x)
+		case 8:
+			lat = protowire.DecodeZigZag(x)
+		case 9:
+			lon = protowire.DecodeZigZag(x)
+		}
+	})
+	if err == nil && need[id] {
+		out[id] = b.coord(lat, lon)
+	}
+	return err
+}
+
+// dense ถอด DenseNodes (id/lat/lon เก็บเป็นผลต่างจากตัวก่อนหน้า)
+func (b *block) dense(v []byte, need map[int64]bool, out map[int64][2]float64) error {
+	var ids, lats, lons []uint64
+	err := eachField(v, func(num protowire.Number, p []byte) error {
+		var err error
+		switch num {
+		case 1:
+			ids, err = appendVarints(ids, p)
+		case 8:
+			lats, err = appendVarints(lats, p)
+		case 9:
+			lons, err = appendVarints(lons, p)
+		}
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if len(lats) != len(ids) || len(lons) != len(ids) {
+		return fmt.Errorf("%w: DenseNodes ไม่ครบ", ErrBadPBF)
+	}
+	var id, lat, lon int64
+	for i := range ids {
+		id += protowire.DecodeZigZag(ids[i])
+		lat += protowire.DecodeZigZag(lats[i])
+		lon += protowire.DecodeZigZag(lons[i])
+		if need[id] {
+			out[id] = b.coord(lat, lon)
+		}
+	}
+	return nil
+}
+
+// eachField วนทุก field ของ message: bytes → fn, varint → onVarint (ถ้ามี)
+func eachField(b []byte, fn func(protowire.Number, []byte) error, onVarint ...func(protowire.Number, uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fmt.Errorf("%w: %v", ErrBadPBF, protowire.ParseError(n))
+		}
+		b = b[n:]
+		switch typ {
+		case protowire.BytesType:
+			v, m := protowire.ConsumeBytes(b)
+			if m < 0 {
+				return fmt.Errorf("%w: %v", ErrBadPBF, protowire.ParseError(m))
+			}
+			if fn != nil {
+				if err := fn(num, v); err != nil {
+					return err
+				}
+			}
+			n = m
+		case protowire.VarintType:
+			x, m := protowire.ConsumeVarint(b)
+			if m < 0 {
+				return fmt.Errorf("%w: %v", ErrBadPBF, protowire.ParseError(m))
+			}
+			for _, f := range onVarint {
+				f(num, x)
+			}
+			n = m
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return fmt.Errorf("%w: %v", ErrBadPBF, protowire.ParseError(n))
+			}
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+// appendVarints ถอด packed varint
+func appendVarints(dst []uint64, p []byte) ([]uint64, error) {
+	for len(p) > 0 {
+		x, n := protowire.ConsumeVarint(p)
+		if n < 0 {
+			return dst, fmt.Errorf("%w: %v", ErrBadPBF, protowire.ParseError(n))
+		}
+		dst = append(dst, x)
+		p = p[n:]
+	}
+	return dst, nil
+}
@@ -0,0 +1,207 @@
+package roads
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// ------------------------------------------------------------
+// fixture: ไฟล์ .osm.pbf เล็ก ๆ ที่สร้างด้วย protowire
+//   node 10-14 (dense), way 1 ซอย (10-11-12), way 2 ถนนหลัก oneway=-1 (11-13), way 3 proposed (ข้าม)
+// ------------------------------------------------------------
+
+func pbMsg(b []byte, num protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
+
+func pbVarint(b []byte, num protowire.Number, x uint64) []byte {
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, x)
+}
+
+// pbPacked packed varint; delta = เก็บเป็นผลต่างแบบ zigzag (ids/refs/lat/lon)
+func pbPacked(b []byte, num protowire.Number, xs []int64, delta bool) []byte {
+	var p []byte
+	prev := int64(0)
+	for _, x := range xs {
+		if delta {
+			p = protowire.AppendVarint(p, protowire.EncodeZigZag(x-prev))
+			prev = x
+		} else {
+			p = protowire.AppendVarint(p, uint64(x))
+		}
+	}
+	return pbMsg(b, num, p)
+}
+
+var fixtureStrings = []string{"", "highway", "residential", "name", "ซอย 1", "primary", "oneway", "-1", "proposed"}
+
+func fixtureWay(id int64, keys, vals, refs []int64) []byte {
+	w := pbVarint(nil, 1, uint64(id))
+	w = pbPacked(w, 2, keys, false)
+	w = pbPacked(w, 3, vals, false)
+	return pbPacked(w, 8, refs, true)
+}
+
+// fixtureDense node 10-14 ที่ (13.75 + i*0.001, 100.5 + i*0.001) หน่วย granularity 100 nanodegree
+func fixtureDense() []byte {
+	var ids, lats, lons []int64
+	for i := int64(0); i < 5; i++ {
+		ids = append(ids, 10+i)
+		lats = append(lats, 137500000+i*10000)
+		lons = append(lons, 1005000000+i*10000)
+	}
+	d := pbPacked(nil, 1, ids, true)
+	d = pbPacked(d, 8, lats, true)
+	return pbPacked(d, 9, lons, true)
+}
+
+func fixtureBlock() []byte {
+	var st []byte
+	for _, s := range fixtureStrings {
+		st = pbMsg(st, 1, []byte(s))
+	}
+	var ways []byte
+	ways = pbMsg(ways, 3, fixtureWay(1, []int64{1, 3}, []int64{2, 4}, []int64{10, 11, 12}))
+	ways = pbMsg(ways, 3, fixtureWay(2, []int64{1, 6}, []int64{5, 7}, []int64{11, 13}))
+	ways = pbMsg(ways, 3, fixtureWay(3, []int64{1}, []int64{8}, []int64{12, 13}))
+
+	b := pbMsg(nil, 1, st)
+	b = pbMsg(b, 2, pbMsg(nil, 2, fixtureDense()))
+	return pbMsg(b, 2, ways)
+}
+
+func rawBlob(data []byte) []byte {
+	return pbMsg(nil, 1, data)
+}
+
+func zlibBlob(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b := pbVarint(nil, 2, uint64(len(data)))
+	return pbMsg(b, 3, z.Bytes())
+}
+
+// writePBF เขียน [ความยาว][BlobHeader][Blob] ของแต่ละ blob ลงไฟล์ชั่วคราว
+func writePBF(t *testing.T, blobs map[string][]byte, order ...string) string {
+	t.Helper()
+	var f []byte
+	for _, typ := range order {
+		blob := blobs[typ]
+		hdr := pbMsg(nil, 1, []byte(typ))
+		hdr = pbVarint(hdr, 3, uint64(len(blob)))
+		f = binary.BigEndian.AppendUint32(f, uint32(len(hdr)))
+		f = append(append(f, hdr...), blob...)
+	}
+	path := filepath.Join(t.TempDir(), "fixture.osm.pbf")
+	if err := os.WriteFile(path, f, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// ------------------------------------------------------------
+// tests
+// ------------------------------------------------------------
+
+func TestBlobData(t *testing.T) {
+	data := fixtureBlock()
+	tests := []struct {
+		name    string
+		blob    []byte
+		wantErr bool
+	}{
+		{"raw", rawBlob(data), false},
+		{"zlib", zlibBlob(t, data), false},
+		{"lzma ไม่รองรับ", pbMsg(nil, 4, []byte{1, 2, 3}), true},
+		{"blob ว่าง", nil, true},
+		{"zlib เสีย", pbMsg(nil, 3, []byte("not zlib")), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := blobData(tt.blob)
+			if tt.wantErr {
+				if !errors.Is(err, ErrBadPBF) {
+					t.Errorf("err = %v ต้องเป็น ErrBadPBF", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, data) {
+				t.Errorf("ได้ %d ไบต์ ไม่ตรงกับข้อมูลเดิม %d ไบต์", len(got), len(data))
+			}
+		})
+	}
+}
+
+func TestDenseDelta(t *testing.T) {
+	blk := &block{granularity: 100}
+	out := map[int64][2]float64{}
+	need := map[int64]bool{10: true, 12: true, 14: true}
+	if err := blk.dense(fixtureDense(), need, out); err != nil {
+		t.Fatal(err)
+	}
+	if len(out) != 3 {
+		t.Fatalf("ได้ %d node ต้องการเฉพาะที่ need (3)", len(out))
+	}
+	for id, want := range map[int64][2]float64{10: {100.5, 13.75}, 12: {100.502, 13.752}, 14: {100.504, 13.754}} {
+		got := out[id]
+		if math.Abs(got[0]-want[0]) > 1e-9 || math.Abs(got[1]-want[1]) > 1e-9 {
+			t.Errorf("node %d = %v ต้องการ %v", id, got, want)
+		}
+	}
+
+	// offset ของ block บวกเพิ่มหลังคูณ granularity
+	blk = &block{granularity: 1000, latOffset: 5, lonOffset: -5}
+	if got := blk.coord(2, 3); math.Abs(got[0]-2.995e-6) > 1e-15 || math.Abs(got[1]-2.005e-6) > 1e-15 {
+		t.Errorf("coord = %v ต้องการ (3000-5, 2000+5) nanodegree", got)
+	}
+}
+
+func TestReadPBF(t *testing.T) {
+	data := fixtureBlock()
+	header := rawBlob(pbMsg(nil, 4, []byte("DenseNodes"))) // OSMHeader ต้องถูกข้าม
+	path := writePBF(t, map[string][]byte{"OSMHeader": header, "OSMData": zlibBlob(t, data)}, "OSMHeader", "OSMData")
+
+	ex, err := ReadPBF(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Way{
+		{ID: 1, Refs: []int64{10, 11, 12}, Highway: "residential", Name: "ซอย 1"},
+		{ID: 2, Refs: []int64{13, 11}, Highway: "primary", Oneway: true}, // oneway=-1 → กลับด้าน
+	}
+	if !reflect.DeepEqual(ex.Ways, want) {
+		t.Errorf("Ways = %+v ต้องการ %+v", ex.Ways, want)
+	}
+	// node 14 ไม่มีถนนใช้ → ไม่เก็บ
+	if len(ex.Nodes) != 4 {
+		t.Errorf("Nodes = %v ต้องการ 10-13", ex.Nodes)
+	}
+	if _, ok := ex.Nodes[14]; ok {
+		t.Errorf("node 14 ไม่มี way ใช้ ต้องไม่ถูกเก็บ")
+	}
+
+	if _, err := ReadPBF(writePBF(t, map[string][]byte{"OSMData": {0xff}}, "OSMData")); !errors.Is(err, ErrBadPBF) {
+		t.Errorf("ไฟล์เสีย: err = %v ต้องเป็น ErrBadPBF", err)
+	}
+}
@@ -0,0 +1,92 @@
+package roads
+
+import (
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// ------------------------------------------------------------
+// พื้นที่ที่ไปถึงได้ตามโครงข่ายถนน (pgr_drivingDistance)
+// ระยะของสถานที่ = snap ต้นทาง + ระยะบนถนน + snap ปลายทาง
+// พื้นที่ = concave hull ของทางแยกที่ไปถึง ขยายออก reachBufferM ให้ครอบสถานที่ริมถนน
+// ------------------------------------------------------------
+
+// reachBufferM ระยะขยายรอบ hull ของทางแยกที่ไปถึง (เมตร)
+const reachBufferM = 100
+
+// Reach ผลของ Reachable
+type Reach struct {
+	Meters map[string]float64 // รหัส P/R/A → ระยะตามถนน (เมตร) ไม่รวมต้นทาง
+	Area   string             // GeoJSON geometry ของพื้นที่ ("" = ไปไม่ถึงทางแยกใดเลย)
+}
+
+// Reachable สถานที่ทุกแห่งที่ไปถึงได้จาก code ภายในระยะถนน maxM เมตร
+// ok=false เมื่อยังไม่มีโครงข่ายหรือ code ไม่ได้ snap (ผู้เรียกใช้ระยะเส้นตรงแทน)
+// directed=false (เดิน) ไม่สนทางเดียว
+func Reachable(db *gorm.DB, code string, maxM float64, directed bool) (*Reach, bool, error) {
+	if !HasNetwork(db) {
+		return nil, false, nil
+	}
+	var src []snapRow
+	if err := db.Raw(`
+		SELECT s.code, s.vertex_id, s.snap_m, ST_X(v.the_geom) AS lon, ST_Y(v.the_geom) AS lat
+		FROM place_snap s
+		JOIN ways_vertices_pgr v ON v.id = s.vertex_id
+		WHERE s.code = ?`, code).Scan(&src).Error; err != nil {
+		return nil, false, err
+	}
+	if len(src) == 0 {
+		return nil, false, nil
+	}
+	s := src[0]
+	res := &Reach{Meters: map[string]float64{}}
+	budget := maxM - s.SnapM
+	if budget < 0 {
+		return res, true, nil
+	}
+
+	// ถนนเฉพาะในกรอบรัศมี budget รอบต้นทาง (ทางอ้อมยาวกว่า budget อยู่แล้ว)
+	dLat := budget/111320 + bboxMarginDeg
+	dLon := budget/(111320*math.Max(0.1, math.Cos(s.Lat*math.Pi/180))) + bboxMarginDeg
+	reverse := "reverse_cost"
+	if !directed {
+		reverse = "cost"
+	}
+	edges := fmt.Sprintf(`SELECT gid AS id, source, target, cost, %s AS reverse_cost FROM ways
+		WHERE the_geom && ST_MakeEnvelope(%f, %f, %f, %f, 4326)`, reverse,
+		s.Lon-dLon, s.Lat-dLat, s.Lon+dLon, s.Lat+dLat)
+
+	var rows []struct {
+		Code   string
+		Meters float64
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(`CREATE TEMP TABLE reach_dd ON COMMIT DROP AS
+			SELECT node, agg_cost FROM pgr_drivingDistance(?, ?::bigint, ?::float8, directed := true)`,
+			edges, s.VertexID, budget).Error; err != nil {
+			return err
+		}
+		if err := tx.Raw(`
+			SELECT p.code, ? + d.agg_cost + p.snap_m AS meters
+			FROM reach_dd d
+			JOIN place_snap p ON p.vertex_id = d.node
+			WHERE p.code <> ? AND ? + d.agg_cost + p.snap_m <= ?`,
+			s.SnapM, code, s.SnapM, maxM).Scan(&rows).Error; err != nil {
+			return err
+		}
+		return tx.Raw(`
+			SELECT COALESCE(ST_AsGeoJSON(
+				ST_Buffer(ST_ConcaveHull(ST_Collect(v.the_geom), 0.8)::geography, ?)::geometry, 6), '')
+			FROM reach_dd d
+			JOIN ways_vertices_pgr v ON v.id = d.node`, reachBufferM).Scan(&res.Area).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	for _, r := range rows {
+		res.Meters[r.Code] = r.Meters
+	}
+	return res, true, nil
+}
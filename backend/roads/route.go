@@ -0,0 +1,129 @@
+package roads
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ------------------------------------------------------------
+// ระยะตามโครงข่ายถนนระหว่างสถานที่ (pgr_dijkstra แบบ many-to-many)
+// ระยะ = ระยะ snap ต้นทาง + ระยะบนถนน + ระยะ snap ปลายทาง
+// ค้นเฉพาะถนนในกรอบที่ครอบทุกจุด + ขอบ bboxMarginDeg กันกราฟใหญ่ทั้งประเทศ
+// ------------------------------------------------------------
+
+// bboxMarginDeg ขอบรอบกรอบของจุดทั้งหมด (~5.5 km) ให้เส้นทางอ้อมออกนอกกรอบได้บ้าง
+const bboxMarginDeg = 0.05
+
+// Route ผลของหนึ่งคู่
+type Route struct {
+	Meters   float64
+	Polyline string // Google encoded polyline (precision 5) ว่างเมื่อไม่ได้ขอหรืออยู่ vertex เดียวกัน
+}
+
+type snapRow struct {
+	Code     string
+	VertexID int64
+	SnapM    float64
+	Lon      float64
+	Lat      float64
+}
+
+// Routes ระยะถนนทุกคู่ของ codes ที่ snap ได้และไปถึงกันได้
+// directed=false (เดิน) ไม่สนทางเดียว; polyline=true คืนรูปเส้นทางด้วย (ช้ากว่า)
+// คู่ที่ไม่อยู่ในผลลัพธ์ = ไม่ได้ snap หรือไม่มีเส้นทาง (ผู้เรียกใช้ระยะเส้นตรงแทน)
+func Routes(db *gorm.DB, codes []string, directed, polyline bool) (map[[2]string]Route, error) {
+	out := map[[2]string]Route{}
+	if len(codes) < 2 || !HasNetwork(db) {
+		return out, nil
+	}
+
+	var snaps []snapRow
+	if err := db.Raw(`
+		SELECT s.code, s.vertex_id, s.snap_m, ST_X(v.the_geom) AS lon, ST_Y(v.the_geom) AS lat
+		FROM place_snap s
+		JOIN ways_vertices_pgr v ON v.id = s.vertex_id
+		WHERE s.code IN ?`, codes).Scan(&snaps).Error; err != nil {
+		return nil, err
+	}
+	if len(snaps) < 2 {
+		return out, nil
+	}
+
+	byVertex := map[int64][]snapRow{}
+	minLon, minLat, maxLon, maxLat := math.Inf(1), math.Inf(1), math.Inf(-1), math.Inf(-1)
+	for _, s := range snaps {
+		byVertex[s.VertexID] = append(byVertex[s.VertexID], s)
+		minLon, maxLon = math.Min(minLon, s.Lon), math.Max(maxLon, s.Lon)
+		minLat, maxLat = math.Min(minLat, s.Lat), math.Max(maxLat, s.Lat)
+	}
+
+	// หลายสถานที่ snap ลง vertex เดียวกัน → ระยะคือ snap สองฝั่งรวมกัน
+	for _, group := range byVertex {
+		for _, a := range group {
+			for _, b := range group {
+				if a.Code != b.Code {
+					out[[2]string{a.Code, b.Code}] = Route{Meters: a.SnapM + b.SnapM}
+				}
+			}
+		}
+	}
+	if len(byVertex) < 2 {
+		return out, nil
+	}
+
+	ids := make([]string, 0, len(byVertex))
+	for v := range byVertex {
+		ids = append(ids, strconv.FormatInt(v, 10))
+	}
+	vertexArr := "ARRAY[" + strings.Join(ids, ",") + "]::bigint[]"
+
+	reverse := "reverse_cost"
+	if !directed {
+		reverse = "cost"
+	}
+	edges := fmt.Sprintf(`SELECT gid AS id, source, target, cost, %s AS reverse_cost FROM ways
+		WHERE the_geom && ST_MakeEnvelope(%f, %f, %f, %f, 4326)`, reverse,
+		minLon-bboxMarginDeg, minLat-bboxMarginDeg, maxLon+bboxMarginDeg, maxLat+bboxMarginDeg)
+
+	type pathRow struct {
+		StartVid int64
+		EndVid   int64
+		Cost     float64
+		Polyline string
+	}
+	var rows []pathRow
+	var q string
+	if polyline {
+		q = `
+			WITH r AS (
+				SELECT * FROM pgr_dijkstra(?, ` + vertexArr + `, ` + vertexArr + `, directed := true)
+			)
+			SELECT r.start_vid, r.end_vid, SUM(r.cost) AS cost,
+				ST_AsEncodedPolyline(ST_MakeLine(
+					CASE WHEN w.source = r.node THEN w.the_geom ELSE ST_Reverse(w.the_geom) END
+					ORDER BY r.path_seq
+				)) AS polyline
+			FROM r
+			JOIN ways w ON w.gid = r.edge
+			GROUP BY r.start_vid, r.end_vid`
+	} else {
+		q = `SELECT start_vid, end_vid, agg_cost AS cost
+			FROM pgr_dijkstraCost(?, ` + vertexArr + `, ` + vertexArr + `, directed := true)`
+	}
+	if err := db.Raw(q, edges).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	for _, r := range rows {
+		for _, a := range byVertex[r.StartVid] {
+			for _, b := range byVertex[r.EndVid] {
+				out[[2]string{a.Code, b.Code}] = Route{Meters: a.SnapM + r.Cost + b.SnapM, Polyline: r.Polyline}
+			}
+		}
+	}
+	return out, nil
+}
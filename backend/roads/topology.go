@@ -0,0 +1,96 @@
+package roads
+
+// ------------------------------------------------------------
+// Topology: ตัด way ที่ node ที่ใช้ร่วมกัน (ทางแยก) ให้เป็นเส้นย่อย
+// หัว-ท้ายของเส้นย่อยคือ vertex ของกราฟ (source/target ของ pgRouting)
+// vertex id เรียงใหม่เป็น 1..N ตามลำดับที่พบ (ผลเหมือนเดิมทุกครั้งกับไฟล์เดิม)
+// ------------------------------------------------------------
+
+// Edge เส้นย่อยระหว่างทางแยกสองจุด
+type Edge struct {
+	OsmID   int64
+	Source  int64
+	Target  int64
+	Coords  [][2]float64 // (lon, lat) จาก Source ไป Target
+	LengthM float64
+	Highway string
+	Name    string
+	Oneway  bool
+}
+
+// Vertex ทางแยก/ปลายทาง
+type Vertex struct {
+	ID    int64
+	OsmID int64
+	Lon   float64
+	Lat   float64
+}
+
+// Network ผลของ Build
+type Network struct {
+	Edges    []Edge
+	Vertices []Vertex
+}
+
+// Build สร้างโครงข่ายจาก Extract (way ที่ node หายไปจากไฟล์ถูกตัดตรงช่วงที่หาย)
+func (ex *Extract) Build() *Network {
+	uses := map[int64]int{}
+	for _, w := range ex.Ways {
+		for i, id := range w.Refs {
+			uses[id]++
+			if i == 0 || i == len(w.Refs)-1 {
+				uses[id]++ // ปลายเส้นเป็น vertex เสมอ
+			}
+		}
+	}
+
+	net := &Network{}
+	vid := map[int64]int64{}
+	vertex := func(osm int64) int64 {
+		if id, ok := vid[osm]; ok {
+			return id
+		}
+		c := ex.Nodes[osm]
+		id := int64(len(net.Vertices) + 1)
+		vid[osm] = id
+		net.Vertices = append(net.Vertices, Vertex{ID: id, OsmID: osm, Lon: c[0], Lat: c[1]})
+		return id
+	}
+
+	for _, w := range ex.Ways {
+		var seg []int64
+		emit := func() {
+			if len(seg) >= 2 {
+				e := Edge{
+					OsmID: w.ID, Highway: w.Highway, Name: w.Name, Oneway: w.Oneway,
+					Source: vertex(seg[0]), Target: vertex(seg[len(seg)-1]),
+				}
+				for i, id := range seg {
+					c := ex.Nodes[id]
+					if i > 0 {
+						e.LengthM += haversineM(e.Coords[i-1], c)
+					}
+					e.Coords = append(e.Coords, c)
+				}
+				if e.Source != e.Target || e.LengthM > 0 {
+					net.Edges = append(net.Edges, e)
+				}
+			}
+			seg = seg[:0]
+		}
+
+		for _, id := range w.Refs {
+			if _, ok := ex.Nodes[id]; !ok {
+				emit() // node อยู่นอกขอบเขตไฟล์
+				continue
+			}
+			seg = append(seg, id)
+			if len(seg) > 1 && uses[id] > 1 {
+				emit()
+				seg = append(seg, id)
+			}
+		}
+		emit()
+	}
+	return net
+}
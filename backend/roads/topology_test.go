@@ -0,0 +1,72 @@
+package roads
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// edgeKeys "osm:source-target" ของทุก edge (vertex ใช้ osm id ให้อ่านง่าย)
+func edgeKeys(net *Network) []string {
+	osm := map[int64]int64{}
+	for _, v := range net.Vertices {
+		osm[v.ID] = v.OsmID
+	}
+	out := make([]string, len(net.Edges))
+	for i, e := range net.Edges {
+		out[i] = fmt.Sprintf("%d:%d-%d", e.OsmID, osm[e.Source], osm[e.Target])
+	}
+	return out
+}
+
+func TestBuildSplitsAtSharedNodes(t *testing.T) {
+	//      4
+	//      |
+	// 1 -- 2 -- 3        way 10: 1-2-3, way 20: 4-2-5-6 (5 อยู่กลางเส้น ไม่ใช่ทางแยก)
+	//      |
+	//      5 -- 6
+	ex := &Extract{
+		Nodes: map[int64][2]float64{
+			1: {100.50, 13.75}, 2: {100.51, 13.75}, 3: {100.52, 13.75},
+			4: {100.51, 13.76}, 5: {100.51, 13.74}, 6: {100.52, 13.74},
+		},
+		Ways: []Way{
+			{ID: 10, Refs: []int64{1, 2, 3}, Highway: "residential"},
+			{ID: 20, Refs: []int64{4, 2, 5, 6}, Highway: "primary", Oneway: true},
+		},
+	}
+	net := ex.Build()
+
+	if want := []string{"10:1-2", "10:2-3", "20:4-2", "20:2-6"}; !reflect.DeepEqual(edgeKeys(net), want) {
+		t.Errorf("edges = %v ต้องการ %v", edgeKeys(net), want)
+	}
+	// vertex เฉพาะปลายเส้นและทางแยก เรียง id ตามลำดับที่พบ
+	var osm []int64
+	for i, v := range net.Vertices {
+		if v.ID != int64(i+1) {
+			t.Errorf("vertex %d มี id %d", i, v.ID)
+		}
+		osm = append(osm, v.OsmID)
+	}
+	if want := []int64{1, 2, 3, 4, 6}; !reflect.DeepEqual(osm, want) {
+		t.Errorf("vertices = %v ต้องการ %v", osm, want)
+	}
+
+	last := net.Edges[3]
+	if len(last.Coords) != 3 || !last.Oneway || last.Highway != "primary" {
+		t.Errorf("edge 2-5-6 = %+v", last)
+	}
+	if want := haversineM(ex.Nodes[2], ex.Nodes[5]) + haversineM(ex.Nodes[5], ex.Nodes[6]); last.LengthM != want {
+		t.Errorf("LengthM = %v ต้องการ %v", last.LengthM, want)
+	}
+}
+
+func TestBuildCutsAtMissingNodes(t *testing.T) {
+	ex := &Extract{
+		Nodes: map[int64][2]float64{1: {100.50, 13.75}, 2: {100.51, 13.75}, 4: {100.53, 13.75}, 5: {100.54, 13.75}},
+		Ways:  []Way{{ID: 10, Refs: []int64{1, 2, 3, 4, 5}, Highway: "residential"}}, // node 3 อยู่นอกไฟล์
+	}
+	if got, want := edgeKeys(ex.Build()), []string{"10:1-2", "10:4-5"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("edges = %v ต้องการ %v", got, want)
+	}
+}
@@ -0,0 +1,243 @@
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ------------------------------------------------------------
+// ดัชนีค้นหาชื่อ/ที่อยู่ของสถานที่ (ในหน่วยความจำ)
+//   - ภาษาไทยไม่เว้นวรรคระหว่างคำ → ตัดเป็น bigram ของตัวอักษรแทนการตัดคำ
+//     ("วัดพระแก้ว" → วั ัด ดพ พร ...) คำค้นที่อยู่กลางคำก็เจอ
+//   - fold: ตัวพิมพ์เล็ก, ตัดวรรณยุกต์/การันต์/ไม้ไต่คู้ (พิมพ์ผิดบ่อยสุด), เครื่องหมายเป็นช่องว่าง
+//   - พิมพ์ผิด: อักษรผิด 1 ตัวทำให้ bigram หายไม่เกิน 2 ตัว → ยอมให้ขาดได้ตามความยาวคำค้น (allowedMiss)
+//   - คะแนน: สัดส่วน bigram ที่ตรง × น้ำหนักของฟิลด์ + โบนัสตรงทั้งคำ/ขึ้นต้นชื่อ
+// ------------------------------------------------------------
+
+// Field ฟิลด์ของเอกสารที่ถูกค้น
+type Field uint8
+
+const (
+	FieldName Field = iota
+	FieldTypes
+	FieldDistrict
+	FieldSubDistrict
+	FieldProvince
+	FieldAddress
+	numFields
+)
+
+var fieldNames = [numFields]string{"name", "types", "district", "sub_district", "province", "address"}
+
+// fieldWeight ชื่อสำคัญสุด ที่อยู่เต็มรองสุดท้าย (ยาวและซ้ำกันมาก)
+var fieldWeight = [numFields]float64{1.0, 0.6, 0.5, 0.45, 0.4, 0.25}
+
+func (f Field) String() string { return fieldNames[f] }
+
+// Doc สถานที่หนึ่งแห่ง
+type Doc struct {
+	Code     string // P/R/A + id
+	Kind     string // landmark | restaurant | accommodation
+	ID       int
+	Name     string
+	Province string
+	District string
+	Review   int
+	Fields   [numFields]string
+}
+
+type posting struct {
+	doc   int32
+	field Field
+}
+
+// Index ดัชนี bigram → (เอกสาร, ฟิลด์); สร้างแล้วอ่านอย่างเดียว ใช้พร้อมกันหลาย goroutine ได้
+type Index struct {
+	docs   []Doc
+	folded [][numFields]string // ข้อความหลัง fold ต่อฟิลด์ (ใช้ตรวจตรงทั้งคำ)
+	grams  [][numFields]int    // จำนวน bigram ไม่ซ้ำต่อฟิลด์
+	post   map[string][]posting
+}
+
+// Hit ผลการค้นหาหนึ่งรายการ
+type Hit struct {
+	Code     string  `json:"code"`
+	Kind     string  `json:"kind"`
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Province string  `json:"province,omitempty"`
+	District string  `json:"district,omitempty"`
+	Match    string  `json:"match"` // ฟิลด์ที่ให้คะแนนสูงสุด
+	Score    float64 `json:"score"`
+}
+
+// Build สร้างดัชนีจาก docs
+func Build(docs []Doc) *Index {
+	idx := &Index{
+		docs:   docs,
+		folded: make([][numFields]string, len(docs)),
+		grams:  make([][numFields]int, len(docs)),
+		post:   map[string][]posting{},
+	}
+	for i, d := range docs {
+		for f := Field(0); f < numFields; f++ {
+			s := Fold(d.Fields[f])
+			idx.folded[i][f] = s
+			gs := Grams(s)
+			idx.grams[i][f] = len(gs)
+			for _, g := range gs {
+				idx.post[g] = append(idx.post[g], posting{doc: int32(i), field: f})
+			}
+		}
+	}
+	return idx
+}
+
+// Len จำนวนเอกสารในดัชนี
+func (idx *Index) Len() int { return len(idx.docs) }
+
+// Search คำค้น q เฉพาะชนิด kinds (เช่น "PRA") เรียงตามคะแนน ได้ไม่เกิน limit รายการ
+func (idx *Index) Search(q, kinds string, limit int) []Hit {
+	fq := Fold(q)
+	qg := Grams(fq)
+	if len(qg) == 0 {
+		return []Hit{}
+	}
+	need := len(qg) - allowedMiss(fq)
+
+	// นับ bigram ที่ตรงต่อ (เอกสาร, ฟิลด์)
+	matched := map[int32]*[numFields]int{}
+	for _, g := range qg {
+		for _, p := range idx.post[g] {
+			m := matched[p.doc]
+			if m == nil {
+				m = new([numFields]int)
+				matched[p.doc] = m
+			}
+			m[p.field]++
+		}
+	}
+
+	hits := make([]Hit, 0, len(matched))
+	for di, m := range matched {
+		d := &idx.docs[di]
+		if !strings.Contains(kinds, d.Code[:1]) {
+			continue
+		}
+		best, bestField := 0.0, Field(0)
+		for f := Field(0); f < numFields; f++ {
+			if m[f] < need {
+				continue
+			}
+			s := fieldWeight[f] * fieldScore(fq, idx.folded[di][f], m[f], len(qg), idx.grams[di][f])
+			if s > best {
+				best, bestField = s, f
+			}
+		}
+		if best == 0 {
+			continue
+		}
+		// สถานที่ที่มีรีวิวมากขึ้นก่อนเมื่อคะแนนใกล้กัน
+		best += 0.01 * math.Log1p(float64(max(d.Review, 0)))
+		hits = append(hits, Hit{
+			Code: d.Code, Kind: d.Kind, ID: d.ID, Name: d.Name, Province: d.Province, District: d.District,
+			Match: bestField.String(), Score: math.Round(best*1000) / 1000,
+		})
+	}
+
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if len(hits[i].Name) != len(hits[j].Name) {
+			return len(hits[i].Name) < len(hits[j].Name)
+		}
+		return hits[i].Code < hits[j].Code
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// fieldScore คะแนน 0..~1.6 ของฟิลด์หนึ่ง
+//   - ส่วนหลัก: สัดส่วน bigram ของคำค้นที่เจอ
+//   - ส่วนรอง: สัดส่วนของฟิลด์ที่คำค้นครอบ (ชื่อสั้นที่ตรงเป๊ะชนะชื่อยาว)
+//   - โบนัส: คำค้นเป็น substring (+0.3) และฟิลด์ขึ้นต้นด้วยคำค้น (+0.2)
+func fieldScore(fq, text string, hit, qGrams, fGrams int) float64 {
+	s := float64(hit) / float64(qGrams)
+	if fGrams > 0 {
+		s += 0.1 * float64(min(hit, fGrams)) / float64(fGrams)
+	}
+	if strings.Contains(text, fq) {
+		s += 0.3
+		if strings.HasPrefix(text, fq) {
+			s += 0.2
+		}
+	}
+	return s
+}
+
+// allowedMiss จำนวน bigram ที่ขาดได้ (พิมพ์ผิด) ตามความยาวคำค้น
+// สั้นมากต้องตรงหมด ไม่งั้น "วัด" จะไปเจอทุกอย่างที่มี "ัด"
+func allowedMiss(fq string) int {
+	n := len([]rune(strings.ReplaceAll(fq, " ", "")))
+	switch {
+	case n <= 3:
+		return 0
+	case n <= 5:
+		return 1
+	case n <= 9:
+		return 2
+	default:
+		return n / 4
+	}
+}
+
+// Fold ทำข้อความให้อยู่ในรูปเทียบกันได้ (ใช้ทั้งตอนสร้างดัชนีและตอนค้น)
+func Fold(s string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case r >= 0x0E47 && r <= 0x0E4E: // ็ ่ ้ ๊ ๋ ์ ํ ๎
+			continue
+		case r == 0x200B || r == 0x200C || r == 0x200D || r == 0xFEFF: // zero-width
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Thai, r):
+			b.WriteRune(r)
+			space = false
+		default:
+			if !space {
+				b.WriteByte(' ')
+				space = true
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Grams bigram ไม่ซ้ำของข้อความที่ fold แล้ว (คำยาว 1 ตัวอักษรใช้ตัวมันเอง)
+func Grams(folded string) []string {
+	seen := map[string]bool{}
+	var out []string
+	add := func(g string) {
+		if !seen[g] {
+			seen[g] = true
+			out = append(out, g)
+		}
+	}
+	for _, w := range strings.Fields(folded) {
+		rs := []rune(w)
+		if len(rs) == 1 {
+			add(w)
+			continue
+		}
+		for i := 0; i+1 < len(rs); i++ {
+			add(string(rs[i : i+2]))
+		}
+	}
+	return out
+}
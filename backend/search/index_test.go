@@ -0,0 +1,127 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFold(t *testing.T) {
+	tests := []struct{ in, want string }{
+		{"วัดพระแก้ว", "วัดพระแกว"},
+		{"ไก่ย่าง", "ไกยาง"},        // ่ ้
+		{"ก๋วยเตี๋ยว", "กวยเตียว"},  // ๋
+		{"เก็บ", "เกบ"},             // ไม้ไต่คู้
+		{"จันทร์", "จันทร"},         // การันต์
+		{"ตลาด\u200bน้ำ", "ตลาดนำ"}, // zero-width space
+		{"  Café, BANGKOK!! ", "café bangkok"},
+		{"ถ.สุขุมวิท-ซ.11", "ถ สุขุมวิท ซ 11"},
+	}
+	for _, tt := range tests {
+		if got := Fold(tt.in); got != tt.want {
+			t.Errorf("Fold(%q) = %q ต้องการ %q", tt.in, got, tt.want)
+		}
+	}
+	// ใส่วรรณยุกต์ผิดที่ก็ยังเทียบกันได้
+	if Fold("ไก้ย่าง") != Fold("ไก่ยาง") {
+		t.Errorf("วรรณยุกต์ต่างกันต้อง fold ได้ค่าเดียวกัน")
+	}
+}
+
+func TestGrams(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"วัด", []string{"วั", "ัด"}},
+		{"a bc", []string{"a", "bc"}},
+		{"aaa", []string{"aa"}}, // ไม่ซ้ำ
+		{"", nil},
+	}
+	for _, tt := range tests {
+		if got := Grams(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Grams(%q) = %q ต้องการ %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestAllowedMiss(t *testing.T) {
+	tests := []struct {
+		fq   string
+		want int
+	}{
+		{"วัด", 0},
+		{"ab c", 0}, // ไม่นับช่องว่าง
+		{"ตลาด", 1},
+		{"กาแฟสด", 2},
+		{"วัดพระแกว", 2},
+		{"วัดพระเชตุพนวิมล", 4},
+	}
+	for _, tt := range tests {
+		if got := allowedMiss(tt.fq); got != tt.want {
+			t.Errorf("allowedMiss(%q) = %d ต้องการ %d", tt.fq, got, tt.want)
+		}
+	}
+}
+
+func testIndex() *Index {
+	doc := func(code, name, address string) Doc {
+		d := Doc{Code: code, Name: name}
+		d.Fields[FieldName], d.Fields[FieldAddress] = name, address
+		return d
+	}
+	return Build([]Doc{
+		doc("P1", "วัดพระแก้ว", "ถนนหน้าพระลาน เขตพระนคร"),
+		doc("R1", "ร้านกาแฟริมน้ำ", "ซอยวัดอรุณ เขตบางกอกใหญ่"),
+		doc("P2", "ผ้ามัดหมี่บ้านเขว้า", "อำเภอเมือง ชัยภูมิ"),
+		doc("A1", "Bangkok Riverside Hotel", "Charoen Krung Road"),
+	})
+}
+
+func hitCodes(hits []Hit) []string {
+	out := make([]string, len(hits))
+	for i, h := range hits {
+		out[i] = h.Code
+	}
+	return out
+}
+
+func TestSearch(t *testing.T) {
+	idx := testIndex()
+	tests := []struct {
+		name, q, kinds string
+		want           []string
+	}{
+		// ชื่อมาก่อนที่อยู่; "มัดหมี่" มีแค่ "ัด" ไม่ถูกนับว่าตรง "วัด"
+		{"ชื่อก่อนที่อยู่", "วัด", "PRA", []string{"P1", "R1"}},
+		{"พิมพ์ผิดหนึ่งตัว", "วัดพระแก้ม", "PRA", []string{"P1"}},
+		{"ขาดสระหนึ่งตัว", "วดพระแกว", "PRA", []string{"P1"}},
+		{"อังกฤษพิมพ์ผิด", "bangkak", "PRA", []string{"A1"}},
+		{"กรองชนิด", "วัด", "R", []string{"R1"}},
+		{"ไม่เกี่ยว", "ตลาดน้ำ", "PRA", []string{}},
+		{"คำค้นว่าง", " !! ", "PRA", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hitCodes(idx.Search(tt.q, tt.kinds, 10)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Search(%q) = %v ต้องการ %v", tt.q, got, tt.want)
+			}
+		})
+	}
+
+	hits := idx.Search("วัด", "PRA", 10)
+	if hits[0].Match != "name" || hits[1].Match != "address" || hits[0].Score <= hits[1].Score {
+		t.Errorf("ชื่อต้องได้คะแนนสูงกว่าที่อยู่: %+v", hits)
+	}
+	if got := idx.Search("วัด", "PRA", 1); len(got) != 1 {
+		t.Errorf("limit 1 ได้ %d รายการ", len(got))
+	}
+}
+
+func TestSearchReviewTiebreak(t *testing.T) {
+	a := Doc{Code: "P1", Name: "ตลาดน้ำ"}
+	b := Doc{Code: "P2", Name: "ตลาดน้ำ", Review: 50}
+	a.Fields[FieldName], b.Fields[FieldName] = a.Name, b.Name
+	if got := hitCodes(Build([]Doc{a, b}).Search("ตลาดน้ำ", "P", 0)); !reflect.DeepEqual(got, []string{"P2", "P1"}) {
+		t.Errorf("ชื่อเท่ากัน ต้องเรียงตามรีวิว: %v", got)
+	}
+}
@@ -0,0 +1,97 @@
+package search
+
+import (
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ------------------------------------------------------------
+// โหลดเอกสารจากฐานข้อมูลหลัก + ดัชนีที่ใช้ร่วมกันทั้ง process
+//   - สร้างครั้งแรกที่มีคนค้น แล้วใช้ซ้ำจนกว่าจะเก่ากว่า maxAge หรือถูก Invalidate
+//   - controller ที่แก้ landmark/restaurant/accommodation เรียก Invalidate หลังบันทึก
+// ------------------------------------------------------------
+
+// maxAge อายุสูงสุดของดัชนี (กันกรณีข้อมูลถูกแก้นอก API เช่น import)
+const maxAge = 10 * time.Minute
+
+// docsSQL ทุกสถานที่ที่ยังไม่ถูกลบ พร้อมชื่อประเภท (คั่นด้วย |)
+const docsSQL = `
+SELECT 'P' || x.id AS code, 'landmark' AS kind, x.id, x.name, x.address, x.province, x.district, x.sub_district, x.review,
+  (SELECT GROUP_CONCAT(t.name, '|') FROM landmark_types pv JOIN travel_types t ON t.id = pv.type_id
+   WHERE pv.landmark_id = x.id) AS types
+FROM landmarks x WHERE x.deleted_at IS NULL
+UNION ALL
+SELECT 'R' || x.id, 'restaurant', x.id, x.name, x.address, x.province, x.district, x.sub_district, x.review,
+  (SELECT GROUP_CONCAT(t.name, '|') FROM restaurant_types pv JOIN travel_types t ON t.id = pv.type_id
+   WHERE pv.restaurant_id = x.id)
+FROM restaurants x WHERE x.deleted_at IS NULL
+UNION ALL
+SELECT 'A' || x.id, 'accommodation', x.id, x.name, x.address, x.province, x.district, x.sub_district, x.review,
+  (SELECT GROUP_CONCAT(t.name, '|') FROM accommodation_types pv JOIN travel_types t ON t.id = pv.type_id
+   WHERE pv.accommodation_id = x.id)
+FROM accommodations x WHERE x.deleted_at IS NULL`
+
+// Load อ่านเอกสารทั้งหมดจากฐานข้อมูลหลัก (SQLite)
+func Load(db *gorm.DB) ([]Doc, error) {
+	var rows []struct {
+		Code        string
+		Kind        string
+		ID          int
+		Name        string
+		Address     string
+		Province    string
+		District    string
+		SubDistrict string
+		Review      int
+		Types       string
+	}
+	if err := db.Raw(docsSQL).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	docs := make([]Doc, len(rows))
+	for i, r := range rows {
+		docs[i] = Doc{Code: r.Code, Kind: r.Kind, ID: r.ID, Name: r.Name,
+			Province: r.Province, District: r.District, Review: r.Review}
+		docs[i].Fields[FieldName] = r.Name
+		docs[i].Fields[FieldTypes] = strings.ReplaceAll(r.Types, "|", " ")
+		docs[i].Fields[FieldDistrict] = r.District
+		docs[i].Fields[FieldSubDistrict] = r.SubDistrict
+		docs[i].Fields[FieldProvince] = r.Province
+		docs[i].Fields[FieldAddress] = r.Address
+	}
+	return docs, nil
+}
+
+var shared struct {
+	sync.Mutex
+	idx   *Index
+	built time.Time
+}
+
+// Shared ดัชนีปัจจุบัน (สร้างใหม่เมื่อยังไม่มี/เก่า; สร้างไม่สำเร็จแต่มีของเดิม → ใช้ของเดิมไปก่อน)
+func Shared(db *gorm.DB) (*Index, error) {
+	shared.Lock()
+	defer shared.Unlock()
+	if shared.idx != nil && time.Since(shared.built) < maxAge {
+		return shared.idx, nil
+	}
+	docs, err := Load(db)
+	if err != nil {
+		if shared.idx != nil {
+			return shared.idx, nil
+		}
+		return nil, err
+	}
+	shared.idx, shared.built = Build(docs), time.Now()
+	return shared.idx, nil
+}
+
+// Invalidate ให้ครั้งถัดไปสร้างดัชนีใหม่
+func Invalidate() {
+	shared.Lock()
+	shared.idx = nil
+	shared.Unlock()
+}
@@ -0,0 +1,143 @@
+// Package timeline คำนวณเวลาเริ่ม-จบของแต่ละจุดในหนึ่งวัน
+// จากเวลาเริ่มวัน + เวลาเที่ยวต่อหมวด (P/R/A) + เวลาเดินทางตามประเภท (เดิน/รถ)
+// ใช้ร่วมกันทั้ง planner (ตอนสร้างทริป) และ Shortestpath (ตอนแก้ไขทริป)
+package timeline
+
+import (
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/gtwndtl/trip-spark-builder/domain"
+)
+
+const (
+	Walk    = "walk"
+	Car     = "car"
+	Transit = "transit"
+)
+
+// Config ค่าตั้งของตัวจัดเวลา
+type Config struct {
+	DayStart    int            // นาทีนับจากเที่ยงคืน
+	Visit       map[string]int // นาทีที่ใช้ต่อจุด ตาม prefix ของรหัส (P/R/A)
+	Profiles    ProfileModel   // ความเร็ว/ตัวคูณระยะ/เวลาคงที่ ตามแบบการเดินทาง (walk/car/transit)
+	DefaultMode string         // ใช้เมื่อ Type ไม่ใช่ เดิน/รถ (เช่น "Activity")
+
+	// Travel โมเดลเวลาเดินทางที่ใช้แทน Profiles (nil = ใช้ Profiles)
+	Travel Model
+}
+
+// DefaultConfig เริ่ม 09:00, แลนด์มาร์ก 90 นาที, ร้าน 60 นาที, ที่พักไม่คิดเวลา, การเดินทางตาม DefaultProfiles
+func DefaultConfig() Config {
+	return Config{
+		DayStart:    9 * 60,
+		Visit:       map[string]int{"P": 90, "R": 60, "A": 0},
+		Profiles:    DefaultProfiles(),
+		DefaultMode: Car,
+	}
+}
+
+// FromEnv DefaultConfig แล้วทับด้วย env (ถ้ามี)
+//
+//	TIMELINE_DAY_START=08:30
+//	TIMELINE_VISIT_P=120  TIMELINE_VISIT_R=45  TIMELINE_VISIT_A=0
+//	TIMELINE_WALK_KMH=4   TIMELINE_CAR_KMH=30   TIMELINE_TRANSIT_KMH=15
+//	TIMELINE_CAR_DETOUR=1.4  TIMELINE_CAR_OVERHEAD=10  TIMELINE_WALK_MAX_KM=1.5
+func FromEnv() Config {
+	c := DefaultConfig()
+	if m, _, err := domain.ParseClock(os.Getenv("TIMELINE_DAY_START")); err == nil && m >= 0 {
+		c.DayStart = m
+	}
+	for _, k := range []string{"P", "R", "A"} {
+		if v, err := strconv.Atoi(os.Getenv("TIMELINE_VISIT_" + k)); err == nil && v >= 0 {
+			c.Visit[k] = v
+		}
+	}
+	for _, mode := range []string{Walk, Car, Transit} {
+		env := "TIMELINE_" + strings.ToUpper(mode) + "_"
+		p := c.Profiles[mode]
+		if v, err := strconv.ParseFloat(os.Getenv(env+"KMH"), 64); err == nil && v > 0 {
+			p.SpeedKmh = v
+		}
+		if v, err := strconv.ParseFloat(os.Getenv(env+"DETOUR"), 64); err == nil && v >= 1 {
+			p.Detour = v
+		}
+		if v, err := strconv.Atoi(os.Getenv(env + "OVERHEAD")); err == nil && v >= 0 {
+			p.OverheadMin = v
+		}
+		if v, err := strconv.ParseFloat(os.Getenv(env+"MAX_KM"), 64); err == nil && v >= 0 {
+			p.MaxKm = v
+		}
+		c.Profiles[mode] = p
+	}
+	return c
+}
+
+// Mode แปลง Shortestpath.Type เป็น walk/car/transit
+func (c Config) Mode(typ string) string {
+	t := strings.ToLower(strings.TrimSpace(typ))
+	switch {
+	case t == Walk || t == "walking" || strings.Contains(t, "เดิน"):
+		return Walk
+	case t == Transit || t == "bus" || strings.Contains(t, "สองแถว") || strings.Contains(t, "รถเมล์") ||
+		strings.Contains(t, "รถสาธารณะ"):
+		return Transit
+	case t == Car || t == "drive" || t == "driving" || strings.Contains(t, "รถ"):
+		return Car
+	}
+	if c.DefaultMode != "" {
+		return c.DefaultMode
+	}
+	return Car
+}
+
+// VisitMin เวลาที่ใช้ ณ จุด code (ตาม prefix)
+func (c Config) VisitMin(code string) int {
+	if code == "" {
+		return 0
+	}
+	return c.Visit[strings.ToUpper(code[:1])]
+}
+
+// TravelMin เวลาเดินทาง (นาที) สำหรับระยะเส้นตรง km ด้วยประเภท typ (ดู Estimate)
+func (c Config) TravelMin(typ string, km float64) int {
+	return c.Estimate(typ, km).Minutes
+}
+
+// ------------------------------------------------------------
+// schedule
+// ------------------------------------------------------------
+
+// Leg หนึ่งช่วงของวัน: เดินทางระยะ DistanceKm ด้วย Type ไปยัง ToCode แล้วทำกิจกรรมที่นั่น
+type Leg struct {
+	ToCode     string
+	Type       string
+	DistanceKm float64
+}
+
+// Slot เวลาถึง (Start) และเวลาออก (End) ของจุดปลายทางของ leg (นาทีนับจากเที่ยงคืน)
+// พร้อมผลประเมินการเดินทางของ leg นั้น
+type Slot struct {
+	Start  int
+	End    int
+	Travel Estimate
+}
+
+// Schedule เรียงเวลาให้ทุก leg ของวันตามลำดับ เริ่มจาก DayStart
+func (c Config) Schedule(legs []Leg) []Slot {
+	return c.ScheduleFrom(c.DayStart, legs)
+}
+
+// ScheduleFrom เหมือน Schedule แต่กำหนดเวลาเริ่มเอง
+func (c Config) ScheduleFrom(start int, legs []Leg) []Slot {
+	out := make([]Slot, len(legs))
+	clock := start
+	for i, l := range legs {
+		est := c.Estimate(l.Type, l.DistanceKm)
+		arrive := clock + est.Minutes
+		out[i] = Slot{Start: arrive, End: arrive + c.VisitMin(l.ToCode), Travel: est}
+		clock = out[i].End
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package timeline
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMode(t *testing.T) {
+	c := DefaultConfig()
+	for typ, want := range map[string]string{
+		"เดิน": Walk, " Walking ": Walk, "รถสองแถว": Transit, "bus": Transit, "รถเมล์": Transit,
+		"รถยนต์": Car, "driving": Car, "Activity": Car, "": Car,
+	} {
+		if got := c.Mode(typ); got != want {
+			t.Errorf("Mode(%q) = %s ต้องการ %s", typ, got, want)
+		}
+	}
+	c.DefaultMode = Walk
+	if got := c.Mode("Activity"); got != Walk {
+		t.Errorf("Mode ใช้ DefaultMode: %s", got)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("TIMELINE_DAY_START", "08:30")
+	t.Setenv("TIMELINE_VISIT_P", "120")
+	t.Setenv("TIMELINE_VISIT_R", "-5") // ติดลบ → ไม่ใช้
+	t.Setenv("TIMELINE_CAR_DETOUR", "1.4")
+	t.Setenv("TIMELINE_CAR_OVERHEAD", "10")
+	t.Setenv("TIMELINE_WALK_MAX_KM", "1.5")
+	t.Setenv("TIMELINE_TRANSIT_KMH", "0")   // ≤ 0 → ไม่ใช้
+	t.Setenv("TIMELINE_WALK_DETOUR", "0.8") // < 1 → ไม่ใช้
+
+	c := FromEnv()
+	if c.DayStart != 510 || c.Visit["P"] != 120 || c.Visit["R"] != 60 {
+		t.Errorf("DayStart %d Visit %v", c.DayStart, c.Visit)
+	}
+	want := DefaultProfiles()
+	want[Car] = Profile{SpeedKmh: 25, Detour: 1.4, OverheadMin: 10}
+	want[Walk] = Profile{SpeedKmh: 4.5, Detour: 1.25, MaxKm: 1.5}
+	if !reflect.DeepEqual(c.Profiles, want) {
+		t.Errorf("Profiles = %+v ต้องการ %+v", c.Profiles, want)
+	}
+	// MaxKm ใหม่มีผลกับการแนะนำแบบ: เดิน 1.6 km เส้นตรง (2 km ถนน) เกินแล้ว
+	if est := c.Estimate(Walk, 1.6); !est.TooFar || est.SuggestMode != Car {
+		t.Errorf("Estimate หลัง env = %+v", est)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	c := DefaultConfig()
+	slots := c.Schedule([]Leg{
+		{ToCode: "P1", Type: "รถ", DistanceKm: 10},
+		{ToCode: "R1", Type: "เดิน", DistanceKm: 1},
+		{ToCode: "A1", Type: "รถ", DistanceKm: 0},
+	})
+	got := make([][2]int, len(slots))
+	for i, s := range slots {
+		got[i] = [2]int{s.Start, s.End}
+	}
+	// 09:00 + 38 นาที → เที่ยว 90 → เดิน 17 → กิน 60 → ที่พักไม่คิดเวลา
+	if want := [][2]int{{578, 668}, {685, 745}, {745, 745}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Schedule = %v ต้องการ %v", got, want)
+	}
+	if slots[1].Travel.Mode != Walk || slots[1].Travel.Minutes != 17 {
+		t.Errorf("Travel ของ leg 2 = %+v", slots[1].Travel)
+	}
+}
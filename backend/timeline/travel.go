@@ -0,0 +1,157 @@
+package timeline
+
+import (
+	"math"
+	"sort"
+)
+
+// ------------------------------------------------------------
+// โมเดลเวลาเดินทาง
+// ระยะที่ระบบมีคือระยะเส้นตรง (ST_DistanceSphere) จึงคูณ Detour เป็นระยะตามถนนก่อน
+// แล้วคิดเวลา = ระยะถนน / ความเร็ว + เวลาคงที่ (หาที่จอด/รอรถ)
+// ------------------------------------------------------------
+
+// Profile ค่าของการเดินทางหนึ่งแบบ
+type Profile struct {
+	SpeedKmh    float64 // ความเร็วเฉลี่ย
+	Detour      float64 // ตัวคูณระยะเส้นตรง → ระยะตามถนน (≥ 1)
+	OverheadMin int     // เวลาคงที่ต่อเที่ยว เช่น หาที่จอดรถ, รอสองแถว
+	MaxKm       float64 // ระยะถนนที่ยังเหมาะกับแบบนี้ (0 = ไม่จำกัด) เกินแล้วจะแนะนำแบบอื่น
+}
+
+// Estimate ผลประเมินของหนึ่งช่วง
+type Estimate struct {
+	Mode        string  `json:"mode"`
+	RoadKm      float64 `json:"road_km"`
+	Minutes     int     `json:"duration_min"`
+	TooFar      bool    `json:"too_far,omitempty"`      // ระยะถนนเกิน MaxKm ของแบบนี้
+	SuggestMode string  `json:"suggest_mode,omitempty"` // แบบที่ควรใช้แทน (เมื่อ TooFar)
+}
+
+// Model ตัวประเมินเวลาเดินทาง (เปลี่ยนเป็นแบบอื่นได้ผ่าน Config.Travel เช่นเวลาจาก routing engine)
+type Model interface {
+	Estimate(mode string, km float64) Estimate
+}
+
+// ProfileModel โมเดลพื้นฐาน: หนึ่ง Profile ต่อแบบการเดินทาง
+type ProfileModel map[string]Profile
+
+// DefaultProfiles เดิน 4.5 km/h (ไม่เกิน 2 km), รถ 25 km/h + จอด 5 นาที, รถสาธารณะ 18 km/h + รอ 10 นาที
+func DefaultProfiles() ProfileModel {
+	return ProfileModel{
+		Walk:    {SpeedKmh: 4.5, Detour: 1.25, MaxKm: 2},
+		Car:     {SpeedKmh: 25, Detour: 1.35, OverheadMin: 5},
+		Transit: {SpeedKmh: 18, Detour: 1.3, OverheadMin: 10},
+	}
+}
+
+func (m ProfileModel) profile(mode string) Profile {
+	p, ok := m[mode]
+	if !ok || p.SpeedKmh <= 0 {
+		p = DefaultProfiles()[Car]
+	}
+	if p.Detour < 1 {
+		p.Detour = 1
+	}
+	return p
+}
+
+func (m ProfileModel) minutes(p Profile, km float64) (float64, int) {
+	road := km * p.Detour
+	return road, int(math.Ceil(road/p.SpeedKmh*60)) + p.OverheadMin
+}
+
+func (m ProfileModel) Estimate(mode string, km float64) Estimate {
+	if km <= 0 {
+		return Estimate{Mode: mode}
+	}
+	p := m.profile(mode)
+	road, mins := m.minutes(p, km)
+	est := Estimate{Mode: mode, RoadKm: math.Round(road*100) / 100, Minutes: mins}
+
+	// ไกลเกินแบบที่เลือก → แนะนำแบบที่เร็วสุดที่ระยะนี้ยังเหมาะ
+	if p.MaxKm > 0 && road > p.MaxKm {
+		est.TooFar = true
+		modes := make([]string, 0, len(m))
+		for k := range m {
+			modes = append(modes, k)
+		}
+		sort.Strings(modes)
+		bestMin := math.MaxInt
+		for _, k := range modes {
+			q := m.profile(k)
+			if k == mode || q.SpeedKmh <= 0 {
+				continue
+			}
+			r, t := m.minutes(q, km)
+			if (q.MaxKm == 0 || r <= q.MaxKm) && t < bestMin {
+				est.SuggestMode, bestMin = k, t
+			}
+		}
+	}
+	return est
+}
+
+// Fits ระยะเส้นตรง km ยังเหมาะกับ mode หรือไม่ (ใช้กับ "เดินอย่างเดียว")
+func (c Config) Fits(typ string, km float64) bool {
+	return !c.Estimate(typ, km).TooFar
+}
+
+// Estimate ประเมินช่วงระยะเส้นตรง km ด้วยประเภท typ (เดิน/รถ/...) ผ่าน Config.Travel
+func (c Config) Estimate(typ string, km float64) Estimate {
+	mode := c.Mode(typ)
+	if c.Travel != nil {
+		return c.Travel.Estimate(mode, km)
+	}
+	if c.Profiles != nil {
+		return c.Profiles.Estimate(mode, km)
+	}
+	return DefaultProfiles().Estimate(mode, km)
+}
+
+// EstimateRoad เหมือน Estimate แต่ roadKm เป็นระยะตามถนนจริงแล้ว (เช่นจากโครงข่าย OSM) จึงไม่คูณ Detour ซ้ำ
+func (c Config) EstimateRoad(typ string, roadKm float64) Estimate {
+	profiles := c.Profiles
+	if profiles == nil {
+		profiles = DefaultProfiles()
+	}
+	est := c.Estimate(typ, roadKm/profiles.profile(c.Mode(typ)).Detour)
+	if roadKm > 0 {
+		est.RoadKm = math.Round(roadKm*100) / 100
+	}
+	return est
+}
+
+// maxReachKm เพดานการค้นระยะของ ReachKm (กันวนไม่จบเมื่อโมเดลไม่คิดเวลาตามระยะ)
+const maxReachKm = 2000
+
+// ReachKm ระยะเส้นตรงไกลสุด (km) ที่เดินทางด้วย typ ได้ภายใน minutes นาที ตามโมเดลเดียวกับ Estimate
+// (0 = ไม่ทันแม้ระยะใกล้ ๆ เช่น minutes น้อยกว่าเวลาคงที่ของแบบนั้น)
+func (c Config) ReachKm(typ string, minutes int) float64 {
+	return reachKm(func(km float64) int { return c.Estimate(typ, km).Minutes }, minutes)
+}
+
+// ReachRoadKm เหมือน ReachKm แต่เป็นระยะตามถนน (ใช้กับโครงข่ายถนนจริง ดู EstimateRoad)
+func (c Config) ReachRoadKm(typ string, minutes int) float64 {
+	return reachKm(func(km float64) int { return c.EstimateRoad(typ, km).Minutes }, minutes)
+}
+
+// reachKm ค้นแบบ binary search หา km มากสุดที่ minutesAt(km) ≤ minutes (เวลาไม่ลดลงเมื่อระยะเพิ่ม)
+func reachKm(minutesAt func(km float64) int, minutes int) float64 {
+	if minutes <= 0 || minutesAt(0.001) > minutes {
+		return 0
+	}
+	lo, hi := 0.0, 1.0
+	for hi < maxReachKm && minutesAt(hi) <= minutes {
+		lo, hi = hi, hi*2
+	}
+	for i := 0; i < 40; i++ {
+		mid := (lo + hi) / 2
+		if minutesAt(mid) <= minutes {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo
+}
@@ -0,0 +1,103 @@
+package timeline
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProfileEstimate(t *testing.T) {
+	custom := DefaultProfiles()
+	custom[Car] = Profile{SpeedKmh: 25, Detour: 1.35, OverheadMin: 5, MaxKm: 2}
+	boat := ProfileModel{"boat": {SpeedKmh: 60, Detour: 0.5}} // Detour < 1 → ใช้ 1
+
+	tests := []struct {
+		name  string
+		model ProfileModel
+		mode  string
+		km    float64
+		want  Estimate
+	}{
+		{"ระยะ 0", DefaultProfiles(), Car, 0, Estimate{Mode: Car}},
+		// 10 × 1.35 = 13.5 km ถนน → ceil(32.4) + จอด 5
+		{"รถ: detour + overhead", DefaultProfiles(), Car, 10, Estimate{Mode: Car, RoadKm: 13.5, Minutes: 38}},
+		{"รถสาธารณะ: รอ 10 นาที", DefaultProfiles(), Transit, 1, Estimate{Mode: Transit, RoadKm: 1.3, Minutes: 15}},
+		{"เดินไม่เกิน MaxKm", DefaultProfiles(), Walk, 1.6, Estimate{Mode: Walk, RoadKm: 2, Minutes: 27}},
+		// 2 × 1.25 = 2.5 km > 2 → แนะนำแบบที่เร็วสุดในระยะนี้ (รถ 12 นาที < รถสาธารณะ 19 นาที)
+		{"เดินไกลเกิน → รถ", DefaultProfiles(), Walk, 2, Estimate{Mode: Walk, RoadKm: 2.5, Minutes: 34, TooFar: true, SuggestMode: Car}},
+		// รถก็เกิน MaxKm ของตัวเอง → เหลือรถสาธารณะ
+		{"ข้ามแบบที่เกิน MaxKm", custom, Walk, 2, Estimate{Mode: Walk, RoadKm: 2.5, Minutes: 34, TooFar: true, SuggestMode: Transit}},
+		{"detour ต่ำกว่า 1", boat, "boat", 60, Estimate{Mode: "boat", RoadKm: 60, Minutes: 60}},
+		// แบบที่ไม่รู้จักใช้ค่าของรถ
+		{"ไม่รู้จักแบบ", DefaultProfiles(), "horse", 10, Estimate{Mode: "horse", RoadKm: 13.5, Minutes: 38}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.model.Estimate(tt.mode, tt.km); got != tt.want {
+				t.Errorf("Estimate(%s, %g) = %+v ต้องการ %+v", tt.mode, tt.km, got, tt.want)
+			}
+		})
+	}
+}
+
+// fixedModel ทุกช่วงใช้เวลาเท่ากัน (เช่นโมเดลภายนอกที่ไม่คิดตามระยะ)
+type fixedModel int
+
+func (m fixedModel) Estimate(mode string, km float64) Estimate {
+	return Estimate{Mode: mode, Minutes: int(m)}
+}
+
+func TestConfigEstimate(t *testing.T) {
+	c := DefaultConfig()
+	if got := c.Estimate("เดิน", 1); got.Mode != Walk || got.Minutes != 17 {
+		t.Errorf("เดิน 1 km = %+v ต้องการ walk 17 นาที", got)
+	}
+	if !c.Fits("walk", 1.6) || c.Fits("walk", 1.7) {
+		t.Errorf("Fits: เดินได้ถึง 1.6 km เส้นตรง (2 km ตามถนน)")
+	}
+	if got := c.TravelMin("Activity", 10); got != 38 {
+		t.Errorf("ประเภทอื่นใช้ DefaultMode (รถ): %d นาที", got)
+	}
+
+	// ระยะตามถนนจริงไม่คูณ detour ซ้ำ
+	if got := c.EstimateRoad(Car, 13.5); got.RoadKm != 13.5 || got.Minutes != 38 {
+		t.Errorf("EstimateRoad = %+v ต้องการ 13.5 km / 38 นาที", got)
+	}
+	if got := c.EstimateRoad(Walk, 2.5); !got.TooFar || got.SuggestMode != Car {
+		t.Errorf("EstimateRoad เดิน 2.5 km ถนน = %+v ต้องแนะนำรถ", got)
+	}
+
+	c.Travel = fixedModel(7)
+	if got := c.TravelMin("รถ", 100); got != 7 {
+		t.Errorf("Config.Travel ต้องแทน Profiles: %d", got)
+	}
+}
+
+func TestReachKm(t *testing.T) {
+	c := DefaultConfig()
+	tests := []struct {
+		name    string
+		got     float64
+		want    float64
+		epsilon float64
+	}{
+		// ceil(km × 1.35 / 25 × 60) + 5 ≤ 38 → km ≤ 33 / 3.24
+		{"รถ 38 นาที", c.ReachKm(Car, 38), 33 / 3.24, 1e-6},
+		{"เดิน 30 นาที", c.ReachKm(Walk, 30), 30 / (1.25 / 4.5 * 60), 1e-6},
+		{"ไม่ถึงเวลาจอด", c.ReachKm(Car, 5), 0, 0},
+		{"ระยะตามถนน", c.ReachRoadKm(Car, 38), 33 / 60.0 * 25, 1e-6},
+	}
+	for _, tt := range tests {
+		if math.Abs(tt.got-tt.want) > tt.epsilon {
+			t.Errorf("%s = %v ต้องการ %v", tt.name, tt.got, tt.want)
+		}
+	}
+	// ระยะที่ได้ต้องเดินทางทันจริง
+	if m := c.Estimate(Car, c.ReachKm(Car, 38)).Minutes; m > 38 {
+		t.Errorf("ReachKm ให้ระยะที่ใช้ %d นาที", m)
+	}
+
+	c.Travel = fixedModel(0)
+	if got := c.ReachKm(Car, 10); got < maxReachKm || got > 2*maxReachKm {
+		t.Errorf("โมเดลที่ไม่คิดตามระยะต้องหยุดที่เพดาน: %v", got)
+	}
+}